@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE authorization_model_last_used (
+    store CHAR(26) NOT NULL,
+    authorization_model_id CHAR(26) NOT NULL,
+    last_used_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (store, authorization_model_id)
+);
+
+-- +goose Down
+DROP TABLE authorization_model_last_used;
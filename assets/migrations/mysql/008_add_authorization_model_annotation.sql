@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE authorization_model_annotation (
+    store CHAR(26) NOT NULL,
+    authorization_model_id CHAR(26) NOT NULL,
+    annotations MEDIUMBLOB,
+    PRIMARY KEY (store, authorization_model_id)
+);
+
+-- +goose Down
+DROP TABLE authorization_model_annotation;
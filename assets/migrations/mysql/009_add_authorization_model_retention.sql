@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE authorization_model_retention (
+    id INT NOT NULL,
+    use_recorded_since TIMESTAMP NULL,
+    use_recorded_at TIMESTAMP NULL,
+    lease_holder VARCHAR(64),
+    lease_expires_at TIMESTAMP NULL,
+    PRIMARY KEY (id)
+);
+
+INSERT INTO authorization_model_retention (id) VALUES (1);
+
+-- +goose Down
+DROP TABLE authorization_model_retention;
@@ -0,0 +1,9 @@
+-- +goose Up
+CREATE TABLE active_authorization_model (
+	store TEXT PRIMARY KEY,
+	authorization_model_id TEXT NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL
+);
+
+-- +goose Down
+DROP TABLE active_authorization_model;
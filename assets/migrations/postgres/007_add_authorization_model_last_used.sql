@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE authorization_model_last_used (
+	store TEXT NOT NULL,
+	authorization_model_id TEXT NOT NULL,
+	last_used_at TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY (store, authorization_model_id)
+);
+
+-- +goose Down
+DROP TABLE authorization_model_last_used;
@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE authorization_model_annotation (
+	store TEXT NOT NULL,
+	authorization_model_id TEXT NOT NULL,
+	annotations BYTEA,
+	PRIMARY KEY (store, authorization_model_id)
+);
+
+-- +goose Down
+DROP TABLE authorization_model_annotation;
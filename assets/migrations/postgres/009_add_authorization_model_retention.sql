@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE authorization_model_retention (
+	id INTEGER NOT NULL,
+	use_recorded_since TIMESTAMPTZ,
+	use_recorded_at TIMESTAMPTZ,
+	lease_holder TEXT,
+	lease_expires_at TIMESTAMPTZ,
+	PRIMARY KEY (id)
+);
+
+INSERT INTO authorization_model_retention (id) VALUES (1);
+
+-- +goose Down
+DROP TABLE authorization_model_retention;
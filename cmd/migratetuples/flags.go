@@ -0,0 +1,24 @@
+package migratetuples
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/openfga/openfga/cmd/util"
+)
+
+// bindRunFlagsFunc binds the cobra cmd flags to the equivalent config value being managed
+// by viper. This bridges the config between cobra flags and viper flags.
+func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
+	return func(cmd *cobra.Command, args []string) {
+		util.MustBindPFlag(datastoreEngineFlag, flags.Lookup(datastoreEngineFlag))
+		util.MustBindPFlag(datastoreURIFlag, flags.Lookup(datastoreURIFlag))
+		util.MustBindPFlag(storeIDFlag, flags.Lookup(storeIDFlag))
+		util.MustBindPFlag(fromModelIDFlag, flags.Lookup(fromModelIDFlag))
+		util.MustBindPFlag(toModelIDFlag, flags.Lookup(toModelIDFlag))
+		util.MustBindPFlag(mappingFlag, flags.Lookup(mappingFlag))
+		util.MustBindPFlag(dryRunFlag, flags.Lookup(dryRunFlag))
+		util.MustBindPFlag(batchSizeFlag, flags.Lookup(batchSizeFlag))
+		util.MustBindPFlag(continuationTokenFlag, flags.Lookup(continuationTokenFlag))
+	}
+}
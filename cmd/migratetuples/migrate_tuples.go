@@ -0,0 +1,200 @@
+// Package migratetuples contains the command to migrate the relationship tuples of a store between authorization models.
+package migratetuples
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/mysql"
+	"github.com/openfga/openfga/pkg/storage/postgres"
+	"github.com/openfga/openfga/pkg/storage/sqlcommon"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+const (
+	datastoreEngineFlag   = "datastore-engine"
+	datastoreURIFlag      = "datastore-uri"
+	storeIDFlag           = "store-id"
+	fromModelIDFlag       = "from-model-id"
+	toModelIDFlag         = "to-model-id"
+	mappingFlag           = "mapping"
+	dryRunFlag            = "dry-run"
+	batchSizeFlag         = "batch-size"
+	continuationTokenFlag = "continuation-token"
+)
+
+func NewMigrateTuplesCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate-tuples",
+		Short: "Migrate the relationship tuples of a store between authorization models. NOTE: this command is in beta and may be removed in future releases.",
+		Long: `Rewrite the relationship tuples of a store written under an authorization model into tuples of another model, according to a mapping file renaming relations, moving types and splitting relations, e.g.
+
+renameRelations:
+  - type: document
+    from: viewer
+    to: reader
+moveTypes:
+  - from: team
+    to: group
+splitRelations:
+  - type: document
+    from: editor
+    to: [writer, commenter]
+
+The types and relations of the mapping are named as in the model migrated from. The tuples are rewritten in batches, and the command outputs a report of the migration which includes a continuation token to resume it from if it fails.
+NOTE: this command is in beta and may be removed in future releases.`,
+		RunE: runMigrateTuples,
+		Args: cobra.NoArgs,
+	}
+
+	flags := cmd.Flags()
+	flags.String(datastoreEngineFlag, "", "the datastore engine")
+	flags.String(datastoreURIFlag, "", "the connection uri to the datastore")
+	flags.String(storeIDFlag, "", "the id of the store whose tuples are migrated")
+	flags.String(fromModelIDFlag, "", "the id of the authorization model the tuples are migrated from")
+	flags.String(toModelIDFlag, "", "the id of the authorization model the tuples are migrated to")
+	flags.String(mappingFlag, "", "the path of the YAML (or JSON) file of the mapping of the tuples")
+	flags.Bool(dryRunFlag, false, "report the tuples that would be rewritten without rewriting them")
+	flags.Int(batchSizeFlag, 100, "the number of tuples read, and rewritten, at once")
+	flags.String(continuationTokenFlag, "", "the continuation token of a previous migration to resume it")
+
+	// NOTE: if you add a new flag here, update the function below, too
+
+	cmd.PreRun = bindRunFlagsFunc(flags)
+
+	return cmd
+}
+
+func runMigrateTuples(_ *cobra.Command, _ []string) error {
+	engine := viper.GetString(datastoreEngineFlag)
+	uri := viper.GetString(datastoreURIFlag)
+
+	var (
+		db  storage.OpenFGADatastore
+		err error
+	)
+	switch engine {
+	case "mysql":
+		db, err = mysql.New(uri, sqlcommon.NewConfig())
+	case "postgres":
+		db, err = postgres.New(uri, sqlcommon.NewConfig())
+	case "":
+		return fmt.Errorf("missing datastore engine type")
+	case "memory":
+		fallthrough
+	default:
+		return fmt.Errorf("storage engine '%s' is unsupported", engine)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to open a connection to the datastore: %v", err)
+	}
+	defer db.Close()
+
+	mapping, err := ReadTupleMapping(viper.GetString(mappingFlag))
+	if err != nil {
+		return err
+	}
+
+	report, migrateErr := MigrateTuples(
+		context.Background(),
+		db,
+		viper.GetString(storeIDFlag),
+		viper.GetString(fromModelIDFlag),
+		viper.GetString(toModelIDFlag),
+		viper.GetString(continuationTokenFlag),
+		mapping,
+		typesystem.WithTupleMigrationDryRun(viper.GetBool(dryRunFlag)),
+		typesystem.WithTupleMigrationBatchSize(viper.GetInt(batchSizeFlag)),
+	)
+	if report != nil {
+		marshalled, err := json.MarshalIndent(report, " ", "    ")
+		if err != nil {
+			return fmt.Errorf("error gathering migration report: %w", err)
+		}
+		fmt.Println(string(marshalled))
+	}
+
+	return migrateErr
+}
+
+// ReadTupleMapping reads a tuple mapping from a YAML (or JSON) file.
+func ReadTupleMapping(path string) (*typesystem.TupleMapping, error) {
+	if path == "" {
+		return nil, fmt.Errorf("missing tuple mapping file")
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the tuple mapping file: %w", err)
+	}
+
+	var mapping typesystem.TupleMapping
+	if err := yaml.UnmarshalStrict(contents, &mapping); err != nil {
+		return nil, fmt.Errorf("failed to parse the tuple mapping file: %w", err)
+	}
+
+	return &mapping, nil
+}
+
+// MigrateTuples migrates the tuples of a store from an authorization model to another according to a mapping,
+// resuming from a continuation token if set. The report is returned even if the migration fails, so that
+// it can be resumed from the continuation token of the report.
+func MigrateTuples(
+	ctx context.Context,
+	db storage.OpenFGADatastore,
+	storeID, fromModelID, toModelID, continuationToken string,
+	mapping *typesystem.TupleMapping,
+	opts ...typesystem.TupleMigratorOption,
+) (*typesystem.TupleMigrationReport, error) {
+	if storeID == "" || fromModelID == "" || toModelID == "" {
+		return nil, fmt.Errorf("missing store id, or authorization model id to migrate from or to")
+	}
+
+	from, err := readTypesystem(ctx, db, storeID, fromModelID)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := readTypesystem(ctx, db, storeID, toModelID)
+	if err != nil {
+		return nil, err
+	}
+
+	migrator, err := typesystem.NewTupleMigrator(db, storeID, from, to, mapping, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := migrator.Migrate(ctx, continuationToken)
+	if err != nil {
+		return report, fmt.Errorf("error migrating tuples: %w", err)
+	}
+
+	return report, nil
+}
+
+func readTypesystem(ctx context.Context, db storage.OpenFGADatastore, storeID, modelID string) (*typesystem.TypeSystem, error) {
+	model, err := db.ReadAuthorizationModel(ctx, storeID, modelID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, fmt.Errorf("authorization model '%s' not found", modelID)
+		}
+		return nil, fmt.Errorf("error reading authorization model '%s': %w", modelID, err)
+	}
+
+	typesys, err := typesystem.NewAndValidate(ctx, model)
+	if err != nil {
+		return nil, fmt.Errorf("invalid authorization model '%s': %w", modelID, err)
+	}
+
+	return typesys, nil
+}
@@ -0,0 +1,185 @@
+package migratetuples
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/cmd"
+	"github.com/openfga/openfga/cmd/util"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+func writeMappingFile(t *testing.T, contents string) string {
+	path := filepath.Join(t.TempDir(), "mapping.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+	return path
+}
+
+func TestReadTupleMapping(t *testing.T) {
+	t.Run("yaml", func(t *testing.T) {
+		mapping, err := ReadTupleMapping(writeMappingFile(t, `
+renameRelations:
+  - type: document
+    from: viewer
+    to: reader
+moveTypes:
+  - from: team
+    to: group
+splitRelations:
+  - type: document
+    from: editor
+    to: [writer, commenter]
+`))
+		require.NoError(t, err)
+		require.Equal(t, &typesystem.TupleMapping{
+			RenameRelations: []typesystem.RenameRelation{{Type: "document", From: "viewer", To: "reader"}},
+			MoveTypes:       []typesystem.MoveType{{From: "team", To: "group"}},
+			SplitRelations:  []typesystem.SplitRelation{{Type: "document", From: "editor", To: []string{"writer", "commenter"}}},
+		}, mapping)
+	})
+
+	t.Run("unknown_fields", func(t *testing.T) {
+		_, err := ReadTupleMapping(writeMappingFile(t, `
+renameTypes:
+  - from: team
+    to: group
+`))
+		require.ErrorContains(t, err, "failed to parse the tuple mapping file")
+	})
+
+	t.Run("missing_file", func(t *testing.T) {
+		_, err := ReadTupleMapping("")
+		require.ErrorContains(t, err, "missing tuple mapping file")
+
+		_, err = ReadTupleMapping(filepath.Join(t.TempDir(), "missing.yaml"))
+		require.ErrorContains(t, err, "failed to read the tuple mapping file")
+	})
+}
+
+func TestMigrateTuples(t *testing.T) {
+	ctx := context.Background()
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID := ulid.Make().String()
+
+	from := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type document
+			relations
+				define viewer: [user]`)
+	require.NoError(t, ds.WriteAuthorizationModel(ctx, storeID, from))
+
+	to := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type document
+			relations
+				define reader: [user]`)
+	require.NoError(t, ds.WriteAuthorizationModel(ctx, storeID, to))
+
+	require.NoError(t, ds.Write(ctx, storeID, nil, tuple.MustParseTupleStrings(
+		"document:1#viewer@user:anne",
+		"document:2#viewer@user:bob",
+	)))
+
+	mapping := &typesystem.TupleMapping{
+		RenameRelations: []typesystem.RenameRelation{{Type: "document", From: "viewer", To: "reader"}},
+	}
+
+	t.Run("missing_ids", func(t *testing.T) {
+		_, err := MigrateTuples(ctx, ds, storeID, "", to.GetId(), "", mapping)
+		require.ErrorContains(t, err, "missing store id")
+	})
+
+	t.Run("model_not_found", func(t *testing.T) {
+		modelID := ulid.Make().String()
+		_, err := MigrateTuples(ctx, ds, storeID, modelID, to.GetId(), "", mapping)
+		require.ErrorContains(t, err, "authorization model '"+modelID+"' not found")
+	})
+
+	t.Run("invalid_mapping", func(t *testing.T) {
+		_, err := MigrateTuples(ctx, ds, storeID, from.GetId(), to.GetId(), "", &typesystem.TupleMapping{
+			MoveTypes: []typesystem.MoveType{{From: "folder", To: "group"}},
+		})
+		require.ErrorIs(t, err, typesystem.ErrInvalidTupleMapping)
+	})
+
+	t.Run("dry_run_then_migrate", func(t *testing.T) {
+		report, err := MigrateTuples(ctx, ds, storeID, from.GetId(), to.GetId(), "", mapping, typesystem.WithTupleMigrationDryRun(true))
+		require.NoError(t, err)
+		require.Equal(t, 2, report.Migrated)
+
+		report, err = MigrateTuples(ctx, ds, storeID, from.GetId(), to.GetId(), "", mapping, typesystem.WithTupleMigrationBatchSize(1))
+		require.NoError(t, err)
+		require.Equal(t, 2, report.Migrated)
+
+		tuples, _, err := ds.ReadPage(ctx, storeID, &openfgav1.TupleKey{}, storage.PaginationOptions{PageSize: 10})
+		require.NoError(t, err)
+
+		var keys []string
+		for _, t := range tuples {
+			keys = append(keys, tuple.TupleKeyToString(t.GetKey()))
+		}
+		require.ElementsMatch(t, []string{"document:1#reader@user:anne", "document:2#reader@user:bob"}, keys)
+	})
+}
+
+func TestMigrateTuplesCommandWhenInvalidEngine(t *testing.T) {
+	for _, tc := range []struct {
+		engine        string
+		errorExpected string
+	}{
+		{
+			engine:        "memory",
+			errorExpected: "storage engine 'memory' is unsupported",
+		},
+		{
+			engine:        "",
+			errorExpected: "missing datastore engine type",
+		},
+	} {
+		t.Run(tc.engine, func(t *testing.T) {
+			migrateTuplesCommand := NewMigrateTuplesCommand()
+			migrateTuplesCommand.SetArgs([]string{"--datastore-engine", tc.engine, "--datastore-uri", ""})
+			err := migrateTuplesCommand.Execute()
+			require.ErrorContains(t, err, tc.errorExpected)
+		})
+	}
+}
+
+func TestMigrateTuplesCommandNoConfigDefaultValues(t *testing.T) {
+	util.PrepareTempConfigDir(t)
+	migrateTuplesCommand := NewMigrateTuplesCommand()
+	migrateTuplesCommand.RunE = func(cmd *cobra.Command, _ []string) error {
+		require.Equal(t, "", viper.GetString(datastoreEngineFlag))
+		require.Equal(t, "", viper.GetString(datastoreURIFlag))
+		require.Equal(t, "", viper.GetString(storeIDFlag))
+		require.Equal(t, "", viper.GetString(mappingFlag))
+		require.False(t, viper.GetBool(dryRunFlag))
+		require.Equal(t, 100, viper.GetInt(batchSizeFlag))
+		require.Equal(t, "", viper.GetString(continuationTokenFlag))
+		return nil
+	}
+
+	cmd := cmd.NewRootCommand()
+	cmd.AddCommand(migrateTuplesCommand)
+	cmd.SetArgs([]string{"migrate-tuples"})
+	require.NoError(t, cmd.Execute())
+}
@@ -0,0 +1,109 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+const (
+	typeFlag     = "type"
+	relationFlag = "relation"
+)
+
+func NewCostCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cost",
+		Short: "Estimate the worst-case cost of the Check and ListObjects queries on the relations of an authorization model",
+		Long: `Validate an authorization model, written in the DSL (.fga), in JSON (.json) or as modules listed by an fga.mod file, and estimate for each of its relations, from the model only:
+
+- the maximum number of nested dispatches of a Check,
+- whether the relation is or refers to a recursive relation,
+- the kinds of datastore reads of a Check,
+- whether intersections or exclusions are involved, and
+- the types of the users for which ListObjects needs to Check each object found.
+
+The costs are output as JSON.`,
+		RunE: runCost,
+		Args: cobra.NoArgs,
+	}
+
+	flags := cmd.Flags()
+	flags.String(fileFlag, "", "the path of the authorization model file, in the DSL (.fga), in JSON (.json) or the fga.mod file of a modular model")
+	flags.String(typeFlag, "", "the object type whose relations are estimated (defaults to all types)")
+	flags.String(relationFlag, "", "the relation of the object type which is estimated (defaults to all relations of the type)")
+
+	// NOTE: if you add a new flag here, update the function below, too
+
+	cmd.PreRun = bindCostFlagsFunc(flags)
+
+	return cmd
+}
+
+func runCost(_ *cobra.Command, _ []string) error {
+	model, err := ReadAuthorizationModel(viper.GetString(fileFlag))
+	if err != nil {
+		return err
+	}
+
+	costs, err := EstimateQueryCosts(context.Background(), model, viper.GetString(typeFlag), viper.GetString(relationFlag))
+	if err != nil {
+		return err
+	}
+
+	marshalled, err := json.MarshalIndent(costs, " ", "    ")
+	if err != nil {
+		return fmt.Errorf("error gathering query costs: %w", err)
+	}
+	fmt.Println(string(marshalled))
+
+	return nil
+}
+
+// EstimateQueryCosts validates an authorization model and returns the costs of the Check and ListObjects queries on
+// every relation of the model if the object type is empty, on every relation of the object type if the relation is
+// empty, or else on the relation only.
+func EstimateQueryCosts(ctx context.Context, model *openfgav1.AuthorizationModel, objectType, relation string) ([]*typesystem.RelationCost, error) {
+	typesys, err := typesystem.NewAndValidate(ctx, model)
+	if err != nil {
+		return nil, fmt.Errorf("invalid authorization model: %w", err)
+	}
+
+	g := graph.New(typesys)
+
+	if objectType != "" && relation != "" {
+		cost, err := g.EstimateCost(objectType, relation)
+		if err != nil {
+			return nil, err
+		}
+
+		return []*typesystem.RelationCost{cost}, nil
+	}
+
+	if objectType != "" {
+		if _, ok := typesys.GetTypeDefinition(objectType); !ok {
+			return nil, fmt.Errorf("type '%s' not found", objectType)
+		}
+	}
+
+	costs, err := g.EstimateCosts()
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]*typesystem.RelationCost, 0, len(costs))
+	for _, cost := range costs {
+		if objectType == "" || cost.Type == objectType {
+			filtered = append(filtered, cost)
+		}
+	}
+
+	return filtered, nil
+}
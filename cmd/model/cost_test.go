@@ -0,0 +1,90 @@
+package model
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/cmd"
+	"github.com/openfga/openfga/cmd/util"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+const estimatedModel = `model
+  schema 1.1
+type user
+type group
+  relations
+    define member: [user, group#member]
+type document
+  relations
+    define allowed: [user]
+    define viewer: [group#member] and allowed`
+
+func TestEstimateQueryCosts(t *testing.T) {
+	model, err := ReadAuthorizationModel(writeModelFile(t, "model.fga", estimatedModel))
+	require.NoError(t, err)
+
+	t.Run("model", func(t *testing.T) {
+		costs, err := EstimateQueryCosts(context.Background(), model, "", "")
+		require.NoError(t, err)
+		require.Len(t, costs, 3)
+	})
+
+	t.Run("type", func(t *testing.T) {
+		costs, err := EstimateQueryCosts(context.Background(), model, "document", "")
+		require.NoError(t, err)
+		require.Len(t, costs, 2)
+	})
+
+	t.Run("relation", func(t *testing.T) {
+		costs, err := EstimateQueryCosts(context.Background(), model, "document", "viewer")
+		require.NoError(t, err)
+		require.Len(t, costs, 1)
+		require.Equal(t, 1, costs[0].MaxDispatchDepth)
+		require.True(t, costs[0].HasCycle)
+		require.True(t, costs[0].HasIntersection)
+		require.Equal(t, []string{"user"}, costs[0].ListObjectsCheckFallbackUserTypes)
+	})
+
+	t.Run("not_found", func(t *testing.T) {
+		_, err := EstimateQueryCosts(context.Background(), model, "folder", "")
+		require.ErrorContains(t, err, "type 'folder' not found")
+
+		_, err = EstimateQueryCosts(context.Background(), model, "document", "editor")
+		require.ErrorIs(t, err, typesystem.ErrRelationUndefined)
+	})
+}
+
+func TestCostCommand(t *testing.T) {
+	costCommand := NewCostCommand()
+	costCommand.SetArgs([]string{"--file", writeModelFile(t, "model.fga", estimatedModel), "--type", "document", "--relation", "viewer"})
+	require.NoError(t, costCommand.Execute())
+
+	costCommand = NewCostCommand()
+	costCommand.SetArgs([]string{"--file", ""})
+	require.ErrorContains(t, costCommand.Execute(), "missing authorization model file")
+}
+
+func TestCostCommandNoConfigDefaultValues(t *testing.T) {
+	util.PrepareTempConfigDir(t)
+	costCommand := NewCostCommand()
+	costCommand.RunE = func(cmd *cobra.Command, _ []string) error {
+		require.Equal(t, "", viper.GetString(fileFlag))
+		require.Equal(t, "", viper.GetString(typeFlag))
+		require.Equal(t, "", viper.GetString(relationFlag))
+		return nil
+	}
+
+	modelCommand := NewModelCommand()
+	modelCommand.RemoveCommand(modelCommand.Commands()...)
+	modelCommand.AddCommand(costCommand)
+
+	cmd := cmd.NewRootCommand()
+	cmd.AddCommand(modelCommand)
+	cmd.SetArgs([]string{"model", "cost"})
+	require.NoError(t, cmd.Execute())
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/openfga/openfga/cmd/util"
+)
+
+// bindLintFlagsFunc binds the cobra cmd flags to the equivalent config value being managed
+// by viper. This bridges the config between cobra flags and viper flags.
+func bindLintFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
+	return func(cmd *cobra.Command, args []string) {
+		util.MustBindPFlag(fileFlag, flags.Lookup(fileFlag))
+		util.MustBindPFlag(maxTupleToUsersetDepthFlag, flags.Lookup(maxTupleToUsersetDepthFlag))
+		util.MustBindPFlag(sensitiveRelationsFlag, flags.Lookup(sensitiveRelationsFlag))
+		util.MustBindPFlag(failOnWarningsFlag, flags.Lookup(failOnWarningsFlag))
+	}
+}
+
+// bindCostFlagsFunc binds the cobra cmd flags to the equivalent config value being managed
+// by viper. This bridges the config between cobra flags and viper flags.
+func bindCostFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
+	return func(cmd *cobra.Command, args []string) {
+		util.MustBindPFlag(fileFlag, flags.Lookup(fileFlag))
+		util.MustBindPFlag(typeFlag, flags.Lookup(typeFlag))
+		util.MustBindPFlag(relationFlag, flags.Lookup(relationFlag))
+	}
+}
@@ -0,0 +1,155 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	parser "github.com/openfga/language/pkg/go/transformer"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+const (
+	fileFlag                   = "file"
+	maxTupleToUsersetDepthFlag = "max-tuple-to-userset-depth"
+	sensitiveRelationsFlag     = "sensitive-relations"
+	failOnWarningsFlag         = "fail-on-warnings"
+
+	// modFileName is the name of the file listing the modules of a modular authorization model.
+	modFileName = "fga.mod"
+)
+
+func NewLintCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Report warnings about an authorization model which may be slow to resolve or may not model what was intended",
+		Long: `Validate an authorization model, written in the DSL (.fga), in JSON (.json) or as modules listed by an fga.mod file, and report warnings about:
+
+- relations which follow a deep chain of tuple to userset rewrites,
+- intersections or exclusions over recursive relations,
+- unused types,
+- relations which no user can ever be related to,
+- typed wildcards on relations whose name suggests they grant sensitive permissions, and
+- conditions which no relation refers to.
+
+The warnings are output as JSON.`,
+		RunE: runLint,
+		Args: cobra.NoArgs,
+	}
+
+	flags := cmd.Flags()
+	flags.String(fileFlag, "", "the path of the authorization model file, in the DSL (.fga), in JSON (.json) or the fga.mod file of a modular model")
+	flags.Int(maxTupleToUsersetDepthFlag, typesystem.DefaultLintMaxTupleToUsersetDepth, "the length of the chains of tuple to userset rewrites above which relations are reported")
+	flags.StringSlice(sensitiveRelationsFlag, typesystem.DefaultLintSensitiveRelations, "the words of the names of the relations on which typed wildcards are reported")
+	flags.Bool(failOnWarningsFlag, false, "exit with an error if warnings are reported")
+
+	// NOTE: if you add a new flag here, update the function below, too
+
+	cmd.PreRun = bindLintFlagsFunc(flags)
+
+	return cmd
+}
+
+func runLint(_ *cobra.Command, _ []string) error {
+	model, err := ReadAuthorizationModel(viper.GetString(fileFlag))
+	if err != nil {
+		return err
+	}
+
+	warnings, err := LintAuthorizationModel(
+		context.Background(),
+		model,
+		typesystem.WithLintMaxTupleToUsersetDepth(viper.GetInt(maxTupleToUsersetDepthFlag)),
+		typesystem.WithLintSensitiveRelations(viper.GetStringSlice(sensitiveRelationsFlag)...),
+	)
+	if err != nil {
+		return err
+	}
+
+	marshalled, err := json.MarshalIndent(warnings, " ", "    ")
+	if err != nil {
+		return fmt.Errorf("error gathering lint warnings: %w", err)
+	}
+	fmt.Println(string(marshalled))
+
+	if viper.GetBool(failOnWarningsFlag) && len(warnings) > 0 {
+		return fmt.Errorf("%d lint warnings reported", len(warnings))
+	}
+
+	return nil
+}
+
+// ReadAuthorizationModel reads an authorization model from a file in the DSL, or in JSON if its extension is '.json',
+// or composes a modular authorization model from its modules if the file is an 'fga.mod' file listing them.
+func ReadAuthorizationModel(path string) (*openfgav1.AuthorizationModel, error) {
+	if path == "" {
+		return nil, fmt.Errorf("missing authorization model file")
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the authorization model file: %w", err)
+	}
+
+	if filepath.Base(path) == modFileName {
+		return readModularAuthorizationModel(filepath.Dir(path), string(contents))
+	}
+
+	if filepath.Ext(path) == ".json" {
+		var model openfgav1.AuthorizationModel
+		if err := protojson.Unmarshal(contents, &model); err != nil {
+			return nil, fmt.Errorf("failed to parse the authorization model file: %w", err)
+		}
+		return &model, nil
+	}
+
+	model, err := parser.TransformDSLToProto(string(contents))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the authorization model file: %w", err)
+	}
+
+	return model, nil
+}
+
+// LintAuthorizationModel validates an authorization model and returns the warnings reported about it.
+func LintAuthorizationModel(ctx context.Context, model *openfgav1.AuthorizationModel, opts ...typesystem.LintOption) ([]*typesystem.LintWarning, error) {
+	typesys, err := typesystem.NewAndValidate(ctx, model)
+	if err != nil {
+		return nil, fmt.Errorf("invalid authorization model: %w", err)
+	}
+
+	warnings := typesystem.Lint(typesys, opts...)
+	if warnings == nil {
+		warnings = []*typesystem.LintWarning{}
+	}
+
+	return warnings, nil
+}
+
+// readModularAuthorizationModel composes a modular authorization model from the modules listed by an 'fga.mod' file,
+// relative to its directory.
+func readModularAuthorizationModel(dir, modFile string) (*openfgav1.AuthorizationModel, error) {
+	mod, err := parser.TransformModFile(modFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the %s file: %w", modFileName, err)
+	}
+
+	modules := make([]typesystem.Module, 0, len(mod.Contents.Value))
+	for _, file := range mod.Contents.Value {
+		contents, err := os.ReadFile(filepath.Join(dir, file.Value))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the module file: %w", err)
+		}
+
+		modules = append(modules, typesystem.Module{Name: file.Value, Contents: string(contents)})
+	}
+
+	return typesystem.ComposeModules(modules)
+}
@@ -0,0 +1,143 @@
+package model
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/cmd"
+	"github.com/openfga/openfga/cmd/util"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+const lintedModel = `model
+  schema 1.1
+type user
+type team
+type document
+  relations
+    define owner: [user, user:*]`
+
+func writeModelFile(t *testing.T, name, contents string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+	return path
+}
+
+func TestReadAuthorizationModel(t *testing.T) {
+	t.Run("dsl", func(t *testing.T) {
+		model, err := ReadAuthorizationModel(writeModelFile(t, "model.fga", lintedModel))
+		require.NoError(t, err)
+		require.Len(t, model.GetTypeDefinitions(), 3)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		model, err := ReadAuthorizationModel(writeModelFile(t, "model.json", `{
+			"schema_version": "1.1",
+			"type_definitions": [{"type": "user"}]
+		}`))
+		require.NoError(t, err)
+		require.Equal(t, "1.1", model.GetSchemaVersion())
+		require.Len(t, model.GetTypeDefinitions(), 1)
+	})
+
+	t.Run("modular", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "core.fga"), []byte("module core\n\ntype user\n\ntype folder\n  relations\n    define owner: [user]"), 0o600))
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, "docs"), 0o700))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "docs", "docs.fga"), []byte("module docs\n\nextend type folder\n  relations\n    define viewer: [user] or owner"), 0o600))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "fga.mod"), []byte("schema: '1.2'\ncontents:\n  - core.fga\n  - docs/docs.fga\n"), 0o600))
+
+		model, err := ReadAuthorizationModel(filepath.Join(dir, "fga.mod"))
+		require.NoError(t, err)
+		require.Equal(t, "1.2", model.GetSchemaVersion())
+		require.Len(t, model.GetTypeDefinitions(), 2)
+
+		warnings, err := LintAuthorizationModel(context.Background(), model)
+		require.NoError(t, err)
+		require.Empty(t, warnings)
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "fga.mod"), []byte("schema: '1.2'\ncontents:\n  - missing.fga\n"), 0o600))
+		_, err = ReadAuthorizationModel(filepath.Join(dir, "fga.mod"))
+		require.ErrorContains(t, err, "failed to read the module file")
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "fga.mod"), []byte("schema: '1.1'\n"), 0o600))
+		_, err = ReadAuthorizationModel(filepath.Join(dir, "fga.mod"))
+		require.ErrorContains(t, err, "failed to parse the fga.mod file")
+	})
+
+	t.Run("invalid_dsl", func(t *testing.T) {
+		_, err := ReadAuthorizationModel(writeModelFile(t, "model.fga", "model\n  schema"))
+		require.ErrorContains(t, err, "failed to parse the authorization model file")
+	})
+
+	t.Run("missing_file", func(t *testing.T) {
+		_, err := ReadAuthorizationModel("")
+		require.ErrorContains(t, err, "missing authorization model file")
+
+		_, err = ReadAuthorizationModel(filepath.Join(t.TempDir(), "missing.fga"))
+		require.ErrorContains(t, err, "failed to read the authorization model file")
+	})
+}
+
+func TestLintAuthorizationModel(t *testing.T) {
+	model, err := ReadAuthorizationModel(writeModelFile(t, "model.fga", lintedModel))
+	require.NoError(t, err)
+
+	warnings, err := LintAuthorizationModel(context.Background(), model)
+	require.NoError(t, err)
+
+	var codes []typesystem.LintCode
+	for _, w := range warnings {
+		codes = append(codes, w.Code)
+	}
+	require.ElementsMatch(t, []typesystem.LintCode{typesystem.LintUnusedType, typesystem.LintWildcardOnSensitiveRelation}, codes)
+
+	warnings, err = LintAuthorizationModel(context.Background(), model, typesystem.WithLintSensitiveRelations())
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+
+	model.SchemaVersion = "1.0"
+	_, err = LintAuthorizationModel(context.Background(), model)
+	require.ErrorContains(t, err, "invalid authorization model")
+}
+
+func TestLintCommand(t *testing.T) {
+	t.Run("fail_on_warnings", func(t *testing.T) {
+		lintCommand := NewLintCommand()
+		lintCommand.SetArgs([]string{"--file", writeModelFile(t, "model.fga", lintedModel), "--fail-on-warnings"})
+		require.ErrorContains(t, lintCommand.Execute(), "2 lint warnings reported")
+	})
+
+	t.Run("missing_file", func(t *testing.T) {
+		lintCommand := NewLintCommand()
+		lintCommand.SetArgs([]string{"--file", ""})
+		require.ErrorContains(t, lintCommand.Execute(), "missing authorization model file")
+	})
+}
+
+func TestLintCommandNoConfigDefaultValues(t *testing.T) {
+	util.PrepareTempConfigDir(t)
+	lintCommand := NewLintCommand()
+	lintCommand.RunE = func(cmd *cobra.Command, _ []string) error {
+		require.Equal(t, "", viper.GetString(fileFlag))
+		require.Equal(t, typesystem.DefaultLintMaxTupleToUsersetDepth, viper.GetInt(maxTupleToUsersetDepthFlag))
+		require.Equal(t, typesystem.DefaultLintSensitiveRelations, viper.GetStringSlice(sensitiveRelationsFlag))
+		require.False(t, viper.GetBool(failOnWarningsFlag))
+		return nil
+	}
+
+	modelCommand := NewModelCommand()
+	modelCommand.RemoveCommand(modelCommand.Commands()...)
+	modelCommand.AddCommand(lintCommand)
+
+	cmd := cmd.NewRootCommand()
+	cmd.AddCommand(modelCommand)
+	cmd.SetArgs([]string{"model", "lint"})
+	require.NoError(t, cmd.Execute())
+}
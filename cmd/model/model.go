@@ -0,0 +1,20 @@
+// Package model contains the commands to work with authorization models.
+package model
+
+import (
+	"github.com/spf13/cobra"
+)
+
+func NewModelCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "model",
+		Short: "Work with authorization models",
+		Long:  "Work with authorization models without a running server.",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(NewLintCommand())
+	cmd.AddCommand(NewCostCommand())
+
+	return cmd
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/openfga/openfga/cmd"
 	"github.com/openfga/openfga/cmd/migrate"
+	"github.com/openfga/openfga/cmd/migratetuples"
+	"github.com/openfga/openfga/cmd/model"
 	"github.com/openfga/openfga/cmd/run"
 	"github.com/openfga/openfga/cmd/validatemodels"
 )
@@ -22,6 +24,12 @@ func main() {
 	validateModelsCmd := validatemodels.NewValidateCommand()
 	rootCmd.AddCommand(validateModelsCmd)
 
+	migrateTuplesCmd := migratetuples.NewMigrateTuplesCommand()
+	rootCmd.AddCommand(migrateTuplesCmd)
+
+	modelCmd := model.NewModelCommand()
+	rootCmd.AddCommand(modelCmd)
+
 	versionCmd := cmd.NewVersionCommand()
 	rootCmd.AddCommand(versionCmd)
 
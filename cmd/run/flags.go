@@ -153,6 +153,33 @@ func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("maxAuthorizationModelSizeInBytes", flags.Lookup("max-authorization-model-size-in-bytes"))
 		util.MustBindEnv("maxAuthorizationModelSizeInBytes", "OPENFGA_MAX_AUTHORIZATION_MODEL_SIZE_IN_BYTES", "OPENFGA_MAXAUTHORIZATIONMODELSIZEINBYTES")
 
+		util.MustBindPFlag("authorizationModelCompatibilityCheck.enabled", flags.Lookup("authorization-model-compatibility-check-enabled"))
+		util.MustBindEnv("authorizationModelCompatibilityCheck.enabled", "OPENFGA_AUTHORIZATION_MODEL_COMPATIBILITY_CHECK_ENABLED")
+
+		util.MustBindPFlag("authorizationModelCompatibilityCheck.reject", flags.Lookup("authorization-model-compatibility-check-reject"))
+		util.MustBindEnv("authorizationModelCompatibilityCheck.reject", "OPENFGA_AUTHORIZATION_MODEL_COMPATIBILITY_CHECK_REJECT")
+
+		util.MustBindPFlag("authorizationModelCompatibilityCheck.maxTuples", flags.Lookup("authorization-model-compatibility-check-max-tuples"))
+		util.MustBindEnv("authorizationModelCompatibilityCheck.maxTuples", "OPENFGA_AUTHORIZATION_MODEL_COMPATIBILITY_CHECK_MAX_TUPLES")
+
+		util.MustBindPFlag("authorizationModelCompatibilityCheck.deadline", flags.Lookup("authorization-model-compatibility-check-deadline"))
+		util.MustBindEnv("authorizationModelCompatibilityCheck.deadline", "OPENFGA_AUTHORIZATION_MODEL_COMPATIBILITY_CHECK_DEADLINE")
+
+		util.MustBindPFlag("authorizationModelLint.enabled", flags.Lookup("authorization-model-lint-enabled"))
+		util.MustBindEnv("authorizationModelLint.enabled", "OPENFGA_AUTHORIZATION_MODEL_LINT_ENABLED")
+
+		util.MustBindPFlag("authorizationModelRetention.enabled", flags.Lookup("authorization-model-retention-enabled"))
+		util.MustBindEnv("authorizationModelRetention.enabled", "OPENFGA_AUTHORIZATION_MODEL_RETENTION_ENABLED")
+
+		util.MustBindPFlag("authorizationModelRetention.keepLast", flags.Lookup("authorization-model-retention-keep-last"))
+		util.MustBindEnv("authorizationModelRetention.keepLast", "OPENFGA_AUTHORIZATION_MODEL_RETENTION_KEEP_LAST")
+
+		util.MustBindPFlag("authorizationModelRetention.keepUsedWithin", flags.Lookup("authorization-model-retention-keep-used-within"))
+		util.MustBindEnv("authorizationModelRetention.keepUsedWithin", "OPENFGA_AUTHORIZATION_MODEL_RETENTION_KEEP_USED_WITHIN")
+
+		util.MustBindPFlag("authorizationModelRetention.interval", flags.Lookup("authorization-model-retention-interval"))
+		util.MustBindEnv("authorizationModelRetention.interval", "OPENFGA_AUTHORIZATION_MODEL_RETENTION_INTERVAL")
+
 		util.MustBindPFlag("maxConcurrentReadsForListObjects", flags.Lookup("max-concurrent-reads-for-list-objects"))
 		util.MustBindEnv("maxConcurrentReadsForListObjects", "OPENFGA_MAX_CONCURRENT_READS_FOR_LIST_OBJECTS", "OPENFGA_MAXCONCURRENTREADSFORLISTOBJECTS")
 
@@ -180,6 +207,15 @@ func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("listObjectsMaxResults", flags.Lookup("listObjects-max-results"))
 		util.MustBindEnv("listObjectsMaxResults", "OPENFGA_LIST_OBJECTS_MAX_RESULTS", "OPENFGA_LISTOBJECTSMAXRESULTS")
 
+		util.MustBindPFlag("continuationTokenSigningKey", flags.Lookup("continuation-token-signing-key"))
+		util.MustBindEnv("continuationTokenSigningKey", "OPENFGA_CONTINUATION_TOKEN_SIGNING_KEY")
+
+		util.MustBindPFlag("streamedListObjects.deduplication", flags.Lookup("streamedListObjects-deduplication"))
+		util.MustBindEnv("streamedListObjects.deduplication", "OPENFGA_STREAMED_LIST_OBJECTS_DEDUPLICATION")
+
+		util.MustBindPFlag("streamedListObjects.sorting", flags.Lookup("streamedListObjects-sorting"))
+		util.MustBindEnv("streamedListObjects.sorting", "OPENFGA_STREAMED_LIST_OBJECTS_SORTING")
+
 		util.MustBindPFlag("listUsersDeadline", flags.Lookup("listUsers-deadline"))
 		util.MustBindEnv("listUsersDeadline", "OPENFGA_LIST_USERS_DEADLINE", "OPENFGA_LISTUSERSDEADLINE")
 
@@ -195,6 +231,21 @@ func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("checkQueryCache.ttl", flags.Lookup("check-query-cache-ttl"))
 		util.MustBindEnv("checkQueryCache.ttl", "OPENFGA_CHECK_QUERY_CACHE_TTL")
 
+		util.MustBindPFlag("materialization.enabled", flags.Lookup("materialization-enabled"))
+		util.MustBindEnv("materialization.enabled", "OPENFGA_MATERIALIZATION_ENABLED")
+
+		util.MustBindPFlag("materialization.relations", flags.Lookup("materialization-relations"))
+		util.MustBindEnv("materialization.relations", "OPENFGA_MATERIALIZATION_RELATIONS")
+
+		util.MustBindPFlag("materialization.syncInterval", flags.Lookup("materialization-sync-interval"))
+		util.MustBindEnv("materialization.syncInterval", "OPENFGA_MATERIALIZATION_SYNC_INTERVAL")
+
+		util.MustBindPFlag("materialization.maxStaleness", flags.Lookup("materialization-max-staleness"))
+		util.MustBindEnv("materialization.maxStaleness", "OPENFGA_MATERIALIZATION_MAX_STALENESS")
+
+		util.MustBindPFlag("materialization.maxEntriesPerStore", flags.Lookup("materialization-max-entries-per-store"))
+		util.MustBindEnv("materialization.maxEntriesPerStore", "OPENFGA_MATERIALIZATION_MAX_ENTRIES_PER_STORE")
+
 		util.MustBindPFlag("requestDurationDatastoreQueryCountBuckets", flags.Lookup("request-duration-datastore-query-count-buckets"))
 		util.MustBindEnv("requestDurationDatastoreQueryCountBuckets", "OPENFGA_REQUEST_DURATION_DATASTORE_QUERY_COUNT_BUCKETS")
 
@@ -225,6 +276,18 @@ func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("listObjectsDispatchThrottling.maxThreshold", flags.Lookup("listObjects-dispatch-throttling-max-threshold"))
 		util.MustBindEnv("listObjectsDispatchThrottling.maxThreshold", "OPENFGA_LIST_OBJECTS_DISPATCH_THROTTLING_MAX_THRESHOLD")
 
+		util.MustBindPFlag("listUsersDispatchThrottling.enabled", flags.Lookup("listUsers-dispatch-throttling-enabled"))
+		util.MustBindEnv("listUsersDispatchThrottling.enabled", "OPENFGA_LIST_USERS_DISPATCH_THROTTLING_ENABLED")
+
+		util.MustBindPFlag("listUsersDispatchThrottling.frequency", flags.Lookup("listUsers-dispatch-throttling-frequency"))
+		util.MustBindEnv("listUsersDispatchThrottling.frequency", "OPENFGA_LIST_USERS_DISPATCH_THROTTLING_FREQUENCY")
+
+		util.MustBindPFlag("listUsersDispatchThrottling.threshold", flags.Lookup("listUsers-dispatch-throttling-threshold"))
+		util.MustBindEnv("listUsersDispatchThrottling.threshold", "OPENFGA_LIST_USERS_DISPATCH_THROTTLING_THRESHOLD")
+
+		util.MustBindPFlag("listUsersDispatchThrottling.maxThreshold", flags.Lookup("listUsers-dispatch-throttling-max-threshold"))
+		util.MustBindEnv("listUsersDispatchThrottling.maxThreshold", "OPENFGA_LIST_USERS_DISPATCH_THROTTLING_MAX_THRESHOLD")
+
 		// The below configuration will be deprecated in favour of OPENFGA_CHECK_DISPATCH_THROTTLING_ENABLED
 		util.MustBindPFlag("dispatchThrottling.enabled", flags.Lookup("dispatch-throttling-enabled"))
 		util.MustBindEnv("dispatchThrottling.enabled", "OPENFGA_DISPATCH_THROTTLING_ENABLED")
@@ -86,7 +86,7 @@ func NewRunCommand() *cobra.Command {
 	defaultConfig := serverconfig.DefaultConfig()
 	flags := cmd.Flags()
 
-	flags.StringSlice("experimentals", defaultConfig.Experimentals, "a list of experimental features to enable. Allowed values: `enable-list-users`")
+	flags.StringSlice("experimentals", defaultConfig.Experimentals, "a list of experimental features to enable. Allowed values: `enable-check-recursive-fast-path`. `enable-list-users` is deprecated and has no effect, as ListUsers is always enabled")
 
 	flags.String("grpc-addr", defaultConfig.GRPC.Addr, "the host:port address to serve the grpc server on")
 
@@ -182,6 +182,24 @@ func NewRunCommand() *cobra.Command {
 
 	flags.Int("max-authorization-model-size-in-bytes", defaultConfig.MaxAuthorizationModelSizeInBytes, "the maximum size in bytes allowed for persisting an Authorization Model.")
 
+	flags.Bool("authorization-model-compatibility-check-enabled", defaultConfig.AuthorizationModelCompatibilityCheck.Enabled, "enables reading the tuples of the store when writing an Authorization Model to log the tuples which are invalid under it")
+
+	flags.Bool("authorization-model-compatibility-check-reject", defaultConfig.AuthorizationModelCompatibilityCheck.Reject, "if the authorization model compatibility check is enabled, rejects writing Authorization Models under which some tuples of the store are invalid")
+
+	flags.Int("authorization-model-compatibility-check-max-tuples", defaultConfig.AuthorizationModelCompatibilityCheck.MaxTuples, "the maximum number of tuples of the store read by an authorization model compatibility check, after which the compatibility of the model is unknown")
+
+	flags.Duration("authorization-model-compatibility-check-deadline", defaultConfig.AuthorizationModelCompatibilityCheck.Deadline, "the maximum duration of an authorization model compatibility check, after which the compatibility of the model is unknown")
+
+	flags.Bool("authorization-model-lint-enabled", defaultConfig.AuthorizationModelLint.Enabled, "enables linting the Authorization Models written, returning a response header per warning about performance anti-patterns and modelling mistakes")
+
+	flags.Bool("authorization-model-retention-enabled", defaultConfig.AuthorizationModelRetention.Enabled, "enables garbage collecting the Authorization Models of the stores which are neither among the latest ones, nor the active one, nor written or used recently. The use of the models by requests is recorded in the datastore, and must be recorded by all the servers sharing it")
+
+	flags.Int("authorization-model-retention-keep-last", defaultConfig.AuthorizationModelRetention.KeepLast, "if the authorization model retention is enabled, this is the number of latest Authorization Models of each store which are kept")
+
+	flags.Duration("authorization-model-retention-keep-used-within", defaultConfig.AuthorizationModelRetention.KeepUsedWithin, "if the authorization model retention is enabled, this is the duration for which the Authorization Models written or used by requests are kept")
+
+	flags.Duration("authorization-model-retention-interval", defaultConfig.AuthorizationModelRetention.Interval, "if the authorization model retention is enabled, this is the interval at which the Authorization Models of the stores are garbage collected")
+
 	flags.Uint32("max-concurrent-reads-for-list-users", defaultConfig.MaxConcurrentReadsForListUsers, "the maximum allowed number of concurrent datastore reads in a single ListUsers query. A high number will consume more connections from the datastore pool and will attempt to prioritize performance for the request at the expense of other queries performance.")
 
 	flags.Uint32("max-concurrent-reads-for-list-objects", defaultConfig.MaxConcurrentReadsForListObjects, "the maximum allowed number of concurrent datastore reads in a single ListObjects or StreamedListObjects query. A high number will consume more connections from the datastore pool and will attempt to prioritize performance for the request at the expense of other queries performance.")
@@ -200,6 +218,12 @@ func NewRunCommand() *cobra.Command {
 
 	flags.Uint32("listObjects-max-results", defaultConfig.ListObjectsMaxResults, "the maximum results to return in non-streaming ListObjects API responses. If 0, all results can be returned")
 
+	flags.String("continuation-token-signing-key", defaultConfig.ContinuationTokenSigningKey, "the key which the continuation tokens of paginated ListObjects queries are signed with. If empty, a random key is generated at startup, so the tokens are only valid on the server instance which issued them")
+
+	flags.Bool("streamedListObjects-deduplication", defaultConfig.StreamedListObjects.Deduplication, "enables streaming each object at most once in StreamedListObjects API responses")
+
+	flags.Bool("streamedListObjects-sorting", defaultConfig.StreamedListObjects.Sorting, "enables buffering the objects of StreamedListObjects API responses, up to 'listObjects-max-results', and streaming them at most once each in lexicographical order")
+
 	flags.Duration("listUsers-deadline", defaultConfig.ListUsersDeadline, "the timeout deadline for serving ListUsers requests. If 0, there is no deadline")
 
 	flags.Uint32("listUsers-max-results", defaultConfig.ListUsersMaxResults, "the maximum results to return in ListUsers API responses. If 0, all results can be returned")
@@ -210,6 +234,16 @@ func NewRunCommand() *cobra.Command {
 
 	flags.Duration("check-query-cache-ttl", defaultConfig.CheckQueryCache.TTL, "if caching of Check and ListObjects is enabled, this is the TTL of each value")
 
+	flags.Bool("materialization-enabled", defaultConfig.Materialization.Enabled, "enables an index of precomputed permissions for the relations configured with 'materialization-relations'. Check requests against those relations are answered from the index when it was synced with the changelog within 'materialization-max-staleness'")
+
+	flags.StringSlice("materialization-relations", defaultConfig.Materialization.Relations, "the relations to materialize if materialization is enabled, as 'type#relation' (e.g. 'document#viewer'). Relations involving intersections, exclusions or conditions are not materialized")
+
+	flags.Duration("materialization-sync-interval", defaultConfig.Materialization.SyncInterval, "if materialization is enabled, this is the interval at which the index applies the changes of the changelog")
+
+	flags.Duration("materialization-max-staleness", defaultConfig.Materialization.MaxStaleness, "if materialization is enabled, this is the maximum time since the start of the last sync of the index of a store for which Check requests are answered from it. It must be at least 'materialization-sync-interval'")
+
+	flags.Int("materialization-max-entries-per-store", defaultConfig.Materialization.MaxEntriesPerStore, "if materialization is enabled, this is the maximum number of (user, object) pairs the index of a store holds, beyond which Check requests of the store are no longer answered from it")
+
 	// Unfortunately UintSlice/IntSlice does not work well when used as environment variable, we need to stick with string slice and convert back to integer
 	flags.StringSlice("request-duration-datastore-query-count-buckets", defaultConfig.RequestDurationDatastoreQueryCountBuckets, "datastore query count buckets used in labelling request_duration_ms.")
 
@@ -231,6 +265,14 @@ func NewRunCommand() *cobra.Command {
 
 	flags.Uint32("listObjects-dispatch-throttling-max-threshold", defaultConfig.ListObjectsDispatchThrottling.MaxThreshold, "define the maximum dispatch threshold beyond which a list objects requests will be throttled. 0 will use the 'listObjects-dispatch-throttling-threshold' value as maximum")
 
+	flags.Bool("listUsers-dispatch-throttling-enabled", defaultConfig.ListUsersDispatchThrottling.Enabled, "enable throttling when a ListUsers request's number of dispatches is high. Enabling this feature will prioritize dispatched requests requiring less than the configured dispatch threshold over requests whose dispatch count exceeds the configured threshold.")
+
+	flags.Duration("listUsers-dispatch-throttling-frequency", defaultConfig.ListUsersDispatchThrottling.Frequency, "defines how frequent ListUsers dispatch throttling will be evaluated. Frequency controls how frequently throttled dispatch ListUsers requests are dispatched.")
+
+	flags.Uint32("listUsers-dispatch-throttling-threshold", defaultConfig.ListUsersDispatchThrottling.Threshold, "defines the number of dispatches above which ListUsers requests will be throttled.")
+
+	flags.Uint32("listUsers-dispatch-throttling-max-threshold", defaultConfig.ListUsersDispatchThrottling.MaxThreshold, "define the maximum dispatch threshold beyond which a list users requests will be throttled. 0 will use the 'listUsers-dispatch-throttling-threshold' value as maximum")
+
 	flags.Bool("dispatch-throttling-enabled", defaultConfig.DispatchThrottling.Enabled, `DEPRECATED: Use check-dispatch-throttling-enabled instead.
     
     Enable throttling for Check requests when the request's number of dispatches is high. Enabling this feature will prioritize dispatched requests requiring less than the configured dispatch threshold over requests whose dispatch count exceeds the configured threshold.`)
@@ -573,6 +615,15 @@ func (s *ServerContext) Run(ctx context.Context, config *serverconfig.Config) er
 
 	checkDispatchThrottlingConfig := serverconfig.GetCheckDispatchThrottlingConfig(s.Logger, config)
 
+	storeLimits := make(map[string]server.StoreLimits, len(config.StoreLimits))
+	for _, limits := range config.StoreLimits {
+		storeLimits[limits.StoreID] = server.StoreLimits{
+			ResolveNodeLimit:           limits.ResolveNodeLimit,
+			ResolveNodeBreadthLimit:    limits.ResolveNodeBreadthLimit,
+			MaxConcurrentReadsForCheck: limits.MaxConcurrentReadsForCheck,
+		}
+	}
+
 	svr := server.MustNewServerWithOpts(
 		server.WithDatastore(datastore),
 		server.WithAuthorizationModelCacheSize(config.Datastore.MaxCacheSize),
@@ -583,17 +634,35 @@ func (s *ServerContext) Run(ctx context.Context, config *serverconfig.Config) er
 		server.WithChangelogHorizonOffset(config.ChangelogHorizonOffset),
 		server.WithListObjectsDeadline(config.ListObjectsDeadline),
 		server.WithListObjectsMaxResults(config.ListObjectsMaxResults),
+		server.WithContinuationTokenSigningKey([]byte(config.ContinuationTokenSigningKey)),
+		server.WithStreamedListObjectsDeduplication(config.StreamedListObjects.Deduplication),
+		server.WithStreamedListObjectsSorting(config.StreamedListObjects.Sorting),
 		server.WithListUsersDeadline(config.ListUsersDeadline),
 		server.WithListUsersMaxResults(config.ListUsersMaxResults),
 		server.WithMaxConcurrentReadsForListObjects(config.MaxConcurrentReadsForListObjects),
 		server.WithMaxConcurrentReadsForCheck(config.MaxConcurrentReadsForCheck),
 		server.WithMaxConcurrentReadsForListUsers(config.MaxConcurrentReadsForListUsers),
+		server.WithStoreLimits(storeLimits),
 		server.WithCheckQueryCacheEnabled(config.CheckQueryCache.Enabled),
 		server.WithCheckQueryCacheLimit(config.CheckQueryCache.Limit),
 		server.WithCheckQueryCacheTTL(config.CheckQueryCache.TTL),
+		server.WithMaterializationEnabled(config.Materialization.Enabled),
+		server.WithMaterializedRelations(config.Materialization.Relations...),
+		server.WithMaterializationSyncInterval(config.Materialization.SyncInterval),
+		server.WithMaterializationMaxStaleness(config.Materialization.MaxStaleness),
+		server.WithMaterializationMaxEntriesPerStore(config.Materialization.MaxEntriesPerStore),
 		server.WithRequestDurationByQueryHistogramBuckets(convertStringArrayToUintArray(config.RequestDurationDatastoreQueryCountBuckets)),
 		server.WithRequestDurationByDispatchCountHistogramBuckets(convertStringArrayToUintArray(config.RequestDurationDispatchCountBuckets)),
 		server.WithMaxAuthorizationModelSizeInBytes(config.MaxAuthorizationModelSizeInBytes),
+		server.WithAuthorizationModelCompatibilityCheck(config.AuthorizationModelCompatibilityCheck.Enabled),
+		server.WithRejectIncompatibleAuthorizationModels(config.AuthorizationModelCompatibilityCheck.Reject),
+		server.WithAuthorizationModelCompatibilityCheckMaxTuples(config.AuthorizationModelCompatibilityCheck.MaxTuples),
+		server.WithAuthorizationModelCompatibilityCheckDeadline(config.AuthorizationModelCompatibilityCheck.Deadline),
+		server.WithAuthorizationModelLinting(config.AuthorizationModelLint.Enabled),
+		server.WithAuthorizationModelRetentionEnabled(config.AuthorizationModelRetention.Enabled),
+		server.WithAuthorizationModelRetentionKeepLast(config.AuthorizationModelRetention.KeepLast),
+		server.WithAuthorizationModelRetentionKeepUsedWithin(config.AuthorizationModelRetention.KeepUsedWithin),
+		server.WithAuthorizationModelRetentionInterval(config.AuthorizationModelRetention.Interval),
 		server.WithDispatchThrottlingCheckResolverEnabled(checkDispatchThrottlingConfig.Enabled),
 		server.WithDispatchThrottlingCheckResolverFrequency(checkDispatchThrottlingConfig.Frequency),
 		server.WithDispatchThrottlingCheckResolverThreshold(checkDispatchThrottlingConfig.Threshold),
@@ -602,6 +671,10 @@ func (s *ServerContext) Run(ctx context.Context, config *serverconfig.Config) er
 		server.WithListObjectsDispatchThrottlingFrequency(config.ListObjectsDispatchThrottling.Frequency),
 		server.WithListObjectsDispatchThrottlingThreshold(config.ListObjectsDispatchThrottling.Threshold),
 		server.WithListObjectsDispatchThrottlingMaxThreshold(config.ListObjectsDispatchThrottling.MaxThreshold),
+		server.WithListUsersDispatchThrottlingEnabled(config.ListUsersDispatchThrottling.Enabled),
+		server.WithListUsersDispatchThrottlingFrequency(config.ListUsersDispatchThrottling.Frequency),
+		server.WithListUsersDispatchThrottlingThreshold(config.ListUsersDispatchThrottling.Threshold),
+		server.WithListUsersDispatchThrottlingMaxThreshold(config.ListUsersDispatchThrottling.MaxThreshold),
 		server.WithExperimentals(experimentals...),
 	)
 
@@ -685,7 +758,7 @@ func (s *ServerContext) Run(ctx context.Context, config *serverconfig.Config) er
 		if err := openfgav1.RegisterOpenFGAServiceHandler(ctx, mux, conn); err != nil {
 			return err
 		}
-		handler := http.Handler(mux)
+		handler := httpmiddleware.AuthorizationModelDSLHandler(mux, config.MaxAuthorizationModelSizeInBytes)
 
 		if config.Trace.Enabled {
 			handler = otelhttp.NewHandler(handler, "grpc-gateway")
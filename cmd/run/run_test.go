@@ -1098,6 +1098,10 @@ func TestDefaultConfig(t *testing.T) {
 	require.True(t, val.Exists())
 	require.EqualValues(t, val.Int(), cfg.ListObjectsMaxResults)
 
+	val = res.Get("properties.continuationTokenSigningKey.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.ContinuationTokenSigningKey)
+
 	val = res.Get("properties.listUsersDeadline.default")
 	require.True(t, val.Exists())
 	require.Equal(t, val.String(), cfg.ListUsersDeadline.String())
@@ -1204,6 +1208,58 @@ func TestDefaultConfig(t *testing.T) {
 	require.True(t, val.Exists())
 	require.EqualValues(t, val.Int(), cfg.ListObjectsDispatchThrottling.MaxThreshold)
 
+	val = res.Get("properties.listUsersDispatchThrottling.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.ListUsersDispatchThrottling.Enabled)
+
+	val = res.Get("properties.listUsersDispatchThrottling.properties.frequency.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.ListUsersDispatchThrottling.Frequency.String())
+
+	val = res.Get("properties.listUsersDispatchThrottling.properties.threshold.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.ListUsersDispatchThrottling.Threshold)
+
+	val = res.Get("properties.listUsersDispatchThrottling.properties.maxThreshold.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.ListUsersDispatchThrottling.MaxThreshold)
+
+	val = res.Get("properties.authorizationModelCompatibilityCheck.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.AuthorizationModelCompatibilityCheck.Enabled)
+
+	val = res.Get("properties.authorizationModelCompatibilityCheck.properties.reject.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.AuthorizationModelCompatibilityCheck.Reject)
+
+	val = res.Get("properties.authorizationModelCompatibilityCheck.properties.maxTuples.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.AuthorizationModelCompatibilityCheck.MaxTuples)
+
+	val = res.Get("properties.authorizationModelCompatibilityCheck.properties.deadline.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.AuthorizationModelCompatibilityCheck.Deadline.String())
+
+	val = res.Get("properties.authorizationModelLint.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.AuthorizationModelLint.Enabled)
+
+	val = res.Get("properties.authorizationModelRetention.properties.enabled.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.Bool(), cfg.AuthorizationModelRetention.Enabled)
+
+	val = res.Get("properties.authorizationModelRetention.properties.keepLast.default")
+	require.True(t, val.Exists())
+	require.EqualValues(t, val.Int(), cfg.AuthorizationModelRetention.KeepLast)
+
+	val = res.Get("properties.authorizationModelRetention.properties.keepUsedWithin.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.AuthorizationModelRetention.KeepUsedWithin.String())
+
+	val = res.Get("properties.authorizationModelRetention.properties.interval.default")
+	require.True(t, val.Exists())
+	require.Equal(t, val.String(), cfg.AuthorizationModelRetention.Interval.String())
+
 	val = res.Get("properties.requestTimeout.default")
 	require.True(t, val.Exists())
 	require.EqualValues(t, val.String(), cfg.RequestTimeout.String())
@@ -1325,7 +1381,6 @@ func TestHTTPHeaders(t *testing.T) {
 		goleak.VerifyNone(t)
 	})
 	cfg := testutils.MustDefaultConfigWithRandomPorts()
-	cfg.Experimentals = []string{string(server.ExperimentalEnableListUsers)}
 	ctx, cancel := context.WithCancel(context.Background())
 	t.Cleanup(cancel)
 
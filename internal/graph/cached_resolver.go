@@ -193,6 +193,11 @@ func CheckRequestCacheKey(req *ResolveCheckRequest) (string, error) {
 		tupleKey.GetUser(),
 	)
 
+	// partially evaluated responses differ from fully evaluated ones, so they must not share a key
+	if req.GetPartialEvaluation() {
+		key += "/partial"
+	}
+
 	if err := hasher.WriteString(key); err != nil {
 		return "", err
 	}
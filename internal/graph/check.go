@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"sync"
 
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
@@ -16,6 +17,7 @@ import (
 	"github.com/openfga/openfga/internal/condition"
 	"github.com/openfga/openfga/internal/condition/eval"
 	serverconfig "github.com/openfga/openfga/internal/server/config"
+	"github.com/openfga/openfga/internal/throttler"
 	"github.com/openfga/openfga/internal/validation"
 	"github.com/openfga/openfga/pkg/storage"
 	"github.com/openfga/openfga/pkg/telemetry"
@@ -33,6 +35,11 @@ type ResolveCheckRequest struct {
 	Context              *structpb.Struct
 	RequestMetadata      *ResolveCheckRequestMetadata
 	VisitedPaths         map[string]struct{}
+
+	// PartialEvaluation, when enabled, causes conditional relationships whose condition
+	// cannot be evaluated because of missing context parameters to resolve to a conditional
+	// outcome (see [[ResolveCheckResponse.MissingParameters]]) instead of an evaluation error.
+	PartialEvaluation bool
 }
 
 func clone(r *ResolveCheckRequest) *ResolveCheckRequest {
@@ -43,12 +50,14 @@ func clone(r *ResolveCheckRequest) *ResolveCheckRequest {
 		ContextualTuples:     r.ContextualTuples,
 		Context:              r.Context,
 		RequestMetadata: &ResolveCheckRequestMetadata{
-			DispatchCounter:     r.GetRequestMetadata().DispatchCounter,
-			Depth:               r.GetRequestMetadata().Depth,
-			DatastoreQueryCount: r.GetRequestMetadata().DatastoreQueryCount,
-			WasThrottled:        r.GetRequestMetadata().WasThrottled,
+			DispatchCounter:         r.GetRequestMetadata().DispatchCounter,
+			Depth:                   r.GetRequestMetadata().Depth,
+			DatastoreQueryCount:     r.GetRequestMetadata().DatastoreQueryCount,
+			WasThrottled:            r.GetRequestMetadata().WasThrottled,
+			ResolveNodeBreadthLimit: r.GetRequestMetadata().ResolveNodeBreadthLimit,
 		},
-		VisitedPaths: maps.Clone(r.VisitedPaths),
+		VisitedPaths:      maps.Clone(r.VisitedPaths),
+		PartialEvaluation: r.PartialEvaluation,
 	}
 }
 
@@ -70,12 +79,18 @@ func CloneResolveCheckResponse(r *ResolveCheckResponse) *ResolveCheckResponse {
 	return &ResolveCheckResponse{
 		Allowed:            r.GetAllowed(),
 		ResolutionMetadata: resolutionMetadata,
+		MissingParameters:  slices.Clone(r.GetMissingParameters()),
 	}
 }
 
 type ResolveCheckResponse struct {
 	Allowed            bool
 	ResolutionMetadata *ResolveCheckResponseMetadata
+
+	// MissingParameters is only ever set if the request enabled PartialEvaluation. If it is
+	// non-empty, the outcome is neither allowed nor denied but conditional on the value of
+	// these condition parameters, which were missing from the request context.
+	MissingParameters []string
 }
 
 func (r *ResolveCheckResponse) GetCycleDetected() bool {
@@ -102,6 +117,20 @@ func (r *ResolveCheckResponse) GetResolutionMetadata() *ResolveCheckResponseMeta
 	return nil
 }
 
+func (r *ResolveCheckResponse) GetMissingParameters() []string {
+	if r != nil {
+		return r.MissingParameters
+	}
+
+	return nil
+}
+
+// IsConditional returns true if the outcome depends on condition parameters that were
+// missing from the request context.
+func (r *ResolveCheckResponse) IsConditional() bool {
+	return !r.GetAllowed() && len(r.GetMissingParameters()) > 0
+}
+
 func (r *ResolveCheckRequest) GetStoreID() string {
 	if r != nil {
 		return r.StoreID
@@ -149,6 +178,87 @@ func (r *ResolveCheckRequest) GetContext() *structpb.Struct {
 	return nil
 }
 
+func (r *ResolveCheckRequest) GetPartialEvaluation() bool {
+	if r != nil {
+		return r.PartialEvaluation
+	}
+	return false
+}
+
+// mergeMissingParameters returns the sorted, deduplicated union of the provided parameter names.
+func mergeMissingParameters(params ...[]string) []string {
+	var merged []string
+	for _, p := range params {
+		merged = append(merged, p...)
+	}
+
+	if len(merged) == 0 {
+		return nil
+	}
+
+	slices.Sort(merged)
+	return slices.Compact(merged)
+}
+
+// conditionalHandler returns a CheckHandlerFunc which resolves to an outcome that is conditional
+// on the provided missing condition parameters.
+func conditionalHandler(missingParameters []string) CheckHandlerFunc {
+	return func(context.Context) (*ResolveCheckResponse, error) {
+		return &ResolveCheckResponse{
+			Allowed:            false,
+			ResolutionMetadata: &ResolveCheckResponseMetadata{},
+			MissingParameters:  mergeMissingParameters(missingParameters),
+		}, nil
+	}
+}
+
+// conditionalOn wraps the provided CheckHandlerFunc so that an allowed or conditional outcome
+// becomes conditional on the provided missing condition parameters as well. This is used when
+// a relationship which leads to the subproblem has a condition that could not be evaluated.
+func conditionalOn(handler CheckHandlerFunc, missingParameters []string) CheckHandlerFunc {
+	return func(ctx context.Context) (*ResolveCheckResponse, error) {
+		resp, err := handler(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.GetAllowed() || resp.IsConditional() {
+			resp.Allowed = false
+			resp.MissingParameters = mergeMissingParameters(resp.GetMissingParameters(), missingParameters)
+		}
+
+		return resp, nil
+	}
+}
+
+// subjectTerminalTypes returns the types of the concrete users that are members of the provided subject.
+func subjectTerminalTypes(typesys *typesystem.TypeSystem, subject string) ([]string, error) {
+	if !tuple.IsObjectRelation(subject) {
+		return []string{tuple.GetType(subject)}, nil
+	}
+
+	object, relation := tuple.SplitObjectRelation(subject)
+
+	return typesys.TerminalUserTypes(tuple.GetType(object), relation)
+}
+
+// subjectIsContainedInWildcard returns true if every member of the provided subject is of the
+// wildcard's type, and thus granted by a relationship with that typed wildcard.
+func subjectIsContainedInWildcard(typesys *typesystem.TypeSystem, subject, wildcardType string) bool {
+	terminalTypes, err := subjectTerminalTypes(typesys, subject)
+	if err != nil || len(terminalTypes) == 0 {
+		return false
+	}
+
+	for _, terminalType := range terminalTypes {
+		if terminalType != wildcardType {
+			return false
+		}
+	}
+
+	return true
+}
+
 type setOperatorType int
 
 const (
@@ -166,6 +276,11 @@ type LocalChecker struct {
 	delegate           CheckResolver
 	concurrencyLimit   uint32
 	maxConcurrentReads uint32
+	recursiveFastPath  bool
+
+	// throttler and throttlingConfig throttle the search of checkRecursive, if set.
+	throttler        throttler.Throttler
+	throttlingConfig DispatchThrottlingCheckResolverConfig
 }
 
 type LocalCheckerOption func(d *LocalChecker)
@@ -184,6 +299,36 @@ func WithMaxConcurrentReads(limit uint32) LocalCheckerOption {
 	}
 }
 
+// WithRecursiveFastPath enables evaluating directly recursive relations (e.g. 'define member: [user, group#member]'
+// or 'define viewer: [user] or viewer from parent') with a breadth-first search over the datastore instead of
+// dispatching once per level of nesting. Such Checks are then bound by a budget of datastore reads derived from the
+// resolution depth and breadth limits rather than by the depth of the nesting, and fall back to dispatching once per
+// level when they exceed it.
+func WithRecursiveFastPath() LocalCheckerOption {
+	return func(d *LocalChecker) {
+		d.recursiveFastPath = true
+	}
+}
+
+// WithRecursiveFastPathThrottling throttles the search of the recursive fast path (see WithRecursiveFastPath) with
+// the provided throttler once the dispatches of a request exceed the threshold of the config, as
+// DispatchThrottlingCheckResolver throttles dispatches. The throttler isn't closed by the LocalChecker.
+func WithRecursiveFastPathThrottling(t throttler.Throttler, config DispatchThrottlingCheckResolverConfig) LocalCheckerOption {
+	return func(d *LocalChecker) {
+		d.throttler = t
+		d.throttlingConfig = config
+	}
+}
+
+// breadthLimit returns the number of subproblems of the provided request which may be evaluated concurrently.
+func (c *LocalChecker) breadthLimit(req *ResolveCheckRequest) uint32 {
+	if limit := req.GetRequestMetadata().ResolveNodeBreadthLimit; limit != 0 {
+		return limit
+	}
+
+	return c.concurrencyLimit
+}
+
 // NewLocalChecker constructs a LocalChecker that can be used to evaluate a Check
 // request locally.
 //
@@ -297,6 +442,8 @@ func resolver(ctx context.Context, concurrencyLimit uint32, resultChan chan<- ch
 
 // union implements a CheckFuncReducer that requires any of the provided CheckHandlerFunc to resolve
 // to an allowed outcome. The first allowed outcome causes premature termination of the reducer.
+// If no outcome is allowed but some are conditional, the result is conditional on the union of
+// their missing parameters.
 func union(ctx context.Context, concurrencyLimit uint32, handlers ...CheckHandlerFunc) (*ResolveCheckResponse, error) {
 	ctx, cancel := context.WithCancel(ctx)
 	resultChan := make(chan checkOutcome, len(handlers))
@@ -312,6 +459,7 @@ func union(ctx context.Context, concurrencyLimit uint32, handlers ...CheckHandle
 	var dbReads uint32
 	var err error
 	var cycleDetected bool
+	var missingParameters []string
 	for i := 0; i < len(handlers); i++ {
 		select {
 		case result := <-resultChan:
@@ -330,6 +478,10 @@ func union(ctx context.Context, concurrencyLimit uint32, handlers ...CheckHandle
 				result.resp.GetResolutionMetadata().DatastoreQueryCount = dbReads
 				return result.resp, nil
 			}
+
+			if result.resp.IsConditional() {
+				missingParameters = mergeMissingParameters(missingParameters, result.resp.GetMissingParameters())
+			}
 		case <-ctx.Done():
 			return nil, ctx.Err()
 		}
@@ -345,11 +497,14 @@ func union(ctx context.Context, concurrencyLimit uint32, handlers ...CheckHandle
 			DatastoreQueryCount: dbReads,
 			CycleDetected:       cycleDetected,
 		},
+		MissingParameters: missingParameters,
 	}, nil
 }
 
 // intersection implements a CheckFuncReducer that requires all of the provided CheckHandlerFunc to resolve
 // to an allowed outcome. The first falsey or erroneous outcome causes premature termination of the reducer.
+// Conditional outcomes are not falsey, so if every other outcome is allowed the result is conditional on
+// the union of their missing parameters.
 func intersection(ctx context.Context, concurrencyLimit uint32, handlers ...CheckHandlerFunc) (*ResolveCheckResponse, error) {
 	if len(handlers) == 0 {
 		return &ResolveCheckResponse{
@@ -373,6 +528,7 @@ func intersection(ctx context.Context, concurrencyLimit uint32, handlers ...Chec
 
 	var dbReads uint32
 	var err error
+	var missingParameters []string
 	for i := 0; i < len(handlers); i++ {
 		select {
 		case result := <-resultChan:
@@ -384,8 +540,14 @@ func intersection(ctx context.Context, concurrencyLimit uint32, handlers ...Chec
 
 			dbReads += result.resp.GetResolutionMetadata().DatastoreQueryCount
 
+			if result.resp.IsConditional() && !result.resp.GetCycleDetected() {
+				missingParameters = mergeMissingParameters(missingParameters, result.resp.GetMissingParameters())
+				continue
+			}
+
 			if result.resp.GetCycleDetected() || !result.resp.GetAllowed() {
 				result.resp.GetResolutionMetadata().DatastoreQueryCount = dbReads
+				result.resp.MissingParameters = nil
 				return result.resp, nil
 			}
 		case <-ctx.Done():
@@ -393,11 +555,21 @@ func intersection(ctx context.Context, concurrencyLimit uint32, handlers ...Chec
 		}
 	}
 
-	// all operands are either truthy or we've seen at least one error
+	// all operands are either truthy, conditional or we've seen at least one error
 	if err != nil {
 		return nil, err
 	}
 
+	if len(missingParameters) > 0 {
+		return &ResolveCheckResponse{
+			Allowed: false,
+			ResolutionMetadata: &ResolveCheckResponseMetadata{
+				DatastoreQueryCount: dbReads,
+			},
+			MissingParameters: missingParameters,
+		}, nil
+	}
+
 	return &ResolveCheckResponse{
 		Allowed: true,
 		ResolutionMetadata: &ResolveCheckResponseMetadata{
@@ -408,7 +580,8 @@ func intersection(ctx context.Context, concurrencyLimit uint32, handlers ...Chec
 
 // exclusion implements a CheckFuncReducer that requires a 'base' CheckHandlerFunc to resolve to an allowed
 // outcome and a 'sub' CheckHandlerFunc to resolve to a falsey outcome. The base and sub computations are
-// handled concurrently relative to one another.
+// handled concurrently relative to one another. If neither outcome is decisive and either of them is
+// conditional, the result is conditional on the union of their missing parameters.
 func exclusion(ctx context.Context, concurrencyLimit uint32, handlers ...CheckHandlerFunc) (*ResolveCheckResponse, error) {
 	if len(handlers) != 2 {
 		panic(fmt.Sprintf("expected two rewrite operands for exclusion operator, but got '%d'", len(handlers)))
@@ -461,6 +634,7 @@ func exclusion(ctx context.Context, concurrencyLimit uint32, handlers ...CheckHa
 
 	var baseErr error
 	var subErr error
+	var missingParameters []string
 
 	var dbReads uint32
 	for i := 0; i < len(handlers); i++ {
@@ -484,6 +658,11 @@ func exclusion(ctx context.Context, concurrencyLimit uint32, handlers ...CheckHa
 				}, nil
 			}
 
+			if baseResult.resp.IsConditional() {
+				missingParameters = mergeMissingParameters(missingParameters, baseResult.resp.GetMissingParameters())
+				continue
+			}
+
 			if !baseResult.resp.GetAllowed() {
 				response.GetResolutionMetadata().DatastoreQueryCount = dbReads
 				return response, nil
@@ -512,6 +691,10 @@ func exclusion(ctx context.Context, concurrencyLimit uint32, handlers ...CheckHa
 				response.GetResolutionMetadata().DatastoreQueryCount = dbReads
 				return response, nil
 			}
+
+			if subResult.resp.IsConditional() {
+				missingParameters = mergeMissingParameters(missingParameters, subResult.resp.GetMissingParameters())
+			}
 		case <-ctx.Done():
 			return nil, ctx.Err()
 		}
@@ -526,6 +709,17 @@ func exclusion(ctx context.Context, concurrencyLimit uint32, handlers ...CheckHa
 		return nil, errors.Join(baseErr, subErr)
 	}
 
+	// base is either (true) or (conditional), sub is either (false) or (conditional) and at least one is conditional
+	if len(missingParameters) > 0 {
+		return &ResolveCheckResponse{
+			Allowed: false,
+			ResolutionMetadata: &ResolveCheckResponseMetadata{
+				DatastoreQueryCount: dbReads,
+			},
+			MissingParameters: missingParameters,
+		}, nil
+	}
+
 	return &ResolveCheckResponse{
 		Allowed: true,
 		ResolutionMetadata: &ResolveCheckResponseMetadata{
@@ -538,8 +732,18 @@ func exclusion(ctx context.Context, concurrencyLimit uint32, handlers ...CheckHa
 func (c *LocalChecker) Close() {
 }
 
+type dispatchResolverCtxKey struct{}
+
+// ContextWithDispatchResolver returns a context in which the LocalChecker dispatches the sub-problems of a Check
+// to the provided CheckResolver rather than to its delegate, e.g. so that a batch of Checks shares a cache of their
+// sub-problems. The provided CheckResolver is expected to delegate to the CheckResolver the Checks are resolved with.
+func ContextWithDispatchResolver(ctx context.Context, resolver CheckResolver) context.Context {
+	return context.WithValue(ctx, dispatchResolverCtxKey{}, resolver)
+}
+
 // dispatch clones the parent request, modifies its metadata and tupleKey, and dispatches the new request
-// to the CheckResolver this LocalChecker was constructed with.
+// to the CheckResolver of the context if any (see ContextWithDispatchResolver), or the CheckResolver this
+// LocalChecker was constructed with.
 func (c *LocalChecker) dispatch(_ context.Context, parentReq *ResolveCheckRequest, tk *openfgav1.TupleKey) CheckHandlerFunc {
 	return func(ctx context.Context) (*ResolveCheckResponse, error) {
 		parentReq.GetRequestMetadata().DispatchCounter.Add(1)
@@ -547,7 +751,12 @@ func (c *LocalChecker) dispatch(_ context.Context, parentReq *ResolveCheckReques
 		childRequest.TupleKey = tk
 		childRequest.GetRequestMetadata().Depth--
 
-		resp, err := c.delegate.ResolveCheck(ctx, childRequest)
+		resolver := c.delegate
+		if dispatchResolver, ok := ctx.Value(dispatchResolverCtxKey{}).(CheckResolver); ok {
+			resolver = dispatchResolver
+		}
+
+		resp, err := resolver.ResolveCheck(ctx, childRequest)
 		if err != nil {
 			return nil, err
 		}
@@ -605,6 +814,23 @@ func (c *LocalChecker) ResolveCheck(
 		return nil, fmt.Errorf("relation '%s' undefined for object type '%s'", relation, objectType)
 	}
 
+	if c.recursiveFastPath && userRelation == "" && !tuple.IsTypedWildcard(userObject) {
+		if edge, ok := recursiveEdgeFor(typesys, objectType, relation); ok {
+			resp, err := c.checkRecursive(ctx, req, edge)
+			if err == nil {
+				return resp, nil
+			}
+
+			if !errors.Is(err, errRecursiveCheckLimitExceeded) {
+				telemetry.TraceError(span, err)
+				return nil, err
+			}
+
+			// the search is too large, so fall back to dispatching once per hop, within the resolution limits
+			span.SetAttributes(attribute.Bool("recursive_fast_path_limit_exceeded", true))
+		}
+	}
+
 	resp, err := c.checkRewrite(ctx, req, rel.GetRewrite())(ctx)
 	if err != nil {
 		telemetry.TraceError(span, err)
@@ -677,6 +903,12 @@ func (c *LocalChecker) checkDirect(parentctx context.Context, req *ResolveCheckR
 				}
 
 				if len(condEvalResult.MissingParameters) > 0 {
+					if req.GetPartialEvaluation() {
+						span.SetAttributes(attribute.StringSlice("missing_parameters", condEvalResult.MissingParameters))
+						response.MissingParameters = mergeMissingParameters(condEvalResult.MissingParameters)
+						return response, nil
+					}
+
 					evalErr := condition.NewEvaluationError(
 						tupleKey.GetCondition().GetName(),
 						fmt.Errorf("context is missing parameters '%v'", condEvalResult.MissingParameters),
@@ -746,18 +978,19 @@ func (c *LocalChecker) checkDirect(parentctx context.Context, req *ResolveCheckR
 					continue
 				}
 
-				if len(condEvalResult.MissingParameters) > 0 {
+				missingParameters := condEvalResult.MissingParameters
+				if len(missingParameters) > 0 && !req.GetPartialEvaluation() {
 					errs = errors.Join(errs, condition.NewEvaluationError(
 						t.GetCondition().GetName(),
 						fmt.Errorf("tuple '%s' is missing context parameters '%v'",
 							tuple.TupleKeyToString(t),
-							condEvalResult.MissingParameters),
+							missingParameters),
 					))
 
 					continue
 				}
 
-				if !condEvalResult.ConditionMet {
+				if !condEvalResult.ConditionMet && len(missingParameters) == 0 {
 					continue
 				}
 
@@ -768,7 +1001,12 @@ func (c *LocalChecker) checkDirect(parentctx context.Context, req *ResolveCheckR
 				if tuple.IsTypedWildcard(usersetObject) && typesystem.IsSchemaVersionSupported(typesys.GetSchemaVersion()) {
 					wildcardType := tuple.GetType(usersetObject)
 
-					if tuple.GetType(reqTupleKey.GetUser()) == wildcardType {
+					if subjectIsContainedInWildcard(typesys, reqTupleKey.GetUser(), wildcardType) {
+						if len(missingParameters) > 0 {
+							handlers = append(handlers, conditionalHandler(missingParameters))
+							continue
+						}
+
 						span.SetAttributes(attribute.Bool("allowed", true))
 						response.Allowed = true
 						return response, nil
@@ -779,7 +1017,12 @@ func (c *LocalChecker) checkDirect(parentctx context.Context, req *ResolveCheckR
 
 				if usersetRelation != "" {
 					tupleKey := tuple.NewTupleKey(usersetObject, usersetRelation, reqTupleKey.GetUser())
-					handlers = append(handlers, c.dispatch(ctx, req, tupleKey))
+					handler := c.dispatch(ctx, req, tupleKey)
+					if len(missingParameters) > 0 {
+						handler = conditionalOn(handler, missingParameters)
+					}
+
+					handlers = append(handlers, handler)
 				}
 			}
 
@@ -788,7 +1031,7 @@ func (c *LocalChecker) checkDirect(parentctx context.Context, req *ResolveCheckR
 				return nil, errs
 			}
 
-			resp, err := union(ctx, c.concurrencyLimit, handlers...)
+			resp, err := union(ctx, c.breadthLimit(req), handlers...)
 			if err != nil {
 				telemetry.TraceError(span, err)
 				return nil, errors.Join(errs, err)
@@ -812,7 +1055,7 @@ func (c *LocalChecker) checkDirect(parentctx context.Context, req *ResolveCheckR
 			checkFuncs = append(checkFuncs, fn2)
 		}
 
-		resp, err := union(ctx, c.concurrencyLimit, checkFuncs...)
+		resp, err := union(ctx, c.breadthLimit(req), checkFuncs...)
 		if err != nil {
 			telemetry.TraceError(span, err)
 			return nil, err
@@ -919,18 +1162,19 @@ func (c *LocalChecker) checkTTU(parentctx context.Context, req *ResolveCheckRequ
 				continue
 			}
 
-			if len(condEvalResult.MissingParameters) > 0 {
+			missingParameters := condEvalResult.MissingParameters
+			if len(missingParameters) > 0 && !req.GetPartialEvaluation() {
 				errs = errors.Join(errs, condition.NewEvaluationError(
 					t.GetCondition().GetName(),
 					fmt.Errorf("tuple '%s' is missing context parameters '%v'",
 						tuple.TupleKeyToString(t),
-						condEvalResult.MissingParameters),
+						missingParameters),
 				))
 
 				continue
 			}
 
-			if !condEvalResult.ConditionMet {
+			if !condEvalResult.ConditionMet && len(missingParameters) == 0 {
 				continue
 			}
 
@@ -949,7 +1193,12 @@ func (c *LocalChecker) checkTTU(parentctx context.Context, req *ResolveCheckRequ
 			}
 
 			// Note: we add TTU read below
-			handlers = append(handlers, c.dispatch(ctx, req, tupleKey))
+			handler := c.dispatch(ctx, req, tupleKey)
+			if len(missingParameters) > 0 {
+				handler = conditionalOn(handler, missingParameters)
+			}
+
+			handlers = append(handlers, handler)
 		}
 
 		if len(handlers) == 0 && errs != nil {
@@ -957,7 +1206,7 @@ func (c *LocalChecker) checkTTU(parentctx context.Context, req *ResolveCheckRequ
 			return nil, errs
 		}
 
-		unionResponse, err := union(ctx, c.concurrencyLimit, handlers...)
+		unionResponse, err := union(ctx, c.breadthLimit(req), handlers...)
 		if err != nil {
 			telemetry.TraceError(span, err)
 			return nil, errors.Join(errs, err)
@@ -1014,7 +1263,7 @@ func (c *LocalChecker) checkSetOperation(
 			span.End()
 		}()
 
-		resp, err = reducer(ctx, c.concurrencyLimit, handlers...)
+		resp, err = reducer(ctx, c.breadthLimit(req), handlers...)
 		return resp, err
 	}
 }
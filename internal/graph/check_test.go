@@ -15,6 +15,7 @@ import (
 	"go.uber.org/goleak"
 	"google.golang.org/protobuf/types/known/structpb"
 
+	"github.com/openfga/openfga/internal/condition"
 	"github.com/openfga/openfga/pkg/storage"
 	"github.com/openfga/openfga/pkg/storage/memory"
 	"github.com/openfga/openfga/pkg/storage/storagewrappers"
@@ -1980,3 +1981,363 @@ func TestCloneResolveCheckResponse(t *testing.T) {
 	require.Equal(t, uint32(0), clonedResp2.GetResolutionMetadata().DatastoreQueryCount)
 	require.False(t, clonedResp2.GetResolutionMetadata().CycleDetected)
 }
+
+func TestLocalCheckerBreadthLimit(t *testing.T) {
+	checker := NewLocalChecker(WithResolveNodeBreadthLimit(10))
+	t.Cleanup(checker.Close)
+
+	req := &ResolveCheckRequest{RequestMetadata: NewCheckRequestMetadata(defaultResolveNodeLimit)}
+	require.Equal(t, uint32(10), checker.breadthLimit(req))
+
+	req.GetRequestMetadata().ResolveNodeBreadthLimit = 2
+	require.Equal(t, uint32(2), checker.breadthLimit(req))
+
+	// the override is inherited by the subproblems of the request
+	require.Equal(t, uint32(2), checker.breadthLimit(clone(req)))
+}
+
+func TestCheckPartialEvaluation(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID := ulid.Make().String()
+
+	model := parser.MustTransformDSLToProto(`
+		model
+			schema 1.1
+
+		type user
+
+		type group
+			relations
+				define member: [user, user with x_less_than, group#member with y_less_than]
+
+		type folder
+			relations
+				define viewer: [user, user:* with x_less_than]
+
+		type document
+			relations
+				define parent: [folder with y_less_than]
+				define blocked: [user with x_less_than]
+				define owner: [user with x_less_than]
+				define viewer: [group#member] or viewer from parent
+				define editor: owner and viewer
+				define restricted_viewer: viewer but not blocked
+
+		condition x_less_than(x: int) {
+			x < 100
+		}
+
+		condition y_less_than(y: int) {
+			y < 100
+		}`)
+
+	err := ds.Write(context.Background(), storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKeyWithCondition("group:eng", "member", "user:jon", "x_less_than", nil),
+		tuple.NewTupleKeyWithCondition("group:eng", "member", "group:fga#member", "y_less_than", nil),
+		tuple.NewTupleKey("group:fga", "member", "user:maria"),
+		tuple.NewTupleKey("document:1", "viewer", "group:eng#member"),
+		tuple.NewTupleKeyWithCondition("document:1", "parent", "folder:1", "y_less_than", nil),
+		tuple.NewTupleKeyWithCondition("folder:1", "viewer", "user:*", "x_less_than", nil),
+		tuple.NewTupleKey("folder:1", "viewer", "user:bob"),
+		tuple.NewTupleKeyWithCondition("document:1", "owner", "user:bob", "x_less_than", nil),
+		tuple.NewTupleKeyWithCondition("document:1", "blocked", "user:maria", "x_less_than", nil),
+	})
+	require.NoError(t, err)
+
+	typesys, err := typesystem.NewAndValidate(context.Background(), model)
+	require.NoError(t, err)
+
+	checker := NewLocalCheckerWithCycleDetection()
+	t.Cleanup(checker.Close)
+
+	ctx := typesystem.ContextWithTypesystem(context.Background(), typesys)
+	ctx = storage.ContextWithRelationshipTupleReader(ctx, ds)
+
+	tests := []struct {
+		name                      string
+		tupleKey                  *openfgav1.TupleKey
+		context                   map[string]interface{}
+		expectedAllowed           bool
+		expectedMissingParameters []string
+	}{
+		{
+			name:                      "direct_conditional",
+			tupleKey:                  tuple.NewTupleKey("group:eng", "member", "user:jon"),
+			expectedMissingParameters: []string{"x"},
+		},
+		{
+			name:            "direct_condition_met",
+			tupleKey:        tuple.NewTupleKey("group:eng", "member", "user:jon"),
+			context:         map[string]interface{}{"x": 1},
+			expectedAllowed: true,
+		},
+		{
+			name:     "direct_condition_not_met",
+			tupleKey: tuple.NewTupleKey("group:eng", "member", "user:jon"),
+			context:  map[string]interface{}{"x": 101},
+		},
+		{
+			name:                      "userset_conditional",
+			tupleKey:                  tuple.NewTupleKey("group:eng", "member", "user:maria"),
+			expectedMissingParameters: []string{"y"},
+		},
+		{
+			name:                      "wildcard_and_ttu_conditional",
+			tupleKey:                  tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+			expectedMissingParameters: []string{"x", "y"},
+		},
+		{
+			name:                      "union_conditional_on_any_operand",
+			tupleKey:                  tuple.NewTupleKey("document:1", "viewer", "user:jon"),
+			context:                   map[string]interface{}{"y": 1},
+			expectedMissingParameters: []string{"x"},
+		},
+		{
+			name:            "union_allowed_if_any_operand_allowed",
+			tupleKey:        tuple.NewTupleKey("document:1", "viewer", "user:jon"),
+			context:         map[string]interface{}{"x": 1},
+			expectedAllowed: true,
+		},
+		{
+			name:                      "intersection_conditional",
+			tupleKey:                  tuple.NewTupleKey("document:1", "editor", "user:bob"),
+			expectedMissingParameters: []string{"x", "y"},
+		},
+		{
+			name:     "intersection_denied_if_any_operand_denied",
+			tupleKey: tuple.NewTupleKey("document:1", "editor", "user:anne"),
+			context:  map[string]interface{}{"y": 1},
+		},
+		{
+			name:                      "exclusion_conditional_on_subtract",
+			tupleKey:                  tuple.NewTupleKey("document:1", "restricted_viewer", "user:maria"),
+			context:                   map[string]interface{}{"y": 1},
+			expectedMissingParameters: []string{"x"},
+		},
+		{
+			name:     "exclusion_denied_if_subtract_allowed",
+			tupleKey: tuple.NewTupleKey("document:1", "restricted_viewer", "user:maria"),
+			context:  map[string]interface{}{"x": 1, "y": 1},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var requestContext *structpb.Struct
+			if test.context != nil {
+				requestContext, err = structpb.NewStruct(test.context)
+				require.NoError(t, err)
+			}
+
+			resp, err := checker.ResolveCheck(ctx, &ResolveCheckRequest{
+				StoreID:              storeID,
+				AuthorizationModelID: typesys.GetAuthorizationModelID(),
+				TupleKey:             test.tupleKey,
+				RequestMetadata:      NewCheckRequestMetadata(defaultResolveNodeLimit),
+				Context:              requestContext,
+				PartialEvaluation:    true,
+			})
+			require.NoError(t, err)
+			require.Equal(t, test.expectedAllowed, resp.GetAllowed())
+			require.Equal(t, test.expectedMissingParameters, resp.GetMissingParameters())
+			require.Equal(t, len(test.expectedMissingParameters) > 0, resp.IsConditional())
+		})
+	}
+
+	t.Run("missing_parameters_error_without_partial_evaluation", func(t *testing.T) {
+		_, err := checker.ResolveCheck(ctx, &ResolveCheckRequest{
+			StoreID:              storeID,
+			AuthorizationModelID: typesys.GetAuthorizationModelID(),
+			TupleKey:             tuple.NewTupleKey("group:eng", "member", "user:jon"),
+			RequestMetadata:      NewCheckRequestMetadata(defaultResolveNodeLimit),
+		})
+		require.ErrorIs(t, err, condition.ErrEvaluationFailed)
+	})
+}
+
+func TestConditionalCheckFuncReducers(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	concurrencyLimit := uint32(10)
+
+	conditionalX := conditionalHandler([]string{"x"})
+	conditionalY := conditionalHandler([]string{"y"})
+
+	t.Run("union", func(t *testing.T) {
+		resp, err := union(ctx, concurrencyLimit, falseHandler, conditionalX, conditionalY)
+		require.NoError(t, err)
+		require.False(t, resp.GetAllowed())
+		require.Equal(t, []string{"x", "y"}, resp.GetMissingParameters())
+
+		resp, err = union(ctx, concurrencyLimit, conditionalX, trueHandler)
+		require.NoError(t, err)
+		require.True(t, resp.GetAllowed())
+		require.Empty(t, resp.GetMissingParameters())
+	})
+
+	t.Run("intersection", func(t *testing.T) {
+		resp, err := intersection(ctx, concurrencyLimit, trueHandler, conditionalX, conditionalY)
+		require.NoError(t, err)
+		require.True(t, resp.IsConditional())
+		require.Equal(t, []string{"x", "y"}, resp.GetMissingParameters())
+
+		resp, err = intersection(ctx, concurrencyLimit, conditionalX, falseHandler)
+		require.NoError(t, err)
+		require.False(t, resp.GetAllowed())
+		require.False(t, resp.IsConditional())
+	})
+
+	t.Run("exclusion", func(t *testing.T) {
+		resp, err := exclusion(ctx, concurrencyLimit, conditionalX, falseHandler)
+		require.NoError(t, err)
+		require.Equal(t, []string{"x"}, resp.GetMissingParameters())
+
+		resp, err = exclusion(ctx, concurrencyLimit, trueHandler, conditionalY)
+		require.NoError(t, err)
+		require.Equal(t, []string{"y"}, resp.GetMissingParameters())
+
+		resp, err = exclusion(ctx, concurrencyLimit, conditionalX, conditionalY)
+		require.NoError(t, err)
+		require.Equal(t, []string{"x", "y"}, resp.GetMissingParameters())
+
+		resp, err = exclusion(ctx, concurrencyLimit, conditionalX, trueHandler)
+		require.NoError(t, err)
+		require.False(t, resp.GetAllowed())
+		require.False(t, resp.IsConditional())
+
+		resp, err = exclusion(ctx, concurrencyLimit, falseHandler, conditionalY)
+		require.NoError(t, err)
+		require.False(t, resp.GetAllowed())
+		require.False(t, resp.IsConditional())
+	})
+}
+
+func TestCheckSetSubjects(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID := ulid.Make().String()
+
+	model := parser.MustTransformDSLToProto(`
+		model
+			schema 1.1
+
+		type user
+		type employee
+
+		type group
+			relations
+				define member: [user, group#member]
+
+		type team
+			relations
+				define member: [user, employee]
+
+		type folder
+			relations
+				define viewer: [user, user:*, group#member]
+
+		type document
+			relations
+				define parent: [folder]
+				define owner: [user, user:*, group, group:*, group#member, team#member]
+				define editor: owner
+				define viewer: editor or viewer from parent
+				define public_editor: editor and viewer`)
+
+	err := ds.Write(context.Background(), storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("document:public", "owner", "user:*"),
+		tuple.NewTupleKey("document:groups", "owner", "group:*"),
+		tuple.NewTupleKey("folder:public", "viewer", "user:*"),
+		tuple.NewTupleKey("document:in_public_folder", "parent", "folder:public"),
+		tuple.NewTupleKey("group:eng", "member", "user:jon"),
+	})
+	require.NoError(t, err)
+
+	typesys, err := typesystem.NewAndValidate(context.Background(), model)
+	require.NoError(t, err)
+
+	checker := NewLocalCheckerWithCycleDetection()
+	t.Cleanup(checker.Close)
+
+	ctx := typesystem.ContextWithTypesystem(context.Background(), typesys)
+	ctx = storage.ContextWithRelationshipTupleReader(ctx, ds)
+
+	tests := []struct {
+		name     string
+		tupleKey *openfgav1.TupleKey
+		expected bool
+	}{
+		{
+			name:     "wildcard_subject_direct",
+			tupleKey: tuple.NewTupleKey("document:public", "owner", "user:*"),
+			expected: true,
+		},
+		{
+			name:     "wildcard_subject_computed_userset",
+			tupleKey: tuple.NewTupleKey("document:public", "editor", "user:*"),
+			expected: true,
+		},
+		{
+			name:     "wildcard_subject_ttu",
+			tupleKey: tuple.NewTupleKey("document:in_public_folder", "viewer", "user:*"),
+			expected: true,
+		},
+		{
+			name:     "userset_subject_contained_in_wildcard",
+			tupleKey: tuple.NewTupleKey("document:public", "editor", "group:eng#member"),
+			expected: true,
+		},
+		{
+			name:     "userset_subject_contained_in_wildcard_through_ttu",
+			tupleKey: tuple.NewTupleKey("document:in_public_folder", "viewer", "group:fga#member"),
+			expected: true,
+		},
+		{
+			name:     "userset_subject_with_other_member_types_not_contained_in_wildcard",
+			tupleKey: tuple.NewTupleKey("document:public", "owner", "team:a#member"),
+			expected: false,
+		},
+		{
+			name:     "userset_subject_not_contained_in_wildcard_of_its_object_type",
+			tupleKey: tuple.NewTupleKey("document:groups", "owner", "group:eng#member"),
+			expected: false,
+		},
+		{
+			name:     "object_contained_in_wildcard_of_its_type",
+			tupleKey: tuple.NewTupleKey("document:groups", "owner", "group:eng"),
+			expected: true,
+		},
+		{
+			name:     "intersection_with_set_subject",
+			tupleKey: tuple.NewTupleKey("document:public", "public_editor", "user:*"),
+			expected: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp, err := checker.ResolveCheck(ctx, &ResolveCheckRequest{
+				StoreID:              storeID,
+				AuthorizationModelID: typesys.GetAuthorizationModelID(),
+				TupleKey:             test.tupleKey,
+				RequestMetadata:      NewCheckRequestMetadata(defaultResolveNodeLimit),
+			})
+			require.NoError(t, err)
+			require.Equal(t, test.expected, resp.GetAllowed())
+		})
+	}
+}
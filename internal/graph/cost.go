@@ -0,0 +1,94 @@
+package graph
+
+import (
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// EstimateCosts returns the static worst-case costs of the Check and ListObjects queries on the relations of the
+// model, sorted by type and relation (see typesystem.EstimateCosts).
+func (g *RelationshipGraph) EstimateCosts() ([]*typesystem.RelationCost, error) {
+	costs := typesystem.EstimateCosts(g.typesystem)
+	for _, cost := range costs {
+		userTypes, err := g.ListObjectsCheckFallbackUserTypes(cost.Type, cost.Relation)
+		if err != nil {
+			return nil, err
+		}
+		cost.ListObjectsCheckFallbackUserTypes = userTypes
+	}
+
+	return costs, nil
+}
+
+// EstimateCost returns the static worst-case cost of the Check and ListObjects queries on a relation of the model.
+func (g *RelationshipGraph) EstimateCost(objectType, relation string) (*typesystem.RelationCost, error) {
+	cost, err := typesystem.EstimateCost(g.typesystem, objectType, relation)
+	if err != nil {
+		return nil, err
+	}
+
+	cost.ListObjectsCheckFallbackUserTypes, err = g.ListObjectsCheckFallbackUserTypes(objectType, relation)
+	if err != nil {
+		return nil, err
+	}
+
+	return cost, nil
+}
+
+// ListObjectsCheckFallbackUserTypes returns the sorted types of the users for which ListObjects on a relation needs
+// to Check each of the objects found by reverse expansion. Like reverse expansion, it follows the pruned relationship
+// edges from the user type up to the relation, and reports the user type if any of them involves an intersection or
+// an exclusion.
+func (g *RelationshipGraph) ListObjectsCheckFallbackUserTypes(objectType, relation string) ([]string, error) {
+	userTypes, err := g.typesystem.TerminalUserTypes(objectType, relation)
+	if err != nil {
+		return nil, err
+	}
+
+	target := typesystem.DirectRelationReference(objectType, relation)
+
+	fallbackUserTypes := []string{}
+	for _, userType := range userTypes {
+		requiresCheck, err := g.requiresCheckFallback(target, typesystem.DirectRelationReference(userType, ""))
+		if err != nil {
+			return nil, err
+		}
+
+		if requiresCheck {
+			fallbackUserTypes = append(fallbackUserTypes, userType)
+		}
+	}
+
+	return fallbackUserTypes, nil
+}
+
+func (g *RelationshipGraph) requiresCheckFallback(target, source *openfgav1.RelationReference) (bool, error) {
+	visited := map[string]struct{}{}
+	sources := []*openfgav1.RelationReference{source}
+
+	for len(sources) > 0 {
+		source, sources = sources[0], sources[1:]
+
+		edges, err := g.GetPrunedRelationshipEdges(target, source)
+		if err != nil {
+			return false, err
+		}
+
+		for _, edge := range edges {
+			if edge.TargetReferenceInvolvesIntersectionOrExclusion {
+				return true, nil
+			}
+
+			key := typesystem.GetRelationReferenceAsString(edge.TargetReference)
+			if _, ok := visited[key]; ok {
+				continue
+			}
+			visited[key] = struct{}{}
+
+			sources = append(sources, edge.TargetReference)
+		}
+	}
+
+	return false, nil
+}
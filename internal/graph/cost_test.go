@@ -0,0 +1,92 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+func TestListObjectsCheckFallbackUserTypes(t *testing.T) {
+	typesys, err := typesystem.NewAndValidate(context.Background(), testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type employee
+		type group
+			relations
+				define member: [user, employee, group#member]
+		type folder
+			relations
+				define allowed: [user]
+				define viewer: [user, employee] and allowed
+		type document
+			relations
+				define parent: [folder]
+				define owner: [user, group#member]
+				define blocked: [user]
+				define editor: owner but not blocked
+				define viewer: [employee] or viewer from parent`))
+	require.NoError(t, err)
+
+	g := New(typesys)
+
+	tests := map[string]struct {
+		objectType string
+		relation   string
+		expected   []string
+	}{
+		`direct_and_usersets`:             {objectType: "document", relation: "owner", expected: []string{}},
+		`intersection`:                    {objectType: "folder", relation: "viewer", expected: []string{"employee", "user"}},
+		`exclusion`:                       {objectType: "document", relation: "editor", expected: []string{"employee", "user"}},
+		`intersection_through_tupleset`:   {objectType: "document", relation: "viewer", expected: []string{"employee", "user"}},
+		`recursive_relation_without_sets`: {objectType: "group", relation: "member", expected: []string{}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			userTypes, err := g.ListObjectsCheckFallbackUserTypes(test.objectType, test.relation)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, userTypes)
+		})
+	}
+}
+
+func TestRelationshipGraphEstimateCosts(t *testing.T) {
+	typesys, err := typesystem.NewAndValidate(context.Background(), testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type document
+			relations
+				define allowed: [user]
+				define viewer: [user] and allowed`))
+	require.NoError(t, err)
+
+	g := New(typesys)
+
+	costs, err := g.EstimateCosts()
+	require.NoError(t, err)
+	require.Len(t, costs, 2)
+	require.Equal(t, "allowed", costs[0].Relation)
+	require.Empty(t, costs[0].ListObjectsCheckFallbackUserTypes)
+	require.Equal(t, "viewer", costs[1].Relation)
+	require.Equal(t, []string{"user"}, costs[1].ListObjectsCheckFallbackUserTypes)
+
+	cost, err := g.EstimateCost("document", "viewer")
+	require.NoError(t, err)
+	require.Equal(t, &typesystem.RelationCost{
+		Type:                              "document",
+		Relation:                          "viewer",
+		MaxDispatchDepth:                  1,
+		DatastoreReadKinds:                []typesystem.DatastoreReadKind{typesystem.DatastoreReadUserTuple},
+		HasIntersection:                   true,
+		ListObjectsCheckFallbackUserTypes: []string{"user"},
+	}, cost)
+
+	_, err = g.EstimateCost("document", "editor")
+	require.ErrorIs(t, err, typesystem.ErrRelationUndefined)
+}
@@ -59,6 +59,10 @@ type ResolveCheckRequestMetadata struct {
 
 	// WasThrottled indicates whether the request was throttled
 	WasThrottled *atomic.Bool
+
+	// ResolveNodeBreadthLimit, if non-zero, overrides the breadth limit the resolver was configured with
+	// (e.g. [[WithResolveNodeBreadthLimit]]) for this request and its subproblems.
+	ResolveNodeBreadthLimit uint32
 }
 
 func NewCheckRequestMetadata(maxDepth uint32) *ResolveCheckRequestMetadata {
@@ -0,0 +1,345 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/openfga/openfga/internal/throttler/threshold"
+	"github.com/openfga/openfga/internal/validation"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// recursiveEdge describes how the objects of a directly recursive relation 'objectType#relation' are linked
+// to each other, either through usersets of the relation itself (e.g. 'define member: [user, group#member]')
+// or through a tupleset relation to objects of the same type (e.g. 'define viewer: [user] or viewer from parent').
+type recursiveEdge struct {
+	// relation is the relation of the tuples linking one object to another.
+	relation string
+
+	// userRelation is the relation of the users of the tuples linking one object to another. It is
+	// empty if the objects are linked through a tupleset relation.
+	userRelation string
+}
+
+// recursiveEdgeFor returns the edge linking the objects of 'objectType#relation' if the relation is directly
+// recursive and can be evaluated with [[LocalChecker.checkRecursive]]. Relations involving conditions, usersets
+// of other relations or rewrites other than the recursive one are not eligible.
+func recursiveEdgeFor(typesys *typesystem.TypeSystem, objectType, relation string) (*recursiveEdge, bool) {
+	rel, err := typesys.GetRelation(objectType, relation)
+	if err != nil {
+		return nil, false
+	}
+
+	directlyRelatedTypes, err := typesys.GetDirectlyRelatedUserTypes(objectType, relation)
+	if err != nil {
+		return nil, false
+	}
+
+	recursiveUserset := false
+	for _, ref := range directlyRelatedTypes {
+		if ref.GetCondition() != "" {
+			return nil, false
+		}
+
+		if ref.GetRelation() == "" {
+			continue
+		}
+
+		if ref.GetType() != objectType || ref.GetRelation() != relation {
+			return nil, false
+		}
+
+		recursiveUserset = true
+	}
+
+	switch rw := rel.GetRewrite().GetUserset().(type) {
+	case *openfgav1.Userset_This:
+		if !recursiveUserset {
+			return nil, false
+		}
+
+		return &recursiveEdge{relation: relation, userRelation: relation}, true
+	case *openfgav1.Userset_Union:
+		children := rw.Union.GetChild()
+		if recursiveUserset || len(children) != 2 {
+			return nil, false
+		}
+
+		if _, ok := children[0].GetUserset().(*openfgav1.Userset_This); !ok {
+			return nil, false
+		}
+
+		ttu := children[1].GetTupleToUserset()
+		if ttu == nil || ttu.GetComputedUserset().GetRelation() != relation {
+			return nil, false
+		}
+
+		tuplesetRelation := ttu.GetTupleset().GetRelation()
+
+		tuplesetTypes, err := typesys.GetDirectlyRelatedUserTypes(objectType, tuplesetRelation)
+		if err != nil || len(tuplesetTypes) != 1 {
+			return nil, false
+		}
+
+		tuplesetType := tuplesetTypes[0]
+		if tuplesetType.GetType() != objectType || tuplesetType.GetRelationOrWildcard() != nil || tuplesetType.GetCondition() != "" {
+			return nil, false
+		}
+
+		return &recursiveEdge{relation: tuplesetRelation}, true
+	default:
+		return nil, false
+	}
+}
+
+// maxRecursiveCheckVisitedObjects is the maximum number of objects which the search of [[LocalChecker.checkRecursive]]
+// visits on both sides before falling back to dispatching once per hop.
+const maxRecursiveCheckVisitedObjects = 100_000
+
+// errRecursiveCheckLimitExceeded is returned by [[LocalChecker.checkRecursive]] when the search reaches its limits,
+// in which case the request is evaluated by dispatching once per hop instead.
+var errRecursiveCheckLimitExceeded = errors.New("recursive check limit exceeded")
+
+// checkRecursive evaluates the Check request for a directly recursive relation (see [[recursiveEdgeFor]]) with
+// a bidirectional breadth-first search over the objects linked by the edge, instead of dispatching once per hop.
+// The search expands forward from the object of the request (to the objects it is linked to) and in reverse from
+// the objects the user is directly related to (to the objects linked to them), always expanding the side with the
+// smaller frontier. The request is allowed as soon as both searches reach a common object.
+//
+// Each object expanded counts as a dispatch of the request, and the objects of a frontier are expanded concurrently
+// up to the breadth limit. The search reads the datastore at most depth times the breadth limit times (the amount
+// of work that dispatching could do concurrently at every remaining level), and visits at most
+// maxRecursiveCheckVisitedObjects objects. It returns errRecursiveCheckLimitExceeded once it reaches either limit.
+func (c *LocalChecker) checkRecursive(ctx context.Context, req *ResolveCheckRequest, edge *recursiveEdge) (*ResolveCheckResponse, error) {
+	ctx, span := tracer.Start(ctx, "checkRecursive", trace.WithAttributes(
+		attribute.String("edge_relation", edge.relation),
+	))
+	defer span.End()
+
+	typesys, ok := typesystem.TypesystemFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("typesystem missing in context")
+	}
+
+	ds, ok := storage.RelationshipTupleReaderFromContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("relationship tuple reader datastore missing in context")
+	}
+
+	reqTupleKey := req.GetTupleKey()
+	object := reqTupleKey.GetObject()
+	objectType := tuple.GetType(object)
+	userType := tuple.GetType(reqTupleKey.GetUser())
+
+	breadthLimit := c.breadthLimit(req)
+	maxReads := int(req.GetRequestMetadata().Depth) * int(breadthLimit)
+	reads := 0
+
+	response := &ResolveCheckResponse{
+		Allowed: false,
+		ResolutionMetadata: &ResolveCheckResponseMetadata{
+			DatastoreQueryCount: req.GetRequestMetadata().DatastoreQueryCount,
+		},
+	}
+
+	// the objects the user is directly related to seed the reverse search
+	reverseFrontier, err := c.readRecursiveObjects(ctx, ds, typesys, req.GetStoreID(), storage.ReadStartingWithUserFilter{
+		ObjectType: objectType,
+		Relation:   reqTupleKey.GetRelation(),
+		UserFilter: []*openfgav1.ObjectRelation{
+			{Object: reqTupleKey.GetUser()},
+			{Object: tuple.TypedPublicWildcard(userType)},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	reads++
+	response.GetResolutionMetadata().DatastoreQueryCount++
+
+	forwardFrontier := []string{object}
+	forwardVisited := map[string]struct{}{object: {}}
+	reverseVisited := make(map[string]struct{}, len(reverseFrontier))
+	for _, reached := range reverseFrontier {
+		reverseVisited[reached] = struct{}{}
+	}
+
+	for len(forwardFrontier) > 0 && len(reverseFrontier) > 0 {
+		forward := len(forwardFrontier) <= len(reverseFrontier)
+
+		frontier, visited, otherVisited := reverseFrontier, reverseVisited, forwardVisited
+		if forward {
+			frontier, visited, otherVisited = forwardFrontier, forwardVisited, reverseVisited
+		}
+
+		for _, current := range frontier {
+			if _, ok := otherVisited[current]; ok {
+				span.SetAttributes(attribute.Bool("allowed", true))
+				response.Allowed = true
+				return response, nil
+			}
+		}
+
+		if reads+len(frontier) > maxReads {
+			span.SetAttributes(attribute.Int("datastore_reads", reads))
+			return nil, errRecursiveCheckLimitExceeded
+		}
+
+		c.throttleRecursiveCheck(ctx, req, len(frontier))
+
+		linked := make([][]string, len(frontier))
+		grp, grpCtx := errgroup.WithContext(ctx)
+		grp.SetLimit(int(breadthLimit))
+		for i, current := range frontier {
+			grp.Go(func() error {
+				ctx := grpCtx
+				var err error
+				if forward {
+					linked[i], err = c.readRecursiveLinkedObjects(ctx, ds, typesys, req.GetStoreID(), current, edge)
+				} else {
+					linked[i], err = c.readRecursiveObjects(ctx, ds, typesys, req.GetStoreID(), storage.ReadStartingWithUserFilter{
+						ObjectType: objectType,
+						Relation:   edge.relation,
+						UserFilter: []*openfgav1.ObjectRelation{{Object: current, Relation: edge.userRelation}},
+					})
+				}
+				return err
+			})
+		}
+		if err := grp.Wait(); err != nil {
+			return nil, err
+		}
+		reads += len(frontier)
+		response.GetResolutionMetadata().DatastoreQueryCount += uint32(len(frontier))
+
+		var nextFrontier []string
+		for _, objects := range linked {
+			for _, reached := range objects {
+				if _, ok := otherVisited[reached]; ok {
+					span.SetAttributes(attribute.Bool("allowed", true))
+					response.Allowed = true
+					return response, nil
+				}
+
+				if _, ok := visited[reached]; ok {
+					continue
+				}
+
+				if len(forwardVisited)+len(reverseVisited) >= maxRecursiveCheckVisitedObjects {
+					span.SetAttributes(attribute.Int("visited_objects", len(forwardVisited)+len(reverseVisited)))
+					return nil, errRecursiveCheckLimitExceeded
+				}
+
+				visited[reached] = struct{}{}
+				nextFrontier = append(nextFrontier, reached)
+			}
+		}
+
+		if forward {
+			forwardFrontier = nextFrontier
+		} else {
+			reverseFrontier = nextFrontier
+		}
+	}
+
+	return response, nil
+}
+
+// throttleRecursiveCheck counts the expansion of the objects of a frontier as dispatches of the request, and
+// throttles the search as the dispatch throttling resolver throttles dispatches.
+func (c *LocalChecker) throttleRecursiveCheck(ctx context.Context, req *ResolveCheckRequest, expanded int) {
+	dispatchCount := req.GetRequestMetadata().DispatchCounter.Add(uint32(expanded))
+
+	if c.throttler == nil {
+		return
+	}
+
+	if threshold.ShouldThrottle(ctx, dispatchCount, c.throttlingConfig.DefaultThreshold, c.throttlingConfig.MaxThreshold) {
+		req.GetRequestMetadata().WasThrottled.Store(true)
+		c.throttler.Throttle(ctx)
+	}
+}
+
+// readRecursiveLinkedObjects returns the objects that the provided object is linked to through the edge
+// (e.g. 'group:2' for the tuple 'group:1#member@group:2#member').
+func (c *LocalChecker) readRecursiveLinkedObjects(
+	ctx context.Context,
+	ds storage.RelationshipTupleReader,
+	typesys *typesystem.TypeSystem,
+	storeID, object string,
+	edge *recursiveEdge,
+) ([]string, error) {
+	var iter storage.TupleIterator
+	var err error
+	if edge.userRelation != "" {
+		iter, err = ds.ReadUsersetTuples(ctx, storeID, storage.ReadUsersetTuplesFilter{
+			Object:                      object,
+			Relation:                    edge.relation,
+			AllowedUserTypeRestrictions: []*openfgav1.RelationReference{typesystem.DirectRelationReference(tuple.GetType(object), edge.userRelation)},
+		})
+	} else {
+		iter, err = ds.Read(ctx, storeID, tuple.NewTupleKey(object, edge.relation, ""))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return collectRecursiveObjects(ctx, iter, typesys, func(t *openfgav1.TupleKey) string {
+		userObject, _ := tuple.SplitObjectRelation(t.GetUser())
+		return userObject
+	})
+}
+
+// readRecursiveObjects returns the objects of the tuples matching the provided filter.
+func (c *LocalChecker) readRecursiveObjects(
+	ctx context.Context,
+	ds storage.RelationshipTupleReader,
+	typesys *typesystem.TypeSystem,
+	storeID string,
+	filter storage.ReadStartingWithUserFilter,
+) ([]string, error) {
+	iter, err := ds.ReadStartingWithUser(ctx, storeID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectRecursiveObjects(ctx, iter, typesys, func(t *openfgav1.TupleKey) string {
+		return t.GetObject()
+	})
+}
+
+func collectRecursiveObjects(
+	ctx context.Context,
+	iter storage.TupleIterator,
+	typesys *typesystem.TypeSystem,
+	objectOf func(t *openfgav1.TupleKey) string,
+) ([]string, error) {
+	// filter out invalid tuples yielded by the database iterator
+	filteredIter := storage.NewFilteredTupleKeyIterator(
+		storage.NewTupleKeyIteratorFromTupleIterator(iter),
+		validation.FilterInvalidTuples(typesys),
+	)
+	defer filteredIter.Stop()
+
+	var objects []string
+	for {
+		t, err := filteredIter.Next(ctx)
+		if err != nil {
+			if errors.Is(err, storage.ErrIteratorDone) {
+				return objects, nil
+			}
+
+			return nil, err
+		}
+
+		objects = append(objects, objectOf(t))
+	}
+}
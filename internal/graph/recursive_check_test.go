@@ -0,0 +1,248 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	parser "github.com/openfga/language/pkg/go/transformer"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+func TestRecursiveEdgeFor(t *testing.T) {
+	model := parser.MustTransformDSLToProto(`
+		model
+			schema 1.1
+
+		type user
+		type employee
+
+		type group
+			relations
+				define member: [user, user:*, group#member]
+				define owner: [user, group#member]
+				define admin: [user, group#admin with x_less_than]
+				define viewer: [user, group#viewer] or owner
+
+		type folder
+			relations
+				define parent: [folder]
+				define other_parent: [folder, group]
+				define viewer: [user, user:*] or viewer from parent
+				define editor: [user, group#member] or editor from parent
+				define owner: [user] or owner from other_parent
+				define blocked: [user]
+				define restricted: ([user] or restricted from parent) but not blocked
+
+		condition x_less_than(x: int) {
+			x < 100
+		}`)
+
+	typesys, err := typesystem.NewAndValidate(context.Background(), model)
+	require.NoError(t, err)
+
+	tests := []struct {
+		objectType string
+		relation   string
+		expected   *recursiveEdge
+	}{
+		{objectType: "group", relation: "member", expected: &recursiveEdge{relation: "member", userRelation: "member"}},
+		{objectType: "group", relation: "owner"},
+		{objectType: "group", relation: "admin"},
+		{objectType: "group", relation: "viewer"},
+		{objectType: "folder", relation: "viewer", expected: &recursiveEdge{relation: "parent"}},
+		{objectType: "folder", relation: "editor"},
+		{objectType: "folder", relation: "owner"},
+		{objectType: "folder", relation: "restricted"},
+		{objectType: "folder", relation: "blocked"},
+		{objectType: "folder", relation: "undefined"},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s#%s", test.objectType, test.relation), func(t *testing.T) {
+			edge, ok := recursiveEdgeFor(typesys, test.objectType, test.relation)
+			require.Equal(t, test.expected != nil, ok)
+			require.Equal(t, test.expected, edge)
+		})
+	}
+}
+
+func TestCheckRecursiveFastPath(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID := ulid.Make().String()
+
+	model := parser.MustTransformDSLToProto(`
+		model
+			schema 1.1
+
+		type user
+		type employee
+
+		type group
+			relations
+				define member: [user, employee:*, group#member]
+
+		type folder
+			relations
+				define parent: [folder]
+				define viewer: [user] or viewer from parent
+
+		type document
+			relations
+				define parent: [folder]
+				define viewer: [group#member] or viewer from parent`)
+
+	typesys, err := typesystem.NewAndValidate(context.Background(), model)
+	require.NoError(t, err)
+
+	// a chain of nested groups and folders deeper than the resolution depth limit
+	const depth = 2 * defaultResolveNodeLimit
+	var tuples []*openfgav1.TupleKey
+	for i := 0; i < depth; i++ {
+		tuples = append(tuples,
+			tuple.NewTupleKey(fmt.Sprintf("group:%d", i), "member", fmt.Sprintf("group:%d#member", i+1)),
+			tuple.NewTupleKey(fmt.Sprintf("folder:%d", i), "parent", fmt.Sprintf("folder:%d", i+1)),
+		)
+	}
+
+	tuples = append(tuples,
+		tuple.NewTupleKey(fmt.Sprintf("group:%d", depth), "member", "user:jon"),
+		tuple.NewTupleKey(fmt.Sprintf("group:%d", depth), "member", "employee:*"),
+		tuple.NewTupleKey(fmt.Sprintf("folder:%d", depth), "viewer", "user:jon"),
+		tuple.NewTupleKey("document:1", "viewer", "group:0#member"),
+		tuple.NewTupleKey("document:2", "parent", "folder:0"),
+
+		// a cycle of groups and folders
+		tuple.NewTupleKey("group:cycle1", "member", "group:cycle2#member"),
+		tuple.NewTupleKey("group:cycle2", "member", "group:cycle1#member"),
+		tuple.NewTupleKey("group:cycle2", "member", "user:maria"),
+		tuple.NewTupleKey("folder:cycle1", "parent", "folder:cycle2"),
+		tuple.NewTupleKey("folder:cycle2", "parent", "folder:cycle1"),
+		tuple.NewTupleKey("folder:cycle2", "viewer", "user:maria"),
+	)
+
+	for i := 0; i < len(tuples); i += storage.DefaultMaxTuplesPerWrite {
+		err := ds.Write(context.Background(), storeID, nil, tuples[i:min(i+storage.DefaultMaxTuplesPerWrite, len(tuples))])
+		require.NoError(t, err)
+	}
+
+	ctx := typesystem.ContextWithTypesystem(context.Background(), typesys)
+	ctx = storage.ContextWithRelationshipTupleReader(ctx, ds)
+
+	fastPathChecker := NewLocalCheckerWithCycleDetection(WithRecursiveFastPath())
+	t.Cleanup(fastPathChecker.Close)
+
+	checker := NewLocalCheckerWithCycleDetection()
+	t.Cleanup(checker.Close)
+
+	resolveCheck := func(checker CheckResolver, tk *openfgav1.TupleKey) (*ResolveCheckResponse, error) {
+		return checker.ResolveCheck(ctx, &ResolveCheckRequest{
+			StoreID:              storeID,
+			AuthorizationModelID: typesys.GetAuthorizationModelID(),
+			TupleKey:             tk,
+			RequestMetadata:      NewCheckRequestMetadata(defaultResolveNodeLimit),
+		})
+	}
+
+	t.Run("nesting_deeper_than_resolution_depth_limit", func(t *testing.T) {
+		tests := []struct {
+			tupleKey *openfgav1.TupleKey
+			expected bool
+		}{
+			{tupleKey: tuple.NewTupleKey("group:0", "member", "user:jon"), expected: true},
+			{tupleKey: tuple.NewTupleKey("group:0", "member", "employee:bob"), expected: true},
+			{tupleKey: tuple.NewTupleKey("group:0", "member", "user:maria"), expected: false},
+			{tupleKey: tuple.NewTupleKey("document:1", "viewer", "user:jon"), expected: true},
+			{tupleKey: tuple.NewTupleKey("folder:0", "viewer", "user:jon"), expected: true},
+			{tupleKey: tuple.NewTupleKey("document:2", "viewer", "user:jon"), expected: true},
+			{tupleKey: tuple.NewTupleKey("document:2", "viewer", "user:maria"), expected: false},
+		}
+
+		for _, test := range tests {
+			t.Run(tuple.TupleKeyToString(test.tupleKey), func(t *testing.T) {
+				_, err := resolveCheck(checker, test.tupleKey)
+				require.ErrorIs(t, err, ErrResolutionDepthExceeded)
+
+				resp, err := resolveCheck(fastPathChecker, test.tupleKey)
+				require.NoError(t, err)
+				require.Equal(t, test.expected, resp.GetAllowed())
+			})
+		}
+	})
+
+	t.Run("expansions_count_as_dispatches", func(t *testing.T) {
+		metadata := NewCheckRequestMetadata(defaultResolveNodeLimit)
+		resp, err := fastPathChecker.ResolveCheck(ctx, &ResolveCheckRequest{
+			StoreID:              storeID,
+			AuthorizationModelID: typesys.GetAuthorizationModelID(),
+			TupleKey:             tuple.NewTupleKey("group:0", "member", "user:jon"),
+			RequestMetadata:      metadata,
+		})
+		require.NoError(t, err)
+		require.True(t, resp.GetAllowed())
+		require.GreaterOrEqual(t, metadata.DispatchCounter.Load(), uint32(depth))
+	})
+
+	t.Run("falls_back_to_dispatching_per_level_beyond_the_read_budget", func(t *testing.T) {
+		// a depth of 2 with a breadth limit of 1 allows 2 reads only
+		limitedChecker := NewLocalCheckerWithCycleDetection(WithRecursiveFastPath(), WithResolveNodeBreadthLimit(1))
+		t.Cleanup(limitedChecker.Close)
+
+		_, err := limitedChecker.ResolveCheck(ctx, &ResolveCheckRequest{
+			StoreID:              storeID,
+			AuthorizationModelID: typesys.GetAuthorizationModelID(),
+			TupleKey:             tuple.NewTupleKey("group:0", "member", "user:jon"),
+			RequestMetadata:      NewCheckRequestMetadata(2),
+		})
+		require.ErrorIs(t, err, ErrResolutionDepthExceeded)
+
+		resp, err := limitedChecker.ResolveCheck(ctx, &ResolveCheckRequest{
+			StoreID:              storeID,
+			AuthorizationModelID: typesys.GetAuthorizationModelID(),
+			TupleKey:             tuple.NewTupleKey(fmt.Sprintf("group:%d", depth-1), "member", "user:jon"),
+			RequestMetadata:      NewCheckRequestMetadata(2),
+		})
+		require.NoError(t, err)
+		require.True(t, resp.GetAllowed())
+	})
+
+	t.Run("same_outcome_as_dispatching_per_level", func(t *testing.T) {
+		var tupleKeys []*openfgav1.TupleKey
+		for _, object := range []string{"group:cycle1", "group:cycle2", fmt.Sprintf("group:%d", depth-2)} {
+			for _, user := range []string{"user:jon", "user:maria", "employee:bob"} {
+				tupleKeys = append(tupleKeys, tuple.NewTupleKey(object, "member", user))
+			}
+		}
+
+		for _, object := range []string{"folder:cycle1", "folder:cycle2", fmt.Sprintf("folder:%d", depth-2)} {
+			for _, user := range []string{"user:jon", "user:maria"} {
+				tupleKeys = append(tupleKeys, tuple.NewTupleKey(object, "viewer", user))
+			}
+		}
+
+		for _, tk := range tupleKeys {
+			t.Run(tuple.TupleKeyToString(tk), func(t *testing.T) {
+				expected, err := resolveCheck(checker, tk)
+				require.NoError(t, err)
+
+				actual, err := resolveCheck(fastPathChecker, tk)
+				require.NoError(t, err)
+				require.Equal(t, expected.GetAllowed(), actual.GetAllowed())
+			})
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package materialize
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// CheckResolver answers Checks of materialized relations from an [[Index]] when it was synced with the
+// changelog of the store recently enough, and delegates every other Check (or every Check with contextual
+// tuples) to the next resolver (e.g. a [[graph.LocalChecker]]).
+//
+// The answers from the Index miss the changes written since the start of its last sync, which is at most the
+// max staleness of the Index ago.
+type CheckResolver struct {
+	delegate graph.CheckResolver
+	index    *Index
+}
+
+var _ graph.CheckResolver = (*CheckResolver)(nil)
+
+// NewCheckResolver constructs a CheckResolver answering from the provided Index. The Index is not owned
+// by the CheckResolver and must be closed separately.
+func NewCheckResolver(index *Index) *CheckResolver {
+	r := &CheckResolver{
+		index: index,
+	}
+	r.delegate = r
+
+	return r
+}
+
+func (r *CheckResolver) SetDelegate(delegate graph.CheckResolver) {
+	r.delegate = delegate
+}
+
+func (r *CheckResolver) GetDelegate() graph.CheckResolver {
+	return r.delegate
+}
+
+// Close implements graph.CheckResolver.
+func (r *CheckResolver) Close() {}
+
+// ResolveCheck implements graph.CheckResolver.
+func (r *CheckResolver) ResolveCheck(ctx context.Context, req *graph.ResolveCheckRequest) (*graph.ResolveCheckResponse, error) {
+	span := trace.SpanFromContext(ctx)
+
+	typesys, ok := typesystem.TypesystemFromContext(ctx)
+	if !ok || len(req.GetContextualTuples()) > 0 {
+		return r.delegate.ResolveCheck(ctx, req)
+	}
+
+	tk := req.GetTupleKey()
+
+	allowed, ok := r.index.Lookup(ctx, typesys, req.GetStoreID(), tk.GetObject(), tk.GetRelation(), tk.GetUser())
+	span.SetAttributes(attribute.Bool("materialized", ok))
+	if !ok {
+		return r.delegate.ResolveCheck(ctx, req)
+	}
+
+	return &graph.ResolveCheckResponse{
+		Allowed: allowed,
+		ResolutionMetadata: &graph.ResolveCheckResponseMetadata{
+			DatastoreQueryCount: req.GetRequestMetadata().DatastoreQueryCount,
+		},
+	}, nil
+}
@@ -0,0 +1,126 @@
+package materialize
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"go.uber.org/mock/gomock"
+
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+func TestCheckResolver(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID := ulid.Make().String()
+
+	typesys, err := typesystem.NewAndValidate(context.Background(), testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+
+		type user
+
+		type document
+			relations
+				define owner: [user]
+				define viewer: [user] or owner`))
+	require.NoError(t, err)
+
+	err = ds.Write(context.Background(), storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("document:1", "owner", "user:jon"),
+	})
+	require.NoError(t, err)
+
+	index := NewIndex(ds, WithRelation("document", "viewer"), WithSyncInterval(time.Hour))
+	t.Cleanup(index.Close)
+
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	mockCheckResolver := graph.NewMockCheckResolver(ctrl)
+
+	resolver := NewCheckResolver(index)
+	t.Cleanup(resolver.Close)
+	resolver.SetDelegate(mockCheckResolver)
+
+	ctx := typesystem.ContextWithTypesystem(context.Background(), typesys)
+
+	newRequest := func(tk *openfgav1.TupleKey, contextualTuples ...*openfgav1.TupleKey) *graph.ResolveCheckRequest {
+		return &graph.ResolveCheckRequest{
+			StoreID:              storeID,
+			AuthorizationModelID: typesys.GetAuthorizationModelID(),
+			TupleKey:             tk,
+			ContextualTuples:     contextualTuples,
+			RequestMetadata:      graph.NewCheckRequestMetadata(25),
+		}
+	}
+
+	t.Run("delegates_until_the_store_is_indexed", func(t *testing.T) {
+		mockCheckResolver.EXPECT().
+			ResolveCheck(gomock.Any(), gomock.Any()).
+			Return(&graph.ResolveCheckResponse{Allowed: true}, nil)
+
+		resp, err := resolver.ResolveCheck(ctx, newRequest(tuple.NewTupleKey("document:1", "viewer", "user:jon")))
+		require.NoError(t, err)
+		require.True(t, resp.GetAllowed())
+	})
+
+	index.Sync(context.Background())
+
+	t.Run("answers_from_the_index", func(t *testing.T) {
+		resp, err := resolver.ResolveCheck(ctx, newRequest(tuple.NewTupleKey("document:1", "viewer", "user:jon")))
+		require.NoError(t, err)
+		require.True(t, resp.GetAllowed())
+
+		resp, err = resolver.ResolveCheck(ctx, newRequest(tuple.NewTupleKey("document:1", "viewer", "user:maria")))
+		require.NoError(t, err)
+		require.False(t, resp.GetAllowed())
+	})
+
+	t.Run("delegates_relations_that_are_not_materialized", func(t *testing.T) {
+		mockCheckResolver.EXPECT().
+			ResolveCheck(gomock.Any(), gomock.Any()).
+			Return(&graph.ResolveCheckResponse{Allowed: true}, nil)
+
+		resp, err := resolver.ResolveCheck(ctx, newRequest(tuple.NewTupleKey("document:1", "owner", "user:jon")))
+		require.NoError(t, err)
+		require.True(t, resp.GetAllowed())
+	})
+
+	t.Run("delegates_requests_with_contextual_tuples", func(t *testing.T) {
+		mockCheckResolver.EXPECT().
+			ResolveCheck(gomock.Any(), gomock.Any()).
+			Return(&graph.ResolveCheckResponse{Allowed: true}, nil)
+
+		resp, err := resolver.ResolveCheck(ctx, newRequest(
+			tuple.NewTupleKey("document:1", "viewer", "user:maria"),
+			tuple.NewTupleKey("document:1", "viewer", "user:maria"),
+		))
+		require.NoError(t, err)
+		require.True(t, resp.GetAllowed())
+	})
+
+	t.Run("delegates_without_typesystem_in_context", func(t *testing.T) {
+		mockCheckResolver.EXPECT().
+			ResolveCheck(gomock.Any(), gomock.Any()).
+			Return(&graph.ResolveCheckResponse{Allowed: false}, nil)
+
+		resp, err := resolver.ResolveCheck(context.Background(), newRequest(tuple.NewTupleKey("document:1", "viewer", "user:jon")))
+		require.NoError(t, err)
+		require.False(t, resp.GetAllowed())
+	})
+}
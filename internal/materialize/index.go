@@ -0,0 +1,757 @@
+// Package materialize maintains precomputed permissions for a few hot relations so that Checks against them
+// can be answered without evaluating the authorization model.
+package materialize
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/server/commands/listusers"
+	"github.com/openfga/openfga/pkg/server/commands/reverseexpand"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+var tracer = otel.Tracer("openfga/internal/materialize")
+
+const (
+	// DefaultSyncInterval is the default interval at which an Index applies the changes of the changelog.
+	DefaultSyncInterval = 1 * time.Second
+
+	// DefaultMaxStores is the default maximum number of stores an Index indexes at once, counting a store once
+	// per authorization model it is indexed for.
+	DefaultMaxStores = 100
+
+	// DefaultMaxStaleness is the default maximum time since the start of the last sync of the index of a store
+	// for which lookups are answered from it.
+	DefaultMaxStaleness = 2 * DefaultSyncInterval
+
+	// DefaultMaxEntriesPerStore is the default maximum number of (user, object) pairs the index of a store holds,
+	// beyond which the store is no longer indexed.
+	DefaultMaxEntriesPerStore = 1000000
+
+	changelogPageSize = 100
+)
+
+// Datastore is the subset of [[storage.OpenFGADatastore]] an Index reads from.
+type Datastore interface {
+	storage.RelationshipTupleReader
+	storage.ChangelogBackend
+}
+
+// Index maintains, for a set of configured hot relations (e.g. 'document#viewer'), the precomputed set of
+// objects each user is related to. The objects of a store are indexed for an authorization model the first time
+// the store is looked up under that model, and the index is then updated incrementally from the changelog of the
+// store at every sync interval. At most maxStores indexes of a store and a model are kept at once: registering
+// another one evicts the index looked up least recently. An index holding more than maxEntriesPerStore entries is
+// dropped, and the lookups of its store and model are no longer answered.
+//
+// Only relations which do not involve intersections, exclusions or conditional relationships are indexed, since
+// their outcome can't be precomputed independently of the request or recomputed for the objects affected by a
+// change. Conditions of the model which the relation doesn't involve don't prevent it from being indexed.
+type Index struct {
+	ds           Datastore
+	logger       logger.Logger
+	relations    map[string]map[string]struct{}
+	syncInterval time.Duration
+	maxStores    int
+
+	maxStaleness       time.Duration
+	maxEntriesPerStore int
+
+	mu sync.RWMutex
+
+	// stores maps the store ID and authorization model ID, as 'store|model', to the index of the store for the
+	// model.
+	stores map[string]*storeIndex
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// storeIndex is the index of the hot relations of a single store, for a single authorization model.
+type storeIndex struct {
+	storeID string
+	typesys *typesystem.TypeSystem
+
+	// relations are the hot relations which can be indexed under the authorization model, as 'type#relation'.
+	relations []string
+
+	// built indicates whether the objects of the store have been indexed.
+	built bool
+
+	// continuationToken is the position in the changelog up to which the index is up-to-date.
+	continuationToken string
+
+	// syncedAt is the start of the last sync of the index, whose changes it's up-to-date with.
+	syncedAt time.Time
+
+	// overflowed indicates whether the index held more than maxEntriesPerStore entries and was dropped.
+	overflowed bool
+
+	// entries is the number of (user, object) pairs of the index.
+	entries int
+
+	// lastLookup is the time, in Unix nanoseconds, of the last lookup of the store, to evict the stores looked up
+	// least recently.
+	lastLookup atomic.Int64
+
+	// objectsByUser maps each hot relation to the objects each user is related to.
+	objectsByUser map[string]map[string]map[string]struct{}
+
+	// usersByObject maps each hot relation to the users related to each object.
+	usersByObject map[string]map[string]map[string]struct{}
+}
+
+// IndexOption defines an option that can be used to change the behavior of an Index.
+type IndexOption func(i *Index)
+
+// WithRelation configures the Index to materialize the relation 'objectType#relation'.
+func WithRelation(objectType, relation string) IndexOption {
+	return func(i *Index) {
+		if _, ok := i.relations[objectType]; !ok {
+			i.relations[objectType] = map[string]struct{}{}
+		}
+
+		i.relations[objectType][relation] = struct{}{}
+	}
+}
+
+// WithSyncInterval sets the interval at which the Index applies the changes of the changelog.
+func WithSyncInterval(interval time.Duration) IndexOption {
+	return func(i *Index) {
+		i.syncInterval = interval
+	}
+}
+
+// WithMaxStores sets the maximum number of stores the Index indexes at once. See DefaultMaxStores.
+func WithMaxStores(maxStores int) IndexOption {
+	return func(i *Index) {
+		i.maxStores = maxStores
+	}
+}
+
+// WithMaxStaleness sets the maximum time since the start of the last sync of the index of a store for which
+// lookups are answered from it. See DefaultMaxStaleness.
+func WithMaxStaleness(maxStaleness time.Duration) IndexOption {
+	return func(i *Index) {
+		i.maxStaleness = maxStaleness
+	}
+}
+
+// WithMaxEntriesPerStore sets the maximum number of (user, object) pairs the index of a store holds. See
+// DefaultMaxEntriesPerStore.
+func WithMaxEntriesPerStore(maxEntries int) IndexOption {
+	return func(i *Index) {
+		i.maxEntriesPerStore = maxEntries
+	}
+}
+
+// WithLogger sets the logger used to report failures to sync the Index.
+func WithLogger(l logger.Logger) IndexOption {
+	return func(i *Index) {
+		i.logger = l
+	}
+}
+
+// NewIndex constructs an Index and starts syncing it in the background.
+// You must call Close on it after you are done using it.
+func NewIndex(ds Datastore, opts ...IndexOption) *Index {
+	i := &Index{
+		ds:                 ds,
+		logger:             logger.NewNoopLogger(),
+		relations:          map[string]map[string]struct{}{},
+		syncInterval:       DefaultSyncInterval,
+		maxStores:          DefaultMaxStores,
+		maxStaleness:       DefaultMaxStaleness,
+		maxEntriesPerStore: DefaultMaxEntriesPerStore,
+		stores:             map[string]*storeIndex{},
+		stop:               make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(i)
+	}
+
+	i.wg.Add(1)
+	go func() {
+		defer i.wg.Done()
+
+		ticker := time.NewTicker(i.syncInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-i.stop:
+				return
+			case <-ticker.C:
+				i.Sync(context.Background())
+			}
+		}
+	}()
+
+	return i
+}
+
+// Close stops syncing the Index.
+func (i *Index) Close() {
+	close(i.stop)
+	i.wg.Wait()
+}
+
+// IsMaterialized returns true if the Index is configured to materialize 'objectType#relation'.
+func (i *Index) IsMaterialized(objectType, relation string) bool {
+	_, ok := i.relations[objectType][relation]
+	return ok
+}
+
+// Lookup returns whether the user is related to the object through the relation, according to the index of
+// the store built for the provided authorization model. The second return value is false if the Index can't
+// answer, because the relation isn't materialized, the user is a userset, or the index isn't built, was dropped
+// or wasn't synced successfully for longer than maxStaleness. The changes written since the start of the last sync
+// (e.g. the revocation of a permission) are missed by the lookups until the next sync applies them.
+//
+// The first lookup of a store under an authorization model registers it to be indexed for that model.
+func (i *Index) Lookup(ctx context.Context, typesys *typesystem.TypeSystem, storeID, object, relation, user string) (bool, bool) {
+	objectType := tuple.GetType(object)
+	if !i.IsMaterialized(objectType, relation) || tuple.IsObjectRelation(user) {
+		return false, false
+	}
+
+	key := storeIndexKey(storeID, typesys.GetAuthorizationModelID())
+
+	i.mu.RLock()
+	st, ok := i.stores[key]
+	if !ok {
+		i.mu.RUnlock()
+		i.register(typesys, storeID)
+
+		return false, false
+	}
+	defer i.mu.RUnlock()
+
+	st.lastLookup.Store(time.Now().UnixNano())
+	if !st.built || st.overflowed || time.Since(st.syncedAt) > i.maxStaleness {
+		return false, false
+	}
+
+	objects, ok := st.objectsByUser[tuple.ToObjectRelationString(objectType, relation)]
+	if !ok {
+		return false, false
+	}
+
+	if _, ok := objects[user][object]; ok {
+		return true, true
+	}
+
+	if _, ok := objects[tuple.TypedPublicWildcard(tuple.GetType(user))][object]; ok {
+		return true, true
+	}
+
+	return false, true
+}
+
+// storeIndexKey returns the key of the index of the store for the authorization model.
+func storeIndexKey(storeID, modelID string) string {
+	return fmt.Sprintf("%s|%s", storeID, modelID)
+}
+
+// register starts indexing the store for the provided authorization model.
+func (i *Index) register(typesys *typesystem.TypeSystem, storeID string) {
+	st := &storeIndex{
+		storeID:       storeID,
+		typesys:       typesys,
+		objectsByUser: map[string]map[string]map[string]struct{}{},
+		usersByObject: map[string]map[string]map[string]struct{}{},
+	}
+
+	st.lastLookup.Store(time.Now().UnixNano())
+
+	for objectType, relations := range i.relations {
+		for relation := range relations {
+			relationKey := tuple.ToObjectRelationString(objectType, relation)
+			if !isMaterializable(typesys, objectType, relation) {
+				i.logger.Info("the relation can't be materialized under the authorization model of the store",
+					zap.String("store_id", storeID),
+					zap.String("authorization_model_id", typesys.GetAuthorizationModelID()),
+					zap.String("relation", relationKey))
+				continue
+			}
+
+			st.relations = append(st.relations, relationKey)
+			st.objectsByUser[relationKey] = map[string]map[string]struct{}{}
+			st.usersByObject[relationKey] = map[string]map[string]struct{}{}
+		}
+	}
+
+	key := storeIndexKey(storeID, typesys.GetAuthorizationModelID())
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if _, ok := i.stores[key]; ok {
+		return
+	}
+
+	if i.maxStores > 0 && len(i.stores) >= i.maxStores {
+		i.evictLeastRecentlyLookedUp()
+	}
+
+	i.stores[key] = st
+}
+
+// evictLeastRecentlyLookedUp drops the index of the store looked up least recently. It must be called with the
+// lock held.
+func (i *Index) evictLeastRecentlyLookedUp() {
+	var evictedKey string
+	var evictedLastLookup int64
+	for key, st := range i.stores {
+		if lastLookup := st.lastLookup.Load(); evictedKey == "" || lastLookup < evictedLastLookup {
+			evictedKey, evictedLastLookup = key, lastLookup
+		}
+	}
+
+	delete(i.stores, evictedKey)
+}
+
+// isMaterializable returns true if the relation 'objectType#relation' can be indexed under the typesystem.
+func isMaterializable(typesys *typesystem.TypeSystem, objectType, relation string) bool {
+	if _, err := typesys.GetRelation(objectType, relation); err != nil {
+		return false
+	}
+
+	if involvesConditions(typesys, objectType, relation, map[string]struct{}{}) {
+		return false
+	}
+
+	involvesIntersection, err := typesys.RelationInvolvesIntersection(objectType, relation)
+	if err != nil || involvesIntersection {
+		return false
+	}
+
+	involvesExclusion, err := typesys.RelationInvolvesExclusion(objectType, relation)
+	if err != nil || involvesExclusion {
+		return false
+	}
+
+	return true
+}
+
+// involvesConditions returns true if the relation 'objectType#relation', or a relation it refers to, may be
+// defined by conditional relationships.
+func involvesConditions(typesys *typesystem.TypeSystem, objectType, relation string, visited map[string]struct{}) bool {
+	relationKey := tuple.ToObjectRelationString(objectType, relation)
+	if _, ok := visited[relationKey]; ok {
+		return false
+	}
+	visited[relationKey] = struct{}{}
+
+	rel, err := typesys.GetRelation(objectType, relation)
+	if err != nil {
+		return false
+	}
+
+	involves, err := typesystem.WalkUsersetRewrite(rel.GetRewrite(), func(r *openfgav1.Userset) interface{} {
+		switch rw := r.GetUserset().(type) {
+		case *openfgav1.Userset_This:
+			for _, rr := range rel.GetTypeInfo().GetDirectlyRelatedUserTypes() {
+				if rr.GetCondition() != "" {
+					return true
+				}
+
+				if rr.GetRelation() != "" && involvesConditions(typesys, rr.GetType(), rr.GetRelation(), visited) {
+					return true
+				}
+			}
+		case *openfgav1.Userset_ComputedUserset:
+			if involvesConditions(typesys, objectType, rw.ComputedUserset.GetRelation(), visited) {
+				return true
+			}
+		case *openfgav1.Userset_TupleToUserset:
+			tuplesetRelation := rw.TupleToUserset.GetTupleset().GetRelation()
+			if involvesConditions(typesys, objectType, tuplesetRelation, visited) {
+				return true
+			}
+
+			tuplesetTypes, err := typesys.GetDirectlyRelatedUserTypes(objectType, tuplesetRelation)
+			if err != nil {
+				return true
+			}
+
+			for _, rr := range tuplesetTypes {
+				if involvesConditions(typesys, rr.GetType(), rw.TupleToUserset.GetComputedUserset().GetRelation(), visited) {
+					return true
+				}
+			}
+		}
+
+		return nil
+	})
+
+	return err != nil || involves != nil
+}
+
+// Sync brings the index of every registered store up-to-date with its changelog. Stores which haven't been
+// indexed yet are indexed in full, and the indexes which were dropped for holding too many entries are skipped.
+func (i *Index) Sync(ctx context.Context) {
+	i.mu.RLock()
+	stores := make(map[string]*storeIndex, len(i.stores))
+	for key, st := range i.stores {
+		if !st.overflowed {
+			stores[key] = st
+		}
+	}
+	i.mu.RUnlock()
+
+	for key, st := range stores {
+		if err := i.syncStore(ctx, key, st); err != nil {
+			i.logger.Error("failed to sync the materialized index",
+				zap.String("store_id", st.storeID),
+				zap.String("authorization_model_id", st.typesys.GetAuthorizationModelID()),
+				zap.Error(err))
+		}
+	}
+}
+
+// syncStore brings the index of the store up-to-date. The objects whose users have to be (re)computed are
+// computed without holding the lock, and the index is only updated if it wasn't evicted in the meantime.
+func (i *Index) syncStore(ctx context.Context, key string, st *storeIndex) error {
+	storeID := st.storeID
+
+	ctx, span := tracer.Start(ctx, "materialize.syncStore", trace.WithAttributes(
+		attribute.String("store_id", storeID),
+		attribute.String("authorization_model_id", st.typesys.GetAuthorizationModelID()),
+	))
+	defer span.End()
+
+	// the changes written before the sync starts are read by it
+	syncedAt := time.Now()
+
+	i.mu.RLock()
+	built := st.built
+	continuationToken := st.continuationToken
+	i.mu.RUnlock()
+
+	var objects map[string]map[string]struct{}
+	var err error
+	if built {
+		var changes []*openfgav1.TupleChange
+		changes, continuationToken, err = i.readChanges(ctx, storeID, continuationToken)
+		if err != nil {
+			return err
+		}
+
+		objects, err = i.affectedObjects(ctx, storeID, st, changes)
+	} else {
+		// read up to the end of the changelog first, so that changes made while reading the tuples are applied again
+		_, continuationToken, err = i.readChanges(ctx, storeID, "")
+		if err != nil {
+			return err
+		}
+
+		objects, err = i.allObjects(ctx, storeID, st)
+	}
+	if err != nil {
+		return err
+	}
+
+	users := make(map[string]map[string]map[string]struct{}, len(objects))
+	var entries int
+	for relationKey, relationObjects := range objects {
+		users[relationKey] = make(map[string]map[string]struct{}, len(relationObjects))
+		for object := range relationObjects {
+			users[relationKey][object], err = i.listUsers(ctx, storeID, st.typesys, relationKey, object)
+			if err != nil {
+				return err
+			}
+
+			// a full build which exceeds the limit is stopped early
+			entries += len(users[relationKey][object])
+			if !built && i.maxEntriesPerStore > 0 && entries > i.maxEntriesPerStore {
+				i.overflow(key, st)
+				return nil
+			}
+		}
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.stores[key] != st {
+		return nil
+	}
+
+	for relationKey, relationUsers := range users {
+		for object, objectUsers := range relationUsers {
+			st.setUsers(relationKey, object, objectUsers)
+		}
+	}
+
+	if i.maxEntriesPerStore > 0 && st.entries > i.maxEntriesPerStore {
+		i.overflowLocked(st)
+		return nil
+	}
+
+	st.built = true
+	st.continuationToken = continuationToken
+	st.syncedAt = syncedAt
+
+	return nil
+}
+
+// overflow drops the entries of the index of the store, which holds more than maxEntriesPerStore of them, so
+// that its lookups are no longer answered.
+func (i *Index) overflow(key string, st *storeIndex) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.stores[key] == st {
+		i.overflowLocked(st)
+	}
+}
+
+// overflowLocked is overflow, called with the lock held.
+func (i *Index) overflowLocked(st *storeIndex) {
+	i.logger.Warn("the materialized index of the store holds too many entries and is dropped",
+		zap.String("store_id", st.storeID),
+		zap.String("authorization_model_id", st.typesys.GetAuthorizationModelID()),
+		zap.Int("max_entries_per_store", i.maxEntriesPerStore))
+
+	st.overflowed = true
+	st.entries = 0
+	st.objectsByUser = nil
+	st.usersByObject = nil
+}
+
+// setUsers replaces the users related to the object through the hot relation.
+func (st *storeIndex) setUsers(relationKey, object string, users map[string]struct{}) {
+	st.entries -= len(st.usersByObject[relationKey][object])
+	for user := range st.usersByObject[relationKey][object] {
+		delete(st.objectsByUser[relationKey][user], object)
+		if len(st.objectsByUser[relationKey][user]) == 0 {
+			delete(st.objectsByUser[relationKey], user)
+		}
+	}
+
+	if len(users) == 0 {
+		delete(st.usersByObject[relationKey], object)
+		return
+	}
+
+	st.usersByObject[relationKey][object] = users
+	st.entries += len(users)
+	for user := range users {
+		if _, ok := st.objectsByUser[relationKey][user]; !ok {
+			st.objectsByUser[relationKey][user] = map[string]struct{}{}
+		}
+
+		st.objectsByUser[relationKey][user][object] = struct{}{}
+	}
+}
+
+// readChanges reads the changelog of the store from the provided continuation token up to its end, and returns
+// the changes read along with the continuation token of the end of the changelog.
+func (i *Index) readChanges(ctx context.Context, storeID, continuationToken string) ([]*openfgav1.TupleChange, string, error) {
+	var changes []*openfgav1.TupleChange
+	for {
+		page, token, err := i.ds.ReadChanges(ctx, storeID, "", storage.PaginationOptions{
+			PageSize: changelogPageSize,
+			From:     continuationToken,
+		}, 0)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				return changes, continuationToken, nil
+			}
+
+			return nil, "", err
+		}
+
+		changes = append(changes, page...)
+		continuationToken = string(token)
+	}
+}
+
+// allObjects returns, for each hot relation of the store, all the objects of its type.
+func (i *Index) allObjects(ctx context.Context, storeID string, st *storeIndex) (map[string]map[string]struct{}, error) {
+	objects := make(map[string]map[string]struct{}, len(st.relations))
+	objectsByType := map[string]map[string]struct{}{}
+	for _, relationKey := range st.relations {
+		objectType, _ := tuple.SplitObjectRelation(relationKey)
+
+		typeObjects, ok := objectsByType[objectType]
+		if !ok {
+			iter, err := i.ds.Read(ctx, storeID, tuple.NewTupleKey(objectType+":", "", ""))
+			if err != nil {
+				return nil, err
+			}
+
+			typeObjects = map[string]struct{}{}
+			for {
+				t, err := iter.Next(ctx)
+				if err != nil {
+					if errors.Is(err, storage.ErrIteratorDone) {
+						break
+					}
+
+					iter.Stop()
+					return nil, err
+				}
+
+				typeObjects[t.GetKey().GetObject()] = struct{}{}
+			}
+			iter.Stop()
+
+			objectsByType[objectType] = typeObjects
+		}
+
+		objects[relationKey] = typeObjects
+	}
+
+	return objects, nil
+}
+
+// affectedObjects returns, for each hot relation of the store, the objects whose users may have changed because
+// of the provided changes: the object of a changed tuple itself and the objects that some relation of it may
+// (transitively) be a part of.
+func (i *Index) affectedObjects(
+	ctx context.Context,
+	storeID string,
+	st *storeIndex,
+	changes []*openfgav1.TupleChange,
+) (map[string]map[string]struct{}, error) {
+	objects := make(map[string]map[string]struct{}, len(st.relations))
+	for _, relationKey := range st.relations {
+		objects[relationKey] = map[string]struct{}{}
+	}
+
+	changedObjects := map[string]struct{}{}
+	for _, change := range changes {
+		changedObjects[change.GetTupleKey().GetObject()] = struct{}{}
+	}
+
+	for changedObject := range changedObjects {
+		changedObjectType := tuple.GetType(changedObject)
+
+		changedObjectRelations, err := st.typesys.GetRelations(changedObjectType)
+		if err != nil {
+			// the type of the changed tuple is not part of the model being indexed
+			continue
+		}
+
+		for _, relationKey := range st.relations {
+			objectType, relation := tuple.SplitObjectRelation(relationKey)
+			if objectType == changedObjectType {
+				objects[relationKey][changedObject] = struct{}{}
+			}
+
+			for changedObjectRelation := range changedObjectRelations {
+				reverseExpandedObjects, err := i.reverseExpand(ctx, storeID, st.typesys, objectType, relation, changedObject, changedObjectRelation)
+				if err != nil {
+					return nil, err
+				}
+
+				for _, object := range reverseExpandedObjects {
+					objects[relationKey][object] = struct{}{}
+				}
+			}
+		}
+	}
+
+	return objects, nil
+}
+
+// reverseExpand returns the objects of type objectType for which the members of 'userObject#userRelation'
+// may be related through the relation.
+func (i *Index) reverseExpand(
+	ctx context.Context,
+	storeID string,
+	typesys *typesystem.TypeSystem,
+	objectType, relation, userObject, userRelation string,
+) ([]string, error) {
+	resultChan := make(chan *reverseexpand.ReverseExpandResult)
+	errChan := make(chan error, 1)
+
+	go func() {
+		errChan <- reverseexpand.NewReverseExpandQuery(i.ds, typesys, reverseexpand.WithLogger(i.logger)).Execute(ctx, &reverseexpand.ReverseExpandRequest{
+			StoreID:    storeID,
+			ObjectType: objectType,
+			Relation:   relation,
+			User: &reverseexpand.UserRefObjectRelation{
+				ObjectRelation: &openfgav1.ObjectRelation{
+					Object:   userObject,
+					Relation: userRelation,
+				},
+			},
+		}, resultChan, reverseexpand.NewResolutionMetadata())
+	}()
+
+	var objects []string
+	for {
+		select {
+		case result, ok := <-resultChan:
+			if !ok {
+				return objects, nil
+			}
+
+			objects = append(objects, result.Object)
+		case err := <-errChan:
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// listUsers returns the concrete users and typed wildcards related to the object through the hot relation.
+func (i *Index) listUsers(
+	ctx context.Context,
+	storeID string,
+	typesys *typesystem.TypeSystem,
+	relationKey, object string,
+) (map[string]struct{}, error) {
+	objectType, relation := tuple.SplitObjectRelation(relationKey)
+	_, objectID := tuple.SplitObject(object)
+
+	userTypes, err := typesys.TerminalUserTypes(objectType, relation)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = typesystem.ContextWithTypesystem(ctx, typesys)
+
+	users := map[string]struct{}{}
+	for _, userType := range userTypes {
+		resp, err := listusers.NewListUsersQuery(i.ds,
+			listusers.WithListUsersQueryLogger(i.logger),
+			listusers.WithListUsersMaxResults(0),
+			listusers.WithListUsersDeadline(0),
+		).ListUsers(ctx, &openfgav1.ListUsersRequest{
+			StoreId:              storeID,
+			AuthorizationModelId: typesys.GetAuthorizationModelID(),
+			Object:               &openfgav1.Object{Type: objectType, Id: objectID},
+			Relation:             relation,
+			UserFilters:          []*openfgav1.UserTypeFilter{{Type: userType}},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list the users of '%s#%s': %w", object, relation, err)
+		}
+
+		for _, user := range resp.GetUsers() {
+			users[string(tuple.UserProtoToString(user))] = struct{}{}
+		}
+	}
+
+	return users, nil
+}
@@ -0,0 +1,387 @@
+package materialize
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+func TestIndex(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID := ulid.Make().String()
+
+	typesys, err := typesystem.NewAndValidate(context.Background(), testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+
+		type user
+		type employee
+
+		type group
+			relations
+				define member: [user, employee:*, group#member]
+
+		type folder
+			relations
+				define viewer: [user, group#member]
+
+		type document
+			relations
+				define parent: [folder]
+				define blocked: [user]
+				define owner: [user]
+				define viewer: [user, user:*, group#member] or owner or viewer from parent
+				define restricted: viewer but not blocked`))
+	require.NoError(t, err)
+
+	err = ds.Write(context.Background(), storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("document:1", "owner", "user:jon"),
+		tuple.NewTupleKey("document:1", "viewer", "group:eng#member"),
+		tuple.NewTupleKey("document:2", "parent", "folder:x"),
+		tuple.NewTupleKey("document:3", "viewer", "user:*"),
+		tuple.NewTupleKey("group:eng", "member", "group:backend#member"),
+		tuple.NewTupleKey("group:backend", "member", "user:maria"),
+		tuple.NewTupleKey("group:backend", "member", "employee:*"),
+		tuple.NewTupleKey("folder:x", "viewer", "user:will"),
+	})
+	require.NoError(t, err)
+
+	index := NewIndex(ds,
+		WithRelation("document", "viewer"),
+		WithRelation("document", "restricted"),
+		WithSyncInterval(time.Hour),
+		WithMaxStaleness(time.Hour),
+	)
+	t.Cleanup(index.Close)
+
+	checker := graph.NewLocalCheckerWithCycleDetection()
+	t.Cleanup(checker.Close)
+
+	ctx := typesystem.ContextWithTypesystem(context.Background(), typesys)
+	ctx = storage.ContextWithRelationshipTupleReader(ctx, ds)
+
+	requireMatchesCheck := func(t *testing.T) {
+		for _, object := range []string{"document:1", "document:2", "document:3", "document:4"} {
+			for _, user := range []string{"user:jon", "user:maria", "user:will", "user:bob", "employee:ann"} {
+				resp, err := checker.ResolveCheck(ctx, &graph.ResolveCheckRequest{
+					StoreID:              storeID,
+					AuthorizationModelID: typesys.GetAuthorizationModelID(),
+					TupleKey:             tuple.NewTupleKey(object, "viewer", user),
+					RequestMetadata:      graph.NewCheckRequestMetadata(25),
+				})
+				require.NoError(t, err)
+
+				allowed, ok := index.Lookup(context.Background(), typesys, storeID, object, "viewer", user)
+				require.True(t, ok)
+				require.Equal(t, resp.GetAllowed(), allowed, "%s#viewer@%s", object, user)
+			}
+		}
+	}
+
+	t.Run("not_answered_before_the_store_is_indexed", func(t *testing.T) {
+		_, ok := index.Lookup(context.Background(), typesys, storeID, "document:1", "viewer", "user:jon")
+		require.False(t, ok)
+	})
+
+	index.Sync(context.Background())
+
+	t.Run("answered_once_the_store_is_indexed", func(t *testing.T) {
+		requireMatchesCheck(t)
+	})
+
+	t.Run("relations_that_are_not_materialized_are_not_answered", func(t *testing.T) {
+		_, ok := index.Lookup(context.Background(), typesys, storeID, "document:1", "owner", "user:jon")
+		require.False(t, ok)
+
+		// relations involving exclusions can't be materialized
+		_, ok = index.Lookup(context.Background(), typesys, storeID, "document:1", "restricted", "user:jon")
+		require.False(t, ok)
+	})
+
+	t.Run("usersets_are_not_answered", func(t *testing.T) {
+		_, ok := index.Lookup(context.Background(), typesys, storeID, "document:1", "viewer", "group:eng#member")
+		require.False(t, ok)
+	})
+
+	t.Run("changes_are_applied_incrementally", func(t *testing.T) {
+		err := ds.Write(context.Background(), storeID, []*openfgav1.TupleKeyWithoutCondition{
+			tuple.TupleKeyToTupleKeyWithoutCondition(tuple.NewTupleKey("group:backend", "member", "user:maria")),
+			tuple.TupleKeyToTupleKeyWithoutCondition(tuple.NewTupleKey("document:1", "owner", "user:jon")),
+		}, []*openfgav1.TupleKey{
+			tuple.NewTupleKey("group:backend", "member", "user:bob"),
+			tuple.NewTupleKey("folder:x", "viewer", "group:eng#member"),
+			tuple.NewTupleKey("document:4", "parent", "folder:x"),
+		})
+		require.NoError(t, err)
+
+		// the changes are missed until the next sync (e.g. the revoked permission of user:maria)
+		allowed, ok := index.Lookup(context.Background(), typesys, storeID, "document:1", "viewer", "user:maria")
+		require.True(t, ok)
+		require.True(t, allowed)
+
+		index.Sync(context.Background())
+
+		requireMatchesCheck(t)
+
+		allowed, ok = index.Lookup(context.Background(), typesys, storeID, "document:4", "viewer", "user:bob")
+		require.True(t, ok)
+		require.True(t, allowed)
+	})
+
+	t.Run("store_is_indexed_again_for_another_model", func(t *testing.T) {
+		otherTypesys, err := typesystem.NewAndValidate(context.Background(), testutils.MustTransformDSLToProtoWithID(`
+			model
+				schema 1.1
+
+			type user
+
+			type document
+				relations
+					define viewer: [user]`))
+		require.NoError(t, err)
+
+		_, ok := index.Lookup(context.Background(), otherTypesys, storeID, "document:3", "viewer", "user:jon")
+		require.False(t, ok)
+
+		index.Sync(context.Background())
+
+		allowed, ok := index.Lookup(context.Background(), otherTypesys, storeID, "document:3", "viewer", "user:jon")
+		require.True(t, ok)
+		require.False(t, allowed)
+
+		// the store is still indexed for the first model
+		allowed, ok = index.Lookup(context.Background(), typesys, storeID, "document:3", "viewer", "user:jon")
+		require.True(t, ok)
+		require.True(t, allowed)
+	})
+}
+
+func TestIndexIsNotLookedUpWhenStale(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID := ulid.Make().String()
+
+	typesys, err := typesystem.NewAndValidate(context.Background(), testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+
+		type user
+
+		type document
+			relations
+				define viewer: [user]`))
+	require.NoError(t, err)
+
+	err = ds.Write(context.Background(), storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("document:1", "viewer", "user:jon"),
+	})
+	require.NoError(t, err)
+
+	index := NewIndex(ds,
+		WithRelation("document", "viewer"),
+		WithSyncInterval(time.Hour),
+		WithMaxStaleness(50*time.Millisecond),
+	)
+	t.Cleanup(index.Close)
+
+	lookup := func() bool {
+		_, ok := index.Lookup(context.Background(), typesys, storeID, "document:1", "viewer", "user:jon")
+		return ok
+	}
+
+	require.False(t, lookup())
+	index.Sync(context.Background())
+	require.True(t, lookup())
+
+	// the index isn't synced again within the max staleness
+	require.Eventually(t, func() bool {
+		return !lookup()
+	}, time.Second, 10*time.Millisecond)
+
+	index.Sync(context.Background())
+	require.True(t, lookup())
+}
+
+func TestIndexIsDroppedBeyondMaxEntriesPerStore(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	typesys, err := typesystem.NewAndValidate(context.Background(), testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+
+		type user
+
+		type document
+			relations
+				define viewer: [user]`))
+	require.NoError(t, err)
+
+	index := NewIndex(ds,
+		WithRelation("document", "viewer"),
+		WithSyncInterval(time.Hour),
+		WithMaxEntriesPerStore(2),
+	)
+	t.Cleanup(index.Close)
+
+	t.Run("on_the_first_build", func(t *testing.T) {
+		storeID := ulid.Make().String()
+		err := ds.Write(context.Background(), storeID, nil, []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:1", "viewer", "user:jon"),
+			tuple.NewTupleKey("document:1", "viewer", "user:maria"),
+			tuple.NewTupleKey("document:2", "viewer", "user:jon"),
+		})
+		require.NoError(t, err)
+
+		_, ok := index.Lookup(context.Background(), typesys, storeID, "document:1", "viewer", "user:jon")
+		require.False(t, ok)
+
+		index.Sync(context.Background())
+
+		_, ok = index.Lookup(context.Background(), typesys, storeID, "document:1", "viewer", "user:jon")
+		require.False(t, ok)
+	})
+
+	t.Run("on_an_incremental_sync", func(t *testing.T) {
+		storeID := ulid.Make().String()
+		err := ds.Write(context.Background(), storeID, nil, []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:1", "viewer", "user:jon"),
+			tuple.NewTupleKey("document:1", "viewer", "user:maria"),
+		})
+		require.NoError(t, err)
+
+		_, ok := index.Lookup(context.Background(), typesys, storeID, "document:1", "viewer", "user:jon")
+		require.False(t, ok)
+
+		index.Sync(context.Background())
+
+		allowed, ok := index.Lookup(context.Background(), typesys, storeID, "document:1", "viewer", "user:jon")
+		require.True(t, ok)
+		require.True(t, allowed)
+
+		err = ds.Write(context.Background(), storeID, nil, []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:2", "viewer", "user:jon"),
+		})
+		require.NoError(t, err)
+
+		index.Sync(context.Background())
+
+		_, ok = index.Lookup(context.Background(), typesys, storeID, "document:1", "viewer", "user:jon")
+		require.False(t, ok)
+	})
+}
+
+func TestIndexEvictsLeastRecentlyLookedUpStore(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	typesys, err := typesystem.NewAndValidate(context.Background(), testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+
+		type user
+
+		type document
+			relations
+				define viewer: [user]`))
+	require.NoError(t, err)
+
+	index := NewIndex(ds,
+		WithRelation("document", "viewer"),
+		WithSyncInterval(time.Hour),
+		WithMaxStores(2),
+	)
+	t.Cleanup(index.Close)
+
+	lookup := func(storeID string) bool {
+		_, ok := index.Lookup(context.Background(), typesys, storeID, "document:1", "viewer", "user:jon")
+		return ok
+	}
+
+	storeIDs := []string{ulid.Make().String(), ulid.Make().String(), ulid.Make().String()}
+	for _, storeID := range storeIDs {
+		err := ds.Write(context.Background(), storeID, nil, []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:1", "viewer", "user:jon"),
+		})
+		require.NoError(t, err)
+	}
+
+	require.False(t, lookup(storeIDs[0]))
+	require.False(t, lookup(storeIDs[1]))
+	index.Sync(context.Background())
+	require.True(t, lookup(storeIDs[1]))
+	require.True(t, lookup(storeIDs[0]))
+
+	// registering a third store evicts the one looked up least recently
+	require.False(t, lookup(storeIDs[2]))
+	index.Sync(context.Background())
+	require.True(t, lookup(storeIDs[0]))
+	require.True(t, lookup(storeIDs[2]))
+	require.False(t, lookup(storeIDs[1]))
+}
+
+func TestIsMaterializable(t *testing.T) {
+	typesys, err := typesystem.NewAndValidate(context.Background(), testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+
+		type user
+
+		type group
+			relations
+				define member: [user, user with in_office_hours]
+
+		type folder
+			relations
+				define viewer: [user]
+
+		type document
+			relations
+				define parent: [folder]
+				define blocked: [user]
+				define viewer: [user] or viewer from parent
+				define editor: [group#member]
+				define restricted: viewer but not blocked
+
+		condition in_office_hours(hour: int) {
+			hour >= 9 && hour <= 17
+		}`))
+	require.NoError(t, err)
+
+	// conditions of the model which the relation doesn't involve don't prevent indexing it
+	require.True(t, isMaterializable(typesys, "document", "viewer"))
+	require.False(t, isMaterializable(typesys, "document", "editor"))
+	require.False(t, isMaterializable(typesys, "document", "restricted"))
+	require.False(t, isMaterializable(typesys, "document", "undefined"))
+}
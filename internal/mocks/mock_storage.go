@@ -334,6 +334,21 @@ func (mr *MockAuthorizationModelReadBackendMockRecorder) FindLatestAuthorization
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindLatestAuthorizationModel", reflect.TypeOf((*MockAuthorizationModelReadBackend)(nil).FindLatestAuthorizationModel), ctx, store)
 }
 
+// ReadActiveAuthorizationModelID mocks base method.
+func (m *MockAuthorizationModelReadBackend) ReadActiveAuthorizationModelID(ctx context.Context, store string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadActiveAuthorizationModelID", ctx, store)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadActiveAuthorizationModelID indicates an expected call of ReadActiveAuthorizationModelID.
+func (mr *MockAuthorizationModelReadBackendMockRecorder) ReadActiveAuthorizationModelID(ctx, store any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadActiveAuthorizationModelID", reflect.TypeOf((*MockAuthorizationModelReadBackend)(nil).ReadActiveAuthorizationModelID), ctx, store)
+}
+
 // ReadAuthorizationModel mocks base method.
 func (m *MockAuthorizationModelReadBackend) ReadAuthorizationModel(ctx context.Context, store, id string) (*openfgav1.AuthorizationModel, error) {
 	m.ctrl.T.Helper()
@@ -349,6 +364,21 @@ func (mr *MockAuthorizationModelReadBackendMockRecorder) ReadAuthorizationModel(
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAuthorizationModel", reflect.TypeOf((*MockAuthorizationModelReadBackend)(nil).ReadAuthorizationModel), ctx, store, id)
 }
 
+// ReadAuthorizationModelAnnotations mocks base method.
+func (m *MockAuthorizationModelReadBackend) ReadAuthorizationModelAnnotations(ctx context.Context, store, modelID string) (*storage.AuthorizationModelAnnotations, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadAuthorizationModelAnnotations", ctx, store, modelID)
+	ret0, _ := ret[0].(*storage.AuthorizationModelAnnotations)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadAuthorizationModelAnnotations indicates an expected call of ReadAuthorizationModelAnnotations.
+func (mr *MockAuthorizationModelReadBackendMockRecorder) ReadAuthorizationModelAnnotations(ctx, store, modelID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAuthorizationModelAnnotations", reflect.TypeOf((*MockAuthorizationModelReadBackend)(nil).ReadAuthorizationModelAnnotations), ctx, store, modelID)
+}
+
 // ReadAuthorizationModels mocks base method.
 func (m *MockAuthorizationModelReadBackend) ReadAuthorizationModels(ctx context.Context, store string, options storage.PaginationOptions) ([]*openfgav1.AuthorizationModel, []byte, error) {
 	m.ctrl.T.Helper()
@@ -365,6 +395,21 @@ func (mr *MockAuthorizationModelReadBackendMockRecorder) ReadAuthorizationModels
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAuthorizationModels", reflect.TypeOf((*MockAuthorizationModelReadBackend)(nil).ReadAuthorizationModels), ctx, store, options)
 }
 
+// ReadAuthorizationModelsLastUsed mocks base method.
+func (m *MockAuthorizationModelReadBackend) ReadAuthorizationModelsLastUsed(ctx context.Context, store string) (map[string]time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadAuthorizationModelsLastUsed", ctx, store)
+	ret0, _ := ret[0].(map[string]time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadAuthorizationModelsLastUsed indicates an expected call of ReadAuthorizationModelsLastUsed.
+func (mr *MockAuthorizationModelReadBackendMockRecorder) ReadAuthorizationModelsLastUsed(ctx, store any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAuthorizationModelsLastUsed", reflect.TypeOf((*MockAuthorizationModelReadBackend)(nil).ReadAuthorizationModelsLastUsed), ctx, store)
+}
+
 // MockTypeDefinitionWriteBackend is a mock of TypeDefinitionWriteBackend interface.
 type MockTypeDefinitionWriteBackend struct {
 	ctrl     *gomock.Controller
@@ -388,6 +433,35 @@ func (m *MockTypeDefinitionWriteBackend) EXPECT() *MockTypeDefinitionWriteBacken
 	return m.recorder
 }
 
+// AcquireAuthorizationModelRetentionLease mocks base method.
+func (m *MockTypeDefinitionWriteBackend) AcquireAuthorizationModelRetentionLease(ctx context.Context, holder string, ttl time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcquireAuthorizationModelRetentionLease", ctx, holder, ttl)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcquireAuthorizationModelRetentionLease indicates an expected call of AcquireAuthorizationModelRetentionLease.
+func (mr *MockTypeDefinitionWriteBackendMockRecorder) AcquireAuthorizationModelRetentionLease(ctx, holder, ttl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireAuthorizationModelRetentionLease", reflect.TypeOf((*MockTypeDefinitionWriteBackend)(nil).AcquireAuthorizationModelRetentionLease), ctx, holder, ttl)
+}
+
+// DeleteAuthorizationModel mocks base method.
+func (m *MockTypeDefinitionWriteBackend) DeleteAuthorizationModel(ctx context.Context, store, modelID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAuthorizationModel", ctx, store, modelID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAuthorizationModel indicates an expected call of DeleteAuthorizationModel.
+func (mr *MockTypeDefinitionWriteBackendMockRecorder) DeleteAuthorizationModel(ctx, store, modelID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAuthorizationModel", reflect.TypeOf((*MockTypeDefinitionWriteBackend)(nil).DeleteAuthorizationModel), ctx, store, modelID)
+}
+
 // MaxTypesPerAuthorizationModel mocks base method.
 func (m *MockTypeDefinitionWriteBackend) MaxTypesPerAuthorizationModel() int {
 	m.ctrl.T.Helper()
@@ -402,6 +476,21 @@ func (mr *MockTypeDefinitionWriteBackendMockRecorder) MaxTypesPerAuthorizationMo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxTypesPerAuthorizationModel", reflect.TypeOf((*MockTypeDefinitionWriteBackend)(nil).MaxTypesPerAuthorizationModel))
 }
 
+// WriteActiveAuthorizationModelID mocks base method.
+func (m *MockTypeDefinitionWriteBackend) WriteActiveAuthorizationModelID(ctx context.Context, store, modelID string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteActiveAuthorizationModelID", ctx, store, modelID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WriteActiveAuthorizationModelID indicates an expected call of WriteActiveAuthorizationModelID.
+func (mr *MockTypeDefinitionWriteBackendMockRecorder) WriteActiveAuthorizationModelID(ctx, store, modelID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteActiveAuthorizationModelID", reflect.TypeOf((*MockTypeDefinitionWriteBackend)(nil).WriteActiveAuthorizationModelID), ctx, store, modelID)
+}
+
 // WriteAuthorizationModel mocks base method.
 func (m *MockTypeDefinitionWriteBackend) WriteAuthorizationModel(ctx context.Context, store string, model *openfgav1.AuthorizationModel) error {
 	m.ctrl.T.Helper()
@@ -416,6 +505,63 @@ func (mr *MockTypeDefinitionWriteBackendMockRecorder) WriteAuthorizationModel(ct
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteAuthorizationModel", reflect.TypeOf((*MockTypeDefinitionWriteBackend)(nil).WriteAuthorizationModel), ctx, store, model)
 }
 
+// WriteAuthorizationModelAnnotations mocks base method.
+func (m *MockTypeDefinitionWriteBackend) WriteAuthorizationModelAnnotations(ctx context.Context, store, modelID string, annotations *storage.AuthorizationModelAnnotations) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteAuthorizationModelAnnotations", ctx, store, modelID, annotations)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteAuthorizationModelAnnotations indicates an expected call of WriteAuthorizationModelAnnotations.
+func (mr *MockTypeDefinitionWriteBackendMockRecorder) WriteAuthorizationModelAnnotations(ctx, store, modelID, annotations any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteAuthorizationModelAnnotations", reflect.TypeOf((*MockTypeDefinitionWriteBackend)(nil).WriteAuthorizationModelAnnotations), ctx, store, modelID, annotations)
+}
+
+// WriteAuthorizationModelLastUsed mocks base method.
+func (m *MockTypeDefinitionWriteBackend) WriteAuthorizationModelLastUsed(ctx context.Context, store, modelID string, lastUsed time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteAuthorizationModelLastUsed", ctx, store, modelID, lastUsed)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteAuthorizationModelLastUsed indicates an expected call of WriteAuthorizationModelLastUsed.
+func (mr *MockTypeDefinitionWriteBackendMockRecorder) WriteAuthorizationModelLastUsed(ctx, store, modelID, lastUsed any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteAuthorizationModelLastUsed", reflect.TypeOf((*MockTypeDefinitionWriteBackend)(nil).WriteAuthorizationModelLastUsed), ctx, store, modelID, lastUsed)
+}
+
+// WriteAuthorizationModelUseRecording mocks base method.
+func (m *MockTypeDefinitionWriteBackend) WriteAuthorizationModelUseRecording(ctx context.Context, recordedAt time.Time, maxGap time.Duration) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteAuthorizationModelUseRecording", ctx, recordedAt, maxGap)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WriteAuthorizationModelUseRecording indicates an expected call of WriteAuthorizationModelUseRecording.
+func (mr *MockTypeDefinitionWriteBackendMockRecorder) WriteAuthorizationModelUseRecording(ctx, recordedAt, maxGap any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteAuthorizationModelUseRecording", reflect.TypeOf((*MockTypeDefinitionWriteBackend)(nil).WriteAuthorizationModelUseRecording), ctx, recordedAt, maxGap)
+}
+
+// WriteAuthorizationModelWithAnnotations mocks base method.
+func (m *MockTypeDefinitionWriteBackend) WriteAuthorizationModelWithAnnotations(ctx context.Context, store string, model *openfgav1.AuthorizationModel, annotations *storage.AuthorizationModelAnnotations) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteAuthorizationModelWithAnnotations", ctx, store, model, annotations)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteAuthorizationModelWithAnnotations indicates an expected call of WriteAuthorizationModelWithAnnotations.
+func (mr *MockTypeDefinitionWriteBackendMockRecorder) WriteAuthorizationModelWithAnnotations(ctx, store, model, annotations any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteAuthorizationModelWithAnnotations", reflect.TypeOf((*MockTypeDefinitionWriteBackend)(nil).WriteAuthorizationModelWithAnnotations), ctx, store, model, annotations)
+}
+
 // MockAuthorizationModelBackend is a mock of AuthorizationModelBackend interface.
 type MockAuthorizationModelBackend struct {
 	ctrl     *gomock.Controller
@@ -439,6 +585,35 @@ func (m *MockAuthorizationModelBackend) EXPECT() *MockAuthorizationModelBackendM
 	return m.recorder
 }
 
+// AcquireAuthorizationModelRetentionLease mocks base method.
+func (m *MockAuthorizationModelBackend) AcquireAuthorizationModelRetentionLease(ctx context.Context, holder string, ttl time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcquireAuthorizationModelRetentionLease", ctx, holder, ttl)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcquireAuthorizationModelRetentionLease indicates an expected call of AcquireAuthorizationModelRetentionLease.
+func (mr *MockAuthorizationModelBackendMockRecorder) AcquireAuthorizationModelRetentionLease(ctx, holder, ttl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireAuthorizationModelRetentionLease", reflect.TypeOf((*MockAuthorizationModelBackend)(nil).AcquireAuthorizationModelRetentionLease), ctx, holder, ttl)
+}
+
+// DeleteAuthorizationModel mocks base method.
+func (m *MockAuthorizationModelBackend) DeleteAuthorizationModel(ctx context.Context, store, modelID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAuthorizationModel", ctx, store, modelID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAuthorizationModel indicates an expected call of DeleteAuthorizationModel.
+func (mr *MockAuthorizationModelBackendMockRecorder) DeleteAuthorizationModel(ctx, store, modelID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAuthorizationModel", reflect.TypeOf((*MockAuthorizationModelBackend)(nil).DeleteAuthorizationModel), ctx, store, modelID)
+}
+
 // FindLatestAuthorizationModel mocks base method.
 func (m *MockAuthorizationModelBackend) FindLatestAuthorizationModel(ctx context.Context, store string) (*openfgav1.AuthorizationModel, error) {
 	m.ctrl.T.Helper()
@@ -468,6 +643,21 @@ func (mr *MockAuthorizationModelBackendMockRecorder) MaxTypesPerAuthorizationMod
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MaxTypesPerAuthorizationModel", reflect.TypeOf((*MockAuthorizationModelBackend)(nil).MaxTypesPerAuthorizationModel))
 }
 
+// ReadActiveAuthorizationModelID mocks base method.
+func (m *MockAuthorizationModelBackend) ReadActiveAuthorizationModelID(ctx context.Context, store string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadActiveAuthorizationModelID", ctx, store)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadActiveAuthorizationModelID indicates an expected call of ReadActiveAuthorizationModelID.
+func (mr *MockAuthorizationModelBackendMockRecorder) ReadActiveAuthorizationModelID(ctx, store any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadActiveAuthorizationModelID", reflect.TypeOf((*MockAuthorizationModelBackend)(nil).ReadActiveAuthorizationModelID), ctx, store)
+}
+
 // ReadAuthorizationModel mocks base method.
 func (m *MockAuthorizationModelBackend) ReadAuthorizationModel(ctx context.Context, store, id string) (*openfgav1.AuthorizationModel, error) {
 	m.ctrl.T.Helper()
@@ -483,6 +673,21 @@ func (mr *MockAuthorizationModelBackendMockRecorder) ReadAuthorizationModel(ctx,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAuthorizationModel", reflect.TypeOf((*MockAuthorizationModelBackend)(nil).ReadAuthorizationModel), ctx, store, id)
 }
 
+// ReadAuthorizationModelAnnotations mocks base method.
+func (m *MockAuthorizationModelBackend) ReadAuthorizationModelAnnotations(ctx context.Context, store, modelID string) (*storage.AuthorizationModelAnnotations, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadAuthorizationModelAnnotations", ctx, store, modelID)
+	ret0, _ := ret[0].(*storage.AuthorizationModelAnnotations)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadAuthorizationModelAnnotations indicates an expected call of ReadAuthorizationModelAnnotations.
+func (mr *MockAuthorizationModelBackendMockRecorder) ReadAuthorizationModelAnnotations(ctx, store, modelID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAuthorizationModelAnnotations", reflect.TypeOf((*MockAuthorizationModelBackend)(nil).ReadAuthorizationModelAnnotations), ctx, store, modelID)
+}
+
 // ReadAuthorizationModels mocks base method.
 func (m *MockAuthorizationModelBackend) ReadAuthorizationModels(ctx context.Context, store string, options storage.PaginationOptions) ([]*openfgav1.AuthorizationModel, []byte, error) {
 	m.ctrl.T.Helper()
@@ -499,6 +704,36 @@ func (mr *MockAuthorizationModelBackendMockRecorder) ReadAuthorizationModels(ctx
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAuthorizationModels", reflect.TypeOf((*MockAuthorizationModelBackend)(nil).ReadAuthorizationModels), ctx, store, options)
 }
 
+// ReadAuthorizationModelsLastUsed mocks base method.
+func (m *MockAuthorizationModelBackend) ReadAuthorizationModelsLastUsed(ctx context.Context, store string) (map[string]time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadAuthorizationModelsLastUsed", ctx, store)
+	ret0, _ := ret[0].(map[string]time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadAuthorizationModelsLastUsed indicates an expected call of ReadAuthorizationModelsLastUsed.
+func (mr *MockAuthorizationModelBackendMockRecorder) ReadAuthorizationModelsLastUsed(ctx, store any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAuthorizationModelsLastUsed", reflect.TypeOf((*MockAuthorizationModelBackend)(nil).ReadAuthorizationModelsLastUsed), ctx, store)
+}
+
+// WriteActiveAuthorizationModelID mocks base method.
+func (m *MockAuthorizationModelBackend) WriteActiveAuthorizationModelID(ctx context.Context, store, modelID string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteActiveAuthorizationModelID", ctx, store, modelID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WriteActiveAuthorizationModelID indicates an expected call of WriteActiveAuthorizationModelID.
+func (mr *MockAuthorizationModelBackendMockRecorder) WriteActiveAuthorizationModelID(ctx, store, modelID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteActiveAuthorizationModelID", reflect.TypeOf((*MockAuthorizationModelBackend)(nil).WriteActiveAuthorizationModelID), ctx, store, modelID)
+}
+
 // WriteAuthorizationModel mocks base method.
 func (m *MockAuthorizationModelBackend) WriteAuthorizationModel(ctx context.Context, store string, model *openfgav1.AuthorizationModel) error {
 	m.ctrl.T.Helper()
@@ -513,6 +748,63 @@ func (mr *MockAuthorizationModelBackendMockRecorder) WriteAuthorizationModel(ctx
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteAuthorizationModel", reflect.TypeOf((*MockAuthorizationModelBackend)(nil).WriteAuthorizationModel), ctx, store, model)
 }
 
+// WriteAuthorizationModelAnnotations mocks base method.
+func (m *MockAuthorizationModelBackend) WriteAuthorizationModelAnnotations(ctx context.Context, store, modelID string, annotations *storage.AuthorizationModelAnnotations) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteAuthorizationModelAnnotations", ctx, store, modelID, annotations)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteAuthorizationModelAnnotations indicates an expected call of WriteAuthorizationModelAnnotations.
+func (mr *MockAuthorizationModelBackendMockRecorder) WriteAuthorizationModelAnnotations(ctx, store, modelID, annotations any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteAuthorizationModelAnnotations", reflect.TypeOf((*MockAuthorizationModelBackend)(nil).WriteAuthorizationModelAnnotations), ctx, store, modelID, annotations)
+}
+
+// WriteAuthorizationModelLastUsed mocks base method.
+func (m *MockAuthorizationModelBackend) WriteAuthorizationModelLastUsed(ctx context.Context, store, modelID string, lastUsed time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteAuthorizationModelLastUsed", ctx, store, modelID, lastUsed)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteAuthorizationModelLastUsed indicates an expected call of WriteAuthorizationModelLastUsed.
+func (mr *MockAuthorizationModelBackendMockRecorder) WriteAuthorizationModelLastUsed(ctx, store, modelID, lastUsed any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteAuthorizationModelLastUsed", reflect.TypeOf((*MockAuthorizationModelBackend)(nil).WriteAuthorizationModelLastUsed), ctx, store, modelID, lastUsed)
+}
+
+// WriteAuthorizationModelUseRecording mocks base method.
+func (m *MockAuthorizationModelBackend) WriteAuthorizationModelUseRecording(ctx context.Context, recordedAt time.Time, maxGap time.Duration) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteAuthorizationModelUseRecording", ctx, recordedAt, maxGap)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WriteAuthorizationModelUseRecording indicates an expected call of WriteAuthorizationModelUseRecording.
+func (mr *MockAuthorizationModelBackendMockRecorder) WriteAuthorizationModelUseRecording(ctx, recordedAt, maxGap any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteAuthorizationModelUseRecording", reflect.TypeOf((*MockAuthorizationModelBackend)(nil).WriteAuthorizationModelUseRecording), ctx, recordedAt, maxGap)
+}
+
+// WriteAuthorizationModelWithAnnotations mocks base method.
+func (m *MockAuthorizationModelBackend) WriteAuthorizationModelWithAnnotations(ctx context.Context, store string, model *openfgav1.AuthorizationModel, annotations *storage.AuthorizationModelAnnotations) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteAuthorizationModelWithAnnotations", ctx, store, model, annotations)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteAuthorizationModelWithAnnotations indicates an expected call of WriteAuthorizationModelWithAnnotations.
+func (mr *MockAuthorizationModelBackendMockRecorder) WriteAuthorizationModelWithAnnotations(ctx, store, model, annotations any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteAuthorizationModelWithAnnotations", reflect.TypeOf((*MockAuthorizationModelBackend)(nil).WriteAuthorizationModelWithAnnotations), ctx, store, model, annotations)
+}
+
 // MockStoresBackend is a mock of StoresBackend interface.
 type MockStoresBackend struct {
 	ctrl     *gomock.Controller
@@ -710,6 +1002,21 @@ func (m *MockOpenFGADatastore) EXPECT() *MockOpenFGADatastoreMockRecorder {
 	return m.recorder
 }
 
+// AcquireAuthorizationModelRetentionLease mocks base method.
+func (m *MockOpenFGADatastore) AcquireAuthorizationModelRetentionLease(ctx context.Context, holder string, ttl time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcquireAuthorizationModelRetentionLease", ctx, holder, ttl)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcquireAuthorizationModelRetentionLease indicates an expected call of AcquireAuthorizationModelRetentionLease.
+func (mr *MockOpenFGADatastoreMockRecorder) AcquireAuthorizationModelRetentionLease(ctx, holder, ttl any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireAuthorizationModelRetentionLease", reflect.TypeOf((*MockOpenFGADatastore)(nil).AcquireAuthorizationModelRetentionLease), ctx, holder, ttl)
+}
+
 // Close mocks base method.
 func (m *MockOpenFGADatastore) Close() {
 	m.ctrl.T.Helper()
@@ -737,6 +1044,20 @@ func (mr *MockOpenFGADatastoreMockRecorder) CreateStore(ctx, store any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateStore", reflect.TypeOf((*MockOpenFGADatastore)(nil).CreateStore), ctx, store)
 }
 
+// DeleteAuthorizationModel mocks base method.
+func (m *MockOpenFGADatastore) DeleteAuthorizationModel(ctx context.Context, store, modelID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAuthorizationModel", ctx, store, modelID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAuthorizationModel indicates an expected call of DeleteAuthorizationModel.
+func (mr *MockOpenFGADatastoreMockRecorder) DeleteAuthorizationModel(ctx, store, modelID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAuthorizationModel", reflect.TypeOf((*MockOpenFGADatastore)(nil).DeleteAuthorizationModel), ctx, store, modelID)
+}
+
 // DeleteStore mocks base method.
 func (m *MockOpenFGADatastore) DeleteStore(ctx context.Context, id string) error {
 	m.ctrl.T.Helper()
@@ -855,6 +1176,21 @@ func (mr *MockOpenFGADatastoreMockRecorder) Read(ctx, store, tupleKey any) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockOpenFGADatastore)(nil).Read), ctx, store, tupleKey)
 }
 
+// ReadActiveAuthorizationModelID mocks base method.
+func (m *MockOpenFGADatastore) ReadActiveAuthorizationModelID(ctx context.Context, store string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadActiveAuthorizationModelID", ctx, store)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadActiveAuthorizationModelID indicates an expected call of ReadActiveAuthorizationModelID.
+func (mr *MockOpenFGADatastoreMockRecorder) ReadActiveAuthorizationModelID(ctx, store any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadActiveAuthorizationModelID", reflect.TypeOf((*MockOpenFGADatastore)(nil).ReadActiveAuthorizationModelID), ctx, store)
+}
+
 // ReadAssertions mocks base method.
 func (m *MockOpenFGADatastore) ReadAssertions(ctx context.Context, store, modelID string) ([]*openfgav1.Assertion, error) {
 	m.ctrl.T.Helper()
@@ -885,6 +1221,21 @@ func (mr *MockOpenFGADatastoreMockRecorder) ReadAuthorizationModel(ctx, store, i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAuthorizationModel", reflect.TypeOf((*MockOpenFGADatastore)(nil).ReadAuthorizationModel), ctx, store, id)
 }
 
+// ReadAuthorizationModelAnnotations mocks base method.
+func (m *MockOpenFGADatastore) ReadAuthorizationModelAnnotations(ctx context.Context, store, modelID string) (*storage.AuthorizationModelAnnotations, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadAuthorizationModelAnnotations", ctx, store, modelID)
+	ret0, _ := ret[0].(*storage.AuthorizationModelAnnotations)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadAuthorizationModelAnnotations indicates an expected call of ReadAuthorizationModelAnnotations.
+func (mr *MockOpenFGADatastoreMockRecorder) ReadAuthorizationModelAnnotations(ctx, store, modelID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAuthorizationModelAnnotations", reflect.TypeOf((*MockOpenFGADatastore)(nil).ReadAuthorizationModelAnnotations), ctx, store, modelID)
+}
+
 // ReadAuthorizationModels mocks base method.
 func (m *MockOpenFGADatastore) ReadAuthorizationModels(ctx context.Context, store string, options storage.PaginationOptions) ([]*openfgav1.AuthorizationModel, []byte, error) {
 	m.ctrl.T.Helper()
@@ -901,6 +1252,21 @@ func (mr *MockOpenFGADatastoreMockRecorder) ReadAuthorizationModels(ctx, store,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAuthorizationModels", reflect.TypeOf((*MockOpenFGADatastore)(nil).ReadAuthorizationModels), ctx, store, options)
 }
 
+// ReadAuthorizationModelsLastUsed mocks base method.
+func (m *MockOpenFGADatastore) ReadAuthorizationModelsLastUsed(ctx context.Context, store string) (map[string]time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadAuthorizationModelsLastUsed", ctx, store)
+	ret0, _ := ret[0].(map[string]time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReadAuthorizationModelsLastUsed indicates an expected call of ReadAuthorizationModelsLastUsed.
+func (mr *MockOpenFGADatastoreMockRecorder) ReadAuthorizationModelsLastUsed(ctx, store any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadAuthorizationModelsLastUsed", reflect.TypeOf((*MockOpenFGADatastore)(nil).ReadAuthorizationModelsLastUsed), ctx, store)
+}
+
 // ReadChanges mocks base method.
 func (m *MockOpenFGADatastore) ReadChanges(ctx context.Context, store, objectType string, paginationOptions storage.PaginationOptions, horizonOffset time.Duration) ([]*openfgav1.TupleChange, []byte, error) {
 	m.ctrl.T.Helper()
@@ -992,6 +1358,21 @@ func (mr *MockOpenFGADatastoreMockRecorder) Write(ctx, store, d, w any) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Write", reflect.TypeOf((*MockOpenFGADatastore)(nil).Write), ctx, store, d, w)
 }
 
+// WriteActiveAuthorizationModelID mocks base method.
+func (m *MockOpenFGADatastore) WriteActiveAuthorizationModelID(ctx context.Context, store, modelID string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteActiveAuthorizationModelID", ctx, store, modelID)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WriteActiveAuthorizationModelID indicates an expected call of WriteActiveAuthorizationModelID.
+func (mr *MockOpenFGADatastoreMockRecorder) WriteActiveAuthorizationModelID(ctx, store, modelID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteActiveAuthorizationModelID", reflect.TypeOf((*MockOpenFGADatastore)(nil).WriteActiveAuthorizationModelID), ctx, store, modelID)
+}
+
 // WriteAssertions mocks base method.
 func (m *MockOpenFGADatastore) WriteAssertions(ctx context.Context, store, modelID string, assertions []*openfgav1.Assertion) error {
 	m.ctrl.T.Helper()
@@ -1019,3 +1400,60 @@ func (mr *MockOpenFGADatastoreMockRecorder) WriteAuthorizationModel(ctx, store,
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteAuthorizationModel", reflect.TypeOf((*MockOpenFGADatastore)(nil).WriteAuthorizationModel), ctx, store, model)
 }
+
+// WriteAuthorizationModelAnnotations mocks base method.
+func (m *MockOpenFGADatastore) WriteAuthorizationModelAnnotations(ctx context.Context, store, modelID string, annotations *storage.AuthorizationModelAnnotations) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteAuthorizationModelAnnotations", ctx, store, modelID, annotations)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteAuthorizationModelAnnotations indicates an expected call of WriteAuthorizationModelAnnotations.
+func (mr *MockOpenFGADatastoreMockRecorder) WriteAuthorizationModelAnnotations(ctx, store, modelID, annotations any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteAuthorizationModelAnnotations", reflect.TypeOf((*MockOpenFGADatastore)(nil).WriteAuthorizationModelAnnotations), ctx, store, modelID, annotations)
+}
+
+// WriteAuthorizationModelLastUsed mocks base method.
+func (m *MockOpenFGADatastore) WriteAuthorizationModelLastUsed(ctx context.Context, store, modelID string, lastUsed time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteAuthorizationModelLastUsed", ctx, store, modelID, lastUsed)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteAuthorizationModelLastUsed indicates an expected call of WriteAuthorizationModelLastUsed.
+func (mr *MockOpenFGADatastoreMockRecorder) WriteAuthorizationModelLastUsed(ctx, store, modelID, lastUsed any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteAuthorizationModelLastUsed", reflect.TypeOf((*MockOpenFGADatastore)(nil).WriteAuthorizationModelLastUsed), ctx, store, modelID, lastUsed)
+}
+
+// WriteAuthorizationModelUseRecording mocks base method.
+func (m *MockOpenFGADatastore) WriteAuthorizationModelUseRecording(ctx context.Context, recordedAt time.Time, maxGap time.Duration) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteAuthorizationModelUseRecording", ctx, recordedAt, maxGap)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WriteAuthorizationModelUseRecording indicates an expected call of WriteAuthorizationModelUseRecording.
+func (mr *MockOpenFGADatastoreMockRecorder) WriteAuthorizationModelUseRecording(ctx, recordedAt, maxGap any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteAuthorizationModelUseRecording", reflect.TypeOf((*MockOpenFGADatastore)(nil).WriteAuthorizationModelUseRecording), ctx, recordedAt, maxGap)
+}
+
+// WriteAuthorizationModelWithAnnotations mocks base method.
+func (m *MockOpenFGADatastore) WriteAuthorizationModelWithAnnotations(ctx context.Context, store string, model *openfgav1.AuthorizationModel, annotations *storage.AuthorizationModelAnnotations) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WriteAuthorizationModelWithAnnotations", ctx, store, model, annotations)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WriteAuthorizationModelWithAnnotations indicates an expected call of WriteAuthorizationModelWithAnnotations.
+func (mr *MockOpenFGADatastoreMockRecorder) WriteAuthorizationModelWithAnnotations(ctx, store, model, annotations any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WriteAuthorizationModelWithAnnotations", reflect.TypeOf((*MockOpenFGADatastore)(nil).WriteAuthorizationModelWithAnnotations), ctx, store, model, annotations)
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -50,6 +51,31 @@ const (
 	DefaultListObjectsDispatchThrottlingDefaultThreshold = 100
 	DefaultListObjectsDispatchThrottlingMaxThreshold     = 0 // 0 means use the default threshold as max
 
+	DefaultListUsersDispatchThrottlingEnabled          = false
+	DefaultListUsersDispatchThrottlingFrequency        = 10 * time.Microsecond
+	DefaultListUsersDispatchThrottlingDefaultThreshold = 100
+	DefaultListUsersDispatchThrottlingMaxThreshold     = 0 // 0 means use the default threshold as max
+
+	DefaultStreamedListObjectsDeduplication = false
+	DefaultStreamedListObjectsSorting       = false
+
+	DefaultAuthorizationModelCompatibilityCheckEnabled   = false
+	DefaultAuthorizationModelCompatibilityCheckReject    = false
+	DefaultAuthorizationModelCompatibilityCheckMaxTuples = 100000
+	DefaultAuthorizationModelCompatibilityCheckDeadline  = 10 * time.Second
+
+	DefaultAuthorizationModelLintEnabled = false
+
+	DefaultAuthorizationModelRetentionEnabled        = false
+	DefaultAuthorizationModelRetentionKeepLast       = 10
+	DefaultAuthorizationModelRetentionKeepUsedWithin = 30 * 24 * time.Hour
+	DefaultAuthorizationModelRetentionInterval       = 1 * time.Hour
+
+	DefaultMaterializationEnabled            = false
+	DefaultMaterializationSyncInterval       = 1 * time.Second
+	DefaultMaterializationMaxStaleness       = 2 * time.Second
+	DefaultMaterializationMaxEntriesPerStore = 1000000
+
 	DefaultRequestTimeout = 3 * time.Second
 
 	additionalUpstreamTimeout = 3 * time.Second
@@ -201,6 +227,89 @@ type DispatchThrottlingConfig struct {
 	MaxThreshold uint32
 }
 
+// StoreLimitsConfig defines resolution limits for the queries of a single store which override
+// the server-wide ones. A limit left unset (zero) falls back to the server-wide value.
+type StoreLimitsConfig struct {
+	// StoreID is the ID of the store the limits apply to.
+	StoreID string
+
+	// ResolveNodeLimit overrides Config.ResolveNodeLimit for the store.
+	ResolveNodeLimit uint32
+
+	// ResolveNodeBreadthLimit overrides Config.ResolveNodeBreadthLimit for the store.
+	ResolveNodeBreadthLimit uint32
+
+	// MaxConcurrentReadsForCheck overrides Config.MaxConcurrentReadsForCheck for the store.
+	MaxConcurrentReadsForCheck uint32
+}
+
+// StreamedListObjectsConfig defines configurations for the guarantees of the StreamedListObjects API.
+type StreamedListObjectsConfig struct {
+	// Deduplication makes each object be streamed at most once.
+	Deduplication bool
+
+	// Sorting makes the objects be buffered, up to ListObjectsMaxResults, and streamed in lexicographical order.
+	Sorting bool
+}
+
+// AuthorizationModelCompatibilityCheckConfig defines configurations for checking that the tuples of a store are
+// valid under the authorization models written to it.
+type AuthorizationModelCompatibilityCheckConfig struct {
+	Enabled bool
+
+	// Reject makes WriteAuthorizationModel requests fail if some tuples are invalid under the model, rather than
+	// only logging them.
+	Reject bool
+
+	// MaxTuples is the maximum number of tuples of the store read by a check, after which the compatibility of
+	// the model is unknown.
+	MaxTuples int
+
+	// Deadline is the maximum duration of a check, after which the compatibility of the model is unknown.
+	Deadline time.Duration
+}
+
+// AuthorizationModelLintConfig defines configurations for linting the authorization models written to stores.
+type AuthorizationModelLintConfig struct {
+	// Enabled makes WriteAuthorizationModel responses carry the lint warnings about the model written.
+	Enabled bool
+}
+
+// AuthorizationModelRetentionConfig defines the retention policy of the authorization models of the stores.
+// The models which are neither among the latest ones, nor the active one, nor used recently are garbage collected.
+type AuthorizationModelRetentionConfig struct {
+	Enabled bool
+
+	// KeepLast is the number of latest models of each store which are kept.
+	KeepLast int
+
+	// KeepUsedWithin is the duration for which the models written or used by requests are kept.
+	KeepUsedWithin time.Duration
+
+	// Interval is the interval at which the models of the stores are garbage collected.
+	Interval time.Duration
+}
+
+// MaterializationConfig defines configurations for the index of precomputed permissions
+// maintained for hot relations.
+type MaterializationConfig struct {
+	Enabled bool
+
+	// Relations are the relations to materialize, as 'type#relation' (e.g. 'document#viewer').
+	Relations []string
+
+	// SyncInterval is the interval at which the index applies the changes of the changelog.
+	SyncInterval time.Duration
+
+	// MaxStaleness is the maximum time since the start of the last sync of the index of a store for which
+	// Checks are answered from it.
+	MaxStaleness time.Duration
+
+	// MaxEntriesPerStore is the maximum number of (user, object) pairs the index of a store holds, beyond
+	// which Checks of the store are no longer answered from it.
+	MaxEntriesPerStore int
+}
+
 type Config struct {
 	// If you change any of these settings, please update the documentation at
 	// https://github.com/openfga/openfga.dev/blob/main/docs/content/intro/setup-openfga.mdx
@@ -215,6 +324,14 @@ type Config struct {
 	// This is to protect the server from misuse of the ListObjects endpoints.
 	ListObjectsMaxResults uint32
 
+	// ContinuationTokenSigningKey is the key which the continuation tokens of paginated ListObjects queries are
+	// signed with. If empty, a random key is generated at startup, so the tokens are only valid on the server
+	// instance which issued them, until it restarts.
+	ContinuationTokenSigningKey string
+
+	// StreamedListObjects configures the ordering and deduplication guarantees of the StreamedListObjects API.
+	StreamedListObjects StreamedListObjectsConfig
+
 	// ListUsersDeadline defines the maximum amount of time to accumulate ListUsers results
 	// before the server will respond. This is to protect the server from misuse of the
 	// ListUsers endpoints. It cannot be larger than the configured server's request timeout (RequestTimeout or HTTPConfig.UpstreamTimeout).
@@ -236,6 +353,16 @@ type Config struct {
 	// persisting an Authorization Model.
 	MaxAuthorizationModelSizeInBytes int
 
+	// AuthorizationModelCompatibilityCheck configures checking the tuples of a store against the authorization
+	// models written to it.
+	AuthorizationModelCompatibilityCheck AuthorizationModelCompatibilityCheckConfig
+
+	// AuthorizationModelLint configures linting the authorization models written to stores.
+	AuthorizationModelLint AuthorizationModelLintConfig
+
+	// AuthorizationModelRetention configures garbage collecting the unused authorization models of stores.
+	AuthorizationModelRetention AuthorizationModelRetentionConfig
+
 	// MaxConcurrentReadsForListObjects defines the maximum number of concurrent database reads
 	// allowed in ListObjects queries
 	MaxConcurrentReadsForListObjects uint32
@@ -266,6 +393,14 @@ type Config struct {
 	// concurrently in a query
 	ResolveNodeBreadthLimit uint32
 
+	// StoreLimits overrides ResolveNodeLimit, ResolveNodeBreadthLimit and MaxConcurrentReadsForCheck
+	// for individual stores, e.g. to give a few large tenants more generous limits than the rest.
+	StoreLimits []StoreLimitsConfig
+
+	// Materialization configures an index of precomputed permissions for hot relations, which is used to
+	// answer Checks against those relations when it is caught up with the changelog.
+	Materialization MaterializationConfig
+
 	// RequestTimeout configures request timeout.  If both HTTP upstream timeout and request timeout are specified,
 	// request timeout will be prioritized
 	RequestTimeout time.Duration
@@ -283,6 +418,7 @@ type Config struct {
 	DispatchThrottling            DispatchThrottlingConfig
 	CheckDispatchThrottling       DispatchThrottlingConfig
 	ListObjectsDispatchThrottling DispatchThrottlingConfig
+	ListUsersDispatchThrottling   DispatchThrottlingConfig
 
 	RequestDurationDatastoreQueryCountBuckets []string
 	RequestDurationDispatchCountBuckets       []string
@@ -394,6 +530,65 @@ func (cfg *Config) Verify() error {
 		}
 	}
 
+	if cfg.ListUsersDispatchThrottling.Enabled {
+		if cfg.ListUsersDispatchThrottling.Frequency <= 0 {
+			return errors.New("'listUsersDispatchThrottling.frequency' must be non-negative time duration")
+		}
+		if cfg.ListUsersDispatchThrottling.Threshold <= 0 {
+			return errors.New("'listUsersDispatchThrottling.threshold' must be non-negative integer")
+		}
+		if cfg.ListUsersDispatchThrottling.MaxThreshold != 0 && cfg.ListUsersDispatchThrottling.Threshold > cfg.ListUsersDispatchThrottling.MaxThreshold {
+			return errors.New("'listUsersDispatchThrottling.threshold' must be less than or equal to 'listUsersDispatchThrottling.maxThreshold'")
+		}
+	}
+
+	storeIDs := make(map[string]struct{}, len(cfg.StoreLimits))
+	for _, storeLimits := range cfg.StoreLimits {
+		if storeLimits.StoreID == "" {
+			return errors.New("'storeLimits.storeID' must be set")
+		}
+
+		if _, ok := storeIDs[storeLimits.StoreID]; ok {
+			return fmt.Errorf("'storeLimits' configured more than once for store '%s'", storeLimits.StoreID)
+		}
+		storeIDs[storeLimits.StoreID] = struct{}{}
+	}
+
+	if cfg.AuthorizationModelRetention.Enabled {
+		if cfg.AuthorizationModelRetention.KeepLast < 1 {
+			return errors.New("'authorizationModelRetention.keepLast' must be at least 1")
+		}
+
+		if cfg.AuthorizationModelRetention.KeepUsedWithin < 0 {
+			return errors.New("'authorizationModelRetention.keepUsedWithin' must be a non-negative time duration")
+		}
+
+		if cfg.AuthorizationModelRetention.Interval <= 0 {
+			return errors.New("'authorizationModelRetention.interval' must be a positive time duration")
+		}
+	}
+
+	if cfg.Materialization.Enabled {
+		if cfg.Materialization.SyncInterval <= 0 {
+			return errors.New("'materialization.syncInterval' must be a positive time duration")
+		}
+
+		if cfg.Materialization.MaxStaleness < cfg.Materialization.SyncInterval {
+			return errors.New("'materialization.maxStaleness' must be a time duration at least 'materialization.syncInterval'")
+		}
+
+		if cfg.Materialization.MaxEntriesPerStore <= 0 {
+			return errors.New("'materialization.maxEntriesPerStore' must be a positive integer")
+		}
+
+		for _, relation := range cfg.Materialization.Relations {
+			objectType, relationName, ok := strings.Cut(relation, "#")
+			if !ok || objectType == "" || relationName == "" || strings.Contains(objectType, ":") {
+				return fmt.Errorf("'materialization.relations' must be of the form 'type#relation', got '%s'", relation)
+			}
+		}
+	}
+
 	if cfg.RequestTimeout < 0 {
 		return errors.New("requestTimeout must be a non-negative time duration")
 	}
@@ -586,6 +781,38 @@ func DefaultConfig() *Config {
 			Threshold:    DefaultListObjectsDispatchThrottlingDefaultThreshold,
 			MaxThreshold: DefaultListObjectsDispatchThrottlingMaxThreshold,
 		},
+		ListUsersDispatchThrottling: DispatchThrottlingConfig{
+			Enabled:      DefaultListUsersDispatchThrottlingEnabled,
+			Frequency:    DefaultListUsersDispatchThrottlingFrequency,
+			Threshold:    DefaultListUsersDispatchThrottlingDefaultThreshold,
+			MaxThreshold: DefaultListUsersDispatchThrottlingMaxThreshold,
+		},
+		StreamedListObjects: StreamedListObjectsConfig{
+			Deduplication: DefaultStreamedListObjectsDeduplication,
+			Sorting:       DefaultStreamedListObjectsSorting,
+		},
+		AuthorizationModelCompatibilityCheck: AuthorizationModelCompatibilityCheckConfig{
+			Enabled:   DefaultAuthorizationModelCompatibilityCheckEnabled,
+			Reject:    DefaultAuthorizationModelCompatibilityCheckReject,
+			MaxTuples: DefaultAuthorizationModelCompatibilityCheckMaxTuples,
+			Deadline:  DefaultAuthorizationModelCompatibilityCheckDeadline,
+		},
+		AuthorizationModelLint: AuthorizationModelLintConfig{
+			Enabled: DefaultAuthorizationModelLintEnabled,
+		},
+		AuthorizationModelRetention: AuthorizationModelRetentionConfig{
+			Enabled:        DefaultAuthorizationModelRetentionEnabled,
+			KeepLast:       DefaultAuthorizationModelRetentionKeepLast,
+			KeepUsedWithin: DefaultAuthorizationModelRetentionKeepUsedWithin,
+			Interval:       DefaultAuthorizationModelRetentionInterval,
+		},
+		Materialization: MaterializationConfig{
+			Enabled:            DefaultMaterializationEnabled,
+			Relations:          []string{},
+			SyncInterval:       DefaultMaterializationSyncInterval,
+			MaxStaleness:       DefaultMaterializationMaxStaleness,
+			MaxEntriesPerStore: DefaultMaterializationMaxEntriesPerStore,
+		},
 		RequestTimeout: DefaultRequestTimeout,
 	}
 }
@@ -273,6 +273,82 @@ func TestVerifyConfig(t *testing.T) {
 		err := cfg.Verify()
 		require.Error(t, err)
 	})
+
+	t.Run("store_limits_require_store_id", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.StoreLimits = []StoreLimitsConfig{{ResolveNodeLimit: 10}}
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "'storeLimits.storeID' must be set")
+	})
+
+	t.Run("store_limits_must_be_unique_per_store", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.StoreLimits = []StoreLimitsConfig{
+			{StoreID: "01HVMMBCMGZNT3SED4Z17ECXCA", ResolveNodeLimit: 10},
+			{StoreID: "01HVMMBCMGZNT3SED4Z17ECXCA", ResolveNodeBreadthLimit: 10},
+		}
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "'storeLimits' configured more than once for store '01HVMMBCMGZNT3SED4Z17ECXCA'")
+	})
+
+	t.Run("materialization_relations_must_be_type_and_relation", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.Materialization.Enabled = true
+		cfg.Materialization.Relations = []string{"document#viewer", "document:1#viewer"}
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "'materialization.relations' must be of the form 'type#relation', got 'document:1#viewer'")
+	})
+
+	t.Run("materialization_sync_interval_must_be_positive", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.Materialization.Enabled = true
+		cfg.Materialization.Relations = []string{"document#viewer"}
+		cfg.Materialization.SyncInterval = 0
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "'materialization.syncInterval' must be a positive time duration")
+	})
+
+	t.Run("materialization_max_staleness_must_be_at_least_the_sync_interval", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.Materialization.Enabled = true
+		cfg.Materialization.Relations = []string{"document#viewer"}
+		cfg.Materialization.MaxStaleness = cfg.Materialization.SyncInterval / 2
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "'materialization.maxStaleness' must be a time duration at least 'materialization.syncInterval'")
+	})
+
+	t.Run("materialization_max_entries_per_store_must_be_positive", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.Materialization.Enabled = true
+		cfg.Materialization.Relations = []string{"document#viewer"}
+		cfg.Materialization.MaxEntriesPerStore = 0
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "'materialization.maxEntriesPerStore' must be a positive integer")
+	})
+
+	t.Run("authorization_model_retention_must_keep_the_latest_model", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.AuthorizationModelRetention.Enabled = true
+		cfg.AuthorizationModelRetention.KeepLast = 0
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "'authorizationModelRetention.keepLast' must be at least 1")
+	})
+
+	t.Run("authorization_model_retention_interval_must_be_positive", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.AuthorizationModelRetention.Enabled = true
+		cfg.AuthorizationModelRetention.Interval = 0
+
+		err := cfg.Verify()
+		require.EqualError(t, err, "'authorizationModelRetention.interval' must be a positive time duration")
+	})
 }
 
 func TestDefaultMaxConditionValuationCost(t *testing.T) {
@@ -0,0 +1,64 @@
+package encoder
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+)
+
+// ErrInvalidSignature is returned when decoding data whose signature doesn't match, e.g. because it was tampered
+// with or signed with another key.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// Ensure HMACEncoder implements the Encoder interface.
+var _ Encoder = (*HMACEncoder)(nil)
+
+// HMACEncoder combines an HMAC-SHA256 signature and an encoder to provide encoding and decoding of tokens which
+// can't be forged by clients, although they can read them.
+type HMACEncoder struct {
+	key     []byte
+	encoder Encoder
+}
+
+// NewHMACEncoder constructs an HMACEncoder signing with the provided key and encoding with the provided encoder.
+func NewHMACEncoder(key []byte, encoder Encoder) *HMACEncoder {
+	return &HMACEncoder{
+		key:     key,
+		encoder: encoder,
+	}
+}
+
+// Decode first decodes the input string using its internal decoder, and subsequently verifies and strips the
+// signature of the resulting data. It returns ErrInvalidSignature if the signature doesn't match.
+func (e *HMACEncoder) Decode(s string) ([]byte, error) {
+	decoded, err := e.encoder.Decode(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(decoded) < sha256.Size {
+		return nil, ErrInvalidSignature
+	}
+
+	data, signature := decoded[:len(decoded)-sha256.Size], decoded[len(decoded)-sha256.Size:]
+	if !hmac.Equal(signature, e.sign(data)) {
+		return nil, ErrInvalidSignature
+	}
+
+	return data, nil
+}
+
+// Encode first appends the signature of the provided data to it, and then encodes the result using its encoder.
+func (e *HMACEncoder) Encode(data []byte) (string, error) {
+	signed := make([]byte, 0, len(data)+sha256.Size)
+	signed = append(signed, data...)
+	signed = append(signed, e.sign(data)...)
+
+	return e.encoder.Encode(signed)
+}
+
+func (e *HMACEncoder) sign(data []byte) []byte {
+	mac := hmac.New(sha256.New, e.key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
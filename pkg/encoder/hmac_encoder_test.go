@@ -0,0 +1,49 @@
+package encoder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHMACEncodeDecode(t *testing.T) {
+	encoder := NewHMACEncoder([]byte("key"), NewBase64Encoder())
+
+	encoded, err := encoder.Encode([]byte("token"))
+	require.NoError(t, err)
+
+	decoded, err := encoder.Decode(encoded)
+	require.NoError(t, err)
+	require.Equal(t, []byte("token"), decoded)
+}
+
+func TestHMACDecodeRejectsForgedData(t *testing.T) {
+	encoder := NewHMACEncoder([]byte("key"), NewBase64Encoder())
+
+	t.Run("unsigned", func(t *testing.T) {
+		encoded, err := NewBase64Encoder().Encode([]byte("token"))
+		require.NoError(t, err)
+
+		_, err = encoder.Decode(encoded)
+		require.ErrorIs(t, err, ErrInvalidSignature)
+	})
+
+	t.Run("signed_with_another_key", func(t *testing.T) {
+		encoded, err := NewHMACEncoder([]byte("other key"), NewBase64Encoder()).Encode([]byte("token"))
+		require.NoError(t, err)
+
+		_, err = encoder.Decode(encoded)
+		require.ErrorIs(t, err, ErrInvalidSignature)
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		encoded, err := NewHMACEncoder([]byte("key"), NoopEncoder{}).Encode([]byte("token"))
+		require.NoError(t, err)
+
+		tampered, err := NewBase64Encoder().Encode(append([]byte("tokeN"), encoded[len("token"):]...))
+		require.NoError(t, err)
+
+		_, err = encoder.Decode(tampered)
+		require.ErrorIs(t, err, ErrInvalidSignature)
+	})
+}
@@ -0,0 +1,174 @@
+package http
+
+import (
+	"bytes"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"google.golang.org/grpc/grpclog"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// DSLContentType is the media type of the authorization models written in the DSL. WriteAuthorizationModel requests
+// with this content type have a DSL body, and ReadAuthorizationModel requests accepting it are answered with the DSL
+// rendering of the model.
+const DSLContentType = "text/vnd.openfga.dsl"
+
+// AuthorizationModelDSLHandler wraps the handler of the HTTP gateway to accept authorization models written in
+// the DSL in WriteAuthorizationModel requests, and to render the authorization models of ReadAuthorizationModel
+// responses in the DSL, according to DSLContentType. The requests are otherwise forwarded as they are. The DSL
+// bodies larger than maxSizeInBytes (see server.WithMaxAuthorizationModelSizeInBytes) are rejected without being
+// read further.
+func AuthorizationModelDSLHandler(next http.Handler, maxSizeInBytes int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && isAuthorizationModelsPath(r.URL.Path) && hasMediaType(r.Header.Get("Content-Type"), DSLContentType):
+			writeAuthorizationModelDSL(next, w, r, maxSizeInBytes)
+		case r.Method == http.MethodGet && isAuthorizationModelPath(r.URL.Path) && acceptsMediaType(r.Header.Get("Accept"), DSLContentType):
+			readAuthorizationModelDSL(next, w, r)
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// isAuthorizationModelsPath returns whether a path is of the form '/stores/{store_id}/authorization-models'.
+func isAuthorizationModelsPath(path string) bool {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	return len(parts) == 3 && parts[0] == "stores" && parts[1] != "" && parts[2] == "authorization-models"
+}
+
+// isAuthorizationModelPath returns whether a path is of the form '/stores/{store_id}/authorization-models/{id}'.
+func isAuthorizationModelPath(path string) bool {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	return len(parts) == 4 && isAuthorizationModelsPath(strings.Join(parts[:3], "/")) && parts[3] != ""
+}
+
+func hasMediaType(header, mediaType string) bool {
+	parsed, _, err := mime.ParseMediaType(header)
+	return err == nil && parsed == mediaType
+}
+
+func acceptsMediaType(header, mediaType string) bool {
+	for _, accepted := range strings.Split(header, ",") {
+		if hasMediaType(strings.TrimSpace(accepted), mediaType) {
+			return true
+		}
+	}
+	return false
+}
+
+func writeAuthorizationModelDSL(next http.Handler, w http.ResponseWriter, r *http.Request, maxSizeInBytes int) {
+	dsl, err := io.ReadAll(http.MaxBytesReader(w, r.Body, int64(maxSizeInBytes)))
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if stderrors.As(err, &maxBytesErr) {
+			CustomHTTPErrorHandler(r.Context(), w, r, errors.NewEncodedError(
+				int32(openfgav1.ErrorCode_exceeded_entity_limit),
+				fmt.Sprintf("model exceeds size limit: more than %d bytes", maxSizeInBytes),
+			))
+			return
+		}
+
+		CustomHTTPErrorHandler(r.Context(), w, r, errors.NewEncodedError(int32(openfgav1.ErrorCode_validation_error), err.Error()))
+		return
+	}
+
+	model, err := typesystem.ParseDSL(string(dsl))
+	if err != nil {
+		CustomHTTPErrorHandler(r.Context(), w, r, errors.NewEncodedError(int32(openfgav1.ErrorCode_validation_error), err.Error()))
+		return
+	}
+
+	body, err := protojson.Marshal(&openfgav1.WriteAuthorizationModelRequest{
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+		Conditions:      model.GetConditions(),
+	})
+	if err != nil {
+		CustomHTTPErrorHandler(r.Context(), w, r, errors.NewEncodedError(int32(openfgav1.InternalErrorCode_internal_error), err.Error()))
+		return
+	}
+
+	r = r.Clone(r.Context())
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Content-Length", strconv.Itoa(len(body)))
+
+	next.ServeHTTP(w, r)
+}
+
+// bufferedResponseWriter buffers a response to rewrite it.
+type bufferedResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponseWriter) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponseWriter) Write(p []byte) (int, error) {
+	return b.body.Write(p)
+}
+
+func (b *bufferedResponseWriter) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+func readAuthorizationModelDSL(next http.Handler, w http.ResponseWriter, r *http.Request) {
+	r = r.Clone(r.Context())
+	r.Header.Set("Accept", "application/json")
+
+	buffered := &bufferedResponseWriter{header: http.Header{}}
+	next.ServeHTTP(buffered, r)
+
+	for key, values := range buffered.header {
+		w.Header()[key] = values
+	}
+
+	status := buffered.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	if status != http.StatusOK {
+		w.WriteHeader(status)
+		if _, err := w.Write(buffered.body.Bytes()); err != nil { // nosemgrep: no-direct-write-to-responsewriter
+			grpclog.Infof("Failed to write response: %v", err)
+		}
+		return
+	}
+
+	var resp openfgav1.ReadAuthorizationModelResponse
+	if err := protojson.Unmarshal(buffered.body.Bytes(), &resp); err != nil {
+		CustomHTTPErrorHandler(r.Context(), w, r, errors.NewEncodedError(int32(openfgav1.InternalErrorCode_internal_error), err.Error()))
+		return
+	}
+
+	dsl, err := typesystem.FormatDSL(resp.GetAuthorizationModel())
+	if err != nil {
+		CustomHTTPErrorHandler(r.Context(), w, r, errors.NewEncodedError(int32(openfgav1.InternalErrorCode_internal_error), err.Error()))
+		return
+	}
+
+	w.Header().Set("Content-Type", DSLContentType)
+	w.Header().Del("Content-Length")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write([]byte(dsl)); err != nil { // nosemgrep: no-direct-write-to-responsewriter
+		grpclog.Infof("Failed to write response: %v", err)
+	}
+}
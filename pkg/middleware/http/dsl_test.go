@@ -0,0 +1,156 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/openfga/openfga/pkg/testutils"
+)
+
+const dslModel = `model
+  schema 1.1
+
+type user
+
+type document
+  relations
+    define viewer: [user]
+`
+
+func TestAuthorizationModelDSLHandlerWrite(t *testing.T) {
+	var forwarded *http.Request
+	var forwardedBody []byte
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded = r
+
+		var err error
+		forwardedBody, err = io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	handler := AuthorizationModelDSLHandler(next, 1024)
+
+	t.Run("dsl", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/stores/01HVMMBCMGZNT3SED4Z17ECXCA/authorization-models", strings.NewReader(dslModel))
+		req.Header.Set("Content-Type", DSLContentType+"; charset=utf-8")
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code)
+		require.Equal(t, "application/json", forwarded.Header.Get("Content-Type"))
+		require.Equal(t, int64(len(forwardedBody)), forwarded.ContentLength)
+
+		var body openfgav1.WriteAuthorizationModelRequest
+		require.NoError(t, protojson.Unmarshal(forwardedBody, &body))
+		require.Equal(t, "1.1", body.GetSchemaVersion())
+		require.Len(t, body.GetTypeDefinitions(), 2)
+	})
+
+	t.Run("syntax_error", func(t *testing.T) {
+		forwarded = nil
+
+		req := httptest.NewRequest(http.MethodPost, "/stores/01HVMMBCMGZNT3SED4Z17ECXCA/authorization-models", strings.NewReader("model\n  schema 1.1\ntype user\n  relations\n    define viewer: [user"))
+		req.Header.Set("Content-Type", DSLContentType)
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+		require.Nil(t, forwarded)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), `"code":"validation_error"`)
+		require.Contains(t, w.Body.String(), "syntax error at line=4")
+	})
+
+	t.Run("too_large", func(t *testing.T) {
+		forwarded = nil
+
+		req := httptest.NewRequest(http.MethodPost, "/stores/01HVMMBCMGZNT3SED4Z17ECXCA/authorization-models", strings.NewReader(dslModel+strings.Repeat("#", 1024)))
+		req.Header.Set("Content-Type", DSLContentType)
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+		require.Nil(t, forwarded)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), `"code":"exceeded_entity_limit"`)
+		require.Contains(t, w.Body.String(), "model exceeds size limit")
+	})
+
+	t.Run("json_is_forwarded_as_it_is", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/stores/01HVMMBCMGZNT3SED4Z17ECXCA/authorization-models", strings.NewReader(`{"schema_version":"1.1"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+		require.Same(t, req, forwarded)
+		require.Equal(t, `{"schema_version":"1.1"}`, string(forwardedBody))
+	})
+}
+
+func TestAuthorizationModelDSLHandlerRead(t *testing.T) {
+	model := testutils.MustTransformDSLToProtoWithID(dslModel)
+
+	status := http.StatusOK
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/json", r.Header.Get("Accept"))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Openfga-Authorization-Model-Id", model.GetId())
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"code":"authorization_model_not_found"}`))
+			return
+		}
+
+		body, err := protojson.Marshal(&openfgav1.ReadAuthorizationModelResponse{AuthorizationModel: model})
+		require.NoError(t, err)
+		_, _ = w.Write(body)
+	})
+
+	handler := AuthorizationModelDSLHandler(next, 1024)
+
+	t.Run("dsl", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/stores/01HVMMBCMGZNT3SED4Z17ECXCA/authorization-models/"+model.GetId(), nil)
+		req.Header.Set("Accept", "application/json, "+DSLContentType)
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Equal(t, DSLContentType, w.Header().Get("Content-Type"))
+		require.Equal(t, model.GetId(), w.Header().Get("Openfga-Authorization-Model-Id"))
+		require.Equal(t, dslModel, w.Body.String())
+	})
+
+	t.Run("error_is_forwarded_as_it_is", func(t *testing.T) {
+		status = http.StatusNotFound
+		t.Cleanup(func() { status = http.StatusOK })
+
+		req := httptest.NewRequest(http.MethodGet, "/stores/01HVMMBCMGZNT3SED4Z17ECXCA/authorization-models/"+model.GetId(), nil)
+		req.Header.Set("Accept", DSLContentType)
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+		require.Equal(t, http.StatusNotFound, w.Code)
+		require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		require.Equal(t, `{"code":"authorization_model_not_found"}`, w.Body.String())
+	})
+}
+
+func TestAuthorizationModelPaths(t *testing.T) {
+	require.True(t, isAuthorizationModelsPath("/stores/abc/authorization-models"))
+	require.True(t, isAuthorizationModelsPath("/stores/abc/authorization-models/"))
+	require.False(t, isAuthorizationModelsPath("/stores//authorization-models"))
+	require.False(t, isAuthorizationModelsPath("/stores/abc/authorization-models/def"))
+	require.False(t, isAuthorizationModelsPath("/stores/abc/assertions"))
+
+	require.True(t, isAuthorizationModelPath("/stores/abc/authorization-models/def"))
+	require.False(t, isAuthorizationModelPath("/stores/abc/authorization-models"))
+	require.False(t, isAuthorizationModelPath("/stores/abc/assertions/def"))
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/telemetry"
+)
+
+// SetActiveAuthorizationModel pins a store to one of its authorization models, which is then used instead of the
+// latest model by the queries that don't specify a model, so that writing a new model doesn't roll it out. An empty
+// model ID unpins the store. It returns the ID of the model the store was pinned to before (empty if it wasn't
+// pinned), so that the change can be rolled back by setting it again. The change is seen immediately by this server,
+// and within a few seconds by the other servers sharing the datastore, which cache the active models of the stores.
+func (s *Server) SetActiveAuthorizationModel(ctx context.Context, storeID, modelID string) (string, error) {
+	ctx, span := tracer.Start(ctx, "SetActiveAuthorizationModel", trace.WithAttributes(
+		attribute.String("store_id", storeID),
+		attribute.String("authorization_model_id", modelID),
+	))
+	defer span.End()
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  "SetActiveAuthorizationModel",
+	})
+
+	if modelID != "" {
+		if _, err := s.resolveTypesystem(ctx, storeID, modelID); err != nil {
+			return "", err
+		}
+	}
+
+	// the model may have been deleted since it was resolved, which the datastore checks atomically with the change
+	previousModelID, err := s.datastore.WriteActiveAuthorizationModelID(ctx, storeID, modelID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return "", serverErrors.AuthorizationModelNotFound(modelID)
+		}
+		return "", serverErrors.HandleError("", err)
+	}
+
+	s.activeAuthorizationModelIDCache.Delete(storeID)
+
+	span.SetAttributes(attribute.String("previous_authorization_model_id", previousModelID))
+
+	return previousModelID, nil
+}
+
+// GetActiveAuthorizationModel returns the ID of the authorization model a store is pinned to, or an empty ID if
+// the store isn't pinned to a model and the queries that don't specify a model use the latest model.
+func (s *Server) GetActiveAuthorizationModel(ctx context.Context, storeID string) (string, error) {
+	ctx, span := tracer.Start(ctx, "GetActiveAuthorizationModel", trace.WithAttributes(
+		attribute.String("store_id", storeID),
+	))
+	defer span.End()
+
+	modelID, err := s.datastore.ReadActiveAuthorizationModelID(ctx, storeID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return "", nil
+		}
+
+		return "", serverErrors.HandleError("", err)
+	}
+
+	return modelID, nil
+}
@@ -0,0 +1,150 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	language "github.com/openfga/language/pkg/go/transformer"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+func TestActiveAuthorizationModel(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+
+	ds := memory.New()
+
+	s := MustNewServerWithOpts(WithDatastore(ds))
+	t.Cleanup(s.Close)
+
+	writeModel := func(dsl string) string {
+		resp, err := s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+			StoreId:         storeID,
+			SchemaVersion:   typesystem.SchemaVersion1_1,
+			TypeDefinitions: language.MustTransformDSLToProto(dsl).GetTypeDefinitions(),
+		})
+		require.NoError(t, err)
+		return resp.GetAuthorizationModelId()
+	}
+
+	check := func() bool {
+		resp, err := s.Check(ctx, &openfgav1.CheckRequest{
+			StoreId:  storeID,
+			TupleKey: tuple.NewCheckRequestTupleKey("document:1", "viewer", "user:anne"),
+		})
+		require.NoError(t, err)
+		return resp.GetAllowed()
+	}
+
+	modelID1 := writeModel(`
+		model
+			schema 1.1
+
+		type user
+
+		type document
+			relations
+				define editor: [user]
+				define viewer: [user] or editor`)
+
+	_, err := s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{
+			TupleKeys: []*openfgav1.TupleKey{
+				tuple.NewTupleKey("document:1", "editor", "user:anne"),
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	activeModelID, err := s.GetActiveAuthorizationModel(ctx, storeID)
+	require.NoError(t, err)
+	require.Empty(t, activeModelID)
+
+	previousModelID, err := s.SetActiveAuthorizationModel(ctx, storeID, modelID1)
+	require.NoError(t, err)
+	require.Empty(t, previousModelID)
+
+	modelID2 := writeModel(`
+		model
+			schema 1.1
+
+		type user
+
+		type document
+			relations
+				define editor: [user]
+				define viewer: [user]`)
+
+	// the store is pinned to the first model, so the latest model isn't used
+	require.True(t, check())
+
+	previousModelID, err = s.SetActiveAuthorizationModel(ctx, storeID, modelID2)
+	require.NoError(t, err)
+	require.Equal(t, modelID1, previousModelID)
+	require.False(t, check())
+
+	// roll back
+	previousModelID, err = s.SetActiveAuthorizationModel(ctx, storeID, previousModelID)
+	require.NoError(t, err)
+	require.Equal(t, modelID2, previousModelID)
+	require.True(t, check())
+
+	activeModelID, err = s.GetActiveAuthorizationModel(ctx, storeID)
+	require.NoError(t, err)
+	require.Equal(t, modelID1, activeModelID)
+
+	t.Run("unpin", func(t *testing.T) {
+		previousModelID, err := s.SetActiveAuthorizationModel(ctx, storeID, "")
+		require.NoError(t, err)
+		require.Equal(t, modelID1, previousModelID)
+
+		activeModelID, err := s.GetActiveAuthorizationModel(ctx, storeID)
+		require.NoError(t, err)
+		require.Empty(t, activeModelID)
+
+		// the latest model is used again
+		require.False(t, check())
+	})
+
+	t.Run("model_not_found", func(t *testing.T) {
+		modelID := ulid.Make().String()
+
+		_, err := s.SetActiveAuthorizationModel(ctx, storeID, modelID)
+		require.ErrorIs(t, err, serverErrors.AuthorizationModelNotFound(modelID))
+
+		_, err = s.SetActiveAuthorizationModel(ctx, ulid.Make().String(), modelID1)
+		require.ErrorIs(t, err, serverErrors.AuthorizationModelNotFound(modelID1))
+	})
+
+	t.Run("deleted_model_not_found", func(t *testing.T) {
+		writeModel(`
+			model
+				schema 1.1
+
+			type user`)
+
+		// the model is still cached by the server once it is deleted
+		err := ds.DeleteAuthorizationModel(ctx, storeID, modelID2)
+		require.NoError(t, err)
+
+		_, err = s.SetActiveAuthorizationModel(ctx, storeID, modelID2)
+		require.ErrorIs(t, err, serverErrors.AuthorizationModelNotFound(modelID2))
+
+		activeModelID, err := s.GetActiveAuthorizationModel(ctx, storeID)
+		require.NoError(t, err)
+		require.Empty(t, activeModelID)
+	})
+}
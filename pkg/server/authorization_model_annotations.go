@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/telemetry"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// WriteAuthorizationModelAnnotations overwrites the annotations (free-form metadata such as descriptions, owners or
+// UI hints) of the types, relations and conditions of an authorization model of a store, which may have been written
+// with the model by WriteAuthorizationModelWithAnnotations. The annotations are validated against the model (see
+// typesystem.ValidateAnnotations).
+func (s *Server) WriteAuthorizationModelAnnotations(
+	ctx context.Context,
+	storeID, modelID string,
+	annotations *storage.AuthorizationModelAnnotations,
+) error {
+	ctx, span := tracer.Start(ctx, "WriteAuthorizationModelAnnotations", trace.WithAttributes(
+		attribute.String("store_id", storeID),
+		attribute.String("authorization_model_id", modelID),
+	))
+	defer span.End()
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  "WriteAuthorizationModelAnnotations",
+	})
+
+	typesys, err := s.resolveTypesystem(ctx, storeID, modelID)
+	if err != nil {
+		return err
+	}
+
+	if err := typesystem.ValidateAnnotations(typesys, annotations); err != nil {
+		return serverErrors.ValidationError(err)
+	}
+
+	if annotations == nil {
+		annotations = &storage.AuthorizationModelAnnotations{}
+	}
+
+	err = s.datastore.WriteAuthorizationModelAnnotations(ctx, storeID, typesys.GetAuthorizationModelID(), annotations)
+	if err != nil {
+		return serverErrors.HandleError("", err)
+	}
+
+	return nil
+}
+
+// ReadAuthorizationModelAnnotations returns the annotations of an authorization model of a store, which
+// ReadAuthorizationModel can't return because the API messages have no field for them.
+func (s *Server) ReadAuthorizationModelAnnotations(ctx context.Context, storeID, modelID string) (*storage.AuthorizationModelAnnotations, error) {
+	ctx, span := tracer.Start(ctx, "ReadAuthorizationModelAnnotations", trace.WithAttributes(
+		attribute.String("store_id", storeID),
+		attribute.String("authorization_model_id", modelID),
+	))
+	defer span.End()
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  "ReadAuthorizationModelAnnotations",
+	})
+
+	typesys, err := s.resolveTypesystem(ctx, storeID, modelID)
+	if err != nil {
+		return nil, err
+	}
+
+	annotations, err := s.datastore.ReadAuthorizationModelAnnotations(ctx, storeID, typesys.GetAuthorizationModelID())
+	if err != nil {
+		return nil, serverErrors.HandleError("", err)
+	}
+
+	return annotations, nil
+}
+
+// DescribeRelation describes a relation of an authorization model of a store (an empty model ID refers to the model
+// used by the queries which don't specify one): its definition, the user types directly related to it, the relations
+// it is computed from, and its annotations and those of its type.
+func (s *Server) DescribeRelation(
+	ctx context.Context,
+	storeID, modelID, objectType, relation string,
+) (*typesystem.RelationDescription, error) {
+	ctx, span := tracer.Start(ctx, "DescribeRelation", trace.WithAttributes(
+		attribute.String("store_id", storeID),
+		attribute.String("authorization_model_id", modelID),
+		attribute.String("object_type", objectType),
+		attribute.String("relation", relation),
+	))
+	defer span.End()
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  "DescribeRelation",
+	})
+
+	typesys, err := s.resolveTypesystem(ctx, storeID, modelID)
+	if err != nil {
+		return nil, err
+	}
+
+	annotations, err := s.datastore.ReadAuthorizationModelAnnotations(ctx, storeID, typesys.GetAuthorizationModelID())
+	if err != nil {
+		return nil, serverErrors.HandleError("", err)
+	}
+
+	description, err := typesystem.DescribeRelation(typesys, annotations, objectType, relation)
+	if err != nil {
+		switch {
+		case errors.Is(err, typesystem.ErrObjectTypeUndefined):
+			return nil, serverErrors.TypeNotFound(objectType)
+		case errors.Is(err, typesystem.ErrRelationUndefined):
+			return nil, serverErrors.RelationNotFound(relation, objectType, nil)
+		default:
+			return nil, serverErrors.HandleError("", err)
+		}
+	}
+
+	return description, nil
+}
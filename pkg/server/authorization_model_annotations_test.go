@@ -0,0 +1,158 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+func TestAuthorizationModelAnnotations(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+
+	s := MustNewServerWithOpts(WithDatastore(memory.New()))
+	t.Cleanup(s.Close)
+
+	resp, err := s.WriteAuthorizationModelDSL(ctx, storeID, `model
+  schema 1.1
+
+type user
+
+type folder
+  relations
+    define viewer: [user]
+
+type document
+  relations
+    define parent: [folder]
+    define owner: [user]
+    define viewer: [user] or owner or viewer from parent
+`)
+	require.NoError(t, err)
+	modelID := resp.GetAuthorizationModelId()
+
+	annotations := &storage.AuthorizationModelAnnotations{
+		Types: map[string]*storage.TypeAnnotations{
+			"document": {
+				Annotations: storage.Annotations{"owner": "docs-team"},
+				Relations: map[string]storage.Annotations{
+					"viewer": {"description": "Can read the document"},
+				},
+			},
+		},
+	}
+
+	t.Run("read_before_write", func(t *testing.T) {
+		readAnnotations, err := s.ReadAuthorizationModelAnnotations(ctx, storeID, modelID)
+		require.NoError(t, err)
+		require.Equal(t, &storage.AuthorizationModelAnnotations{}, readAnnotations)
+	})
+
+	t.Run("write_and_read", func(t *testing.T) {
+		err := s.WriteAuthorizationModelAnnotations(ctx, storeID, modelID, annotations)
+		require.NoError(t, err)
+
+		readAnnotations, err := s.ReadAuthorizationModelAnnotations(ctx, storeID, modelID)
+		require.NoError(t, err)
+		require.Equal(t, annotations, readAnnotations)
+
+		// an empty model ID refers to the latest model
+		readAnnotations, err = s.ReadAuthorizationModelAnnotations(ctx, storeID, "")
+		require.NoError(t, err)
+		require.Equal(t, annotations, readAnnotations)
+	})
+
+	t.Run("describe_relation", func(t *testing.T) {
+		description, err := s.DescribeRelation(ctx, storeID, "", "document", "viewer")
+		require.NoError(t, err)
+		require.Equal(t, &typesystem.RelationDescription{
+			Type:                     "document",
+			Relation:                 "viewer",
+			Definition:               "[user] or owner or viewer from parent",
+			DirectlyRelatedUserTypes: []string{"user"},
+			ComputedFrom: []*typesystem.RelationSource{
+				{Relation: "document#owner", Operator: typesystem.RelationSourceUnion},
+				{Relation: "folder#viewer", Tupleset: "parent", Operator: typesystem.RelationSourceUnion},
+			},
+			Annotations:     storage.Annotations{"description": "Can read the document"},
+			TypeAnnotations: storage.Annotations{"owner": "docs-team"},
+		}, description)
+	})
+
+	t.Run("invalid_annotations", func(t *testing.T) {
+		err := s.WriteAuthorizationModelAnnotations(ctx, storeID, modelID, &storage.AuthorizationModelAnnotations{
+			Types: map[string]*storage.TypeAnnotations{
+				"document": {Relations: map[string]storage.Annotations{"editor": {"description": "Can edit the document"}}},
+			},
+		})
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_validation_error), status.Code(err))
+		require.Contains(t, status.Convert(err).Message(), "relation 'document#editor' is not defined")
+	})
+
+	t.Run("type_not_found", func(t *testing.T) {
+		_, err := s.DescribeRelation(ctx, storeID, modelID, "team", "member")
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_type_not_found), status.Code(err))
+	})
+
+	t.Run("relation_not_found", func(t *testing.T) {
+		_, err := s.DescribeRelation(ctx, storeID, modelID, "document", "editor")
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_relation_not_found), status.Code(err))
+	})
+
+	t.Run("write_with_the_model", func(t *testing.T) {
+		model := testutils.MustTransformDSLToProtoWithID(`model
+  schema 1.1
+
+type user
+
+type document
+  relations
+    define viewer: [user]
+`)
+
+		resp, err := s.WriteAuthorizationModelWithAnnotations(ctx, &openfgav1.WriteAuthorizationModelRequest{
+			StoreId:         storeID,
+			SchemaVersion:   model.GetSchemaVersion(),
+			TypeDefinitions: model.GetTypeDefinitions(),
+		}, annotations)
+		require.NoError(t, err)
+
+		readAnnotations, err := s.ReadAuthorizationModelAnnotations(ctx, storeID, resp.GetAuthorizationModelId())
+		require.NoError(t, err)
+		require.Equal(t, annotations, readAnnotations)
+
+		// neither the model nor its annotations are written if the annotations are invalid
+		_, err = s.WriteAuthorizationModelWithAnnotations(ctx, &openfgav1.WriteAuthorizationModelRequest{
+			StoreId:         storeID,
+			SchemaVersion:   model.GetSchemaVersion(),
+			TypeDefinitions: model.GetTypeDefinitions(),
+		}, &storage.AuthorizationModelAnnotations{
+			Types: map[string]*storage.TypeAnnotations{"folder": {}},
+		})
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_validation_error), status.Code(err))
+
+		latestModel, err := s.datastore.FindLatestAuthorizationModel(ctx, storeID)
+		require.NoError(t, err)
+		require.Equal(t, resp.GetAuthorizationModelId(), latestModel.GetId())
+	})
+
+	t.Run("model_not_found", func(t *testing.T) {
+		err := s.WriteAuthorizationModelAnnotations(ctx, storeID, ulid.Make().String(), annotations)
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_authorization_model_not_found), status.Code(err))
+	})
+}
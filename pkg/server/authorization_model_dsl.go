@@ -0,0 +1,51 @@
+package server
+
+import (
+	"context"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// WriteAuthorizationModelDSL writes an authorization model written in the DSL, as WriteAuthorizationModel does. The
+// syntax errors of the DSL are reported as a validation error listing their line and column.
+func (s *Server) WriteAuthorizationModelDSL(ctx context.Context, storeID, dsl string) (*openfgav1.WriteAuthorizationModelResponse, error) {
+	ctx, span := tracer.Start(ctx, "WriteAuthorizationModelDSL")
+	defer span.End()
+
+	model, err := typesystem.ParseDSL(dsl)
+	if err != nil {
+		return nil, serverErrors.ValidationError(err)
+	}
+
+	return s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+		Conditions:      model.GetConditions(),
+	})
+}
+
+// ReadAuthorizationModelDSL reads an authorization model, as ReadAuthorizationModel does, and returns its rendering
+// in the DSL.
+func (s *Server) ReadAuthorizationModelDSL(ctx context.Context, storeID, modelID string) (string, error) {
+	ctx, span := tracer.Start(ctx, "ReadAuthorizationModelDSL")
+	defer span.End()
+
+	resp, err := s.ReadAuthorizationModel(ctx, &openfgav1.ReadAuthorizationModelRequest{
+		StoreId: storeID,
+		Id:      modelID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	dsl, err := typesystem.FormatDSL(resp.GetAuthorizationModel())
+	if err != nil {
+		return "", serverErrors.HandleError("", err)
+	}
+
+	return dsl, nil
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openfga/openfga/pkg/storage/memory"
+)
+
+func TestAuthorizationModelDSL(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+
+	s := MustNewServerWithOpts(WithDatastore(memory.New()))
+	t.Cleanup(s.Close)
+
+	dsl := `model
+  schema 1.1
+
+type user
+
+type document
+  relations
+    define editor: [user]
+    define viewer: [user] or editor
+`
+
+	t.Run("write_and_read", func(t *testing.T) {
+		resp, err := s.WriteAuthorizationModelDSL(ctx, storeID, dsl)
+		require.NoError(t, err)
+
+		readDSL, err := s.ReadAuthorizationModelDSL(ctx, storeID, resp.GetAuthorizationModelId())
+		require.NoError(t, err)
+		require.Equal(t, dsl, readDSL)
+	})
+
+	t.Run("syntax_error", func(t *testing.T) {
+		_, err := s.WriteAuthorizationModelDSL(ctx, storeID, `model
+  schema 1.1
+
+type user
+
+type document
+  relations
+    define viewer: [user editor]`)
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_validation_error), status.Code(err))
+		require.Contains(t, status.Convert(err).Message(), "syntax error at line=")
+	})
+
+	t.Run("invalid_model", func(t *testing.T) {
+		_, err := s.WriteAuthorizationModelDSL(ctx, storeID, `model
+  schema 1.1
+
+type user
+
+type document
+  relations
+    define viewer: [user] and editor`)
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_invalid_authorization_model), status.Code(err))
+	})
+
+	t.Run("model_not_found", func(t *testing.T) {
+		_, err := s.ReadAuthorizationModelDSL(ctx, storeID, ulid.Make().String())
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_authorization_model_not_found), status.Code(err))
+	})
+}
@@ -0,0 +1,295 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/telemetry"
+)
+
+// authorizationModelLastUsedRecordInterval is the interval at which the use of an authorization model is recorded
+// at most, so that requests don't all write to the datastore. The recorded time of the last use of a model may lag
+// behind by up to this interval, which the retention policy accounts for.
+const authorizationModelLastUsedRecordInterval = 10 * time.Minute
+
+// authorizationModelUseQueueSize is the number of uses of authorization models which can wait to be recorded. The
+// uses which don't fit are recorded by later requests.
+const authorizationModelUseQueueSize = 1000
+
+// authorizationModelUse is the use of an authorization model by a request, waiting to be recorded.
+type authorizationModelUse struct {
+	storeID string
+	modelID string
+	usedAt  time.Time
+}
+
+// DeleteAuthorizationModel deletes an authorization model of a store, along with its assertions and annotations. The
+// latest model of the store and the active model it is pinned to (see SetActiveAuthorizationModel) can't be deleted.
+//
+// Servers may keep answering the queries which specify a deleted model from their caches of typesystems until they
+// are evicted.
+func (s *Server) DeleteAuthorizationModel(ctx context.Context, storeID, modelID string) error {
+	ctx, span := tracer.Start(ctx, "DeleteAuthorizationModel", trace.WithAttributes(
+		attribute.String("store_id", storeID),
+		attribute.String("authorization_model_id", modelID),
+	))
+	defer span.End()
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  "DeleteAuthorizationModel",
+	})
+
+	if _, err := ulid.Parse(modelID); err != nil {
+		return serverErrors.AuthorizationModelNotFound(modelID)
+	}
+
+	if err := s.datastore.DeleteAuthorizationModel(ctx, storeID, modelID); err != nil {
+		switch {
+		case errors.Is(err, storage.ErrNotFound):
+			return serverErrors.AuthorizationModelNotFound(modelID)
+		case errors.Is(err, storage.ErrLatestAuthorizationModel):
+			return serverErrors.AuthorizationModelInUse(modelID, "latest")
+		case errors.Is(err, storage.ErrActiveAuthorizationModel):
+			return serverErrors.AuthorizationModelInUse(modelID, "active")
+		}
+
+		return serverErrors.HandleError("", err)
+	}
+
+	return nil
+}
+
+// GarbageCollectAuthorizationModels deletes the authorization models of a store which the retention policy doesn't
+// keep, along with their assertions, and returns their IDs. The policy keeps the latest models of the store (see
+// WithAuthorizationModelRetentionKeepLast), the active model the store is pinned to, and the models written or used
+// by requests recently (see WithAuthorizationModelRetentionKeepUsedWithin).
+//
+// The use of the models is only recorded if the retention is enabled, and no model is deleted until it has been
+// recorded without interruption for the KeepUsedWithin duration, since the models used before can't be told apart
+// from the unused ones. The servers sharing the datastore must all enable the retention, otherwise the models only
+// used through the others are deleted.
+func (s *Server) GarbageCollectAuthorizationModels(ctx context.Context, storeID string) ([]string, error) {
+	ctx, span := tracer.Start(ctx, "GarbageCollectAuthorizationModels", trace.WithAttributes(
+		attribute.String("store_id", storeID),
+	))
+	defer span.End()
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  "GarbageCollectAuthorizationModels",
+	})
+
+	activeModelID, err := s.GetActiveAuthorizationModel(ctx, storeID)
+	if err != nil {
+		return nil, err
+	}
+
+	lastUsed, err := s.datastore.ReadAuthorizationModelsLastUsed(ctx, storeID)
+	if err != nil {
+		return nil, serverErrors.HandleError("", err)
+	}
+
+	usedSince := time.Now().Add(-s.authorizationModelRetentionKeepUsedWithin - authorizationModelLastUsedRecordInterval)
+
+	if recordedSince := s.authorizationModelUseRecordedSince.Load(); recordedSince == nil || recordedSince.After(usedSince) {
+		// the use of the models hasn't been recorded for long enough to tell the unused ones
+		return nil, nil
+	}
+
+	var unusedModelIDs []string
+	var continuationToken string
+	var position int
+	for {
+		// the models are read from newest to oldest
+		models, token, err := s.datastore.ReadAuthorizationModels(ctx, storeID, storage.NewPaginationOptions(storage.DefaultPageSize, continuationToken))
+		if err != nil {
+			return nil, serverErrors.HandleError("", err)
+		}
+
+		for _, model := range models {
+			position++
+
+			modelID := model.GetId()
+			if position <= s.authorizationModelRetentionKeepLast || modelID == activeModelID {
+				continue
+			}
+
+			if used, ok := lastUsed[modelID]; ok && used.After(usedSince) {
+				continue
+			}
+
+			if id, err := ulid.Parse(modelID); err == nil && ulid.Time(id.Time()).After(usedSince) {
+				continue
+			}
+
+			unusedModelIDs = append(unusedModelIDs, modelID)
+		}
+
+		if len(token) == 0 {
+			break
+		}
+		continuationToken = string(token)
+	}
+
+	deletedModelIDs := make([]string, 0, len(unusedModelIDs))
+	for _, modelID := range unusedModelIDs {
+		if err := s.datastore.DeleteAuthorizationModel(ctx, storeID, modelID); err != nil {
+			if errors.Is(err, storage.ErrNotFound) ||
+				errors.Is(err, storage.ErrLatestAuthorizationModel) ||
+				errors.Is(err, storage.ErrActiveAuthorizationModel) {
+				// deleted or pinned concurrently
+				continue
+			}
+
+			return deletedModelIDs, serverErrors.HandleError("", err)
+		}
+
+		deletedModelIDs = append(deletedModelIDs, modelID)
+	}
+
+	span.SetAttributes(attribute.Int("deleted_authorization_models", len(deletedModelIDs)))
+
+	return deletedModelIDs, nil
+}
+
+// recordAuthorizationModelUse queues the use of an authorization model by a request to be recorded, at most once per
+// authorizationModelLastUsedRecordInterval, without waiting for it to be written.
+func (s *Server) recordAuthorizationModelUse(storeID, modelID string) {
+	key := fmt.Sprintf("%s/%s", storeID, modelID)
+	if item := s.authorizationModelsLastUsed.Get(key); item != nil && !item.Expired() {
+		return
+	}
+
+	// the use is cached before it's written so that the concurrent requests don't queue it too
+	now := time.Now()
+	s.authorizationModelsLastUsed.Set(key, now, authorizationModelLastUsedRecordInterval)
+
+	select {
+	case s.authorizationModelUses <- authorizationModelUse{storeID: storeID, modelID: modelID, usedAt: now}:
+	default:
+		// the queue is full, a later request records the use
+		s.authorizationModelsLastUsed.Delete(key)
+	}
+}
+
+// runAuthorizationModelUseRecording writes the queued uses of the authorization models, and records that their use
+// is being recorded at every retention interval, until the context is done.
+func (s *Server) runAuthorizationModelUseRecording(ctx context.Context) {
+	ticker := time.NewTicker(s.authorizationModelRetentionInterval)
+	defer ticker.Stop()
+
+	s.writeAuthorizationModelUseRecording(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.writeAuthorizationModelUseRecording(ctx)
+		case use := <-s.authorizationModelUses:
+			if err := s.datastore.WriteAuthorizationModelLastUsed(ctx, use.storeID, use.modelID, use.usedAt); err != nil {
+				if ctx.Err() == nil {
+					s.logger.Warn("failed to record the use of the authorization model",
+						zap.String("store_id", use.storeID),
+						zap.String("authorization_model_id", use.modelID),
+						zap.Error(err))
+				}
+
+				s.authorizationModelsLastUsed.Delete(fmt.Sprintf("%s/%s", use.storeID, use.modelID))
+			}
+		}
+	}
+}
+
+// writeAuthorizationModelUseRecording records that the use of the authorization models is being recorded, and keeps
+// the time since which it has been recorded without interruption, by any server, for the garbage collection.
+func (s *Server) writeAuthorizationModelUseRecording(ctx context.Context) {
+	recordedSince, err := s.datastore.WriteAuthorizationModelUseRecording(ctx, time.Now(), 2*s.authorizationModelRetentionInterval)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Error("failed to record that the use of the authorization models is recorded", zap.Error(err))
+		}
+
+		s.authorizationModelUseRecordedSince.Store(nil)
+		return
+	}
+
+	s.authorizationModelUseRecordedSince.Store(&recordedSince)
+}
+
+// runAuthorizationModelRetention garbage collects the authorization models of all the stores at every retention
+// interval, while the server holds the lease of the garbage collection, until the context is done.
+func (s *Server) runAuthorizationModelRetention(ctx context.Context) {
+	ticker := time.NewTicker(s.authorizationModelRetentionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			leader, err := s.datastore.AcquireAuthorizationModelRetentionLease(ctx, s.authorizationModelRetentionLeaseHolder, 2*s.authorizationModelRetentionInterval)
+			if err != nil {
+				if ctx.Err() == nil {
+					s.logger.Error("failed to acquire the lease of the garbage collection of the authorization models", zap.Error(err))
+				}
+				continue
+			}
+
+			// another server garbage collects the models
+			if !leader {
+				continue
+			}
+
+			s.garbageCollectAllAuthorizationModels(ctx)
+		}
+	}
+}
+
+func (s *Server) garbageCollectAllAuthorizationModels(ctx context.Context) {
+	var continuationToken string
+	for {
+		stores, token, err := s.datastore.ListStores(ctx, storage.NewPaginationOptions(storage.DefaultPageSize, continuationToken))
+		if err != nil {
+			if ctx.Err() == nil {
+				s.logger.Error("failed to list the stores to garbage collect their authorization models", zap.Error(err))
+			}
+			return
+		}
+
+		for _, store := range stores {
+			deletedModelIDs, err := s.GarbageCollectAuthorizationModels(ctx, store.GetId())
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+
+				s.logger.Error("failed to garbage collect the authorization models of the store",
+					zap.String("store_id", store.GetId()),
+					zap.Error(err))
+				continue
+			}
+
+			if len(deletedModelIDs) > 0 {
+				s.logger.Info("garbage collected the authorization models of the store",
+					zap.String("store_id", store.GetId()),
+					zap.Strings("authorization_model_ids", deletedModelIDs))
+			}
+		}
+
+		if len(token) == 0 {
+			return
+		}
+		continuationToken = string(token)
+	}
+}
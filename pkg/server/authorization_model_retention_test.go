@@ -0,0 +1,288 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// writeAuthorizationModelAt writes an authorization model to the datastore as if it had been written at the given
+// time, and returns its ID.
+func writeAuthorizationModelAt(t *testing.T, ds storage.OpenFGADatastore, storeID string, writtenAt time.Time) string {
+	t.Helper()
+
+	model := testutils.MustTransformDSLToProtoWithID(`model
+  schema 1.1
+type user
+type group
+  relations
+    define member: [user]`)
+	model.Id = ulid.MustNew(ulid.Timestamp(writtenAt), ulid.DefaultEntropy()).String()
+
+	err := ds.WriteAuthorizationModel(context.Background(), storeID, model)
+	require.NoError(t, err)
+
+	return model.GetId()
+}
+
+func TestDeleteAuthorizationModel(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+
+	ds := memory.New()
+	s := MustNewServerWithOpts(WithDatastore(ds))
+	t.Cleanup(s.Close)
+
+	now := time.Now()
+	activeModelID := writeAuthorizationModelAt(t, ds, storeID, now.Add(-3*time.Hour))
+	modelID := writeAuthorizationModelAt(t, ds, storeID, now.Add(-2*time.Hour))
+	latestModelID := writeAuthorizationModelAt(t, ds, storeID, now.Add(-1*time.Hour))
+
+	_, err := s.SetActiveAuthorizationModel(ctx, storeID, activeModelID)
+	require.NoError(t, err)
+
+	_, err = s.WriteAssertions(ctx, &openfgav1.WriteAssertionsRequest{
+		StoreId:              storeID,
+		AuthorizationModelId: modelID,
+		Assertions:           []*openfgav1.Assertion{},
+	})
+	require.NoError(t, err)
+
+	t.Run("latest_model_is_not_deleted", func(t *testing.T) {
+		err := s.DeleteAuthorizationModel(ctx, storeID, latestModelID)
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_validation_error), status.Code(err))
+		require.Contains(t, status.Convert(err).Message(), "it is the latest model of the store")
+	})
+
+	t.Run("active_model_is_not_deleted", func(t *testing.T) {
+		err := s.DeleteAuthorizationModel(ctx, storeID, activeModelID)
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_validation_error), status.Code(err))
+		require.Contains(t, status.Convert(err).Message(), "it is the active model of the store")
+	})
+
+	t.Run("deleted", func(t *testing.T) {
+		err := s.DeleteAuthorizationModel(ctx, storeID, modelID)
+		require.NoError(t, err)
+
+		_, err = s.ReadAuthorizationModel(ctx, &openfgav1.ReadAuthorizationModelRequest{
+			StoreId: storeID,
+			Id:      modelID,
+		})
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_authorization_model_not_found), status.Code(err))
+
+		assertions, err := ds.ReadAssertions(ctx, storeID, modelID)
+		require.NoError(t, err)
+		require.Empty(t, assertions)
+
+		err = s.DeleteAuthorizationModel(ctx, storeID, modelID)
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_authorization_model_not_found), status.Code(err))
+	})
+
+	t.Run("invalid_model_id", func(t *testing.T) {
+		err := s.DeleteAuthorizationModel(ctx, storeID, "invalid")
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_authorization_model_not_found), status.Code(err))
+	})
+
+	t.Run("store_without_models", func(t *testing.T) {
+		err := s.DeleteAuthorizationModel(ctx, ulid.Make().String(), modelID)
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_authorization_model_not_found), status.Code(err))
+	})
+}
+
+func TestGarbageCollectAuthorizationModels(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+
+	ds := memory.New()
+	s := MustNewServerWithOpts(
+		WithDatastore(ds),
+		WithAuthorizationModelRetentionKeepLast(1),
+		WithAuthorizationModelRetentionKeepUsedWithin(24*time.Hour),
+	)
+	t.Cleanup(s.Close)
+
+	now := time.Now()
+	activeModelID := writeAuthorizationModelAt(t, ds, storeID, now.Add(-100*time.Hour))
+	recentlyUsedModelID := writeAuthorizationModelAt(t, ds, storeID, now.Add(-90*time.Hour))
+	formerlyUsedModelID := writeAuthorizationModelAt(t, ds, storeID, now.Add(-80*time.Hour))
+	unusedModelID := writeAuthorizationModelAt(t, ds, storeID, now.Add(-70*time.Hour))
+	recentlyWrittenModelID := writeAuthorizationModelAt(t, ds, storeID, now.Add(-1*time.Hour))
+	latestModelID := writeAuthorizationModelAt(t, ds, storeID, now.Add(-30*time.Minute))
+
+	_, err := s.SetActiveAuthorizationModel(ctx, storeID, activeModelID)
+	require.NoError(t, err)
+
+	err = ds.WriteAuthorizationModelLastUsed(ctx, storeID, recentlyUsedModelID, now.Add(-1*time.Hour))
+	require.NoError(t, err)
+
+	err = ds.WriteAuthorizationModelLastUsed(ctx, storeID, formerlyUsedModelID, now.Add(-50*time.Hour))
+	require.NoError(t, err)
+
+	// the use of the models hasn't been recorded for long enough to tell the unused ones
+	recordedSince := now.Add(-1 * time.Hour)
+	s.authorizationModelUseRecordedSince.Store(&recordedSince)
+
+	deletedModelIDs, err := s.GarbageCollectAuthorizationModels(ctx, storeID)
+	require.NoError(t, err)
+	require.Empty(t, deletedModelIDs)
+
+	recordedSince = now.Add(-100 * time.Hour)
+	s.authorizationModelUseRecordedSince.Store(&recordedSince)
+
+	deletedModelIDs, err = s.GarbageCollectAuthorizationModels(ctx, storeID)
+	require.NoError(t, err)
+	require.Equal(t, []string{unusedModelID, formerlyUsedModelID}, deletedModelIDs)
+
+	models, _, err := ds.ReadAuthorizationModels(ctx, storeID, storage.NewPaginationOptions(storage.DefaultPageSize, ""))
+	require.NoError(t, err)
+
+	modelIDs := make([]string, 0, len(models))
+	for _, model := range models {
+		modelIDs = append(modelIDs, model.GetId())
+	}
+	require.Equal(t, []string{latestModelID, recentlyWrittenModelID, recentlyUsedModelID, activeModelID}, modelIDs)
+
+	deletedModelIDs, err = s.GarbageCollectAuthorizationModels(ctx, storeID)
+	require.NoError(t, err)
+	require.Empty(t, deletedModelIDs)
+}
+
+func TestAuthorizationModelRetention(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+
+	t.Run("unused_models_are_garbage_collected", func(t *testing.T) {
+		ds := memory.New()
+
+		store, err := ds.CreateStore(ctx, &openfgav1.Store{Id: ulid.Make().String(), Name: "retention"})
+		require.NoError(t, err)
+		storeID := store.GetId()
+
+		now := time.Now()
+		usedModelID := writeAuthorizationModelAt(t, ds, storeID, now.Add(-50*time.Hour))
+		unusedModelID := writeAuthorizationModelAt(t, ds, storeID, now.Add(-40*time.Hour))
+		writeAuthorizationModelAt(t, ds, storeID, now.Add(-30*time.Hour))
+
+		// the use of the models has been recorded by other servers for long enough
+		_, err = ds.WriteAuthorizationModelUseRecording(ctx, now.Add(-48*time.Hour), time.Hour)
+		require.NoError(t, err)
+		_, err = ds.WriteAuthorizationModelUseRecording(ctx, now, 48*time.Hour)
+		require.NoError(t, err)
+
+		s := MustNewServerWithOpts(
+			WithDatastore(ds),
+			WithAuthorizationModelRetentionEnabled(true),
+			WithAuthorizationModelRetentionKeepLast(1),
+			WithAuthorizationModelRetentionKeepUsedWithin(24*time.Hour),
+			WithAuthorizationModelRetentionInterval(100*time.Millisecond),
+		)
+		t.Cleanup(s.Close)
+
+		// the use of the model by a request is recorded
+		_, err = s.Check(ctx, &openfgav1.CheckRequest{
+			StoreId:              storeID,
+			AuthorizationModelId: usedModelID,
+			TupleKey:             tuple.NewCheckRequestTupleKey("group:1", "member", "user:1"),
+		})
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			lastUsed, err := ds.ReadAuthorizationModelsLastUsed(ctx, storeID)
+			require.NoError(t, err)
+			_, ok := lastUsed[usedModelID]
+			return ok
+		}, 5*time.Second, 10*time.Millisecond)
+
+		require.Eventually(t, func() bool {
+			_, err := ds.ReadAuthorizationModel(ctx, storeID, unusedModelID)
+			return err != nil
+		}, 5*time.Second, 10*time.Millisecond)
+
+		_, err = ds.ReadAuthorizationModel(ctx, storeID, usedModelID)
+		require.NoError(t, err)
+	})
+
+	t.Run("models_are_kept_until_the_use_is_recorded_for_long_enough", func(t *testing.T) {
+		ds := memory.New()
+
+		store, err := ds.CreateStore(ctx, &openfgav1.Store{Id: ulid.Make().String(), Name: "retention"})
+		require.NoError(t, err)
+		storeID := store.GetId()
+
+		now := time.Now()
+		modelID := writeAuthorizationModelAt(t, ds, storeID, now.Add(-40*time.Hour))
+		writeAuthorizationModelAt(t, ds, storeID, now.Add(-30*time.Hour))
+
+		s := MustNewServerWithOpts(
+			WithDatastore(ds),
+			WithAuthorizationModelRetentionEnabled(true),
+			WithAuthorizationModelRetentionKeepLast(1),
+			WithAuthorizationModelRetentionKeepUsedWithin(24*time.Hour),
+			WithAuthorizationModelRetentionInterval(10*time.Millisecond),
+		)
+		t.Cleanup(s.Close)
+
+		time.Sleep(100 * time.Millisecond)
+
+		_, err = ds.ReadAuthorizationModel(ctx, storeID, modelID)
+		require.NoError(t, err)
+	})
+
+	t.Run("models_are_kept_while_another_server_holds_the_lease", func(t *testing.T) {
+		ds := memory.New()
+
+		store, err := ds.CreateStore(ctx, &openfgav1.Store{Id: ulid.Make().String(), Name: "retention"})
+		require.NoError(t, err)
+		storeID := store.GetId()
+
+		now := time.Now()
+		modelID := writeAuthorizationModelAt(t, ds, storeID, now.Add(-40*time.Hour))
+		writeAuthorizationModelAt(t, ds, storeID, now.Add(-30*time.Hour))
+
+		_, err = ds.WriteAuthorizationModelUseRecording(ctx, now.Add(-48*time.Hour), time.Hour)
+		require.NoError(t, err)
+		_, err = ds.WriteAuthorizationModelUseRecording(ctx, now, 48*time.Hour)
+		require.NoError(t, err)
+
+		leader, err := ds.AcquireAuthorizationModelRetentionLease(ctx, ulid.Make().String(), time.Hour)
+		require.NoError(t, err)
+		require.True(t, leader)
+
+		s := MustNewServerWithOpts(
+			WithDatastore(ds),
+			WithAuthorizationModelRetentionEnabled(true),
+			WithAuthorizationModelRetentionKeepLast(1),
+			WithAuthorizationModelRetentionKeepUsedWithin(24*time.Hour),
+			WithAuthorizationModelRetentionInterval(10*time.Millisecond),
+		)
+		t.Cleanup(s.Close)
+
+		time.Sleep(100 * time.Millisecond)
+
+		_, err = ds.ReadAuthorizationModel(ctx, storeID, modelID)
+		require.NoError(t, err)
+	})
+}
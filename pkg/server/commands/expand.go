@@ -3,11 +3,15 @@ package commands
 import (
 	"context"
 	"errors"
+	"maps"
+	"sync/atomic"
 
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 	"golang.org/x/sync/errgroup"
 
+	serverconfig "github.com/openfga/openfga/internal/server/config"
 	"github.com/openfga/openfga/internal/validation"
+	"github.com/openfga/openfga/pkg/encoder"
 	"github.com/openfga/openfga/pkg/logger"
 	serverErrors "github.com/openfga/openfga/pkg/server/errors"
 	"github.com/openfga/openfga/pkg/storage"
@@ -17,8 +21,10 @@ import (
 
 // ExpandQuery resolves a target TupleKey into a UsersetTree by expanding type definitions.
 type ExpandQuery struct {
-	logger    logger.Logger
-	datastore storage.OpenFGADatastore
+	logger                  logger.Logger
+	datastore               storage.OpenFGADatastore
+	encoder                 encoder.Encoder
+	resolveNodeBreadthLimit uint32
 }
 
 type ExpandQueryOption func(*ExpandQuery)
@@ -29,11 +35,29 @@ func WithExpandQueryLogger(l logger.Logger) ExpandQueryOption {
 	}
 }
 
+// WithExpandQueryEncoder sets the encoder of the continuation tokens of paginated Expand queries.
+func WithExpandQueryEncoder(e encoder.Encoder) ExpandQueryOption {
+	return func(eq *ExpandQuery) {
+		eq.encoder = e
+	}
+}
+
+// WithExpandQueryResolveNodeBreadthLimit sets the maximum number of nodes of a level of the tree which are
+// resolved concurrently when expanding computed usersets and tuple to usersets. See server.WithResolveNodeBreadthLimit.
+func WithExpandQueryResolveNodeBreadthLimit(limit uint32) ExpandQueryOption {
+	return func(eq *ExpandQuery) {
+		eq.resolveNodeBreadthLimit = limit
+	}
+}
+
 // NewExpandQuery creates a new ExpandQuery using the supplied backends for retrieving data.
 func NewExpandQuery(datastore storage.OpenFGADatastore, opts ...ExpandQueryOption) *ExpandQuery {
 	eq := &ExpandQuery{
 		datastore: datastore,
 		logger:    logger.NewNoopLogger(),
+		encoder:   encoder.NewBase64Encoder(),
+
+		resolveNodeBreadthLimit: serverconfig.DefaultResolveNodeBreadthLimit,
 	}
 
 	for _, opt := range opts {
@@ -42,15 +66,76 @@ func NewExpandQuery(datastore storage.OpenFGADatastore, opts ...ExpandQueryOptio
 	return eq
 }
 
+// expandBranch is the state of a branch of the tree of an Expand query.
+type expandBranch struct {
+	// depth is the number of levels of computed usersets and tuple to usersets left to expand below the branch.
+	depth uint32
+
+	// visited are the relations of objects (of the form 'object#relation') expanded on the path to the branch.
+	visited map[string]struct{}
+
+	// page is the pagination state of the query, or nil if the leaves are not paginated.
+	page *expandPage
+
+	// pending are the flags of the pages of tuplesets on the path to the branch, which are set when a leaf below
+	// them has more users.
+	pending []*atomic.Bool
+
+	// fresh is whether the leaves of the branch are read from their first page when the continuation token has
+	// no state for them, rather than considered as done.
+	fresh bool
+}
+
+// descend returns the branch below b which expands objectRelation.
+func (b *expandBranch) descend(objectRelation string) *expandBranch {
+	visited := maps.Clone(b.visited)
+	if visited == nil {
+		visited = make(map[string]struct{}, 1)
+	}
+	visited[objectRelation] = struct{}{}
+
+	return &expandBranch{
+		depth:   b.depth - 1,
+		visited: visited,
+		page:    b.page,
+		pending: b.pending,
+		fresh:   b.fresh,
+	}
+}
+
 func (q *ExpandQuery) Execute(ctx context.Context, req *openfgav1.ExpandRequest) (*openfgav1.ExpandResponse, error) {
 	store := req.GetStoreId()
+
+	typesys, userset, err := q.resolveRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	tk := tupleUtils.NewTupleKey(req.GetTupleKey().GetObject(), req.GetTupleKey().GetRelation(), "")
+
+	root, err := q.resolveUserset(ctx, store, userset, tk, typesys, &expandBranch{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &openfgav1.ExpandResponse{
+		Tree: &openfgav1.UsersetTree{
+			Root: root,
+		},
+	}, nil
+}
+
+// resolveRequest validates the request against its authorization model, returning the typesystem of the model and
+// the rewrite of the relation to expand.
+func (q *ExpandQuery) resolveRequest(ctx context.Context, req *openfgav1.ExpandRequest) (*typesystem.TypeSystem, *openfgav1.Userset, error) {
+	store := req.GetStoreId()
 	modelID := req.GetAuthorizationModelId()
 	tupleKey := req.GetTupleKey()
 	object := tupleKey.GetObject()
 	relation := tupleKey.GetRelation()
 
 	if object == "" || relation == "" {
-		return nil, serverErrors.InvalidExpandInput
+		return nil, nil, serverErrors.InvalidExpandInput
 	}
 
 	tk := tupleUtils.NewTupleKey(object, relation, "")
@@ -58,56 +143,45 @@ func (q *ExpandQuery) Execute(ctx context.Context, req *openfgav1.ExpandRequest)
 	model, err := q.datastore.ReadAuthorizationModel(ctx, store, modelID)
 	if err != nil {
 		if errors.Is(err, storage.ErrNotFound) {
-			return nil, serverErrors.AuthorizationModelNotFound(modelID)
+			return nil, nil, serverErrors.AuthorizationModelNotFound(modelID)
 		}
 
-		return nil, serverErrors.HandleError("", err)
+		return nil, nil, serverErrors.HandleError("", err)
 	}
 
 	if !typesystem.IsSchemaVersionSupported(model.GetSchemaVersion()) {
-		return nil, serverErrors.ValidationError(typesystem.ErrInvalidSchemaVersion)
+		return nil, nil, serverErrors.ValidationError(typesystem.ErrInvalidSchemaVersion)
 	}
 
 	typesys, err := typesystem.NewAndValidate(ctx, model)
 	if err != nil {
-		return nil, serverErrors.ValidationError(typesystem.ErrInvalidModel)
+		return nil, nil, serverErrors.ValidationError(typesystem.ErrInvalidModel)
 	}
 
 	if err = validation.ValidateObject(typesys, tk); err != nil {
-		return nil, serverErrors.ValidationError(err)
+		return nil, nil, serverErrors.ValidationError(err)
 	}
 
 	err = validation.ValidateRelation(typesys, tk)
 	if err != nil {
-		return nil, serverErrors.ValidationError(err)
+		return nil, nil, serverErrors.ValidationError(err)
 	}
 
 	objectType := tupleUtils.GetType(object)
 	rel, err := typesys.GetRelation(objectType, relation)
 	if err != nil {
 		if errors.Is(err, typesystem.ErrObjectTypeUndefined) {
-			return nil, serverErrors.TypeNotFound(objectType)
+			return nil, nil, serverErrors.TypeNotFound(objectType)
 		}
 
 		if errors.Is(err, typesystem.ErrRelationUndefined) {
-			return nil, serverErrors.RelationNotFound(relation, objectType, tk)
+			return nil, nil, serverErrors.RelationNotFound(relation, objectType, tk)
 		}
 
-		return nil, serverErrors.HandleError("", err)
+		return nil, nil, serverErrors.HandleError("", err)
 	}
 
-	userset := rel.GetRewrite()
-
-	root, err := q.resolveUserset(ctx, store, userset, tk, typesys)
-	if err != nil {
-		return nil, err
-	}
-
-	return &openfgav1.ExpandResponse{
-		Tree: &openfgav1.UsersetTree{
-			Root: root,
-		},
-	}, nil
+	return typesys, rel.GetRewrite(), nil
 }
 
 func (q *ExpandQuery) resolveUserset(
@@ -116,23 +190,37 @@ func (q *ExpandQuery) resolveUserset(
 	userset *openfgav1.Userset,
 	tk *openfgav1.TupleKey,
 	typesys *typesystem.TypeSystem,
+	branch *expandBranch,
 ) (*openfgav1.UsersetTree_Node, error) {
 	ctx, span := tracer.Start(ctx, "resolveUserset")
 	defer span.End()
 
 	switch us := userset.GetUserset().(type) {
 	case nil, *openfgav1.Userset_This:
+		if branch.page != nil {
+			return q.resolveThisPage(ctx, store, tk, typesys, branch)
+		}
 		return q.resolveThis(ctx, store, tk, typesys)
 	case *openfgav1.Userset_ComputedUserset:
+		if branch.depth > 0 {
+			return q.expandComputedUserset(ctx, store, us.ComputedUserset, tk, typesys, branch)
+		}
 		return q.resolveComputedUserset(ctx, us.ComputedUserset, tk)
 	case *openfgav1.Userset_TupleToUserset:
-		return q.resolveTupleToUserset(ctx, store, us.TupleToUserset, tk, typesys)
+		if branch.page != nil {
+			return q.resolveTupleToUsersetPage(ctx, store, us.TupleToUserset, tk, typesys, branch)
+		}
+		node, err := q.resolveTupleToUserset(ctx, store, us.TupleToUserset, tk, typesys)
+		if err != nil || branch.depth == 0 {
+			return node, err
+		}
+		return q.expandTupleToUserset(ctx, store, node, typesys, branch)
 	case *openfgav1.Userset_Union:
-		return q.resolveUnionUserset(ctx, store, us.Union, tk, typesys)
+		return q.resolveUnionUserset(ctx, store, us.Union, tk, typesys, branch)
 	case *openfgav1.Userset_Difference:
-		return q.resolveDifferenceUserset(ctx, store, us.Difference, tk, typesys)
+		return q.resolveDifferenceUserset(ctx, store, us.Difference, tk, typesys, branch)
 	case *openfgav1.Userset_Intersection:
-		return q.resolveIntersectionUserset(ctx, store, us.Intersection, tk, typesys)
+		return q.resolveIntersectionUserset(ctx, store, us.Intersection, tk, typesys, branch)
 	default:
 		return nil, serverErrors.UnsupportedUserSet
 	}
@@ -203,18 +291,22 @@ func (q *ExpandQuery) resolveComputedUserset(ctx context.Context, userset *openf
 		computed.Relation = tk.GetRelation()
 	}
 
+	return computedLeafNode(toObjectRelation(tk), toObjectRelation(computed)), nil
+}
+
+func computedLeafNode(name string, userset string) *openfgav1.UsersetTree_Node {
 	return &openfgav1.UsersetTree_Node{
-		Name: toObjectRelation(tk),
+		Name: name,
 		Value: &openfgav1.UsersetTree_Node_Leaf{
 			Leaf: &openfgav1.UsersetTree_Leaf{
 				Value: &openfgav1.UsersetTree_Leaf_Computed{
 					Computed: &openfgav1.UsersetTree_Computed{
-						Userset: toObjectRelation(computed),
+						Userset: userset,
 					},
 				},
 			},
 		},
-	}, nil
+	}
 }
 
 // resolveTupleToUserset creates a new leaf node containing the result of expanding a TupleToUserset rewrite.
@@ -228,6 +320,39 @@ func (q *ExpandQuery) resolveTupleToUserset(
 	ctx, span := tracer.Start(ctx, "resolveTupleToUserset")
 	defer span.End()
 
+	tsKey, err := tuplesetKey(userset, tk, typesys)
+	if err != nil {
+		return nil, err
+	}
+
+	tupleIter, err := q.datastore.Read(ctx, store, tsKey)
+	if err != nil {
+		return nil, serverErrors.HandleError("", err)
+	}
+
+	filteredIter := storage.NewFilteredTupleKeyIterator(
+		storage.NewTupleKeyIteratorFromTupleIterator(tupleIter),
+		validation.FilterInvalidTuples(typesys),
+	)
+	defer filteredIter.Stop()
+
+	var tuplesetUsers []string
+	for {
+		tk, err := filteredIter.Next(ctx)
+		if err != nil {
+			if err == storage.ErrIteratorDone {
+				break
+			}
+			return nil, serverErrors.HandleError("", err)
+		}
+		tuplesetUsers = append(tuplesetUsers, tk.GetUser())
+	}
+
+	return tupleToUsersetLeafNode(userset, tk, tsKey, tuplesetUsers), nil
+}
+
+// tuplesetKey returns the key of the tuples of the tupleset of a TupleToUserset rewrite of tk.
+func tuplesetKey(userset *openfgav1.TupleToUserset, tk *openfgav1.TupleKey, typesys *typesystem.TypeSystem) (*openfgav1.TupleKey, error) {
 	targetObject := tk.GetObject()
 
 	tupleset := userset.GetTupleset().GetRelation()
@@ -253,29 +378,20 @@ func (q *ExpandQuery) resolveTupleToUserset(
 		tsKey.Relation = tk.GetRelation()
 	}
 
-	tupleIter, err := q.datastore.Read(ctx, store, tsKey)
-	if err != nil {
-		return nil, serverErrors.HandleError("", err)
-	}
-
-	filteredIter := storage.NewFilteredTupleKeyIterator(
-		storage.NewTupleKeyIteratorFromTupleIterator(tupleIter),
-		validation.FilterInvalidTuples(typesys),
-	)
-	defer filteredIter.Stop()
+	return tsKey, nil
+}
 
+// tupleToUsersetLeafNode creates a leaf node containing the distinct computed usersets of the users of the tuples
+// of the tupleset of a TupleToUserset rewrite.
+func tupleToUsersetLeafNode(
+	userset *openfgav1.TupleToUserset,
+	tk *openfgav1.TupleKey,
+	tsKey *openfgav1.TupleKey,
+	tuplesetUsers []string,
+) *openfgav1.UsersetTree_Node {
 	var computed []*openfgav1.UsersetTree_Computed
 	seen := make(map[string]bool)
-	for {
-		tk, err := filteredIter.Next(ctx)
-		if err != nil {
-			if err == storage.ErrIteratorDone {
-				break
-			}
-			return nil, serverErrors.HandleError("", err)
-		}
-		user := tk.GetUser()
-
+	for _, user := range tuplesetUsers {
 		tObject, tRelation := tupleUtils.SplitObjectRelation(user)
 		// We only proceed in the case that tRelation == userset.GetComputedUserset().GetRelation().
 		// tRelation may be empty, and in this case, we set it to userset.GetComputedUserset().GetRelation().
@@ -307,9 +423,128 @@ func (q *ExpandQuery) resolveTupleToUserset(
 				},
 			},
 		},
+	}
+}
+
+// expandComputedUserset creates an intermediate Usertree node containing the expansion of the relation that a
+// ComputedUserset rewrite refers to, down to depth levels of computed usersets and tuple to usersets.
+func (q *ExpandQuery) expandComputedUserset(
+	ctx context.Context,
+	store string,
+	userset *openfgav1.ObjectRelation,
+	tk *openfgav1.TupleKey,
+	typesys *typesystem.TypeSystem,
+	branch *expandBranch,
+) (*openfgav1.UsersetTree_Node, error) {
+	ctx, span := tracer.Start(ctx, "expandComputedUserset")
+	defer span.End()
+
+	leaf, err := q.resolveComputedUserset(ctx, userset, tk)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes, err := q.expandObjectRelations(ctx, store, []string{leaf.GetLeaf().GetComputed().GetUserset()}, typesys, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	return &openfgav1.UsersetTree_Node{
+		Name: toObjectRelation(tk),
+		Value: &openfgav1.UsersetTree_Node_Union{
+			Union: &openfgav1.UsersetTree_Nodes{
+				Nodes: nodes,
+			},
+		},
+	}, nil
+}
+
+// expandTupleToUserset creates an intermediate Usertree node containing the expansion of each of the computed
+// usersets of a TupleToUserset leaf node, down to depth levels of computed usersets and tuple to usersets.
+func (q *ExpandQuery) expandTupleToUserset(
+	ctx context.Context,
+	store string,
+	node *openfgav1.UsersetTree_Node,
+	typesys *typesystem.TypeSystem,
+	branch *expandBranch,
+) (*openfgav1.UsersetTree_Node, error) {
+	ctx, span := tracer.Start(ctx, "expandTupleToUserset")
+	defer span.End()
+
+	computed := node.GetLeaf().GetTupleToUserset().GetComputed()
+	objectRelations := make([]string, 0, len(computed))
+	for _, c := range computed {
+		objectRelations = append(objectRelations, c.GetUserset())
+	}
+
+	nodes, err := q.expandObjectRelations(ctx, store, objectRelations, typesys, branch)
+	if err != nil {
+		return nil, err
+	}
+
+	return &openfgav1.UsersetTree_Node{
+		Name: node.GetName(),
+		Value: &openfgav1.UsersetTree_Node_Union{
+			Union: &openfgav1.UsersetTree_Nodes{
+				Nodes: nodes,
+			},
+		},
 	}, nil
 }
 
+// expandObjectRelations creates Usertree nodes for the rewrites of multiple relations of objects (of the form
+// 'object#relation') one level below the branch, skipping the relations which aren't defined on the type of their
+// object. The relations which have already been expanded on the path to the branch are cycles, and are not expanded
+// again: they are returned as computed leaves referring to themselves.
+func (q *ExpandQuery) expandObjectRelations(
+	ctx context.Context,
+	store string,
+	objectRelations []string,
+	typesys *typesystem.TypeSystem,
+	branch *expandBranch,
+) ([]*openfgav1.UsersetTree_Node, error) {
+	out := make([]*openfgav1.UsersetTree_Node, len(objectRelations))
+	grp, ctx := errgroup.WithContext(ctx)
+	grp.SetLimit(int(q.resolveNodeBreadthLimit))
+	for i, objectRelation := range objectRelations {
+		i, objectRelation := i, objectRelation
+		grp.Go(func() error {
+			object, relation := tupleUtils.SplitObjectRelation(objectRelation)
+
+			if _, ok := branch.visited[objectRelation]; ok {
+				out[i] = computedLeafNode(objectRelation, objectRelation)
+				return nil
+			}
+
+			rel, err := typesys.GetRelation(tupleUtils.GetType(object), relation)
+			if err != nil {
+				if errors.Is(err, typesystem.ErrObjectTypeUndefined) || errors.Is(err, typesystem.ErrRelationUndefined) {
+					return nil
+				}
+				return serverErrors.HandleError("", err)
+			}
+
+			node, err := q.resolveUserset(ctx, store, rel.GetRewrite(), tupleUtils.NewTupleKey(object, relation, ""), typesys, branch.descend(objectRelation))
+			if err != nil {
+				return err
+			}
+			out[i] = node
+			return nil
+		})
+	}
+	if err := grp.Wait(); err != nil {
+		return nil, err
+	}
+
+	nodes := make([]*openfgav1.UsersetTree_Node, 0, len(out))
+	for _, node := range out {
+		if node != nil {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes, nil
+}
+
 // resolveUnionUserset creates an intermediate Usertree node containing the union of its children.
 func (q *ExpandQuery) resolveUnionUserset(
 	ctx context.Context,
@@ -317,11 +552,12 @@ func (q *ExpandQuery) resolveUnionUserset(
 	usersets *openfgav1.Usersets,
 	tk *openfgav1.TupleKey,
 	typesys *typesystem.TypeSystem,
+	branch *expandBranch,
 ) (*openfgav1.UsersetTree_Node, error) {
 	ctx, span := tracer.Start(ctx, "resolveUnionUserset")
 	defer span.End()
 
-	nodes, err := q.resolveUsersets(ctx, store, usersets.GetChild(), tk, typesys)
+	nodes, err := q.resolveUsersets(ctx, store, usersets.GetChild(), tk, typesys, branch)
 	if err != nil {
 		return nil, err
 	}
@@ -342,11 +578,12 @@ func (q *ExpandQuery) resolveIntersectionUserset(
 	usersets *openfgav1.Usersets,
 	tk *openfgav1.TupleKey,
 	typesys *typesystem.TypeSystem,
+	branch *expandBranch,
 ) (*openfgav1.UsersetTree_Node, error) {
 	ctx, span := tracer.Start(ctx, "resolveIntersectionUserset")
 	defer span.End()
 
-	nodes, err := q.resolveUsersets(ctx, store, usersets.GetChild(), tk, typesys)
+	nodes, err := q.resolveUsersets(ctx, store, usersets.GetChild(), tk, typesys, branch)
 	if err != nil {
 		return nil, err
 	}
@@ -367,11 +604,12 @@ func (q *ExpandQuery) resolveDifferenceUserset(
 	userset *openfgav1.Difference,
 	tk *openfgav1.TupleKey,
 	typesys *typesystem.TypeSystem,
+	branch *expandBranch,
 ) (*openfgav1.UsersetTree_Node, error) {
 	ctx, span := tracer.Start(ctx, "resolveDifferenceUserset")
 	defer span.End()
 
-	nodes, err := q.resolveUsersets(ctx, store, []*openfgav1.Userset{userset.GetBase(), userset.GetSubtract()}, tk, typesys)
+	nodes, err := q.resolveUsersets(ctx, store, []*openfgav1.Userset{userset.GetBase(), userset.GetSubtract()}, tk, typesys, branch)
 	if err != nil {
 		return nil, err
 	}
@@ -395,17 +633,19 @@ func (q *ExpandQuery) resolveUsersets(
 	usersets []*openfgav1.Userset,
 	tk *openfgav1.TupleKey,
 	typesys *typesystem.TypeSystem,
+	branch *expandBranch,
 ) ([]*openfgav1.UsersetTree_Node, error) {
 	ctx, span := tracer.Start(ctx, "resolveUsersets")
 	defer span.End()
 
 	out := make([]*openfgav1.UsersetTree_Node, len(usersets))
 	grp, ctx := errgroup.WithContext(ctx)
+	grp.SetLimit(int(q.resolveNodeBreadthLimit))
 	for i, us := range usersets {
 		// https://golang.org/doc/faq#closures_and_goroutines
 		i, us := i, us
 		grp.Go(func() error {
-			node, err := q.resolveUserset(ctx, store, us, tk, typesys)
+			node, err := q.resolveUserset(ctx, store, us, tk, typesys, branch)
 			if err != nil {
 				return err
 			}
@@ -0,0 +1,355 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/openfga/openfga/internal/validation"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/storage"
+	tupleUtils "github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// expandContinuationToken is the state of a paginated Expand query. It records the request it was issued for,
+// so that it can't be used to resume a different query.
+type expandContinuationToken struct {
+	StoreID              string `json:"store_id"`
+	AuthorizationModelID string `json:"authorization_model_id"`
+	Object               string `json:"object"`
+	Relation             string `json:"relation"`
+	Depth                uint32 `json:"depth"`
+
+	// Leaves are the datastore continuation tokens of the leaves which have more users, keyed by the name of
+	// the leaves (of the form 'object#relation').
+	Leaves map[string][]byte `json:"leaves,omitempty"`
+
+	// Tuplesets are the cursors of the tuplesets of the expanded tuple to usersets which have more tuples, or
+	// leaves below them with more users, keyed by the name of the tuplesets (of the form 'object#relation').
+	Tuplesets map[string]expandTuplesetCursor `json:"tuplesets,omitempty"`
+}
+
+// expandTuplesetCursor is the position of the page of tuples of a tupleset to read in the next page of a
+// paginated Expand query.
+type expandTuplesetCursor struct {
+	// From is the datastore continuation token of the page, or empty for the first page.
+	From []byte `json:"from,omitempty"`
+
+	// Fresh is whether the page has not been read yet, in which case the leaves below it are read from their
+	// first page.
+	Fresh bool `json:"fresh,omitempty"`
+}
+
+// expandPage is the pagination state of the leaves of users and the tuplesets of a paginated Expand query.
+type expandPage struct {
+	pageSize uint32
+
+	// fromLeaves and fromTuplesets are the state of the continuation token to read the page from.
+	fromLeaves    map[string][]byte
+	fromTuplesets map[string]expandTuplesetCursor
+
+	mu            sync.Mutex
+	nextLeaves    map[string][]byte
+	nextTuplesets map[string]expandTuplesetCursor
+}
+
+// setLeaf records the datastore continuation token of the next page of users of a leaf of the branch.
+func (p *expandPage) setLeaf(name string, contToken []byte, branch *expandBranch) {
+	p.mu.Lock()
+	p.nextLeaves[name] = contToken
+	p.mu.Unlock()
+
+	branch.markPending()
+}
+
+// setTupleset records the cursor of the next page of tuples of a tupleset of the branch.
+func (p *expandPage) setTupleset(name string, cursor expandTuplesetCursor, branch *expandBranch) {
+	p.mu.Lock()
+	p.nextTuplesets[name] = cursor
+	p.mu.Unlock()
+
+	branch.markPending()
+}
+
+// markPending flags the pages of the tuplesets on the path to the branch as having leaves with more users.
+func (b *expandBranch) markPending() {
+	for _, pending := range b.pending {
+		pending.Store(true)
+	}
+}
+
+// ExecutePaginated executes the ExpandQuery, expanding the computed usersets and tuple to usersets of the tree
+// recursively down to depth levels (Execute is the same as a depth of zero), and returning up to pageSize users
+// in each leaf of users along with a continuation token to get the next page of users of every leaf, which is empty
+// once all users have been returned. The leaves whose users have all been returned in previous pages are empty in
+// the next pages. If pageSize is zero, the leaves are not paginated.
+//
+// The tuples of the tuplesets of tuple to usersets are paginated as well: a page of a tupleset is expanded again in
+// the next page of the query until all the users of the leaves below it have been returned, and only then the next
+// page of the tupleset is expanded, with the leaves below it read from their first page. The relations of objects
+// which are already expanded on the path to a node are cycles, and are returned as computed leaves instead of being
+// expanded again.
+//
+// The state of the continuation token is keyed by the name of the leaves and tuplesets, so a leaf which appears
+// below a page of a tupleset that was already expanded (e.g. because of tuples written between pages) is returned
+// empty, as are the leaves reached through more than one path once their users have been returned. Start again
+// from the first page to get a consistent view of the tree.
+func (q *ExpandQuery) ExecutePaginated(
+	ctx context.Context,
+	req *openfgav1.ExpandRequest,
+	depth uint32,
+	pageSize uint32,
+	continuationToken string,
+) (*openfgav1.ExpandResponse, string, error) {
+	ctx, span := tracer.Start(ctx, "ExecutePaginated")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("depth", int(depth)), attribute.Int("page_size", int(pageSize)))
+
+	typesys, userset, err := q.resolveRequest(ctx, req)
+	if err != nil {
+		return nil, "", err
+	}
+
+	token, err := q.decodeContinuationToken(req, depth, continuationToken)
+	if err != nil {
+		return nil, "", err
+	}
+
+	tk := tupleUtils.NewTupleKey(req.GetTupleKey().GetObject(), req.GetTupleKey().GetRelation(), "")
+
+	branch := &expandBranch{
+		depth:   depth,
+		visited: map[string]struct{}{toObjectRelation(tk): {}},
+		fresh:   continuationToken == "",
+	}
+
+	if pageSize > 0 {
+		branch.page = &expandPage{
+			pageSize:      pageSize,
+			fromLeaves:    token.Leaves,
+			fromTuplesets: token.Tuplesets,
+			nextLeaves:    make(map[string][]byte),
+			nextTuplesets: make(map[string]expandTuplesetCursor),
+		}
+	} else if continuationToken != "" {
+		return nil, "", serverErrors.InvalidContinuationToken
+	}
+
+	root, err := q.resolveUserset(ctx, req.GetStoreId(), userset, tk, typesys, branch)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var encodedContToken string
+	if page := branch.page; page != nil && (len(page.nextLeaves) > 0 || len(page.nextTuplesets) > 0) {
+		token.Leaves = page.nextLeaves
+		token.Tuplesets = page.nextTuplesets
+
+		encodedContToken, err = q.encodeContinuationToken(token)
+		if err != nil {
+			return nil, "", serverErrors.HandleError("", err)
+		}
+	}
+
+	span.SetAttributes(attribute.Bool("has_more", encodedContToken != ""))
+
+	return &openfgav1.ExpandResponse{
+		Tree: &openfgav1.UsersetTree{
+			Root: root,
+		},
+	}, encodedContToken, nil
+}
+
+// resolveThisPage resolves a DirectUserset into a leaf node containing the next page of users with that relation.
+func (q *ExpandQuery) resolveThisPage(
+	ctx context.Context,
+	store string,
+	tk *openfgav1.TupleKey,
+	typesys *typesystem.TypeSystem,
+	branch *expandBranch,
+) (*openfgav1.UsersetTree_Node, error) {
+	ctx, span := tracer.Start(ctx, "resolveThisPage")
+	defer span.End()
+
+	page := branch.page
+	name := toObjectRelation(tk)
+
+	users := make([]string, 0)
+
+	from, ok := page.fromLeaves[name]
+	if !ok && !branch.fresh {
+		// all users of the leaf have been returned already
+		return usersLeafNode(name, users), nil
+	}
+
+	tuples, contToken, err := q.datastore.ReadPage(ctx, store, tk, storage.PaginationOptions{
+		PageSize: int(page.pageSize),
+		From:     string(from),
+	})
+	if err != nil {
+		return nil, serverErrors.HandleError("", err)
+	}
+
+	filter := validation.FilterInvalidTuples(typesys)
+	for _, t := range tuples {
+		if filter(t.GetKey()) {
+			users = append(users, t.GetKey().GetUser())
+		}
+	}
+
+	if len(contToken) > 0 {
+		page.setLeaf(name, contToken, branch)
+	}
+
+	return usersLeafNode(name, users), nil
+}
+
+// resolveTupleToUsersetPage creates a leaf node containing the computed usersets of a page of tuples of the
+// tupleset of a TupleToUserset rewrite, expanded below the branch if it has depth left. See ExecutePaginated for
+// how the pages of the tupleset and of the leaves below it are interleaved.
+func (q *ExpandQuery) resolveTupleToUsersetPage(
+	ctx context.Context,
+	store string,
+	userset *openfgav1.TupleToUserset,
+	tk *openfgav1.TupleKey,
+	typesys *typesystem.TypeSystem,
+	branch *expandBranch,
+) (*openfgav1.UsersetTree_Node, error) {
+	ctx, span := tracer.Start(ctx, "resolveTupleToUsersetPage")
+	defer span.End()
+
+	tsKey, err := tuplesetKey(userset, tk, typesys)
+	if err != nil {
+		return nil, err
+	}
+
+	page := branch.page
+	name := toObjectRelation(tsKey)
+
+	var tuplesetUsers []string
+	var contToken []byte
+
+	cursor, ok := page.fromTuplesets[name]
+	if ok || branch.fresh {
+		var tuples []*openfgav1.Tuple
+		tuples, contToken, err = q.datastore.ReadPage(ctx, store, tsKey, storage.PaginationOptions{
+			PageSize: int(page.pageSize),
+			From:     string(cursor.From),
+		})
+		if err != nil {
+			return nil, serverErrors.HandleError("", err)
+		}
+
+		filter := validation.FilterInvalidTuples(typesys)
+		for _, t := range tuples {
+			if filter(t.GetKey()) {
+				tuplesetUsers = append(tuplesetUsers, t.GetKey().GetUser())
+			}
+		}
+	}
+	// else all the tuples of the tupleset, and the users of the leaves below them, have been returned already
+
+	node := tupleToUsersetLeafNode(userset, tk, tsKey, tuplesetUsers)
+
+	if branch.depth == 0 {
+		if len(contToken) > 0 {
+			page.setTupleset(name, expandTuplesetCursor{From: contToken, Fresh: true}, branch)
+		}
+		return node, nil
+	}
+
+	pending := &atomic.Bool{}
+	below := &expandBranch{
+		depth:   branch.depth,
+		visited: branch.visited,
+		page:    page,
+		pending: append(slices.Clip(branch.pending), pending),
+		fresh:   branch.fresh || cursor.Fresh,
+	}
+
+	node, err = q.expandTupleToUserset(ctx, store, node, typesys, below)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case pending.Load():
+		// expand the same page of the tupleset again, to return the next users of the leaves below it
+		page.setTupleset(name, expandTuplesetCursor{From: cursor.From}, branch)
+	case len(contToken) > 0:
+		page.setTupleset(name, expandTuplesetCursor{From: contToken, Fresh: true}, branch)
+	}
+
+	return node, nil
+}
+
+func usersLeafNode(name string, users []string) *openfgav1.UsersetTree_Node {
+	return &openfgav1.UsersetTree_Node{
+		Name: name,
+		Value: &openfgav1.UsersetTree_Node_Leaf{
+			Leaf: &openfgav1.UsersetTree_Leaf{
+				Value: &openfgav1.UsersetTree_Leaf_Users{
+					Users: &openfgav1.UsersetTree_Users{
+						Users: users,
+					},
+				},
+			},
+		},
+	}
+}
+
+// decodeContinuationToken decodes the continuation token of a paginated Expand query, or returns a token to
+// start the query from if it is empty.
+func (q *ExpandQuery) decodeContinuationToken(req *openfgav1.ExpandRequest, depth uint32, continuationToken string) (*expandContinuationToken, error) {
+	token := &expandContinuationToken{
+		StoreID:              req.GetStoreId(),
+		AuthorizationModelID: req.GetAuthorizationModelId(),
+		Object:               req.GetTupleKey().GetObject(),
+		Relation:             req.GetTupleKey().GetRelation(),
+		Depth:                depth,
+	}
+
+	if continuationToken == "" {
+		return token, nil
+	}
+
+	decoded, err := q.encoder.Decode(continuationToken)
+	if err != nil {
+		return nil, serverErrors.InvalidContinuationToken
+	}
+
+	var decodedToken expandContinuationToken
+	if err := json.Unmarshal(decoded, &decodedToken); err != nil {
+		return nil, serverErrors.InvalidContinuationToken
+	}
+
+	if (len(decodedToken.Leaves) == 0 && len(decodedToken.Tuplesets) == 0) ||
+		decodedToken.StoreID != token.StoreID ||
+		decodedToken.AuthorizationModelID != token.AuthorizationModelID ||
+		decodedToken.Object != token.Object ||
+		decodedToken.Relation != token.Relation ||
+		decodedToken.Depth != token.Depth {
+		return nil, serverErrors.InvalidContinuationToken
+	}
+
+	token.Leaves = decodedToken.Leaves
+	token.Tuplesets = decodedToken.Tuplesets
+
+	return token, nil
+}
+
+func (q *ExpandQuery) encodeContinuationToken(token *expandContinuationToken) (string, error) {
+	marshalled, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+
+	return q.encoder.Encode(marshalled)
+}
@@ -0,0 +1,304 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	storagetest "github.com/openfga/openfga/pkg/storage/test"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// leafUsers returns the users of the leaves of users of a tree, keyed by the name of the leaves.
+func leafUsers(node *openfgav1.UsersetTree_Node, users map[string][]string) {
+	switch n := node.GetValue().(type) {
+	case *openfgav1.UsersetTree_Node_Leaf:
+		if leaf, ok := n.Leaf.GetValue().(*openfgav1.UsersetTree_Leaf_Users); ok {
+			users[node.GetName()] = append(users[node.GetName()], leaf.Users.GetUsers()...)
+		}
+	case *openfgav1.UsersetTree_Node_Union:
+		for _, child := range n.Union.GetNodes() {
+			leafUsers(child, users)
+		}
+	case *openfgav1.UsersetTree_Node_Intersection:
+		for _, child := range n.Intersection.GetNodes() {
+			leafUsers(child, users)
+		}
+	case *openfgav1.UsersetTree_Node_Difference:
+		leafUsers(n.Difference.GetBase(), users)
+		leafUsers(n.Difference.GetSubtract(), users)
+	}
+}
+
+func TestExpandExecutePaginated(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	tuples := []string{
+		"document:1#parent@folder:x",
+		"folder:x#editor@user:anne",
+		"folder:x#editor@user:bob",
+	}
+	var viewers []string
+	for i := 0; i < 7; i++ {
+		viewer := fmt.Sprintf("user:%d", i)
+		tuples = append(tuples, "document:1#viewer@"+viewer)
+		viewers = append(viewers, viewer)
+	}
+
+	storeID, model := storagetest.BootstrapFGAStore(t, ds, `
+		model
+			schema 1.1
+
+		type user
+
+		type folder
+			relations
+				define editor: [user]
+
+		type document
+			relations
+				define parent: [folder]
+				define editor: editor from parent
+				define viewer: [user] or editor`, tuples)
+
+	ctx := context.Background()
+
+	req := &openfgav1.ExpandRequest{
+		StoreId:              storeID,
+		AuthorizationModelId: model.GetId(),
+		TupleKey:             tuple.NewExpandRequestTupleKey("document:1", "viewer"),
+	}
+
+	q := NewExpandQuery(ds)
+
+	t.Run("depth_zero_is_execute", func(t *testing.T) {
+		expected, err := q.Execute(ctx, req)
+		require.NoError(t, err)
+
+		resp, contToken, err := q.ExecutePaginated(ctx, req, 0, 0, "")
+		require.NoError(t, err)
+		require.Empty(t, contToken)
+
+		expectedUsers := make(map[string][]string)
+		leafUsers(expected.GetTree().GetRoot(), expectedUsers)
+		users := make(map[string][]string)
+		leafUsers(resp.GetTree().GetRoot(), users)
+		require.Equal(t, len(expectedUsers), len(users))
+		require.ElementsMatch(t, expectedUsers["document:1#viewer"], users["document:1#viewer"])
+
+		computed := resp.GetTree().GetRoot().GetUnion().GetNodes()[1].GetLeaf().GetComputed()
+		require.Equal(t, "document:1#editor", computed.GetUserset())
+	})
+
+	t.Run("depth_expands_computed_usersets_and_tuple_to_usersets", func(t *testing.T) {
+		resp, contToken, err := q.ExecutePaginated(ctx, req, 1, 0, "")
+		require.NoError(t, err)
+		require.Empty(t, contToken)
+
+		users := make(map[string][]string)
+		leafUsers(resp.GetTree().GetRoot(), users)
+		require.NotContains(t, users, "folder:x#editor")
+
+		resp, _, err = q.ExecutePaginated(ctx, req, 2, 0, "")
+		require.NoError(t, err)
+
+		users = make(map[string][]string)
+		leafUsers(resp.GetTree().GetRoot(), users)
+		require.ElementsMatch(t, viewers, users["document:1#viewer"])
+		require.ElementsMatch(t, []string{"user:anne", "user:bob"}, users["folder:x#editor"])
+	})
+
+	t.Run("pagination", func(t *testing.T) {
+		users := make(map[string][]string)
+
+		var contToken string
+		pages := 0
+		for {
+			resp, nextContToken, err := q.ExecutePaginated(ctx, req, 2, 3, contToken)
+			require.NoError(t, err)
+			pages++
+
+			pageUsers := make(map[string][]string)
+			leafUsers(resp.GetTree().GetRoot(), pageUsers)
+			for name, u := range pageUsers {
+				require.LessOrEqual(t, len(u), 3)
+				users[name] = append(users[name], u...)
+			}
+
+			if nextContToken == "" {
+				break
+			}
+			contToken = nextContToken
+		}
+
+		require.Equal(t, 3, pages)
+		require.ElementsMatch(t, viewers, users["document:1#viewer"])
+		require.ElementsMatch(t, []string{"user:anne", "user:bob"}, users["folder:x#editor"])
+	})
+
+	t.Run("invalid_continuation_token", func(t *testing.T) {
+		_, contToken, err := q.ExecutePaginated(ctx, req, 2, 3, "")
+		require.NoError(t, err)
+		require.NotEmpty(t, contToken)
+
+		_, _, err = q.ExecutePaginated(ctx, req, 1, 3, contToken)
+		require.ErrorIs(t, err, serverErrors.InvalidContinuationToken)
+
+		_, _, err = q.ExecutePaginated(ctx, &openfgav1.ExpandRequest{
+			StoreId:              storeID,
+			AuthorizationModelId: model.GetId(),
+			TupleKey:             tuple.NewExpandRequestTupleKey("document:1", "editor"),
+		}, 2, 3, contToken)
+		require.ErrorIs(t, err, serverErrors.InvalidContinuationToken)
+
+		_, _, err = q.ExecutePaginated(ctx, req, 2, 0, contToken)
+		require.ErrorIs(t, err, serverErrors.InvalidContinuationToken)
+
+		_, _, err = q.ExecutePaginated(ctx, req, 2, 3, "invalid")
+		require.ErrorIs(t, err, serverErrors.InvalidContinuationToken)
+	})
+}
+
+func TestExpandExecutePaginatedTupleToUsersetPages(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	var tuples []string
+	expected := make(map[string][]string)
+	for i := 0; i < 5; i++ {
+		folder := fmt.Sprintf("folder:%d", i)
+		tuples = append(tuples, "document:1#parent@"+folder)
+		for j := 0; j < 3; j++ {
+			editor := fmt.Sprintf("user:%d-%d", i, j)
+			tuples = append(tuples, folder+"#editor@"+editor)
+			expected[folder+"#editor"] = append(expected[folder+"#editor"], editor)
+		}
+	}
+
+	storeID, model := storagetest.BootstrapFGAStore(t, ds, `
+		model
+			schema 1.1
+
+		type user
+
+		type folder
+			relations
+				define editor: [user]
+
+		type document
+			relations
+				define parent: [folder]
+				define editor: editor from parent`, tuples)
+
+	ctx := context.Background()
+
+	req := &openfgav1.ExpandRequest{
+		StoreId:              storeID,
+		AuthorizationModelId: model.GetId(),
+		TupleKey:             tuple.NewExpandRequestTupleKey("document:1", "editor"),
+	}
+
+	q := NewExpandQuery(ds)
+
+	users := make(map[string][]string)
+
+	var contToken string
+	pages := 0
+	for {
+		resp, nextContToken, err := q.ExecutePaginated(ctx, req, 1, 2, contToken)
+		require.NoError(t, err)
+		pages++
+		require.Less(t, pages, 20)
+
+		computed := resp.GetTree().GetRoot().GetUnion().GetNodes()
+		require.LessOrEqual(t, len(computed), 2)
+
+		pageUsers := make(map[string][]string)
+		leafUsers(resp.GetTree().GetRoot(), pageUsers)
+		for name, u := range pageUsers {
+			require.LessOrEqual(t, len(u), 2)
+			users[name] = append(users[name], u...)
+		}
+
+		if nextContToken == "" {
+			break
+		}
+		contToken = nextContToken
+	}
+
+	require.Len(t, users, len(expected))
+	for name, editors := range expected {
+		require.ElementsMatch(t, editors, users[name], name)
+	}
+}
+
+func TestExpandExecutePaginatedCycles(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID, model := storagetest.BootstrapFGAStore(t, ds, `
+		model
+			schema 1.1
+
+		type user
+
+		type folder
+			relations
+				define parent: [folder]
+				define viewer: [user] or viewer from parent`, []string{
+		"folder:a#parent@folder:b",
+		"folder:b#parent@folder:a",
+		"folder:a#viewer@user:anne",
+		"folder:b#viewer@user:bob",
+	})
+
+	ctx := context.Background()
+
+	req := &openfgav1.ExpandRequest{
+		StoreId:              storeID,
+		AuthorizationModelId: model.GetId(),
+		TupleKey:             tuple.NewExpandRequestTupleKey("folder:a", "viewer"),
+	}
+
+	q := NewExpandQuery(ds, WithExpandQueryResolveNodeBreadthLimit(1))
+
+	for _, pageSize := range []uint32{0, 1} {
+		t.Run(fmt.Sprintf("page_size_%d", pageSize), func(t *testing.T) {
+			resp, contToken, err := q.ExecutePaginated(ctx, req, 10, pageSize, "")
+			require.NoError(t, err)
+			require.Empty(t, contToken)
+
+			users := make(map[string][]string)
+			leafUsers(resp.GetTree().GetRoot(), users)
+			require.Equal(t, map[string][]string{
+				"folder:a#viewer": {"user:anne"},
+				"folder:b#viewer": {"user:bob"},
+			}, users)
+
+			// folder:a#viewer -> viewer from parent -> folder:b#viewer -> viewer from parent -> folder:a#viewer
+			cycle := resp.GetTree().GetRoot().GetUnion().GetNodes()[1].GetUnion().GetNodes()[0].
+				GetUnion().GetNodes()[1].GetUnion().GetNodes()[0]
+			require.Equal(t, "folder:a#viewer", cycle.GetName())
+			require.Equal(t, "folder:a#viewer", cycle.GetLeaf().GetComputed().GetUserset())
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -22,6 +23,7 @@ import (
 	"github.com/openfga/openfga/internal/throttler"
 	"github.com/openfga/openfga/internal/throttler/threshold"
 	"github.com/openfga/openfga/internal/validation"
+	"github.com/openfga/openfga/pkg/encoder"
 	"github.com/openfga/openfga/pkg/logger"
 	"github.com/openfga/openfga/pkg/server/commands/reverseexpand"
 	serverErrors "github.com/openfga/openfga/pkg/server/errors"
@@ -55,6 +57,11 @@ type ListObjectsQuery struct {
 	resolveNodeLimit        uint32
 	resolveNodeBreadthLimit uint32
 	maxConcurrentReads      uint32
+	encoder                 encoder.Encoder
+	frontierCache           *ListObjectsFrontierCache
+	filterCheckThreshold    uint32
+	streamedDeduplication   bool
+	streamedSorting         bool
 
 	dispatchThrottlerConfig threshold.Config
 
@@ -70,19 +77,32 @@ type ListObjectsResolutionMetadata struct {
 
 	// WasThrottled indicates whether the request was throttled
 	WasThrottled *atomic.Bool
+
+	// WasDeadlineExceeded indicates whether the deadline was hit before all objects were found
+	WasDeadlineExceeded *atomic.Bool
+
+	// WasMaxResultsReached indicates whether the maximum number of results was reached while there
+	// were possibly more objects to be found
+	WasMaxResultsReached *atomic.Bool
 }
 
 func NewListObjectsResolutionMetadata() *ListObjectsResolutionMetadata {
 	return &ListObjectsResolutionMetadata{
-		DatastoreQueryCount: new(uint32),
-		DispatchCounter:     new(atomic.Uint32),
-		WasThrottled:        new(atomic.Bool),
+		DatastoreQueryCount:  new(uint32),
+		DispatchCounter:      new(atomic.Uint32),
+		WasThrottled:         new(atomic.Bool),
+		WasDeadlineExceeded:  new(atomic.Bool),
+		WasMaxResultsReached: new(atomic.Bool),
 	}
 }
 
 type ListObjectsResponse struct {
 	Objects            []string
 	ResolutionMetadata ListObjectsResolutionMetadata
+
+	// ContinuationToken is the token to resume from to get the next page of objects (see ExecutePaginated).
+	// It is empty once all objects have been returned.
+	ContinuationToken string
 }
 
 type ListObjectsQueryOption func(d *ListObjectsQuery)
@@ -132,6 +152,36 @@ func WithMaxConcurrentReads(limit uint32) ListObjectsQueryOption {
 	}
 }
 
+// WithListObjectsEncoder sets the encoder of the continuation tokens of paginated ListObjects queries.
+func WithListObjectsEncoder(e encoder.Encoder) ListObjectsQueryOption {
+	return func(d *ListObjectsQuery) {
+		d.encoder = e
+	}
+}
+
+// WithListObjectsFrontierCache sets the cache of the reverse expansion frontiers of paginated ListObjects queries,
+// without which every page but the first is resumed by replaying the expansion up to the position of its
+// continuation token. The cache is not owned by the query and must be stopped separately.
+func WithListObjectsFrontierCache(cache *ListObjectsFrontierCache) ListObjectsQueryOption {
+	return func(d *ListObjectsQuery) {
+		d.frontierCache = cache
+	}
+}
+
+// WithStreamedDeduplication see server.WithStreamedListObjectsDeduplication.
+func WithStreamedDeduplication(enabled bool) ListObjectsQueryOption {
+	return func(d *ListObjectsQuery) {
+		d.streamedDeduplication = enabled
+	}
+}
+
+// WithStreamedSorting see server.WithStreamedListObjectsSorting.
+func WithStreamedSorting(enabled bool) ListObjectsQueryOption {
+	return func(d *ListObjectsQuery) {
+		d.streamedSorting = enabled
+	}
+}
+
 func NewListObjectsQuery(
 	ds storage.RelationshipTupleReader,
 	checkResolver graph.CheckResolver,
@@ -153,6 +203,8 @@ func NewListObjectsQuery(
 		resolveNodeLimit:        serverconfig.DefaultResolveNodeLimit,
 		resolveNodeBreadthLimit: serverconfig.DefaultResolveNodeBreadthLimit,
 		maxConcurrentReads:      serverconfig.DefaultMaxConcurrentReadsForListObjects,
+		encoder:                 encoder.NewBase64Encoder(),
+		filterCheckThreshold:    DefaultFilterCheckThreshold,
 		dispatchThrottlerConfig: threshold.Config{
 			Throttler:    throttler.NewNoopThrottler(),
 			Enabled:      serverconfig.DefaultListObjectsDispatchThrottlingEnabled,
@@ -189,29 +241,8 @@ type listObjectsRequest interface {
 	GetContext() *structpb.Struct
 }
 
-// evaluate fires of evaluation of the ListObjects query by delegating to
-// [[reverseexpand.ReverseExpand#Execute]] and resolving the results yielded
-// from it. If any results yielded by reverse expansion require further eval,
-// then these results get dispatched to Check to resolve the residual outcome.
-//
-// The resultsChan is **always** closed by evaluate when it is done with its work,
-// which is either when all results have been yielded, the deadline has been met,
-// or some other terminal error case has occurred.
-func (q *ListObjectsQuery) evaluate(
-	ctx context.Context,
-	req listObjectsRequest,
-	resultsChan chan<- ListObjectsResult,
-	maxResults uint32,
-	resolutionMetadata *ListObjectsResolutionMetadata,
-) error {
-	targetObjectType := req.GetType()
-	targetRelation := req.GetRelation()
-
-	typesys, ok := typesystem.TypesystemFromContext(ctx)
-	if !ok {
-		panic("typesystem missing in context")
-	}
-
+// validateListObjectsRequest validates the ListObjects request against the authorization model.
+func validateListObjectsRequest(typesys *typesystem.TypeSystem, req listObjectsRequest) error {
 	if !typesystem.IsSchemaVersionSupported(typesys.GetSchemaVersion()) {
 		return serverErrors.ValidationError(typesystem.ErrInvalidSchemaVersion)
 	}
@@ -222,14 +253,14 @@ func (q *ListObjectsQuery) evaluate(
 		}
 	}
 
-	_, err := typesys.GetRelation(targetObjectType, targetRelation)
+	_, err := typesys.GetRelation(req.GetType(), req.GetRelation())
 	if err != nil {
 		if errors.Is(err, typesystem.ErrObjectTypeUndefined) {
-			return serverErrors.TypeNotFound(targetObjectType)
+			return serverErrors.TypeNotFound(req.GetType())
 		}
 
 		if errors.Is(err, typesystem.ErrRelationUndefined) {
-			return serverErrors.RelationNotFound(targetRelation, targetObjectType, nil)
+			return serverErrors.RelationNotFound(req.GetRelation(), req.GetType(), nil)
 		}
 
 		return serverErrors.HandleError("", err)
@@ -239,30 +270,70 @@ func (q *ListObjectsQuery) evaluate(
 		return serverErrors.ValidationError(fmt.Errorf("invalid 'user' value: %s", err))
 	}
 
-	handler := func() {
-		userObj, userRel := tuple.SplitObjectRelation(req.GetUser())
-		userObjType, userObjID := tuple.SplitObject(userObj)
-
-		var sourceUserRef reverseexpand.IsUserRef
-		sourceUserRef = &reverseexpand.UserRefObject{
-			Object: &openfgav1.Object{
-				Type: userObjType,
-				Id:   userObjID,
+	return nil
+}
+
+// sourceUserRefFor returns the reverse expansion reference of the user of a ListObjects request,
+// e.g. 'user:bob', 'user:*' or 'group:eng#member'.
+func sourceUserRefFor(user string) reverseexpand.IsUserRef {
+	userObj, userRel := tuple.SplitObjectRelation(user)
+	userObjType, userObjID := tuple.SplitObject(userObj)
+
+	if userRel != "" {
+		return &reverseexpand.UserRefObjectRelation{
+			ObjectRelation: &openfgav1.ObjectRelation{
+				Object:   userObj,
+				Relation: userRel,
 			},
 		}
+	}
 
-		if tuple.IsTypedWildcard(userObj) {
-			sourceUserRef = &reverseexpand.UserRefTypedWildcard{Type: tuple.GetType(userObj)}
-		}
+	if tuple.IsTypedWildcard(userObj) {
+		return &reverseexpand.UserRefTypedWildcard{Type: userObjType}
+	}
 
-		if userRel != "" {
-			sourceUserRef = &reverseexpand.UserRefObjectRelation{
-				ObjectRelation: &openfgav1.ObjectRelation{
-					Object:   userObj,
-					Relation: userRel,
-				},
-			}
-		}
+	return &reverseexpand.UserRefObject{
+		Object: &openfgav1.Object{
+			Type: userObjType,
+			Id:   userObjID,
+		},
+	}
+}
+
+// evaluate fires of evaluation of the ListObjects query by delegating to
+// [[reverseexpand.ReverseExpand#Execute]] and resolving the results yielded
+// from it. If any results yielded by reverse expansion require further eval,
+// then these results get dispatched to Check to resolve the residual outcome.
+//
+// The user of the request may also be a userset (e.g. 'team:a#member') or a typed wildcard (e.g. 'user:*'),
+// in which case the objects are those the whole set of users has the relation with. Results behind an
+// intersection or exclusion are then checked for that same set of users, which Check resolves as set
+// containment, so e.g. an object is excluded for 'team:a#member' only if the whole team is excluded.
+//
+// The resultsChan is **always** closed by evaluate when it is done with its work,
+// which is either when all results have been yielded, the deadline has been met,
+// or some other terminal error case has occurred.
+func (q *ListObjectsQuery) evaluate(
+	ctx context.Context,
+	req listObjectsRequest,
+	resultsChan chan<- ListObjectsResult,
+	maxResults uint32,
+	resolutionMetadata *ListObjectsResolutionMetadata,
+) error {
+	targetObjectType := req.GetType()
+	targetRelation := req.GetRelation()
+
+	typesys, ok := typesystem.TypesystemFromContext(ctx)
+	if !ok {
+		panic("typesystem missing in context")
+	}
+
+	if err := validateListObjectsRequest(typesys, req); err != nil {
+		return err
+	}
+
+	handler := func() {
+		sourceUserRef := sourceUserRefFor(req.GetUser())
 
 		reverseExpandResultsChan := make(chan *reverseexpand.ReverseExpandResult, 1)
 		objectsFound := atomic.Uint32{}
@@ -325,12 +396,13 @@ func (q *ListObjectsQuery) evaluate(
 				}
 
 				if !(maxResults == 0) && objectsFound.Load() >= maxResults {
+					resolutionMetadata.WasMaxResultsReached.Store(true)
 					break ConsumerReadLoop
 				}
 
 				if res.ResultStatus == reverseexpand.NoFurtherEvalStatus {
 					noFurtherEvalRequiredCounter.Inc()
-					trySendObject(res.Object, &objectsFound, maxResults, resultsChan)
+					trySendObject(res.Object, &objectsFound, maxResults, resultsChan, resolutionMetadata)
 					continue
 				}
 
@@ -345,6 +417,7 @@ func (q *ListObjectsQuery) evaluate(
 
 					concurrencyLimiterCh <- struct{}{}
 					checkRequestMetadata := graph.NewCheckRequestMetadata(q.resolveNodeLimit)
+					checkRequestMetadata.ResolveNodeBreadthLimit = q.resolveNodeBreadthLimit
 
 					resp, err := q.checkResolver.ResolveCheck(ctx, &graph.ResolveCheckRequest{
 						StoreID:              req.GetStoreId(),
@@ -368,7 +441,7 @@ func (q *ListObjectsQuery) evaluate(
 					resolutionMetadata.WasThrottled.Store(reverseExpandResolutionMetadata.WasThrottled.Load())
 
 					if resp.Allowed {
-						trySendObject(res.Object, &objectsFound, maxResults, resultsChan)
+						trySendObject(res.Object, &objectsFound, maxResults, resultsChan, resolutionMetadata)
 					}
 				}(res)
 
@@ -392,9 +465,10 @@ func (q *ListObjectsQuery) evaluate(
 	return nil
 }
 
-func trySendObject(object string, objectsFound *atomic.Uint32, maxResults uint32, resultsChan chan<- ListObjectsResult) {
+func trySendObject(object string, objectsFound *atomic.Uint32, maxResults uint32, resultsChan chan<- ListObjectsResult, resolutionMetadata *ListObjectsResolutionMetadata) {
 	if !(maxResults == 0) {
 		if objectsFound.Add(1) > maxResults {
+			resolutionMetadata.WasMaxResultsReached.Store(true)
 			return
 		}
 	}
@@ -402,7 +476,8 @@ func trySendObject(object string, objectsFound *atomic.Uint32, maxResults uint32
 }
 
 // Execute the ListObjectsQuery, returning a list of object IDs up to a maximum of q.listObjectsMaxResults
-// or until q.listObjectsDeadline is hit, whichever happens first.
+// or until q.listObjectsDeadline is hit, whichever happens first. The user of the request may be an object
+// (e.g. 'user:jon'), a userset (e.g. 'team:a#member') or a typed wildcard (e.g. 'user:*'), see evaluate.
 func (q *ListObjectsQuery) Execute(
 	ctx context.Context,
 	req *openfgav1.ListObjectsRequest,
@@ -452,6 +527,8 @@ func (q *ListObjectsQuery) Execute(
 		objects = append(objects, result.ObjectID)
 	}
 
+	recordDeadlineExceeded(ctx, timeoutCtx, resolutionMetadata)
+
 	if len(objects) < int(maxResults) && errs != nil {
 		return nil, errs
 	}
@@ -465,8 +542,16 @@ func (q *ListObjectsQuery) Execute(
 // ExecuteStreamed executes the ListObjectsQuery, returning a stream of object IDs.
 // It ignores the value of q.listObjectsMaxResults and returns all available results
 // until q.listObjectsDeadline is hit.
+//
+// With WithStreamedDeduplication, each object is streamed at most once. With WithStreamedSorting, the objects
+// are instead buffered until all of them have been found, up to q.listObjectsMaxResults or until the deadline
+// is hit, and then streamed once each in lexicographical order.
 func (q *ListObjectsQuery) ExecuteStreamed(ctx context.Context, req *openfgav1.StreamedListObjectsRequest, srv openfgav1.OpenFGAService_StreamedListObjectsServer) (*ListObjectsResolutionMetadata, error) {
 	maxResults := uint32(math.MaxUint32)
+	if q.streamedSorting && q.listObjectsMaxResults > 0 {
+		maxResults = q.listObjectsMaxResults
+	}
+
 	// make a buffered channel so that writer goroutines aren't blocked when attempting to send a result
 	resultsChan := make(chan ListObjectsResult, streamedBufferSize)
 
@@ -484,6 +569,13 @@ func (q *ListObjectsQuery) ExecuteStreamed(ctx context.Context, req *openfgav1.S
 		return nil, err
 	}
 
+	var seen map[string]struct{}
+	if q.streamedDeduplication {
+		seen = map[string]struct{}{}
+	}
+
+	var buffered []string
+
 	for result := range resultsChan {
 		if result.Err != nil {
 			if errors.Is(result.Err, serverErrors.AuthorizationModelResolutionTooComplex) {
@@ -497,6 +589,18 @@ func (q *ListObjectsQuery) ExecuteStreamed(ctx context.Context, req *openfgav1.S
 			return nil, serverErrors.HandleError("", result.Err)
 		}
 
+		if q.streamedSorting {
+			buffered = append(buffered, result.ObjectID)
+			continue
+		}
+
+		if seen != nil {
+			if _, ok := seen[result.ObjectID]; ok {
+				continue
+			}
+			seen[result.ObjectID] = struct{}{}
+		}
+
 		if err := srv.Send(&openfgav1.StreamedListObjectsResponse{
 			Object: result.ObjectID,
 		}); err != nil {
@@ -504,5 +608,26 @@ func (q *ListObjectsQuery) ExecuteStreamed(ctx context.Context, req *openfgav1.S
 		}
 	}
 
+	recordDeadlineExceeded(ctx, timeoutCtx, resolutionMetadata)
+
+	if q.streamedSorting {
+		slices.Sort(buffered)
+		for _, object := range slices.Compact(buffered) {
+			if err := srv.Send(&openfgav1.StreamedListObjectsResponse{
+				Object: object,
+			}); err != nil {
+				return nil, serverErrors.HandleError("", err)
+			}
+		}
+	}
+
 	return resolutionMetadata, nil
 }
+
+// recordDeadlineExceeded records whether the deadline of the query (as opposed to the context
+// of the request) was hit before all objects were found.
+func recordDeadlineExceeded(ctx, timeoutCtx context.Context, resolutionMetadata *ListObjectsResolutionMetadata) {
+	if errors.Is(timeoutCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+		resolutionMetadata.WasDeadlineExceeded.Store(true)
+	}
+}
@@ -0,0 +1,352 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/sourcegraph/conc/pool"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/openfga/openfga/internal/condition"
+	"github.com/openfga/openfga/internal/graph"
+	serverconfig "github.com/openfga/openfga/internal/server/config"
+	"github.com/openfga/openfga/pkg/server/commands/reverseexpand"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/storagewrappers"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// DefaultFilterCheckThreshold is the number of candidate objects up to which ExecuteFiltered checks each of
+// the candidates, rather than reverse expanding the user of the request.
+const DefaultFilterCheckThreshold = 100
+
+// maxCachedChecksPerFilterQuery is the number of Check sub-problems that the candidates of an ExecuteFiltered query
+// share.
+const maxCachedChecksPerFilterQuery = 10_000
+
+// WithFilterCheckThreshold sets the number of candidate objects up to which ExecuteFiltered checks each of
+// the candidates, rather than reverse expanding the user of the request.
+func WithFilterCheckThreshold(threshold uint32) ListObjectsQueryOption {
+	return func(d *ListObjectsQuery) {
+		d.filterCheckThreshold = threshold
+	}
+}
+
+// ExecuteFiltered executes the ListObjectsQuery restricted to the provided candidate objects (e.g. 'document:1'),
+// returning the candidates that the user of the request has the relation with, in the order they were provided.
+//
+// Up to q.filterCheckThreshold candidates, each candidate is checked. Beyond that, the user of the request is
+// reverse expanded until all candidates have been found or the expansion completes, and only the candidates it yields
+// are checked if needed. In both cases, the candidates are checked through the Check resolver of the query, so that
+// their Checks are throttled and cached as other Checks are, and their sub-problems (e.g. the members of a group the
+// candidates are shared with) are cached for the whole batch (up to maxCachedChecksPerFilterQuery of them).
+//
+// At most q.listObjectsMaxResults candidates may be provided, if non-zero. As with Execute, the candidates found to be
+// allowed until q.listObjectsDeadline is hit are returned.
+func (q *ListObjectsQuery) ExecuteFiltered(
+	ctx context.Context,
+	req *openfgav1.ListObjectsRequest,
+	candidates []string,
+) (*ListObjectsResponse, error) {
+	ctx, span := tracer.Start(ctx, "ExecuteFiltered")
+	defer span.End()
+
+	typesys, ok := typesystem.TypesystemFromContext(ctx)
+	if !ok {
+		panic("typesystem missing in context")
+	}
+
+	if err := validateListObjectsRequest(typesys, req); err != nil {
+		return nil, err
+	}
+
+	candidateSet := make(map[string]struct{}, len(candidates))
+	uniqueCandidates := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		objectType, objectID := tuple.SplitObject(candidate)
+		if objectType != req.GetType() || objectID == "" || tuple.IsTypedWildcard(candidate) {
+			return nil, serverErrors.ValidationError(fmt.Errorf("invalid candidate object '%s': must be an object of type '%s'", candidate, req.GetType()))
+		}
+
+		if _, ok := candidateSet[candidate]; ok {
+			continue
+		}
+
+		candidateSet[candidate] = struct{}{}
+		uniqueCandidates = append(uniqueCandidates, candidate)
+	}
+
+	if q.listObjectsMaxResults != 0 && uint32(len(uniqueCandidates)) > q.listObjectsMaxResults {
+		return nil, serverErrors.ValidationError(fmt.Errorf("at most %d candidate objects may be provided", q.listObjectsMaxResults))
+	}
+
+	timeoutCtx := ctx
+	if q.listObjectsDeadline != 0 {
+		var cancel context.CancelFunc
+		timeoutCtx, cancel = context.WithTimeout(ctx, q.listObjectsDeadline)
+		defer cancel()
+	}
+
+	ds := storagewrappers.NewCombinedTupleReader(q.datastore, req.GetContextualTuples().GetTupleKeys())
+	timeoutCtx = storage.ContextWithRelationshipTupleReader(timeoutCtx, ds)
+
+	// the sub-problems of the Checks of the candidates are dispatched through a cache which lives as long as the batch
+	checkResolver, closeCheckResolver := q.newBatchCheckResolver()
+	defer closeCheckResolver()
+	timeoutCtx = graph.ContextWithDispatchResolver(timeoutCtx, checkResolver)
+
+	resolutionMetadata := NewListObjectsResolutionMetadata()
+
+	useChecks := uint32(len(uniqueCandidates)) <= q.filterCheckThreshold
+	span.SetAttributes(
+		attribute.Int("candidates", len(uniqueCandidates)),
+		attribute.Bool("checks", useChecks),
+	)
+
+	var allowed map[string]struct{}
+	var err error
+	if useChecks {
+		allowed, err = q.filterWithChecks(timeoutCtx, req, uniqueCandidates, checkResolver, resolutionMetadata)
+	} else {
+		allowed, err = q.filterWithReverseExpand(timeoutCtx, req, typesys, ds, candidateSet, checkResolver, resolutionMetadata)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]string, 0, len(allowed))
+	for _, candidate := range uniqueCandidates {
+		if _, ok := allowed[candidate]; ok {
+			objects = append(objects, candidate)
+		}
+	}
+
+	return &ListObjectsResponse{
+		Objects:            objects,
+		ResolutionMetadata: *resolutionMetadata,
+	}, nil
+}
+
+// newBatchCheckResolver returns a Check resolver which caches the Checks it resolves through the Check resolver of
+// the query for the lifetime of a batch of Checks, and a function to release it once the batch is done.
+func (q *ListObjectsQuery) newBatchCheckResolver() (graph.CheckResolver, func()) {
+	cacheTTL := q.listObjectsDeadline
+	if cacheTTL == 0 {
+		cacheTTL = serverconfig.DefaultCheckQueryCacheTTL
+	}
+
+	cachedCheckResolver := graph.NewCachedCheckResolver(
+		graph.WithMaxCacheSize(maxCachedChecksPerFilterQuery),
+		graph.WithCacheTTL(cacheTTL),
+		graph.WithLogger(q.logger),
+	)
+	cachedCheckResolver.SetDelegate(q.checkResolver)
+
+	return cachedCheckResolver, cachedCheckResolver.Close
+}
+
+// filterWithChecks returns the candidates that the user of the request has the relation with by checking each of them.
+func (q *ListObjectsQuery) filterWithChecks(
+	ctx context.Context,
+	req *openfgav1.ListObjectsRequest,
+	candidates []string,
+	checkResolver graph.CheckResolver,
+	resolutionMetadata *ListObjectsResolutionMetadata,
+) (map[string]struct{}, error) {
+	var mu sync.Mutex
+	allowed := make(map[string]struct{}, len(candidates))
+
+	p := pool.New().WithContext(ctx).WithMaxGoroutines(int(q.resolveNodeBreadthLimit))
+	for _, candidate := range candidates {
+		p.Go(func(ctx context.Context) error {
+			ok, err := q.checkCandidate(ctx, req, candidate, checkResolver, resolutionMetadata)
+			if err != nil {
+				return err
+			}
+
+			if ok {
+				mu.Lock()
+				allowed[candidate] = struct{}{}
+				mu.Unlock()
+			}
+
+			return nil
+		})
+	}
+
+	if err := p.Wait(); err != nil {
+		if err := filterError(ctx, err, resolutionMetadata); err != nil {
+			return nil, err
+		}
+	}
+
+	return allowed, nil
+}
+
+// filterWithReverseExpand returns the candidates that the user of the request has the relation with by
+// reverse expanding the user, until all candidates have been found.
+func (q *ListObjectsQuery) filterWithReverseExpand(
+	ctx context.Context,
+	req *openfgav1.ListObjectsRequest,
+	typesys *typesystem.TypeSystem,
+	ds storage.RelationshipTupleReader,
+	candidates map[string]struct{},
+	checkResolver graph.CheckResolver,
+	resolutionMetadata *ListObjectsResolutionMetadata,
+) (map[string]struct{}, error) {
+	reverseExpandQuery := reverseexpand.NewReverseExpandQuery(
+		ds,
+		typesys,
+		reverseexpand.WithResolveNodeLimit(q.resolveNodeLimit),
+		reverseexpand.WithDispatchThrottlerConfig(q.dispatchThrottlerConfig),
+		reverseexpand.WithResolveNodeBreadthLimit(q.resolveNodeBreadthLimit),
+		reverseexpand.WithLogger(q.logger),
+	)
+
+	cancelCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	reverseExpandResultsChan := make(chan *reverseexpand.ReverseExpandResult, 1)
+	reverseExpandResolutionMetadata := reverseexpand.NewResolutionMetadata()
+	errChan := make(chan error, 1)
+
+	go func() {
+		errChan <- reverseExpandQuery.Execute(cancelCtx, &reverseexpand.ReverseExpandRequest{
+			StoreID:          req.GetStoreId(),
+			ObjectType:       req.GetType(),
+			Relation:         req.GetRelation(),
+			User:             sourceUserRefFor(req.GetUser()),
+			ContextualTuples: req.GetContextualTuples().GetTupleKeys(),
+			Context:          req.GetContext(),
+		}, reverseExpandResultsChan, reverseExpandResolutionMetadata)
+	}()
+
+	var mu sync.Mutex
+	allowed := make(map[string]struct{}, len(candidates))
+
+	p := pool.New().WithContext(cancelCtx).WithMaxGoroutines(int(q.resolveNodeBreadthLimit))
+
+	var found int
+	var reverseExpandErr error
+
+ConsumerReadLoop:
+	for found < len(candidates) {
+		select {
+		case res, channelOpen := <-reverseExpandResultsChan:
+			if !channelOpen {
+				break ConsumerReadLoop
+			}
+
+			if _, ok := candidates[res.Object]; !ok {
+				continue
+			}
+			found++
+
+			if res.ResultStatus == reverseexpand.NoFurtherEvalStatus {
+				noFurtherEvalRequiredCounter.Inc()
+				mu.Lock()
+				allowed[res.Object] = struct{}{}
+				mu.Unlock()
+				continue
+			}
+
+			furtherEvalRequiredCounter.Inc()
+
+			p.Go(func(ctx context.Context) error {
+				ok, err := q.checkCandidate(ctx, req, res.Object, checkResolver, resolutionMetadata)
+				if err != nil {
+					return err
+				}
+
+				if ok {
+					mu.Lock()
+					allowed[res.Object] = struct{}{}
+					mu.Unlock()
+				}
+
+				return nil
+			})
+		case err := <-errChan:
+			if err != nil {
+				reverseExpandErr = err
+				break ConsumerReadLoop
+			}
+
+			// the expansion completed, keep consuming the results until the channel is closed
+			errChan = nil
+		}
+	}
+
+	checkErr := p.Wait()
+
+	// all candidates have been found, or the expansion is done, so wait for the expansion to return
+	cancel()
+	if errChan != nil && reverseExpandErr == nil {
+		if err := <-errChan; err != nil && !errors.Is(err, context.Canceled) {
+			reverseExpandErr = err
+		}
+	}
+
+	atomic.AddUint32(resolutionMetadata.DatastoreQueryCount, *reverseExpandResolutionMetadata.DatastoreQueryCount)
+	resolutionMetadata.DispatchCounter.Add(reverseExpandResolutionMetadata.DispatchCounter.Load())
+	resolutionMetadata.WasThrottled.Store(reverseExpandResolutionMetadata.WasThrottled.Load())
+
+	if err := filterError(ctx, errors.Join(reverseExpandErr, checkErr), resolutionMetadata); err != nil {
+		return nil, err
+	}
+
+	return allowed, nil
+}
+
+func (q *ListObjectsQuery) checkCandidate(
+	ctx context.Context,
+	req *openfgav1.ListObjectsRequest,
+	object string,
+	checkResolver graph.CheckResolver,
+	resolutionMetadata *ListObjectsResolutionMetadata,
+) (bool, error) {
+	checkRequestMetadata := graph.NewCheckRequestMetadata(q.resolveNodeLimit)
+	checkRequestMetadata.ResolveNodeBreadthLimit = q.resolveNodeBreadthLimit
+
+	resp, err := checkResolver.ResolveCheck(ctx, &graph.ResolveCheckRequest{
+		StoreID:              req.GetStoreId(),
+		AuthorizationModelID: req.GetAuthorizationModelId(),
+		TupleKey:             tuple.NewTupleKey(object, req.GetRelation(), req.GetUser()),
+		ContextualTuples:     req.GetContextualTuples().GetTupleKeys(),
+		Context:              req.GetContext(),
+		RequestMetadata:      checkRequestMetadata,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	atomic.AddUint32(resolutionMetadata.DatastoreQueryCount, resp.GetResolutionMetadata().DatastoreQueryCount)
+	resolutionMetadata.DispatchCounter.Add(checkRequestMetadata.DispatchCounter.Load())
+
+	return resp.GetAllowed(), nil
+}
+
+// filterError translates the error of filtering candidates, or returns nil if the error is only due to
+// the deadline being hit, in which case the candidates found to be allowed so far are returned and the
+// resolution metadata records that the deadline was exceeded.
+func filterError(ctx context.Context, err error, resolutionMetadata *ListObjectsResolutionMetadata) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, graph.ErrResolutionDepthExceeded):
+		return serverErrors.AuthorizationModelResolutionTooComplex
+	case errors.Is(err, condition.ErrEvaluationFailed):
+		return serverErrors.ValidationError(err)
+	case errors.Is(err, context.DeadlineExceeded) && ctx.Err() != nil:
+		resolutionMetadata.WasDeadlineExceeded.Store(true)
+		return nil
+	default:
+		return serverErrors.HandleError("", err)
+	}
+}
@@ -0,0 +1,178 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"go.uber.org/mock/gomock"
+
+	"github.com/openfga/openfga/internal/graph"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	storagetest "github.com/openfga/openfga/pkg/storage/test"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+func TestListObjectsExecuteFiltered(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	tuples := []string{
+		"group:eng#member@user:jon",
+		"document:blocked#viewer@group:eng#member",
+		"document:blocked#blocked@user:jon",
+		"document:public#viewer@user:*",
+	}
+	for i := 0; i < 20; i++ {
+		tuples = append(tuples, fmt.Sprintf("document:%d#viewer@group:eng#member", i))
+	}
+
+	storeID, model := storagetest.BootstrapFGAStore(t, ds, `
+		model
+			schema 1.1
+
+		type user
+
+		type group
+			relations
+				define member: [user]
+
+		type document
+			relations
+				define blocked: [user]
+				define viewer: [user, user:*, group#member] but not blocked`, tuples)
+
+	typesys, err := typesystem.NewAndValidate(context.Background(), model)
+	require.NoError(t, err)
+
+	ctx := typesystem.ContextWithTypesystem(context.Background(), typesys)
+	ctx = storage.ContextWithRelationshipTupleReader(ctx, ds)
+
+	checker := graph.NewLocalCheckerWithCycleDetection()
+	t.Cleanup(checker.Close)
+
+	req := &openfgav1.ListObjectsRequest{
+		StoreId:              storeID,
+		AuthorizationModelId: model.GetId(),
+		Type:                 "document",
+		Relation:             "viewer",
+		User:                 "user:jon",
+	}
+
+	candidates := []string{"document:public", "document:3", "document:blocked", "document:missing", "document:17", "document:3"}
+	expected := []string{"document:public", "document:3", "document:17"}
+
+	tests := []struct {
+		name      string
+		threshold uint32
+	}{
+		{name: "checks", threshold: DefaultFilterCheckThreshold},
+		{name: "reverse_expand", threshold: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			q, err := NewListObjectsQuery(ds, checker, WithFilterCheckThreshold(test.threshold))
+			require.NoError(t, err)
+
+			resp, err := q.ExecuteFiltered(ctx, req, candidates)
+			require.NoError(t, err)
+			require.Equal(t, expected, resp.Objects)
+
+			resp, err = q.ExecuteFiltered(ctx, req, []string{"document:1", "document:2"})
+			require.NoError(t, err)
+			require.Equal(t, []string{"document:1", "document:2"}, resp.Objects)
+
+			resp, err = q.ExecuteFiltered(ctx, req, nil)
+			require.NoError(t, err)
+			require.Empty(t, resp.Objects)
+		})
+	}
+
+	t.Run("too_many_candidates", func(t *testing.T) {
+		q, err := NewListObjectsQuery(ds, checker, WithListObjectsMaxResults(2))
+		require.NoError(t, err)
+
+		// duplicate candidates don't count towards the limit
+		resp, err := q.ExecuteFiltered(ctx, req, []string{"document:1", "document:2", "document:1"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"document:1", "document:2"}, resp.Objects)
+
+		_, err = q.ExecuteFiltered(ctx, req, []string{"document:1", "document:2", "document:3"})
+		require.ErrorIs(t, err, serverErrors.ValidationError(fmt.Errorf("at most 2 candidate objects may be provided")))
+	})
+
+	t.Run("deadline_exceeded", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+
+		// the Checks of the candidates don't complete before the deadline
+		slowChecker := graph.NewMockCheckResolver(ctrl)
+		slowChecker.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, _ *graph.ResolveCheckRequest) (*graph.ResolveCheckResponse, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}).
+			AnyTimes()
+
+		q, err := NewListObjectsQuery(ds, slowChecker, WithListObjectsDeadline(10*time.Millisecond))
+		require.NoError(t, err)
+
+		resp, err := q.ExecuteFiltered(ctx, req, []string{"document:1", "document:2"})
+		require.NoError(t, err)
+		require.Empty(t, resp.Objects)
+		require.True(t, resp.ResolutionMetadata.WasDeadlineExceeded.Load())
+	})
+
+	t.Run("shared_sub_checks_are_resolved_once", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+
+		localChecker := graph.NewLocalChecker()
+		t.Cleanup(localChecker.Close)
+
+		// the Checks and sub-problems reaching the Check resolver of the query
+		var groupChecks atomic.Uint32
+		countingChecker := graph.NewMockCheckResolver(ctrl)
+		countingChecker.EXPECT().ResolveCheck(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, req *graph.ResolveCheckRequest) (*graph.ResolveCheckResponse, error) {
+				if req.GetTupleKey().GetObject() == "group:eng" {
+					groupChecks.Add(1)
+				}
+
+				return localChecker.ResolveCheck(ctx, req)
+			}).
+			AnyTimes()
+		localChecker.SetDelegate(countingChecker)
+
+		// the candidates are checked one after the other
+		q, err := NewListObjectsQuery(ds, countingChecker, WithResolveNodeBreadthLimit(1))
+		require.NoError(t, err)
+
+		resp, err := q.ExecuteFiltered(ctx, req, []string{"document:1", "document:2", "document:3"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"document:1", "document:2", "document:3"}, resp.Objects)
+		require.Equal(t, uint32(1), groupChecks.Load())
+	})
+
+	t.Run("invalid_candidate", func(t *testing.T) {
+		q, err := NewListObjectsQuery(ds, checker)
+		require.NoError(t, err)
+
+		for _, candidate := range []string{"folder:1", "document:", "document:*", "1"} {
+			_, err = q.ExecuteFiltered(ctx, req, []string{"document:1", candidate})
+			require.ErrorIs(t, err, serverErrors.ValidationError(fmt.Errorf("invalid candidate object '%s': must be an object of type 'document'", candidate)))
+		}
+	})
+}
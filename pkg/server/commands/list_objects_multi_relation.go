@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/sourcegraph/conc/pool"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/protobuf/proto"
+
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/storage/storagewrappers"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// maxMemoizedTuplesPerMultiRelationQuery is the number of tuples that the relations of an ExecuteMultiRelation query
+// share through memoized reads. The reads beyond that are streamed from the datastore for every relation.
+const maxMemoizedTuplesPerMultiRelationQuery = 10_000
+
+type ListObjectsMultiRelationResponse struct {
+	// Objects are the objects the user has each relation with, keyed by relation.
+	Objects            map[string][]string
+	ResolutionMetadata ListObjectsResolutionMetadata
+}
+
+// ExecuteMultiRelation executes the ListObjectsQuery for each of the provided relations of the object type of the
+// request (whose own relation is ignored), returning the objects that the user of the request has each relation with.
+// Each relation is subject to q.listObjectsMaxResults and q.listObjectsDeadline as with Execute.
+//
+// The relations are evaluated concurrently over the same reads of the datastore, so that e.g. the tuples of the
+// groups the user is a member of are only read once even though both 'viewer' and 'editor' are reached through them
+// (up to maxMemoizedTuplesPerMultiRelationQuery tuples), and the objects needing further evaluation are checked through
+// the Check resolver of the query.
+func (q *ListObjectsQuery) ExecuteMultiRelation(
+	ctx context.Context,
+	req *openfgav1.ListObjectsRequest,
+	relations []string,
+) (*ListObjectsMultiRelationResponse, error) {
+	ctx, span := tracer.Start(ctx, "ExecuteMultiRelation")
+	defer span.End()
+
+	typesys, ok := typesystem.TypesystemFromContext(ctx)
+	if !ok {
+		panic("typesystem missing in context")
+	}
+
+	if len(relations) == 0 {
+		return nil, serverErrors.ValidationError(fmt.Errorf("at least one relation must be provided"))
+	}
+
+	requests := make(map[string]*openfgav1.ListObjectsRequest, len(relations))
+	for _, relation := range relations {
+		if _, ok := requests[relation]; ok {
+			continue
+		}
+
+		relationReq := proto.Clone(req).(*openfgav1.ListObjectsRequest)
+		relationReq.Relation = relation
+
+		if err := validateListObjectsRequest(typesys, relationReq); err != nil {
+			return nil, err
+		}
+
+		requests[relation] = relationReq
+	}
+
+	span.SetAttributes(attribute.Int("relations", len(requests)))
+
+	// a copy of the query whose reads are shared by all relations
+	shared := *q
+	shared.datastore = storagewrappers.NewMemoizingTupleReader(q.datastore, maxMemoizedTuplesPerMultiRelationQuery)
+
+	var mu sync.Mutex
+	objects := make(map[string][]string, len(requests))
+	resolutionMetadata := NewListObjectsResolutionMetadata()
+
+	p := pool.New().WithContext(ctx).WithCancelOnError().WithFirstError()
+	for relation, relationReq := range requests {
+		p.Go(func(ctx context.Context) error {
+			resp, err := shared.Execute(ctx, relationReq)
+			if err != nil {
+				return err
+			}
+
+			atomic.AddUint32(resolutionMetadata.DatastoreQueryCount, *resp.ResolutionMetadata.DatastoreQueryCount)
+			resolutionMetadata.DispatchCounter.Add(resp.ResolutionMetadata.DispatchCounter.Load())
+			if resp.ResolutionMetadata.WasThrottled.Load() {
+				resolutionMetadata.WasThrottled.Store(true)
+			}
+			if resp.ResolutionMetadata.WasDeadlineExceeded.Load() {
+				resolutionMetadata.WasDeadlineExceeded.Store(true)
+			}
+			if resp.ResolutionMetadata.WasMaxResultsReached.Load() {
+				resolutionMetadata.WasMaxResultsReached.Store(true)
+			}
+
+			mu.Lock()
+			objects[relation] = resp.Objects
+			mu.Unlock()
+
+			return nil
+		})
+	}
+
+	if err := p.Wait(); err != nil {
+		return nil, err
+	}
+
+	return &ListObjectsMultiRelationResponse{
+		Objects:            objects,
+		ResolutionMetadata: *resolutionMetadata,
+	}, nil
+}
@@ -0,0 +1,154 @@
+package commands
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/openfga/openfga/internal/graph"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	storagetest "github.com/openfga/openfga/pkg/storage/test"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+type readCountingTupleReader struct {
+	storage.RelationshipTupleReader
+	reads atomic.Uint32
+}
+
+func (r *readCountingTupleReader) ReadStartingWithUser(
+	ctx context.Context,
+	store string,
+	filter storage.ReadStartingWithUserFilter,
+) (storage.TupleIterator, error) {
+	r.reads.Add(1)
+	return r.RelationshipTupleReader.ReadStartingWithUser(ctx, store, filter)
+}
+
+func TestListObjectsExecuteMultiRelation(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID, model := storagetest.BootstrapFGAStore(t, ds, `
+		model
+			schema 1.1
+
+		type user
+
+		type group
+			relations
+				define member: [user, group#member]
+
+		type document
+			relations
+				define blocked: [user]
+				define owner: [user, group#member]
+				define editor: [user, group#member] or owner
+				define viewer: ([user, group#member] or editor) but not blocked
+				define share: editor and viewer`, []string{
+		"group:fga#member@user:jon",
+		"group:eng#member@group:fga#member",
+		"document:1#viewer@user:jon",
+		"document:2#editor@group:eng#member",
+		"document:3#owner@group:fga#member",
+		"document:4#editor@user:jon",
+		"document:4#blocked@user:jon",
+		"document:5#viewer@user:maria",
+	})
+
+	typesys, err := typesystem.NewAndValidate(context.Background(), model)
+	require.NoError(t, err)
+
+	ctx := typesystem.ContextWithTypesystem(context.Background(), typesys)
+
+	checker := graph.NewLocalCheckerWithCycleDetection()
+	t.Cleanup(checker.Close)
+
+	req := &openfgav1.ListObjectsRequest{
+		StoreId:              storeID,
+		AuthorizationModelId: model.GetId(),
+		Type:                 "document",
+		Relation:             "viewer",
+		User:                 "user:jon",
+	}
+
+	relations := []string{"viewer", "editor", "share", "editor"}
+
+	t.Run("lists_the_objects_of_each_relation", func(t *testing.T) {
+		q, err := NewListObjectsQuery(ds, checker)
+		require.NoError(t, err)
+
+		resp, err := q.ExecuteMultiRelation(ctx, req, relations)
+		require.NoError(t, err)
+		require.Len(t, resp.Objects, 3)
+		require.ElementsMatch(t, []string{"document:1", "document:2", "document:3"}, resp.Objects["viewer"])
+		require.ElementsMatch(t, []string{"document:2", "document:3", "document:4"}, resp.Objects["editor"])
+		require.ElementsMatch(t, []string{"document:2", "document:3"}, resp.Objects["share"])
+
+		for relation, objects := range resp.Objects {
+			relationReq := &openfgav1.ListObjectsRequest{
+				StoreId:              storeID,
+				AuthorizationModelId: model.GetId(),
+				Type:                 "document",
+				Relation:             relation,
+				User:                 "user:jon",
+			}
+
+			expected, err := q.Execute(ctx, relationReq)
+			require.NoError(t, err)
+			require.ElementsMatch(t, expected.Objects, objects)
+		}
+	})
+
+	t.Run("shares_reads_among_relations", func(t *testing.T) {
+		separateReader := &readCountingTupleReader{RelationshipTupleReader: ds}
+		q, err := NewListObjectsQuery(separateReader, checker)
+		require.NoError(t, err)
+
+		for _, relation := range []string{"viewer", "editor", "share"} {
+			_, err := q.Execute(storage.ContextWithRelationshipTupleReader(ctx, separateReader), &openfgav1.ListObjectsRequest{
+				StoreId:              storeID,
+				AuthorizationModelId: model.GetId(),
+				Type:                 "document",
+				Relation:             relation,
+				User:                 "user:jon",
+			})
+			require.NoError(t, err)
+		}
+
+		sharedReader := &readCountingTupleReader{RelationshipTupleReader: ds}
+		q, err = NewListObjectsQuery(sharedReader, checker)
+		require.NoError(t, err)
+
+		_, err = q.ExecuteMultiRelation(ctx, req, relations)
+		require.NoError(t, err)
+
+		require.Less(t, sharedReader.reads.Load(), separateReader.reads.Load())
+	})
+
+	t.Run("undefined_relation", func(t *testing.T) {
+		q, err := NewListObjectsQuery(ds, checker)
+		require.NoError(t, err)
+
+		_, err = q.ExecuteMultiRelation(ctx, req, []string{"viewer", "undefined"})
+		require.ErrorIs(t, err, serverErrors.RelationNotFound("undefined", "document", nil))
+	})
+
+	t.Run("no_relations", func(t *testing.T) {
+		q, err := NewListObjectsQuery(ds, checker)
+		require.NoError(t, err)
+
+		_, err = q.ExecuteMultiRelation(ctx, req, nil)
+		require.Error(t, err)
+	})
+}
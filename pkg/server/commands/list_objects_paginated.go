@@ -0,0 +1,446 @@
+package commands
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/karlseguin/ccache/v3"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/sourcegraph/conc/pool"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/openfga/openfga/internal/condition"
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/internal/keys"
+	"github.com/openfga/openfga/pkg/server/commands/reverseexpand"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/storagewrappers"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// listObjectsFrontierCacheTTL is how long the reverse expansion frontier of a page of a paginated ListObjects query
+// is cached for, to resume the expansion from it for the next page.
+const listObjectsFrontierCacheTTL = 10 * time.Minute
+
+// ListObjectsFrontierCache caches the reverse expansion frontiers of paginated ListObjects queries, keyed by their
+// hash, so that continuation tokens only need to refer to them. See WithListObjectsFrontierCache.
+type ListObjectsFrontierCache = ccache.Cache[*reverseexpand.Frontier]
+
+// NewListObjectsFrontierCache constructs a ListObjectsFrontierCache holding frontiers of up to maxSizeInBytes in total
+// (see [[reverseexpand.Frontier.Size]]), since a frontier grows with the number of users expanded. You must call Stop
+// on it after you are done using it.
+func NewListObjectsFrontierCache(maxSizeInBytes int64) *ListObjectsFrontierCache {
+	return ccache.New(ccache.Configure[*reverseexpand.Frontier]().MaxSize(maxSizeInBytes))
+}
+
+// listObjectsContinuationToken is the cursor of a paginated ListObjects query. It records the request it was
+// issued for, so that it can't be used to resume a different query, and the position reached in the reverse
+// expansion, whose frontier isn't part of the token since it grows with the number of objects found.
+type listObjectsContinuationToken struct {
+	StoreID              string `json:"store_id"`
+	AuthorizationModelID string `json:"authorization_model_id"`
+	Type                 string `json:"type"`
+	Relation             string `json:"relation"`
+	User                 string `json:"user"`
+
+	// RequestHash is the hash of the contextual tuples and context of the request, if any.
+	RequestHash string `json:"request_hash,omitempty"`
+
+	// Position is the number of results yielded by the reverse expansion for the previous pages, and LastObject
+	// the last of them.
+	Position   uint32 `json:"position"`
+	LastObject string `json:"last_object,omitempty"`
+
+	// FrontierHash is the hash of the query and of the frontier to resume the reverse expansion from, under which
+	// the frontier is cached.
+	FrontierHash string `json:"frontier_hash"`
+}
+
+// ExecutePaginated executes the ListObjectsQuery, returning a page of up to pageSize object IDs (or up to
+// q.listObjectsMaxResults if pageSize is zero) along with a continuation token to get the next page, which is
+// empty once all objects have been returned. Pages are resumed from the reverse expansion frontier captured in the
+// continuation token, so paging through the objects with the same contextual tuples and context yields each object
+// exactly once, in an order which only depends on the tuples of the store.
+//
+// The continuation token is a cursor: the frontier to resume the reverse expansion from is cached (see
+// WithListObjectsFrontierCache) by the server which issued it, so pages are meant to be requested from the same
+// server. If the frontier isn't cached (e.g. the next page is served by another server, or the frontier was evicted),
+// it is recomputed by replaying the expansion up to the position of the cursor, whose cost grows with the position,
+// within q.listObjectsDeadline: if the deadline is hit, the ContinuationTokenReplayDeadlineExceeded error is returned.
+// The token is rejected if it is used with other contextual tuples or context, or if the tuples changed so that the
+// replay doesn't reach the same position.
+//
+// If q.listObjectsDeadline is hit, the objects found so far are returned along with a continuation token, so a page
+// may hold less than pageSize objects even though more objects remain.
+func (q *ListObjectsQuery) ExecutePaginated(
+	ctx context.Context,
+	req *openfgav1.ListObjectsRequest,
+	pageSize uint32,
+	continuationToken string,
+) (*ListObjectsResponse, error) {
+	ctx, span := tracer.Start(ctx, "ExecutePaginated")
+	defer span.End()
+
+	typesys, ok := typesystem.TypesystemFromContext(ctx)
+	if !ok {
+		panic("typesystem missing in context")
+	}
+
+	if err := validateListObjectsRequest(typesys, req); err != nil {
+		return nil, err
+	}
+
+	token, err := q.decodeContinuationToken(req, continuationToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if pageSize == 0 {
+		pageSize = q.listObjectsMaxResults
+	}
+
+	timeoutCtx := ctx
+	if q.listObjectsDeadline != 0 {
+		var cancel context.CancelFunc
+		timeoutCtx, cancel = context.WithTimeout(ctx, q.listObjectsDeadline)
+		defer cancel()
+	}
+
+	ds := storagewrappers.NewCombinedTupleReader(q.datastore, req.GetContextualTuples().GetTupleKeys())
+
+	reverseExpandQuery := reverseexpand.NewReverseExpandQuery(
+		ds,
+		typesys,
+		reverseexpand.WithResolveNodeLimit(q.resolveNodeLimit),
+		reverseexpand.WithDispatchThrottlerConfig(q.dispatchThrottlerConfig),
+		reverseexpand.WithResolveNodeBreadthLimit(q.resolveNodeBreadthLimit),
+		reverseexpand.WithLogger(q.logger),
+	)
+
+	reverseExpandRequest := &reverseexpand.ReverseExpandRequest{
+		StoreID:          req.GetStoreId(),
+		ObjectType:       req.GetType(),
+		Relation:         req.GetRelation(),
+		User:             sourceUserRefFor(req.GetUser()),
+		ContextualTuples: req.GetContextualTuples().GetTupleKeys(),
+		Context:          req.GetContext(),
+	}
+
+	ctx = storage.ContextWithRelationshipTupleReader(ctx, ds)
+
+	resolutionMetadata := NewListObjectsResolutionMetadata()
+	reverseExpandResolutionMetadata := reverseexpand.NewResolutionMetadata()
+
+	objects := make([]string, 0)
+
+	frontier, done, err := q.resumeFrontier(timeoutCtx, reverseExpandQuery, reverseExpandRequest, token, reverseExpandResolutionMetadata)
+	if err != nil {
+		if timeoutCtx.Err() == nil || ctx.Err() != nil {
+			return nil, err
+		}
+
+		// the deadline was hit while replaying the expansion, which another attempt is unlikely to complete
+		return nil, serverErrors.ContinuationTokenReplayDeadlineExceeded
+	}
+
+	var errs error
+	for !done {
+		var remaining uint32
+		if pageSize > 0 {
+			remaining = pageSize - uint32(len(objects))
+		}
+
+		results, nextFrontier, err := reverseExpandQuery.ExecutePage(timeoutCtx, reverseExpandRequest, frontier, remaining, reverseExpandResolutionMetadata)
+		if err != nil {
+			switch {
+			case errors.Is(err, graph.ErrResolutionDepthExceeded):
+				return nil, serverErrors.AuthorizationModelResolutionTooComplex
+			case errors.Is(err, condition.ErrEvaluationFailed):
+				errs = errors.Join(errs, err)
+			case timeoutCtx.Err() != nil && ctx.Err() == nil:
+				// the deadline was hit, return the objects found so far
+				resolutionMetadata.WasDeadlineExceeded.Store(true)
+			default:
+				return nil, serverErrors.HandleError("", err)
+			}
+		}
+
+		// the objects yielded by the page are part of the frontier already, so they are checked with the
+		// context of the request rather than the deadline to not lose them
+		allowed, err := q.checkPageResults(ctx, req, results, resolutionMetadata)
+		if err != nil {
+			return nil, err
+		}
+
+		objects = append(objects, allowed...)
+		frontier = nextFrontier
+
+		token.Position += uint32(len(results))
+		if len(results) > 0 {
+			token.LastObject = results[len(results)-1].Object
+		}
+
+		if frontier == nil || timeoutCtx.Err() != nil || (pageSize > 0 && uint32(len(objects)) >= pageSize) {
+			break
+		}
+	}
+
+	atomic.AddUint32(resolutionMetadata.DatastoreQueryCount, *reverseExpandResolutionMetadata.DatastoreQueryCount)
+	resolutionMetadata.DispatchCounter.Add(reverseExpandResolutionMetadata.DispatchCounter.Load())
+	resolutionMetadata.WasThrottled.Store(resolutionMetadata.WasThrottled.Load() || reverseExpandResolutionMetadata.WasThrottled.Load())
+
+	// errors are returned unless the page is full, a page size of zero meaning that there is no limit
+	if errs != nil && (pageSize == 0 || uint32(len(objects)) < pageSize) {
+		return nil, errs
+	}
+
+	var encodedContToken string
+	if frontier != nil {
+		token.FrontierHash, err = listObjectsFrontierHash(token, frontier)
+		if err != nil {
+			return nil, serverErrors.HandleError("", err)
+		}
+
+		if q.frontierCache != nil {
+			q.frontierCache.Set(token.FrontierHash, frontier, listObjectsFrontierCacheTTL)
+		}
+
+		encodedContToken, err = q.encodeContinuationToken(token)
+		if err != nil {
+			return nil, serverErrors.HandleError("", err)
+		}
+	}
+
+	span.SetAttributes(attribute.Int("objects", len(objects)), attribute.Bool("has_more", frontier != nil))
+
+	return &ListObjectsResponse{
+		Objects:            objects,
+		ResolutionMetadata: *resolutionMetadata,
+		ContinuationToken:  encodedContToken,
+	}, nil
+}
+
+// checkPageResults returns the objects of the results of a page of reverse expansion that the user of the
+// request has the relation with, in the order of the results. Results which require further evaluation are
+// checked concurrently.
+func (q *ListObjectsQuery) checkPageResults(
+	ctx context.Context,
+	req *openfgav1.ListObjectsRequest,
+	results []*reverseexpand.ReverseExpandResult,
+	resolutionMetadata *ListObjectsResolutionMetadata,
+) ([]string, error) {
+	allowed := make([]bool, len(results))
+
+	p := pool.New().WithContext(ctx).WithCancelOnError().WithFirstError().WithMaxGoroutines(int(q.resolveNodeBreadthLimit))
+	for i, res := range results {
+		if res.ResultStatus == reverseexpand.NoFurtherEvalStatus {
+			noFurtherEvalRequiredCounter.Inc()
+			allowed[i] = true
+			continue
+		}
+
+		furtherEvalRequiredCounter.Inc()
+
+		p.Go(func(ctx context.Context) error {
+			checkRequestMetadata := graph.NewCheckRequestMetadata(q.resolveNodeLimit)
+			checkRequestMetadata.ResolveNodeBreadthLimit = q.resolveNodeBreadthLimit
+
+			resp, err := q.checkResolver.ResolveCheck(ctx, &graph.ResolveCheckRequest{
+				StoreID:              req.GetStoreId(),
+				AuthorizationModelID: req.GetAuthorizationModelId(),
+				TupleKey:             tuple.NewTupleKey(res.Object, req.GetRelation(), req.GetUser()),
+				ContextualTuples:     req.GetContextualTuples().GetTupleKeys(),
+				Context:              req.GetContext(),
+				RequestMetadata:      checkRequestMetadata,
+			})
+			if err != nil {
+				return err
+			}
+
+			atomic.AddUint32(resolutionMetadata.DatastoreQueryCount, resp.GetResolutionMetadata().DatastoreQueryCount)
+			allowed[i] = resp.GetAllowed()
+			return nil
+		})
+	}
+
+	if err := p.Wait(); err != nil {
+		if errors.Is(err, graph.ErrResolutionDepthExceeded) {
+			return nil, serverErrors.AuthorizationModelResolutionTooComplex
+		}
+
+		if errors.Is(err, condition.ErrEvaluationFailed) {
+			return nil, serverErrors.ValidationError(err)
+		}
+
+		return nil, serverErrors.HandleError("", err)
+	}
+
+	objects := make([]string, 0, len(results))
+	for i, res := range results {
+		if allowed[i] {
+			objects = append(objects, res.Object)
+		}
+	}
+
+	return objects, nil
+}
+
+// decodeContinuationToken decodes the continuation token of a paginated ListObjects query, or returns a
+// token to start the query from if it is empty.
+func (q *ListObjectsQuery) decodeContinuationToken(req *openfgav1.ListObjectsRequest, continuationToken string) (*listObjectsContinuationToken, error) {
+	requestHash, err := listObjectsRequestHash(req)
+	if err != nil {
+		return nil, serverErrors.HandleError("", err)
+	}
+
+	token := &listObjectsContinuationToken{
+		StoreID:              req.GetStoreId(),
+		AuthorizationModelID: req.GetAuthorizationModelId(),
+		Type:                 req.GetType(),
+		Relation:             req.GetRelation(),
+		User:                 req.GetUser(),
+		RequestHash:          requestHash,
+	}
+
+	if continuationToken == "" {
+		return token, nil
+	}
+
+	decoded, err := q.encoder.Decode(continuationToken)
+	if err != nil {
+		return nil, serverErrors.InvalidContinuationToken
+	}
+
+	var decodedToken listObjectsContinuationToken
+	if err := json.Unmarshal(decoded, &decodedToken); err != nil {
+		return nil, serverErrors.InvalidContinuationToken
+	}
+
+	if decodedToken.FrontierHash == "" {
+		return nil, serverErrors.InvalidContinuationToken
+	}
+
+	token.Position = decodedToken.Position
+	token.LastObject = decodedToken.LastObject
+	token.FrontierHash = decodedToken.FrontierHash
+	if *token != decodedToken {
+		return nil, serverErrors.InvalidContinuationToken
+	}
+
+	return token, nil
+}
+
+// resumeFrontier returns the frontier to resume the reverse expansion of a paginated ListObjects query from: nil
+// to start the expansion if the token is a new one, the cached frontier of the token if any, or else the frontier
+// reached by replaying the expansion up to the position of the token. It returns true if the replay found that
+// the expansion is done.
+func (q *ListObjectsQuery) resumeFrontier(
+	ctx context.Context,
+	reverseExpandQuery *reverseexpand.ReverseExpandQuery,
+	req *reverseexpand.ReverseExpandRequest,
+	token *listObjectsContinuationToken,
+	resolutionMetadata *reverseexpand.ResolutionMetadata,
+) (*reverseexpand.Frontier, bool, error) {
+	if token.FrontierHash == "" {
+		return nil, false, nil
+	}
+
+	if q.frontierCache != nil {
+		if item := q.frontierCache.Get(token.FrontierHash); item != nil && !item.Expired() {
+			// the hash covers the query, so a frontier cached for another query is never resumed
+			if hash, err := listObjectsFrontierHash(token, item.Value()); err == nil && hash == token.FrontierHash {
+				return item.Value(), false, nil
+			}
+		}
+	}
+
+	if token.Position == 0 {
+		return nil, false, nil
+	}
+
+	results, frontier, err := reverseExpandQuery.ExecutePage(ctx, req, nil, token.Position, resolutionMetadata)
+	if err != nil && !errors.Is(err, condition.ErrEvaluationFailed) {
+		if errors.Is(err, graph.ErrResolutionDepthExceeded) {
+			return nil, false, serverErrors.AuthorizationModelResolutionTooComplex
+		}
+
+		if ctx.Err() != nil {
+			return nil, false, err
+		}
+
+		return nil, false, serverErrors.HandleError("", err)
+	}
+
+	if uint32(len(results)) != token.Position || results[len(results)-1].Object != token.LastObject {
+		// the tuples changed since the token was issued
+		return nil, false, serverErrors.InvalidContinuationToken
+	}
+
+	return frontier, frontier == nil, nil
+}
+
+// listObjectsFrontierHash returns the hash of the query of the token and of the frontier.
+func listObjectsFrontierHash(token *listObjectsContinuationToken, frontier *reverseexpand.Frontier) (string, error) {
+	h := sha256.New()
+
+	encoder := json.NewEncoder(h)
+	if err := encoder.Encode([]string{
+		token.StoreID,
+		token.AuthorizationModelID,
+		token.Type,
+		token.Relation,
+		token.User,
+		token.RequestHash,
+	}); err != nil {
+		return "", err
+	}
+
+	if err := encoder.Encode(frontier); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// listObjectsRequestHash returns the hash of the contextual tuples and context of the request, regardless of their
+// order, or an empty hash if the request has neither.
+func listObjectsRequestHash(req *openfgav1.ListObjectsRequest) (string, error) {
+	contextualTuples := req.GetContextualTuples().GetTupleKeys()
+	if len(contextualTuples) == 0 && req.GetContext() == nil {
+		return "", nil
+	}
+
+	hasher := keys.NewCacheKeyHasher(xxhash.New())
+
+	if len(contextualTuples) > 0 {
+		if err := keys.NewTupleKeysHasher(contextualTuples...).Append(hasher); err != nil {
+			return "", err
+		}
+	}
+
+	if req.GetContext() != nil {
+		if err := keys.NewContextHasher(req.GetContext()).Append(hasher); err != nil {
+			return "", err
+		}
+	}
+
+	return strconv.FormatUint(hasher.Key().ToUInt64(), 10), nil
+}
+
+func (q *ListObjectsQuery) encodeContinuationToken(token *listObjectsContinuationToken) (string, error) {
+	marshalled, err := json.Marshal(token)
+	if err != nil {
+		return "", err
+	}
+
+	return q.encoder.Encode(marshalled)
+}
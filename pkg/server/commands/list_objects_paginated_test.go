@@ -0,0 +1,393 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/openfga/openfga/internal/condition"
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/pkg/encoder"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	storagetest "github.com/openfga/openfga/pkg/storage/test"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+func TestListObjectsExecutePaginated(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	tuples := []string{
+		"group:eng#member@group:fga#member",
+		"group:fga#member@user:jon",
+		"group:fga#member@group:eng#member",
+		"folder:x#viewer@group:eng#member",
+		"document:blocked#viewer@user:jon",
+		"document:blocked#blocked@user:jon",
+		"document:public#viewer@user:*",
+	}
+	for i := 0; i < 25; i++ {
+		tuples = append(tuples,
+			fmt.Sprintf("document:direct-%d#viewer@user:jon", i),
+			fmt.Sprintf("document:group-%d#viewer@group:fga#member", i),
+			fmt.Sprintf("document:folder-%d#parent@folder:x", i),
+		)
+	}
+
+	storeID, model := storagetest.BootstrapFGAStore(t, ds, `
+		model
+			schema 1.1
+
+		type user
+
+		type group
+			relations
+				define member: [user, group#member]
+
+		type folder
+			relations
+				define viewer: [user, group#member]
+
+		type document
+			relations
+				define parent: [folder]
+				define blocked: [user]
+				define viewer: ([user, user:*, group#member] or viewer from parent) but not blocked`, tuples)
+
+	typesys, err := typesystem.NewAndValidate(context.Background(), model)
+	require.NoError(t, err)
+
+	ctx := typesystem.ContextWithTypesystem(context.Background(), typesys)
+	ctx = storage.ContextWithRelationshipTupleReader(ctx, ds)
+
+	checker := graph.NewLocalCheckerWithCycleDetection()
+	t.Cleanup(checker.Close)
+
+	q, err := NewListObjectsQuery(ds, checker, WithListObjectsMaxResults(0), WithListObjectsEncoder(encoder.NewBase64Encoder()))
+	require.NoError(t, err)
+
+	req := &openfgav1.ListObjectsRequest{
+		StoreId:              storeID,
+		AuthorizationModelId: model.GetId(),
+		Type:                 "document",
+		Relation:             "viewer",
+		User:                 "user:jon",
+	}
+
+	expected, err := q.Execute(ctx, req)
+	require.NoError(t, err)
+	require.Len(t, expected.Objects, 76)
+	require.NotContains(t, expected.Objects, "document:blocked")
+
+	frontierCache := NewListObjectsFrontierCache(1 << 20)
+	t.Cleanup(frontierCache.Stop)
+
+	signingEncoder := encoder.NewHMACEncoder([]byte("signing-key"), encoder.NewBase64Encoder())
+	cachedQ, err := NewListObjectsQuery(ds, checker,
+		WithListObjectsMaxResults(0),
+		WithListObjectsEncoder(signingEncoder),
+		WithListObjectsFrontierCache(frontierCache),
+	)
+	require.NoError(t, err)
+
+	paginateWith := func(t *testing.T, q *ListObjectsQuery, pageSize uint32) ([]string, int) {
+		var objects []string
+		var pages int
+		var continuationToken string
+		for {
+			resp, err := q.ExecutePaginated(ctx, req, pageSize, continuationToken)
+			require.NoError(t, err)
+			require.LessOrEqual(t, len(resp.Objects), int(pageSize))
+
+			objects = append(objects, resp.Objects...)
+			pages++
+
+			continuationToken = resp.ContinuationToken
+			if continuationToken == "" {
+				return objects, pages
+			}
+		}
+	}
+
+	// without a frontier cache, every page but the first is resumed by replaying the reverse expansion
+	paginate := func(t *testing.T, pageSize uint32) ([]string, int) {
+		return paginateWith(t, q, pageSize)
+	}
+
+	t.Run("pages_through_all_objects_once", func(t *testing.T) {
+		objects, pages := paginate(t, 10)
+		require.ElementsMatch(t, expected.Objects, objects)
+		require.GreaterOrEqual(t, pages, 8)
+	})
+
+	t.Run("pages_are_deterministic", func(t *testing.T) {
+		first, _ := paginate(t, 7)
+		second, _ := paginate(t, 7)
+		require.Equal(t, first, second)
+
+		third, _ := paginate(t, 30)
+		require.Equal(t, first, third)
+	})
+
+	t.Run("pages_resumed_from_the_frontier_cache", func(t *testing.T) {
+		replayed, _ := paginate(t, 7)
+		cached, _ := paginateWith(t, cachedQ, 7)
+		require.Equal(t, replayed, cached)
+	})
+
+	t.Run("frontiers_larger_than_the_cache_are_replayed", func(t *testing.T) {
+		smallFrontierCache := NewListObjectsFrontierCache(1)
+		t.Cleanup(smallFrontierCache.Stop)
+
+		smallCacheQ, err := NewListObjectsQuery(ds, checker,
+			WithListObjectsMaxResults(0),
+			WithListObjectsEncoder(signingEncoder),
+			WithListObjectsFrontierCache(smallFrontierCache),
+		)
+		require.NoError(t, err)
+
+		replayed, _ := paginate(t, 7)
+		cached, _ := paginateWith(t, smallCacheQ, 7)
+		require.Equal(t, replayed, cached)
+	})
+
+	t.Run("replay_deadline_exceeded", func(t *testing.T) {
+		resp, err := q.ExecutePaginated(ctx, req, 60, "")
+		require.NoError(t, err)
+		require.NotEmpty(t, resp.ContinuationToken)
+
+		// the frontier of the token isn't cached, and replaying the expansion up to it exceeds the deadline
+		deadlineQ, err := NewListObjectsQuery(ds, checker,
+			WithListObjectsMaxResults(0),
+			WithListObjectsEncoder(encoder.NewBase64Encoder()),
+			WithListObjectsDeadline(time.Nanosecond),
+		)
+		require.NoError(t, err)
+
+		_, err = deadlineQ.ExecutePaginated(ctx, req, 10, resp.ContinuationToken)
+		require.ErrorIs(t, err, serverErrors.ContinuationTokenReplayDeadlineExceeded)
+	})
+
+	t.Run("continuation_token_is_a_compact_cursor", func(t *testing.T) {
+		first, err := q.ExecutePaginated(ctx, req, 1, "")
+		require.NoError(t, err)
+
+		resp, err := q.ExecutePaginated(ctx, req, 60, "")
+		require.NoError(t, err)
+		require.NotEmpty(t, resp.ContinuationToken)
+
+		// the token doesn't grow with the number of objects found, only with the length of the last one
+		require.InDelta(t, len(first.ContinuationToken), len(resp.ContinuationToken), 64)
+	})
+
+	t.Run("unsigned_continuation_token", func(t *testing.T) {
+		resp, err := q.ExecutePaginated(ctx, req, 10, "")
+		require.NoError(t, err)
+		require.NotEmpty(t, resp.ContinuationToken)
+
+		_, err = cachedQ.ExecutePaginated(ctx, req, 10, resp.ContinuationToken)
+		require.ErrorIs(t, err, serverErrors.InvalidContinuationToken)
+	})
+
+	t.Run("continuation_token_signed_with_another_key", func(t *testing.T) {
+		otherQ, err := NewListObjectsQuery(ds, checker,
+			WithListObjectsMaxResults(0),
+			WithListObjectsEncoder(encoder.NewHMACEncoder([]byte("another-key"), encoder.NewBase64Encoder())),
+		)
+		require.NoError(t, err)
+
+		resp, err := otherQ.ExecutePaginated(ctx, req, 10, "")
+		require.NoError(t, err)
+		require.NotEmpty(t, resp.ContinuationToken)
+
+		_, err = cachedQ.ExecutePaginated(ctx, req, 10, resp.ContinuationToken)
+		require.ErrorIs(t, err, serverErrors.InvalidContinuationToken)
+	})
+
+	t.Run("invalid_continuation_token", func(t *testing.T) {
+		_, err := q.ExecutePaginated(ctx, req, 10, "not-a-token")
+		require.ErrorIs(t, err, serverErrors.InvalidContinuationToken)
+	})
+
+	t.Run("continuation_token_of_another_query", func(t *testing.T) {
+		resp, err := q.ExecutePaginated(ctx, req, 10, "")
+		require.NoError(t, err)
+		require.NotEmpty(t, resp.ContinuationToken)
+
+		_, err = q.ExecutePaginated(ctx, &openfgav1.ListObjectsRequest{
+			StoreId:              storeID,
+			AuthorizationModelId: model.GetId(),
+			Type:                 "document",
+			Relation:             "viewer",
+			User:                 "user:maria",
+		}, 10, resp.ContinuationToken)
+		require.ErrorIs(t, err, serverErrors.InvalidContinuationToken)
+
+		_, err = q.ExecutePaginated(ctx, &openfgav1.ListObjectsRequest{
+			StoreId:              ulid.Make().String(),
+			AuthorizationModelId: model.GetId(),
+			Type:                 "document",
+			Relation:             "viewer",
+			User:                 "user:jon",
+		}, 10, resp.ContinuationToken)
+		require.ErrorIs(t, err, serverErrors.InvalidContinuationToken)
+	})
+
+	t.Run("continuation_token_of_other_contextual_tuples_or_context", func(t *testing.T) {
+		contextualReq := &openfgav1.ListObjectsRequest{
+			StoreId:              storeID,
+			AuthorizationModelId: model.GetId(),
+			Type:                 "document",
+			Relation:             "viewer",
+			User:                 "user:jon",
+			ContextualTuples: &openfgav1.ContextualTupleKeys{
+				TupleKeys: []*openfgav1.TupleKey{
+					tuple.NewTupleKey("document:contextual", "viewer", "user:jon"),
+					tuple.NewTupleKey("document:other-contextual", "viewer", "user:jon"),
+				},
+			},
+			Context: testutils.MustNewStruct(t, map[string]interface{}{"x": 1}),
+		}
+
+		resp, err := cachedQ.ExecutePaginated(ctx, contextualReq, 10, "")
+		require.NoError(t, err)
+		require.NotEmpty(t, resp.ContinuationToken)
+
+		// the order of the contextual tuples doesn't matter
+		reorderedReq := &openfgav1.ListObjectsRequest{
+			StoreId:              storeID,
+			AuthorizationModelId: model.GetId(),
+			Type:                 "document",
+			Relation:             "viewer",
+			User:                 "user:jon",
+			ContextualTuples: &openfgav1.ContextualTupleKeys{
+				TupleKeys: []*openfgav1.TupleKey{
+					tuple.NewTupleKey("document:other-contextual", "viewer", "user:jon"),
+					tuple.NewTupleKey("document:contextual", "viewer", "user:jon"),
+				},
+			},
+			Context: testutils.MustNewStruct(t, map[string]interface{}{"x": 1}),
+		}
+
+		_, err = cachedQ.ExecutePaginated(ctx, reorderedReq, 10, resp.ContinuationToken)
+		require.NoError(t, err)
+
+		_, err = cachedQ.ExecutePaginated(ctx, req, 10, resp.ContinuationToken)
+		require.ErrorIs(t, err, serverErrors.InvalidContinuationToken)
+
+		otherContextReq := &openfgav1.ListObjectsRequest{
+			StoreId:              storeID,
+			AuthorizationModelId: model.GetId(),
+			Type:                 "document",
+			Relation:             "viewer",
+			User:                 "user:jon",
+			ContextualTuples:     contextualReq.GetContextualTuples(),
+			Context:              testutils.MustNewStruct(t, map[string]interface{}{"x": 2}),
+		}
+
+		_, err = cachedQ.ExecutePaginated(ctx, otherContextReq, 10, resp.ContinuationToken)
+		require.ErrorIs(t, err, serverErrors.InvalidContinuationToken)
+
+		otherContextualTuplesReq := &openfgav1.ListObjectsRequest{
+			StoreId:              storeID,
+			AuthorizationModelId: model.GetId(),
+			Type:                 "document",
+			Relation:             "viewer",
+			User:                 "user:jon",
+			ContextualTuples: &openfgav1.ContextualTupleKeys{
+				TupleKeys: []*openfgav1.TupleKey{
+					tuple.NewTupleKey("document:contextual", "viewer", "user:jon"),
+				},
+			},
+			Context: contextualReq.GetContext(),
+		}
+
+		_, err = cachedQ.ExecutePaginated(ctx, otherContextualTuplesReq, 10, resp.ContinuationToken)
+		require.ErrorIs(t, err, serverErrors.InvalidContinuationToken)
+	})
+
+	t.Run("unlimited_page_size", func(t *testing.T) {
+		resp, err := q.ExecutePaginated(ctx, req, 0, "")
+		require.NoError(t, err)
+		require.ElementsMatch(t, expected.Objects, resp.Objects)
+		require.Empty(t, resp.ContinuationToken)
+	})
+}
+
+func TestListObjectsExecutePaginatedConditionErrors(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID, model := storagetest.BootstrapFGAStore(t, ds, `
+		model
+			schema 1.1
+
+		type user
+
+		type document
+			relations
+				define viewer: [user, user with x_less_than]
+
+		condition x_less_than(x: int) {
+			x < 100
+		}`, []string{"document:1#viewer@user:jon"})
+
+	err := ds.Write(context.Background(), storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKeyWithCondition("document:2", "viewer", "user:jon", "x_less_than", nil),
+	})
+	require.NoError(t, err)
+
+	typesys, err := typesystem.NewAndValidate(context.Background(), model)
+	require.NoError(t, err)
+
+	ctx := typesystem.ContextWithTypesystem(context.Background(), typesys)
+	ctx = storage.ContextWithRelationshipTupleReader(ctx, ds)
+
+	checker := graph.NewLocalCheckerWithCycleDetection()
+	t.Cleanup(checker.Close)
+
+	q, err := NewListObjectsQuery(ds, checker, WithListObjectsMaxResults(0), WithListObjectsEncoder(encoder.NewBase64Encoder()))
+	require.NoError(t, err)
+
+	req := &openfgav1.ListObjectsRequest{
+		StoreId:              storeID,
+		AuthorizationModelId: model.GetId(),
+		Type:                 "document",
+		Relation:             "viewer",
+		User:                 "user:jon",
+	}
+
+	t.Run("returned_if_the_page_is_unlimited", func(t *testing.T) {
+		_, err := q.ExecutePaginated(ctx, req, 0, "")
+		require.ErrorIs(t, err, condition.ErrEvaluationFailed)
+	})
+
+	t.Run("returned_if_the_page_is_not_full", func(t *testing.T) {
+		_, err := q.ExecutePaginated(ctx, req, 2, "")
+		require.ErrorIs(t, err, condition.ErrEvaluationFailed)
+	})
+
+	t.Run("not_returned_if_the_page_is_full", func(t *testing.T) {
+		resp, err := q.ExecutePaginated(ctx, req, 1, "")
+		require.NoError(t, err)
+		require.Equal(t, []string{"document:1"}, resp.Objects)
+	})
+}
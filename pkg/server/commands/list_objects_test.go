@@ -2,10 +2,14 @@ package commands
 
 import (
 	"context"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
 	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
 
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 
@@ -225,3 +229,110 @@ func TestListObjectsDispatchCount(t *testing.T) {
 		})
 	}
 }
+
+type recordingStreamServer struct {
+	grpc.ServerStream
+	ctx     context.Context
+	objects []string
+}
+
+func (r *recordingStreamServer) Context() context.Context {
+	return r.ctx
+}
+
+func (r *recordingStreamServer) Send(resp *openfgav1.StreamedListObjectsResponse) error {
+	r.objects = append(r.objects, resp.GetObject())
+	return nil
+}
+
+func TestListObjectsExecuteStreamed(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	var tuples []string
+	var expected []string
+	for i := 0; i < 30; i++ {
+		object := fmt.Sprintf("document:%02d", i)
+		tuples = append(tuples, object+"#viewer@user:jon", object+"#editor@user:jon")
+		expected = append(expected, object)
+	}
+
+	storeID, model := storagetest.BootstrapFGAStore(t, ds, `
+		model
+			schema 1.1
+
+		type user
+
+		type document
+			relations
+				define editor: [user]
+				define viewer: [user] or editor`, tuples)
+
+	typesys, err := typesystem.NewAndValidate(context.Background(), model)
+	require.NoError(t, err)
+
+	ctx := typesystem.ContextWithTypesystem(context.Background(), typesys)
+
+	checker := graph.NewLocalCheckerWithCycleDetection()
+	t.Cleanup(checker.Close)
+
+	req := &openfgav1.StreamedListObjectsRequest{
+		StoreId:              storeID,
+		AuthorizationModelId: model.GetId(),
+		Type:                 "document",
+		Relation:             "viewer",
+		User:                 "user:jon",
+	}
+
+	t.Run("deduplication", func(t *testing.T) {
+		q, err := NewListObjectsQuery(ds, checker, WithStreamedDeduplication(true))
+		require.NoError(t, err)
+
+		srv := &recordingStreamServer{ctx: ctx}
+		resolutionMetadata, err := q.ExecuteStreamed(ctx, req, srv)
+		require.NoError(t, err)
+		require.ElementsMatch(t, expected, srv.objects)
+		require.False(t, resolutionMetadata.WasDeadlineExceeded.Load())
+		require.False(t, resolutionMetadata.WasMaxResultsReached.Load())
+	})
+
+	t.Run("sorting", func(t *testing.T) {
+		q, err := NewListObjectsQuery(ds, checker, WithStreamedSorting(true))
+		require.NoError(t, err)
+
+		srv := &recordingStreamServer{ctx: ctx}
+		_, err = q.ExecuteStreamed(ctx, req, srv)
+		require.NoError(t, err)
+		require.Equal(t, expected, srv.objects)
+	})
+
+	t.Run("sorting_bounded_by_max_results", func(t *testing.T) {
+		q, err := NewListObjectsQuery(ds, checker, WithStreamedSorting(true), WithListObjectsMaxResults(5))
+		require.NoError(t, err)
+
+		srv := &recordingStreamServer{ctx: ctx}
+		resolutionMetadata, err := q.ExecuteStreamed(ctx, req, srv)
+		require.NoError(t, err)
+		require.True(t, resolutionMetadata.WasMaxResultsReached.Load())
+		require.Len(t, srv.objects, 5)
+		require.IsIncreasing(t, srv.objects)
+		require.Subset(t, expected, srv.objects)
+	})
+
+	t.Run("deadline_exceeded", func(t *testing.T) {
+		slowDatastore := mocks.NewMockSlowDataStorage(ds, 50*time.Millisecond)
+
+		q, err := NewListObjectsQuery(slowDatastore, checker, WithListObjectsDeadline(10*time.Millisecond), WithStreamedSorting(true))
+		require.NoError(t, err)
+
+		srv := &recordingStreamServer{ctx: ctx}
+		resolutionMetadata, err := q.ExecuteStreamed(ctx, req, srv)
+		require.NoError(t, err)
+		require.Empty(t, srv.objects)
+		require.True(t, resolutionMetadata.WasDeadlineExceeded.Load())
+	})
+}
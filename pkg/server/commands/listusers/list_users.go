@@ -34,6 +34,9 @@ type internalListUsersRequest struct {
 	datastoreQueryCount *atomic.Uint32
 
 	dispatchCount *atomic.Uint32
+
+	// wasThrottled indicates whether any dispatch of the traversal was throttled.
+	wasThrottled *atomic.Bool
 }
 
 var _ listUsersRequest = (*internalListUsersRequest)(nil)
@@ -114,6 +117,16 @@ type listUsersResponseMetadata struct {
 	// The number of times we are recursively expanding to find users.
 	// Atomic is used to be consistent with the Check and ListObjects.
 	DispatchCounter *atomic.Uint32
+
+	// WasThrottled indicates whether the request was throttled.
+	WasThrottled bool
+
+	// WasDeadlineExceeded indicates whether the deadline was hit before all users were found.
+	WasDeadlineExceeded bool
+
+	// WasMaxResultsReached indicates whether the maximum number of results was reached while there
+	// were possibly more users to be found.
+	WasMaxResultsReached bool
 }
 
 func (r *listUsersResponse) GetUsers() []*openfgav1.User {
@@ -130,13 +143,16 @@ func (r *listUsersResponse) GetMetadata() listUsersResponseMetadata {
 	return r.Metadata
 }
 
-func fromListUsersRequest(o listUsersRequest, datastoreQueryCount *atomic.Uint32, dispatchCount *atomic.Uint32) *internalListUsersRequest {
+func fromListUsersRequest(o listUsersRequest, datastoreQueryCount *atomic.Uint32, dispatchCount *atomic.Uint32, wasThrottled *atomic.Bool) *internalListUsersRequest {
 	if datastoreQueryCount == nil {
 		datastoreQueryCount = new(atomic.Uint32)
 	}
 	if dispatchCount == nil {
 		dispatchCount = new(atomic.Uint32)
 	}
+	if wasThrottled == nil {
+		wasThrottled = new(atomic.Bool)
+	}
 	return &internalListUsersRequest{
 		ListUsersRequest: &openfgav1.ListUsersRequest{
 			StoreId:              o.GetStoreId(),
@@ -151,12 +167,13 @@ func fromListUsersRequest(o listUsersRequest, datastoreQueryCount *atomic.Uint32
 		depth:               0,
 		datastoreQueryCount: datastoreQueryCount,
 		dispatchCount:       dispatchCount,
+		wasThrottled:        wasThrottled,
 	}
 }
 
 // clone creates a copy of the request. Note that some fields are not deep-cloned.
 func (r *internalListUsersRequest) clone() *internalListUsersRequest {
-	v := fromListUsersRequest(r, r.datastoreQueryCount, r.dispatchCount)
+	v := fromListUsersRequest(r, r.datastoreQueryCount, r.dispatchCount, r.wasThrottled)
 	v.visitedUsersetsMap = maps.Clone(r.visitedUsersetsMap)
 	v.depth = r.depth
 	return v
@@ -12,6 +12,8 @@ import (
 	"github.com/sourcegraph/conc/pool"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
 
 	serverconfig "github.com/openfga/openfga/internal/server/config"
 
@@ -24,6 +26,8 @@ import (
 	"github.com/openfga/openfga/internal/condition"
 	"github.com/openfga/openfga/internal/condition/eval"
 	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/internal/throttler"
+	"github.com/openfga/openfga/internal/throttler/threshold"
 	"github.com/openfga/openfga/internal/validation"
 	"github.com/openfga/openfga/pkg/storage"
 	"github.com/openfga/openfga/pkg/tuple"
@@ -41,6 +45,8 @@ type listUsersQuery struct {
 	maxResults              uint32
 	maxConcurrentReads      uint32
 	deadline                time.Duration
+	dispatchThrottlerConfig threshold.Config
+	expandUsersets          bool
 }
 
 type expandResponse struct {
@@ -118,6 +124,23 @@ func WithListUsersMaxConcurrentReads(limit uint32) ListUsersQueryOption {
 	}
 }
 
+// WithDispatchThrottlerConfig see server.WithListUsersDispatchThrottlingEnabled.
+func WithDispatchThrottlerConfig(config threshold.Config) ListUsersQueryOption {
+	return func(d *listUsersQuery) {
+		d.dispatchThrottlerConfig = config
+	}
+}
+
+// WithListUsersExpandUsersets sets whether the userset filters of a request (e.g. 'group#member') are replaced by the
+// user types which their usersets may contain that are not usersets themselves (e.g. 'user'), such that those users
+// (e.g. 'user:jon' or 'user:*') are returned rather than the usersets (e.g. 'group:eng#member'). The users of those
+// types are returned whether they have the relation through a matching userset or otherwise, e.g. directly.
+func WithListUsersExpandUsersets(expand bool) ListUsersQueryOption {
+	return func(d *listUsersQuery) {
+		d.expandUsersets = expand
+	}
+}
+
 // NewListUsersQuery is not meant to be shared.
 func NewListUsersQuery(ds storage.RelationshipTupleReader, opts ...ListUsersQueryOption) *listUsersQuery {
 	l := &listUsersQuery{
@@ -136,6 +159,12 @@ func NewListUsersQuery(ds storage.RelationshipTupleReader, opts ...ListUsersQuer
 		deadline:                serverconfig.DefaultListUsersDeadline,
 		maxResults:              serverconfig.DefaultListUsersMaxResults,
 		maxConcurrentReads:      serverconfig.DefaultMaxConcurrentReadsForListUsers,
+		dispatchThrottlerConfig: threshold.Config{
+			Throttler:    throttler.NewNoopThrottler(),
+			Enabled:      serverconfig.DefaultListUsersDispatchThrottlingEnabled,
+			Threshold:    serverconfig.DefaultListUsersDispatchThrottlingDefaultThreshold,
+			MaxThreshold: serverconfig.DefaultListUsersDispatchThrottlingMaxThreshold,
+		},
 	}
 
 	for _, opt := range opts {
@@ -153,6 +182,98 @@ func (l *listUsersQuery) ListUsers(
 	ctx, span := tracer.Start(ctx, "ListUsers")
 	defer span.End()
 
+	foundUsersUnique := make(map[tuple.UserString]foundUser, 1000)
+
+	metadata, err := l.execute(ctx, req, func(foundUser foundUser) (bool, error) {
+		foundUserKey := tuple.UserProtoToString(foundUser.user)
+		if _, ok := foundUsersUnique[foundUserKey]; !ok && l.maxResults > 0 && uint32(len(foundUsersUnique)) >= l.maxResults {
+			// the results are only truncated once a user beyond the maximum number of results has the relation
+			return foundUser.relationshipStatus != NoRelationship, nil
+		}
+
+		foundUsersUnique[foundUserKey] = foundUser
+
+		return false, nil
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, err
+	}
+
+	foundUsers := make([]*openfgav1.User, 0, len(foundUsersUnique))
+	for foundUserKey, foundUser := range foundUsersUnique {
+		if foundUser.relationshipStatus == NoRelationship {
+			continue
+		}
+
+		foundUsers = append(foundUsers, tuple.StringToUserProto(foundUserKey))
+	}
+
+	span.SetAttributes(attribute.Int("result_count", len(foundUsers)))
+
+	return &listUsersResponse{
+		Users:    foundUsers,
+		Metadata: metadata,
+	}, nil
+}
+
+// StreamedListUsers is the streaming variant of ListUsers: rather than responding once all users are found, it
+// sends each user as soon as it is found to have the relation, and never sends users that are excluded from it.
+// Each user is sent at most once, and the same maximum number of results and deadline as ListUsers apply.
+//
+// StreamedListUsers assumes that the typesystem is in the context and that the request is valid.
+func (l *listUsersQuery) StreamedListUsers(
+	ctx context.Context,
+	req *openfgav1.ListUsersRequest,
+	send func(*openfgav1.User) error,
+) (*listUsersResponseMetadata, error) {
+	ctx, span := tracer.Start(ctx, "StreamedListUsers")
+	defer span.End()
+
+	sentUsers := make(map[tuple.UserString]struct{}, 1000)
+
+	metadata, err := l.execute(ctx, req, func(foundUser foundUser) (bool, error) {
+		if foundUser.relationshipStatus == NoRelationship {
+			return false, nil
+		}
+
+		foundUserKey := tuple.UserProtoToString(foundUser.user)
+		if _, ok := sentUsers[foundUserKey]; ok {
+			return false, nil
+		}
+
+		if l.maxResults > 0 && uint32(len(sentUsers)) >= l.maxResults {
+			// the results are only truncated once a user beyond the maximum number of results is found
+			return true, nil
+		}
+		sentUsers[foundUserKey] = struct{}{}
+
+		if err := send(foundUser.user); err != nil {
+			return false, err
+		}
+
+		return false, nil
+	})
+	if err != nil {
+		telemetry.TraceError(span, err)
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("result_count", len(sentUsers)))
+
+	return &metadata, nil
+}
+
+// execute expands the users that have the relation of the request with its object, calling handleFoundUser with each
+// user as it is found until all of them are found, the deadline is exceeded, or handleFoundUser returns true (i.e. a
+// user beyond the maximum number of results was found) or an error. handleFoundUser is never called concurrently, nor after execute returns.
+func (l *listUsersQuery) execute(
+	ctx context.Context,
+	req *openfgav1.ListUsersRequest,
+	handleFoundUser func(foundUser) (bool, error),
+) (listUsersResponseMetadata, error) {
+	span := trace.SpanFromContext(ctx)
+
 	cancellableCtx, cancelCtx := context.WithCancel(ctx)
 	if l.deadline != 0 {
 		cancellableCtx, cancelCtx = context.WithTimeout(cancellableCtx, l.deadline)
@@ -166,47 +287,64 @@ func (l *listUsersQuery) ListUsers(
 	)
 	typesys, ok := typesystem.TypesystemFromContext(cancellableCtx)
 	if !ok {
-		return nil, fmt.Errorf("typesystem missing in context")
+		return listUsersResponseMetadata{}, fmt.Errorf("typesystem missing in context")
+	}
+
+	if l.expandUsersets {
+		userFilters, err := expandUsersetFilters(typesys, req.GetUserFilters())
+		if err != nil {
+			return listUsersResponseMetadata{}, err
+		}
+
+		req = proto.Clone(req).(*openfgav1.ListUsersRequest)
+		req.UserFilters = userFilters
 	}
 
-	userFilter := req.GetUserFilters()[0]
-	isReflexiveUserset := userFilter.GetType() == req.GetObject().GetType() && userFilter.GetRelation() == req.GetRelation()
+	isReflexiveUserset := false
+	for _, userFilter := range req.GetUserFilters() {
+		if userFilter.GetType() == req.GetObject().GetType() && userFilter.GetRelation() == req.GetRelation() {
+			isReflexiveUserset = true
+			break
+		}
+	}
 
 	if !isReflexiveUserset {
 		hasPossibleEdges, err := doesHavePossibleEdges(typesys, req)
 		if err != nil {
-			return nil, err
+			return listUsersResponseMetadata{}, err
 		}
 		if !hasPossibleEdges {
 			span.SetAttributes(attribute.Bool("no_possible_edges", true))
-			return &listUsersResponse{
-				Users: []*openfgav1.User{},
-				Metadata: listUsersResponseMetadata{
-					DatastoreQueryCount: 0,
-					DispatchCounter:     new(atomic.Uint32),
-				},
+			return listUsersResponseMetadata{
+				DatastoreQueryCount: 0,
+				DispatchCounter:     new(atomic.Uint32),
 			}, nil
 		}
 	}
 
 	datastoreQueryCount := atomic.Uint32{}
 	dispatchCount := atomic.Uint32{}
+	wasThrottled := atomic.Bool{}
 
 	foundUsersCh := l.buildResultsChannel()
 	expandErrCh := make(chan error, 1)
 
-	foundUsersUnique := make(map[tuple.UserString]foundUser, 1000)
+	var maxResultsReached bool
+	var handleErr error
 
 	doneWithFoundUsersCh := make(chan struct{}, 1)
 	go func() {
 		for foundUser := range foundUsersCh {
-			foundUsersUnique[tuple.UserProtoToString(foundUser.user)] = foundUser
+			stop, err := handleFoundUser(foundUser)
+			if err != nil {
+				handleErr = err
+				break
+			}
 
-			if l.maxResults > 0 {
-				if uint32(len(foundUsersUnique)) >= l.maxResults {
-					span.SetAttributes(attribute.Bool("max_results_found", true))
-					break
-				}
+			if stop {
+				span.SetAttributes(attribute.Bool("max_results_found", true))
+				maxResultsReached = true
+				break
 			}
 		}
 
@@ -214,7 +352,7 @@ func (l *listUsersQuery) ListUsers(
 	}()
 
 	go func() {
-		internalRequest := fromListUsersRequest(req, &datastoreQueryCount, &dispatchCount)
+		internalRequest := fromListUsersRequest(req, &datastoreQueryCount, &dispatchCount, &wasThrottled)
 		resp := l.expand(cancellableCtx, internalRequest, foundUsersCh)
 		// first send error and then close results channel, to ensure that error takes precedence
 		if resp.err != nil {
@@ -226,52 +364,87 @@ func (l *listUsersQuery) ListUsers(
 	select {
 	// Note: if all cases can proceed, one will be selected at random
 	case err := <-expandErrCh:
-		telemetry.TraceError(span, err)
-		return nil, err
+		// to avoid calling handleFoundUser after returning, wait for the range over the channel to close
+		cancelCtx()
+		<-doneWithFoundUsersCh
+		return listUsersResponseMetadata{}, err
 	case <-doneWithFoundUsersCh:
 		break
 	case <-cancellableCtx.Done():
-		// to avoid a race on the 'foundUsersUnique' map below, wait for the range over the channel to close
+		// to avoid a race on the results of handleFoundUser, wait for the range over the channel to close
 		<-doneWithFoundUsersCh
 		break
 	}
 
+	if handleErr != nil {
+		return listUsersResponseMetadata{}, handleErr
+	}
+
+	deadlineExceeded := errors.Is(cancellableCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil
+
 	cancelCtx()
 
-	foundUsers := make([]*openfgav1.User, 0, len(foundUsersUnique))
-	for foundUserKey, foundUser := range foundUsersUnique {
-		if foundUser.relationshipStatus == NoRelationship {
+	return listUsersResponseMetadata{
+		DatastoreQueryCount:  datastoreQueryCount.Load(),
+		DispatchCounter:      &dispatchCount,
+		WasThrottled:         wasThrottled.Load(),
+		WasDeadlineExceeded:  deadlineExceeded,
+		WasMaxResultsReached: maxResultsReached,
+	}, nil
+}
+
+// expandUsersetFilters returns the user filters with each userset filter (e.g. 'group#member') replaced by filters of
+// the terminal user types of the userset (e.g. 'user'), without duplicates.
+func expandUsersetFilters(typesys *typesystem.TypeSystem, userFilters []*openfgav1.UserTypeFilter) ([]*openfgav1.UserTypeFilter, error) {
+	expandedUserFilters := make([]*openfgav1.UserTypeFilter, 0, len(userFilters))
+	seen := make(map[string]struct{}, len(userFilters))
+
+	addTypeFilter := func(userType string) {
+		if _, ok := seen[userType]; ok {
+			return
+		}
+		seen[userType] = struct{}{}
+		expandedUserFilters = append(expandedUserFilters, &openfgav1.UserTypeFilter{Type: userType})
+	}
+
+	for _, userFilter := range userFilters {
+		if userFilter.GetRelation() == "" {
+			addTypeFilter(userFilter.GetType())
 			continue
 		}
 
-		foundUsers = append(foundUsers, tuple.StringToUserProto(foundUserKey))
-	}
+		terminalTypes, err := typesys.TerminalUserTypes(userFilter.GetType(), userFilter.GetRelation())
+		if err != nil {
+			return nil, err
+		}
 
-	span.SetAttributes(attribute.Int("result_count", len(foundUsers)))
+		for _, terminalType := range terminalTypes {
+			addTypeFilter(terminalType)
+		}
+	}
 
-	return &listUsersResponse{
-		Users: foundUsers,
-		Metadata: listUsersResponseMetadata{
-			DatastoreQueryCount: datastoreQueryCount.Load(),
-			DispatchCounter:     &dispatchCount,
-		},
-	}, nil
+	return expandedUserFilters, nil
 }
 
 func doesHavePossibleEdges(typesys *typesystem.TypeSystem, req *openfgav1.ListUsersRequest) (bool, error) {
 	g := graph.New(typesys)
 
-	userFilters := req.GetUserFilters()
-
-	source := typesystem.DirectRelationReference(userFilters[0].GetType(), userFilters[0].GetRelation())
 	target := typesystem.DirectRelationReference(req.GetObject().GetType(), req.GetRelation())
 
-	edges, err := g.GetPrunedRelationshipEdges(target, source)
-	if err != nil {
-		return false, err
+	for _, userFilter := range req.GetUserFilters() {
+		source := typesystem.DirectRelationReference(userFilter.GetType(), userFilter.GetRelation())
+
+		edges, err := g.GetPrunedRelationshipEdges(target, source)
+		if err != nil {
+			return false, err
+		}
+
+		if len(edges) > 0 {
+			return true, nil
+		}
 	}
 
-	return len(edges) > 0, err
+	return false, nil
 }
 
 func (l *listUsersQuery) dispatch(
@@ -279,10 +452,33 @@ func (l *listUsersQuery) dispatch(
 	req *internalListUsersRequest,
 	foundUsersChan chan<- foundUser,
 ) expandResponse {
-	req.dispatchCount.Add(1)
+	newcount := req.dispatchCount.Add(1)
+	if l.dispatchThrottlerConfig.Enabled {
+		l.throttle(ctx, newcount, req.wasThrottled)
+	}
 	return l.expand(ctx, req, foundUsersChan)
 }
 
+func (l *listUsersQuery) throttle(ctx context.Context, currentNumDispatch uint32, wasThrottled *atomic.Bool) {
+	span := trace.SpanFromContext(ctx)
+
+	shouldThrottle := threshold.ShouldThrottle(
+		ctx,
+		currentNumDispatch,
+		l.dispatchThrottlerConfig.Threshold,
+		l.dispatchThrottlerConfig.MaxThreshold,
+	)
+
+	span.SetAttributes(
+		attribute.Int("dispatch_count", int(currentNumDispatch)),
+		attribute.Bool("is_throttled", shouldThrottle))
+
+	if shouldThrottle {
+		wasThrottled.Store(true)
+		l.dispatchThrottlerConfig.Throttler.Throttle(ctx)
+	}
+}
+
 func (l *listUsersQuery) expand(
 	ctx context.Context,
 	req *internalListUsersRequest,
@@ -460,12 +656,13 @@ LoopOnIterator:
 
 		if userRelation == "" {
 			for _, f := range req.GetUserFilters() {
-				if f.GetType() == userObjectType {
+				if f.GetType() == userObjectType && f.GetRelation() == "" {
 					user := tuple.StringToUserProto(tuple.BuildObject(userObjectType, userObjectID))
 
 					trySendResult(ctx, foundUser{
 						user: user,
 					}, foundUsersChan)
+					break
 				}
 			}
 			continue
@@ -533,8 +730,7 @@ func (l *listUsersQuery) expandIntersection(
 	var wg sync.WaitGroup
 	wg.Add(len(childOperands))
 
-	wildcardCount := atomic.Uint32{}
-	wildcardKey := tuple.TypedPublicWildcard(req.GetUserFilters()[0].GetType())
+	wildcardCountMap := make(map[string]uint32, 0)
 	foundUsersCountMap := make(map[string]uint32, 0)
 	excludedUsersMap := make(map[string]struct{}, 0)
 	for _, foundUsersChan := range intersectionFoundUsersChans {
@@ -555,19 +751,21 @@ func (l *listUsersQuery) expandIntersection(
 				foundUsersMap[key]++
 			}
 
-			_, wildcardExists := foundUsersMap[wildcardKey]
-			if wildcardExists {
-				wildcardCount.Add(1)
-			}
 			for userKey := range foundUsersMap {
 				mu.Lock()
+				if tuple.IsTypedWildcard(userKey) {
+					wildcardCountMap[userKey]++
+				}
+
 				// Increment the count for a user but decrement if a wildcard
-				// also exists to prevent double counting. This ensures accurate
-				// tracking for intersection criteria, avoiding inflated counts
-				// when both a user and a wildcard are present.
+				// of its type also exists to prevent double counting. This ensures
+				// accurate tracking for intersection criteria, avoiding inflated
+				// counts when both a user and a wildcard are present.
 				foundUsersCountMap[userKey]++
-				if wildcardExists {
-					foundUsersCountMap[userKey]--
+				if wildcardKey, ok := userWildcardKey(userKey); ok {
+					if _, wildcardExists := foundUsersMap[wildcardKey]; wildcardExists {
+						foundUsersCountMap[userKey]--
+					}
 				}
 				mu.Unlock()
 			}
@@ -582,10 +780,15 @@ func (l *listUsersQuery) expandIntersection(
 
 	for key, count := range foundUsersCountMap {
 		// Compare the number of times the specific user was returned for
-		// all intersection operands plus the number of wildcards.
+		// all intersection operands plus the number of wildcards of its type.
 		// If this summed value equals the number of operands, the user satisfies
 		// the intersection expression and can be sent on `foundUsersChan`
-		if (count + wildcardCount.Load()) == uint32(len(childOperands)) {
+		var wildcardCount uint32
+		if wildcardKey, ok := userWildcardKey(key); ok {
+			wildcardCount = wildcardCountMap[wildcardKey]
+		}
+
+		if (count + wildcardCount) == uint32(len(childOperands)) {
 			fu := foundUser{
 				user:          tuple.StringToUserProto(key),
 				excludedUsers: excludedUsers,
@@ -735,14 +938,16 @@ func (l *listUsersQuery) expandExclusion(
 		}
 	}
 
-	wildcardKey := tuple.TypedPublicWildcard(req.GetUserFilters()[0].GetType())
-
-	_, baseWildcardExists := baseFoundUsersMap[wildcardKey]
-	_, subtractWildcardExists := subtractFoundUsersMap[wildcardKey]
-
 	for userKey, fu := range baseFoundUsersMap {
 		subtractedUser, userIsSubtracted := subtractFoundUsersMap[userKey]
-		_, wildcardSubtracted := subtractFoundUsersMap[wildcardKey]
+
+		// wildcards only apply to users of their type, and never to usersets
+		wildcardKey, hasWildcardKey := userWildcardKey(userKey)
+		_, baseWildcardExists := baseFoundUsersMap[wildcardKey]
+		_, subtractWildcardExists := subtractFoundUsersMap[wildcardKey]
+		baseWildcardExists = baseWildcardExists && hasWildcardKey
+		subtractWildcardExists = subtractWildcardExists && hasWildcardKey
+		wildcardSubtracted := subtractWildcardExists
 
 		switch {
 		case baseWildcardExists:
@@ -753,6 +958,10 @@ func (l *listUsersQuery) expandExclusion(
 			}
 
 			for subtractedUserKey, subtractedFu := range subtractFoundUsersMap {
+				if subtractedWildcardKey, ok := userWildcardKey(subtractedUserKey); !ok || subtractedWildcardKey != wildcardKey {
+					continue
+				}
+
 				if tuple.IsTypedWildcard(subtractedUserKey) {
 					if !userIsSubtracted {
 						trySendResult(ctx, foundUser{
@@ -908,6 +1117,21 @@ LoopOnIterator:
 	}
 }
 
+// userWildcardKey returns the key of the wildcard of the type of the user with the given key (e.g. 'user:*' for
+// 'user:jon' or 'user:*'), or false if the user is a userset, to which wildcards do not apply.
+func userWildcardKey(userKey string) (string, bool) {
+	if tuple.IsTypedWildcard(userKey) {
+		return userKey, true
+	}
+
+	userObject, userRelation := tuple.SplitObjectRelation(userKey)
+	if userRelation != "" {
+		return "", false
+	}
+
+	return tuple.TypedPublicWildcard(tuple.GetType(userObject)), true
+}
+
 func enteredCycle(req *internalListUsersRequest) bool {
 	key := fmt.Sprintf("%s#%s", tuple.ObjectKey(req.GetObject()), req.Relation)
 	if _, loaded := req.visitedUsersetsMap[key]; loaded {
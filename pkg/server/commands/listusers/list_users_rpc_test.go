@@ -18,6 +18,7 @@ import (
 
 	"github.com/openfga/openfga/internal/graph"
 	"github.com/openfga/openfga/internal/mocks"
+	"github.com/openfga/openfga/internal/throttler/threshold"
 
 	"github.com/openfga/openfga/pkg/storage/memory"
 	"github.com/openfga/openfga/pkg/storage/storagewrappers"
@@ -2972,6 +2973,21 @@ func (testCases ListUsersTests) runListUsersTestCases(t *testing.T) {
 				actualCompare[i] = tuple.UserProtoToString(u)
 			}
 			require.ElementsMatch(t, actualCompare, test.expectedUsers)
+
+			var streamedUsers []string
+			_, err = NewListUsersQuery(ds, WithResolveNodeLimit(maximumRecursiveDepth)).StreamedListUsers(ctx, test.req, func(user *openfgav1.User) error {
+				streamedUsers = append(streamedUsers, tuple.UserProtoToString(user))
+				return nil
+			})
+
+			actualErrorMsg = ""
+			if err != nil {
+				actualErrorMsg = err.Error()
+			}
+			require.Equal(t, test.expectedErrorMsg, actualErrorMsg)
+			if err == nil {
+				require.ElementsMatch(t, streamedUsers, test.expectedUsers)
+			}
 		})
 	}
 }
@@ -3813,3 +3829,436 @@ func TestListUsers_ExpandExclusionHandler(t *testing.T) {
 		}, actualResults)
 	})
 }
+
+func TestStreamedListUsers(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID, model := storagetest.BootstrapFGAStore(t, ds, `
+		model
+			schema 1.1
+
+		type user
+
+		type document
+			relations
+				define blocked: [user]
+				define allowed: [user, user:*]
+				define viewer: allowed but not blocked`, []string{
+		"document:1#allowed@user:jon",
+		"document:1#allowed@user:maria",
+		"document:1#allowed@user:will",
+		"document:1#blocked@user:maria",
+		"document:2#allowed@user:*",
+		"document:2#blocked@user:jon",
+	})
+
+	typesys, err := typesystem.NewAndValidate(context.Background(), model)
+	require.NoError(t, err)
+
+	ctx := typesystem.ContextWithTypesystem(context.Background(), typesys)
+
+	newRequest := func(objectID string) *openfgav1.ListUsersRequest {
+		return &openfgav1.ListUsersRequest{
+			StoreId:              storeID,
+			AuthorizationModelId: model.GetId(),
+			Object:               &openfgav1.Object{Type: "document", Id: objectID},
+			Relation:             "viewer",
+			UserFilters:          []*openfgav1.UserTypeFilter{{Type: "user"}},
+		}
+	}
+
+	t.Run("excluded_users_are_never_sent", func(t *testing.T) {
+		for objectID, expectedUsers := range map[string][]string{
+			"1": {"user:jon", "user:will"},
+			"2": {"user:*"},
+		} {
+			var sentUsers []string
+			metadata, err := NewListUsersQuery(ds).StreamedListUsers(ctx, newRequest(objectID), func(user *openfgav1.User) error {
+				sentUsers = append(sentUsers, tuple.UserProtoToString(user))
+				return nil
+			})
+			require.NoError(t, err)
+			require.ElementsMatch(t, expectedUsers, sentUsers)
+			require.False(t, metadata.WasMaxResultsReached)
+			require.False(t, metadata.WasDeadlineExceeded)
+		}
+	})
+
+	t.Run("max_results", func(t *testing.T) {
+		var sentUsers []string
+		metadata, err := NewListUsersQuery(ds, WithListUsersMaxResults(1)).StreamedListUsers(ctx, newRequest("1"), func(user *openfgav1.User) error {
+			sentUsers = append(sentUsers, tuple.UserProtoToString(user))
+			return nil
+		})
+		require.NoError(t, err)
+		require.Len(t, sentUsers, 1)
+		require.Subset(t, []string{"user:jon", "user:will"}, sentUsers)
+		require.True(t, metadata.WasMaxResultsReached)
+	})
+
+	t.Run("max_results_reached_exactly", func(t *testing.T) {
+		var sentUsers []string
+		metadata, err := NewListUsersQuery(ds, WithListUsersMaxResults(2)).StreamedListUsers(ctx, newRequest("1"), func(user *openfgav1.User) error {
+			sentUsers = append(sentUsers, tuple.UserProtoToString(user))
+			return nil
+		})
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"user:jon", "user:will"}, sentUsers)
+		require.False(t, metadata.WasMaxResultsReached)
+	})
+
+	t.Run("send_error", func(t *testing.T) {
+		sendErr := fmt.Errorf("stream closed")
+
+		var sendCount int
+		_, err := NewListUsersQuery(ds).StreamedListUsers(ctx, newRequest("1"), func(user *openfgav1.User) error {
+			sendCount++
+			return sendErr
+		})
+		require.ErrorIs(t, err, sendErr)
+		require.Equal(t, 1, sendCount)
+	})
+
+	t.Run("deadline_exceeded", func(t *testing.T) {
+		slowDatastore := mocks.NewMockSlowDataStorage(ds, 50*time.Millisecond)
+
+		var sentUsers []string
+		metadata, err := NewListUsersQuery(slowDatastore, WithListUsersDeadline(10*time.Millisecond)).StreamedListUsers(ctx, newRequest("1"), func(user *openfgav1.User) error {
+			sentUsers = append(sentUsers, tuple.UserProtoToString(user))
+			return nil
+		})
+		require.NoError(t, err)
+		require.Empty(t, sentUsers)
+		require.True(t, metadata.WasDeadlineExceeded)
+	})
+}
+
+func TestListUsersDispatchThrottling(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID, model := storagetest.BootstrapFGAStore(t, ds, `
+		model
+			schema 1.1
+
+		type user
+
+		type group
+			relations
+				define member: [user, group#member]
+
+		type document
+			relations
+				define viewer: [group#member]`, []string{
+		"document:1#viewer@group:1#member",
+		"group:1#member@group:2#member",
+		"group:2#member@group:3#member",
+		"group:3#member@user:jon",
+	})
+
+	typesys, err := typesystem.NewAndValidate(context.Background(), model)
+	require.NoError(t, err)
+
+	ctx := typesystem.ContextWithTypesystem(context.Background(), typesys)
+
+	req := &openfgav1.ListUsersRequest{
+		StoreId:              storeID,
+		AuthorizationModelId: model.GetId(),
+		Object:               &openfgav1.Object{Type: "document", Id: "1"},
+		Relation:             "viewer",
+		UserFilters:          []*openfgav1.UserTypeFilter{{Type: "user"}},
+	}
+
+	tests := []struct {
+		name                  string
+		enabled               bool
+		threshold             uint32
+		expectedThrottleCalls int
+	}{
+		{
+			name:                  "disabled",
+			enabled:               false,
+			threshold:             1,
+			expectedThrottleCalls: 0,
+		},
+		{
+			name:                  "dispatches_below_threshold",
+			enabled:               true,
+			threshold:             100,
+			expectedThrottleCalls: 0,
+		},
+		{
+			name:                  "dispatches_above_threshold",
+			enabled:               true,
+			threshold:             1,
+			expectedThrottleCalls: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			t.Cleanup(ctrl.Finish)
+
+			mockThrottler := mocks.NewMockThrottler(ctrl)
+			mockThrottler.EXPECT().Throttle(gomock.Any()).Times(test.expectedThrottleCalls)
+
+			resp, err := NewListUsersQuery(ds, WithDispatchThrottlerConfig(threshold.Config{
+				Throttler: mockThrottler,
+				Enabled:   test.enabled,
+				Threshold: test.threshold,
+			})).ListUsers(ctx, req)
+			require.NoError(t, err)
+			require.Equal(t, []*openfgav1.User{{User: &openfgav1.User_Object{Object: &openfgav1.Object{Type: "user", Id: "jon"}}}}, resp.GetUsers())
+			require.Equal(t, uint32(3), resp.GetMetadata().DispatchCounter.Load())
+			require.Equal(t, test.expectedThrottleCalls > 0, resp.GetMetadata().WasThrottled)
+		})
+	}
+}
+
+func TestListUsersMultipleUserFilters(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	model := `
+		model
+			schema 1.1
+
+		type user
+
+		type employee
+
+		type group
+			relations
+				define member: [user, employee, group#member]
+
+		type document
+			relations
+				define blocked: [user, employee]
+				define allowed: [user, user:*, employee, employee:*, group#member]
+				define viewer: allowed but not blocked
+				define reader: [user, user:*, employee, group#member]
+				define shared: allowed and reader
+				define owner: [user, group]`
+
+	tuples := []*openfgav1.TupleKey{
+		tuple.NewTupleKey("group:eng", "member", "user:maria"),
+		tuple.NewTupleKey("group:eng", "member", "employee:bob"),
+		tuple.NewTupleKey("group:fga", "member", "group:eng#member"),
+		tuple.NewTupleKey("document:1", "allowed", "user:jon"),
+		tuple.NewTupleKey("document:1", "allowed", "employee:alice"),
+		tuple.NewTupleKey("document:1", "allowed", "group:fga#member"),
+		tuple.NewTupleKey("document:1", "blocked", "employee:alice"),
+		tuple.NewTupleKey("document:2", "allowed", "user:*"),
+		tuple.NewTupleKey("document:2", "allowed", "employee:alice"),
+		tuple.NewTupleKey("document:2", "blocked", "user:jon"),
+		tuple.NewTupleKey("document:2", "reader", "employee:alice"),
+		tuple.NewTupleKey("document:2", "reader", "user:will"),
+		tuple.NewTupleKey("document:3", "allowed", "employee:*"),
+		tuple.NewTupleKey("document:3", "reader", "user:*"),
+		tuple.NewTupleKey("document:3", "reader", "employee:bob"),
+		tuple.NewTupleKey("document:4", "owner", "user:jon"),
+		tuple.NewTupleKey("document:4", "owner", "group:eng"),
+	}
+
+	tests := ListUsersTests{
+		{
+			name: "users_and_usersets",
+			req: &openfgav1.ListUsersRequest{
+				Object:   &openfgav1.Object{Type: "document", Id: "1"},
+				Relation: "allowed",
+				UserFilters: []*openfgav1.UserTypeFilter{
+					{Type: "user"},
+					{Type: "group", Relation: "member"},
+				},
+			},
+			model:         model,
+			tuples:        tuples,
+			expectedUsers: []string{"user:jon", "user:maria", "group:fga#member", "group:eng#member"},
+		},
+		{
+			name: "users_of_several_types",
+			req: &openfgav1.ListUsersRequest{
+				Object:   &openfgav1.Object{Type: "document", Id: "1"},
+				Relation: "allowed",
+				UserFilters: []*openfgav1.UserTypeFilter{
+					{Type: "user"},
+					{Type: "employee"},
+				},
+			},
+			model:         model,
+			tuples:        tuples,
+			expectedUsers: []string{"user:jon", "user:maria", "employee:alice", "employee:bob"},
+		},
+		{
+			name: "group_objects_do_not_match_userset_filter",
+			req: &openfgav1.ListUsersRequest{
+				Object:   &openfgav1.Object{Type: "document", Id: "4"},
+				Relation: "owner",
+				UserFilters: []*openfgav1.UserTypeFilter{
+					{Type: "user"},
+					{Type: "group", Relation: "member"},
+				},
+			},
+			model:         model,
+			tuples:        tuples,
+			expectedUsers: []string{"user:jon"},
+		},
+		{
+			name: "exclusion_applies_to_each_type",
+			req: &openfgav1.ListUsersRequest{
+				Object:   &openfgav1.Object{Type: "document", Id: "1"},
+				Relation: "viewer",
+				UserFilters: []*openfgav1.UserTypeFilter{
+					{Type: "user"},
+					{Type: "employee"},
+				},
+			},
+			model:         model,
+			tuples:        tuples,
+			expectedUsers: []string{"user:jon", "user:maria", "employee:bob"},
+		},
+		{
+			name: "exclusion_of_wildcard_only_applies_to_its_type",
+			req: &openfgav1.ListUsersRequest{
+				Object:   &openfgav1.Object{Type: "document", Id: "2"},
+				Relation: "viewer",
+				UserFilters: []*openfgav1.UserTypeFilter{
+					{Type: "user"},
+					{Type: "employee"},
+				},
+			},
+			model:         model,
+			tuples:        tuples,
+			expectedUsers: []string{"user:*", "employee:alice"},
+		},
+		{
+			name: "intersection_with_wildcards_of_several_types",
+			req: &openfgav1.ListUsersRequest{
+				Object:   &openfgav1.Object{Type: "document", Id: "2"},
+				Relation: "shared",
+				UserFilters: []*openfgav1.UserTypeFilter{
+					{Type: "user"},
+					{Type: "employee"},
+				},
+			},
+			model:         model,
+			tuples:        tuples,
+			expectedUsers: []string{"user:will", "employee:alice"},
+		},
+		{
+			name: "intersection_of_wildcards_of_different_types",
+			req: &openfgav1.ListUsersRequest{
+				Object:   &openfgav1.Object{Type: "document", Id: "3"},
+				Relation: "shared",
+				UserFilters: []*openfgav1.UserTypeFilter{
+					{Type: "user"},
+					{Type: "employee"},
+				},
+			},
+			model:         model,
+			tuples:        tuples,
+			expectedUsers: []string{"employee:bob"},
+		},
+	}
+
+	tests.runListUsersTestCases(t)
+}
+
+func TestListUsersExpandUsersets(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID, model := storagetest.BootstrapFGAStore(t, ds, `
+		model
+			schema 1.1
+
+		type user
+
+		type employee
+
+		type group
+			relations
+				define member: [user, employee:*, group#member]
+
+		type document
+			relations
+				define viewer: [user, group#member]`, []string{
+		"group:eng#member@user:maria",
+		"group:contractors#member@employee:*",
+		"group:fga#member@group:eng#member",
+		"group:fga#member@group:contractors#member",
+		"document:1#viewer@user:jon",
+		"document:1#viewer@group:fga#member",
+	})
+
+	typesys, err := typesystem.NewAndValidate(context.Background(), model)
+	require.NoError(t, err)
+
+	ctx := typesystem.ContextWithTypesystem(context.Background(), typesys)
+
+	tests := []struct {
+		name           string
+		userFilters    []*openfgav1.UserTypeFilter
+		expandUsersets bool
+		expectedUsers  []string
+	}{
+		{
+			name:          "usersets_are_returned",
+			userFilters:   []*openfgav1.UserTypeFilter{{Type: "user"}, {Type: "group", Relation: "member"}},
+			expectedUsers: []string{"user:jon", "user:maria", "group:fga#member", "group:eng#member", "group:contractors#member"},
+		},
+		{
+			name:           "usersets_are_expanded",
+			userFilters:    []*openfgav1.UserTypeFilter{{Type: "user"}, {Type: "group", Relation: "member"}},
+			expandUsersets: true,
+			expectedUsers:  []string{"user:jon", "user:maria", "employee:*"},
+		},
+		{
+			// the users of the terminal types are returned even if they aren't reached through a userset
+			name:           "userset_filter_is_replaced_by_its_terminal_types",
+			userFilters:    []*openfgav1.UserTypeFilter{{Type: "group", Relation: "member"}},
+			expandUsersets: true,
+			expectedUsers:  []string{"user:jon", "user:maria", "employee:*"},
+		},
+		{
+			name:           "type_filters_are_unaffected",
+			userFilters:    []*openfgav1.UserTypeFilter{{Type: "user"}},
+			expandUsersets: true,
+			expectedUsers:  []string{"user:jon", "user:maria"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp, err := NewListUsersQuery(ds, WithListUsersExpandUsersets(test.expandUsersets)).ListUsers(ctx, &openfgav1.ListUsersRequest{
+				StoreId:              storeID,
+				AuthorizationModelId: model.GetId(),
+				Object:               &openfgav1.Object{Type: "document", Id: "1"},
+				Relation:             "viewer",
+				UserFilters:          test.userFilters,
+			})
+			require.NoError(t, err)
+
+			users := make([]string, 0, len(resp.GetUsers()))
+			for _, user := range resp.GetUsers() {
+				users = append(users, tuple.UserProtoToString(user))
+			}
+			require.ElementsMatch(t, test.expectedUsers, users)
+		})
+	}
+}
@@ -0,0 +1,335 @@
+package reverseexpand
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/openfga/openfga/internal/condition"
+	"github.com/openfga/openfga/internal/condition/eval"
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/internal/validation"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/storagewrappers"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// Frontier captures the state of a paginated reverse expansion (see [[ReverseExpandQuery.ExecutePage]])
+// so that it can be resumed later, e.g. from a continuation token.
+type Frontier struct {
+	// Pending are the users (e.g. 'user:jon' or 'group:eng#member') which are yet to be expanded, in the
+	// order in which they will be expanded.
+	Pending []FrontierNode `json:"pending"`
+
+	// Visited are the keys of the users which have already been expanded, sorted.
+	Visited []string `json:"visited,omitempty"`
+}
+
+// FrontierNode is a user which is yet to be expanded.
+type FrontierNode struct {
+	User string `json:"user"`
+
+	// Depth is the number of edges followed from the user of the request to reach this user.
+	Depth uint32 `json:"depth,omitempty"`
+
+	// RequiresFurtherEval indicates whether an intersection or exclusion was followed to reach this user,
+	// in which case the objects found from it must be checked.
+	RequiresFurtherEval bool `json:"requires_further_eval,omitempty"`
+}
+
+// Size returns the approximate number of bytes the frontier holds in memory, so that a cache of frontiers can be
+// bounded by their size.
+func (f *Frontier) Size() int64 {
+	// the headers of the strings and the other fields of the nodes
+	const overhead = 32
+
+	size := int64(overhead)
+	for _, node := range f.Pending {
+		size += overhead + int64(len(node.User))
+	}
+
+	for _, key := range f.Visited {
+		size += overhead + int64(len(key))
+	}
+
+	return size
+}
+
+func (n FrontierNode) key() string {
+	if n.RequiresFurtherEval {
+		return n.User + "|?"
+	}
+
+	return n.User
+}
+
+// ExecutePage yields up to pageSize of the objects of req.ObjectType that the user of the request possibly
+// has req.Relation with (zero means no limit), resuming the expansion from the provided frontier (or from the
+// user of the request if it is nil), and returns the frontier to resume the expansion from for the next page,
+// which is nil once all objects have been yielded.
+//
+// Unlike Execute, the users are expanded breadth-first and one at a time, in an order that only depends on
+// the tuples of the store, so that the same frontier always yields the same objects and no object is yielded
+// twice across the pages of an expansion. If an error occurs (e.g. the context is cancelled), the results
+// yielded so far are returned along with a frontier which resumes the expansion from the user being expanded.
+// Errors evaluating the conditions of tuples (see [[condition.ErrEvaluationFailed]]) don't stop the expansion,
+// and are returned along with the results of the page.
+func (c *ReverseExpandQuery) ExecutePage(
+	ctx context.Context,
+	req *ReverseExpandRequest,
+	frontier *Frontier,
+	pageSize uint32,
+	resolutionMetadata *ResolutionMetadata,
+) ([]*ReverseExpandResult, *Frontier, error) {
+	ctx, span := tracer.Start(ctx, "reverseExpand.ExecutePage", trace.WithAttributes(
+		attribute.String("target_type", req.ObjectType),
+		attribute.String("target_relation", req.Relation),
+		attribute.String("source", req.User.String()),
+	))
+	defer span.End()
+
+	if frontier == nil {
+		frontier = &Frontier{Pending: []FrontierNode{{User: req.User.String()}}}
+
+		// see Execute
+		if wildcard := c.containingWildcard(req.User); wildcard != nil {
+			frontier.Pending = append(frontier.Pending, FrontierNode{User: wildcard.String()})
+		}
+	}
+
+	pending := slices.Clone(frontier.Pending)
+	visited := make(map[string]struct{}, len(frontier.Visited))
+	for _, key := range frontier.Visited {
+		visited[key] = struct{}{}
+	}
+
+	next := func() *Frontier {
+		if len(pending) == 0 {
+			return nil
+		}
+
+		visitedKeys := make([]string, 0, len(visited))
+		for key := range visited {
+			visitedKeys = append(visitedKeys, key)
+		}
+		slices.Sort(visitedKeys)
+
+		return &Frontier{Pending: pending, Visited: visitedKeys}
+	}
+
+	var results []*ReverseExpandResult
+	var errs error
+	for len(pending) > 0 && (pageSize == 0 || uint32(len(results)) < pageSize) {
+		if err := ctx.Err(); err != nil {
+			return results, next(), err
+		}
+
+		node := pending[0]
+		if _, ok := visited[node.key()]; ok {
+			pending = pending[1:]
+			continue
+		}
+
+		if node.Depth >= c.resolveNodeLimit {
+			return nil, nil, graph.ErrResolutionDepthExceeded
+		}
+
+		result, reached, err := c.expandNode(ctx, req, node, visited, resolutionMetadata)
+		if err != nil {
+			if !errors.Is(err, condition.ErrEvaluationFailed) {
+				return results, next(), errors.Join(errs, err)
+			}
+
+			// the tuples whose condition failed to evaluate are skipped, as in Execute
+			errs = errors.Join(errs, err)
+		}
+
+		pending = append(pending[1:], reached...)
+		visited[node.key()] = struct{}{}
+
+		if result != nil {
+			results = append(results, result)
+		}
+	}
+
+	return results, next(), errs
+}
+
+// expandNode returns the object yielded by the given user if it is a userset of the target relation, and the
+// users reached from it by following the edges of the relationship graph, sorted. Tuples whose condition fails
+// to evaluate are skipped, and the evaluation errors are returned along with the users reached.
+func (c *ReverseExpandQuery) expandNode(
+	ctx context.Context,
+	req *ReverseExpandRequest,
+	node FrontierNode,
+	visited map[string]struct{},
+	resolutionMetadata *ResolutionMetadata,
+) (*ReverseExpandResult, []FrontierNode, error) {
+	newcount := resolutionMetadata.DispatchCounter.Add(1)
+	if c.dispatchThrottlerConfig.Enabled {
+		c.throttle(ctx, newcount, resolutionMetadata)
+	}
+
+	user, sourceUserRef := parseUserRef(node.User)
+	sourceUserObj, sourceUserRel := tuple.SplitObjectRelation(node.User)
+
+	var result *ReverseExpandResult
+	if sourceUserRel != "" && tuple.GetType(sourceUserObj) == req.ObjectType && sourceUserRel == req.Relation {
+		// an object is only yielded once, even if it is reached both with and without further evaluation
+		if _, yielded := visited[FrontierNode{User: node.User, RequiresFurtherEval: !node.RequiresFurtherEval}.key()]; !yielded {
+			result = &ReverseExpandResult{Object: sourceUserObj, ResultStatus: NoFurtherEvalStatus}
+			if node.RequiresFurtherEval {
+				result.ResultStatus = RequiresFurtherEvalStatus
+			}
+		}
+	}
+
+	g := graph.New(c.typesystem)
+
+	edges, err := g.GetPrunedRelationshipEdges(typesystem.DirectRelationReference(req.ObjectType, req.Relation), sourceUserRef)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var reached []FrontierNode
+	var errs error
+	for _, edge := range edges {
+		reachedNode := FrontierNode{
+			Depth:               node.Depth + 1,
+			RequiresFurtherEval: node.RequiresFurtherEval || edge.TargetReferenceInvolvesIntersectionOrExclusion,
+		}
+
+		switch edge.Type {
+		case graph.ComputedUsersetEdge:
+			reachedNode.User = tuple.ToObjectRelationString(sourceUserObj, edge.TargetReference.GetRelation())
+			reached = append(reached, reachedNode)
+		case graph.DirectEdge, graph.TupleToUsersetEdge:
+			objects, err := c.readReachedObjects(ctx, req, edge, user, resolutionMetadata)
+			if err != nil {
+				if !errors.Is(err, condition.ErrEvaluationFailed) {
+					return nil, nil, err
+				}
+
+				errs = errors.Join(errs, err)
+			}
+
+			for _, object := range objects {
+				reachedNode.User = object
+				reached = append(reached, reachedNode)
+			}
+		default:
+			panic("unsupported edge type")
+		}
+	}
+
+	slices.SortFunc(reached, func(a, b FrontierNode) int {
+		return strings.Compare(a.key(), b.key())
+	})
+
+	return result, slices.CompactFunc(reached, func(a, b FrontierNode) bool {
+		return a.key() == b.key()
+	}), errs
+}
+
+// readReachedObjects returns the usersets (e.g. 'group:eng#member') reached from the user through the given
+// direct or tuple to userset edge.
+func (c *ReverseExpandQuery) readReachedObjects(
+	ctx context.Context,
+	req *ReverseExpandRequest,
+	edge *graph.RelationshipEdge,
+	user IsUserRef,
+	resolutionMetadata *ResolutionMetadata,
+) ([]string, error) {
+	relationFilter, userFilter, err := c.readTuplesFilter(edge, user)
+	if err != nil {
+		return nil, err
+	}
+
+	combinedTupleReader := storagewrappers.NewCombinedTupleReader(c.datastore, req.ContextualTuples)
+
+	iter, err := combinedTupleReader.ReadStartingWithUser(ctx, req.StoreID, storage.ReadStartingWithUserFilter{
+		ObjectType: edge.TargetReference.GetType(),
+		Relation:   relationFilter,
+		UserFilter: userFilter,
+	})
+	atomic.AddUint32(resolutionMetadata.DatastoreQueryCount, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	// filter out invalid tuples yielded by the database iterator
+	filteredIter := storage.NewFilteredTupleKeyIterator(
+		storage.NewTupleKeyIteratorFromTupleIterator(iter),
+		validation.FilterInvalidTuples(c.typesystem),
+	)
+	defer filteredIter.Stop()
+
+	var objects []string
+	var errs error
+	for {
+		tk, err := filteredIter.Next(ctx)
+		if err != nil {
+			if errors.Is(err, storage.ErrIteratorDone) {
+				break
+			}
+
+			return nil, err
+		}
+
+		condEvalResult, err := eval.EvaluateTupleCondition(ctx, tk, c.typesystem, req.Context)
+		if err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+
+		if !condEvalResult.ConditionMet {
+			if len(condEvalResult.MissingParameters) > 0 {
+				errs = errors.Join(errs, condition.NewEvaluationError(
+					tk.GetCondition().GetName(),
+					fmt.Errorf("tuple '%s' is missing context parameters '%v'",
+						tuple.TupleKeyToString(tk),
+						condEvalResult.MissingParameters),
+				))
+			}
+
+			continue
+		}
+
+		newRelation := tk.GetRelation()
+		if edge.Type == graph.TupleToUsersetEdge {
+			newRelation = edge.TargetReference.GetRelation()
+		}
+
+		objects = append(objects, tuple.ToObjectRelationString(tk.GetObject(), newRelation))
+	}
+
+	return objects, errs
+}
+
+// parseUserRef returns the user reference of the given user (e.g. 'user:jon', 'user:*' or 'group:eng#member')
+// and the reference of its type.
+func parseUserRef(user string) (IsUserRef, *openfgav1.RelationReference) {
+	userObj, userRel := tuple.SplitObjectRelation(user)
+	userObjType, userObjID := tuple.SplitObject(userObj)
+
+	switch {
+	case userRel != "":
+		return &UserRefObjectRelation{
+			ObjectRelation: &openfgav1.ObjectRelation{Object: userObj, Relation: userRel},
+		}, typesystem.DirectRelationReference(userObjType, userRel)
+	case tuple.IsTypedWildcard(userObj):
+		return &UserRefTypedWildcard{Type: userObjType}, typesystem.WildcardRelationReference(userObjType)
+	default:
+		return &UserRefObject{
+			Object: &openfgav1.Object{Type: userObjType, Id: userObjID},
+		}, typesystem.DirectRelationReference(userObjType, "")
+	}
+}
@@ -0,0 +1,116 @@
+package reverseexpand
+
+import (
+	"context"
+	"testing"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	storagetest "github.com/openfga/openfga/pkg/storage/test"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+func TestReverseExpandExecutePage(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID, model := storagetest.BootstrapFGAStore(t, ds, `
+		model
+			schema 1.1
+
+		type user
+
+		type group
+			relations
+				define member: [user, group#member]
+
+		type document
+			relations
+				define owner: [user]
+				define editor: [user, group#member] or owner
+				define viewer: [user] or editor`, []string{
+		"group:a#member@user:jon",
+		"group:a#member@group:b#member",
+		"group:b#member@group:a#member",
+		"document:1#viewer@user:jon",
+		"document:1#editor@user:jon",
+		"document:2#editor@group:a#member",
+		"document:3#editor@group:b#member",
+		"document:4#owner@user:jon",
+		"document:5#viewer@user:maria",
+	})
+
+	typesys, err := typesystem.NewAndValidate(context.Background(), model)
+	require.NoError(t, err)
+
+	req := &ReverseExpandRequest{
+		StoreID:    storeID,
+		ObjectType: "document",
+		Relation:   "viewer",
+		User: &UserRefObject{
+			Object: &openfgav1.Object{Type: "user", Id: "jon"},
+		},
+	}
+
+	t.Run("yields_each_object_once_across_pages", func(t *testing.T) {
+		q := NewReverseExpandQuery(ds, typesys)
+
+		var objects []string
+		var frontier *Frontier
+		for {
+			results, nextFrontier, err := q.ExecutePage(context.Background(), req, frontier, 1, NewResolutionMetadata())
+			require.NoError(t, err)
+			require.LessOrEqual(t, len(results), 1)
+
+			for _, result := range results {
+				require.Equal(t, NoFurtherEvalStatus, result.ResultStatus)
+				objects = append(objects, result.Object)
+			}
+
+			if nextFrontier == nil {
+				break
+			}
+			frontier = nextFrontier
+		}
+
+		require.ElementsMatch(t, []string{"document:1", "document:2", "document:3", "document:4"}, objects)
+	})
+
+	t.Run("same_frontier_yields_same_objects", func(t *testing.T) {
+		first, frontier, err := NewReverseExpandQuery(ds, typesys).ExecutePage(context.Background(), req, nil, 2, NewResolutionMetadata())
+		require.NoError(t, err)
+		require.Len(t, first, 2)
+		require.NotNil(t, frontier)
+
+		second, _, err := NewReverseExpandQuery(ds, typesys).ExecutePage(context.Background(), req, frontier, 0, NewResolutionMetadata())
+		require.NoError(t, err)
+
+		again, _, err := NewReverseExpandQuery(ds, typesys).ExecutePage(context.Background(), req, frontier, 0, NewResolutionMetadata())
+		require.NoError(t, err)
+		require.Equal(t, second, again)
+	})
+
+	t.Run("cancelled_context_returns_resumable_frontier", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		results, frontier, err := NewReverseExpandQuery(ds, typesys).ExecutePage(ctx, req, nil, 0, NewResolutionMetadata())
+		require.ErrorIs(t, err, context.Canceled)
+		require.Empty(t, results)
+		require.Equal(t, []FrontierNode{{User: "user:jon"}}, frontier.Pending)
+		require.Empty(t, frontier.Visited)
+	})
+
+	t.Run("resolution_depth_exceeded", func(t *testing.T) {
+		_, _, err := NewReverseExpandQuery(ds, typesys, WithResolveNodeLimit(1)).ExecutePage(context.Background(), req, nil, 0, NewResolutionMetadata())
+		require.ErrorIs(t, err, graph.ErrResolutionDepthExceeded)
+	})
+}
@@ -226,10 +226,42 @@ func (c *ReverseExpandQuery) Execute(
 		return err
 	}
 
+	// a userset whose members are all of one type is also granted by the typed wildcard of that type,
+	// as it is in Check, so the objects related to the wildcard are yielded as well
+	if wildcard := c.containingWildcard(req.User); wildcard != nil {
+		err = c.execute(ctx, &ReverseExpandRequest{
+			StoreID:          req.StoreID,
+			ObjectType:       req.ObjectType,
+			Relation:         req.Relation,
+			User:             wildcard,
+			ContextualTuples: req.ContextualTuples,
+			Context:          req.Context,
+		}, resultChan, false, resolutionMetadata)
+		if err != nil {
+			return err
+		}
+	}
+
 	close(resultChan)
 	return nil
 }
 
+// containingWildcard returns the typed wildcard that grants every member of the provided user if it is a userset
+// (e.g. 'user:*' for 'group:eng#member' if the members of groups can only be users), or nil otherwise.
+func (c *ReverseExpandQuery) containingWildcard(user IsUserRef) *UserRefTypedWildcard {
+	val, ok := user.(*UserRefObjectRelation)
+	if !ok || !typesystem.IsSchemaVersionSupported(c.typesystem.GetSchemaVersion()) {
+		return nil
+	}
+
+	terminalTypes, err := c.typesystem.TerminalUserTypes(tuple.GetType(val.ObjectRelation.GetObject()), val.ObjectRelation.GetRelation())
+	if err != nil || len(terminalTypes) != 1 {
+		return nil
+	}
+
+	return &UserRefTypedWildcard{Type: terminalTypes[0]}
+}
+
 func (c *ReverseExpandQuery) dispatch(
 	ctx context.Context,
 	req *ReverseExpandRequest,
@@ -442,50 +474,9 @@ func (c *ReverseExpandQuery) readTuplesAndExecute(
 	ctx, span := tracer.Start(ctx, "readTuplesAndExecute")
 	defer span.End()
 
-	var userFilter []*openfgav1.ObjectRelation
-	var relationFilter string
-
-	switch req.edge.Type {
-	case graph.DirectEdge:
-		relationFilter = req.edge.TargetReference.GetRelation()
-		targetUserObjectType := req.User.GetObjectType()
-
-		publiclyAssignable, err := c.typesystem.IsPubliclyAssignable(req.edge.TargetReference, targetUserObjectType)
-		if err != nil {
-			return err
-		}
-
-		if publiclyAssignable {
-			// e.g. 'user:*'
-			userFilter = append(userFilter, &openfgav1.ObjectRelation{
-				Object: tuple.TypedPublicWildcard(targetUserObjectType),
-			})
-		}
-
-		// e.g. 'user:bob'
-		if val, ok := req.User.(*UserRefObject); ok {
-			userFilter = append(userFilter, &openfgav1.ObjectRelation{
-				Object: tuple.BuildObject(val.Object.GetType(), val.Object.GetId()),
-			})
-		}
-
-		// e.g. 'group:eng#member'
-		if val, ok := req.User.(*UserRefObjectRelation); ok {
-			userFilter = append(userFilter, val.ObjectRelation)
-		}
-	case graph.TupleToUsersetEdge:
-		relationFilter = req.edge.TuplesetRelation
-		// a TTU edge can only have a userset as a source node
-		// e.g. 'group:eng#member'
-		if val, ok := req.User.(*UserRefObjectRelation); ok {
-			userFilter = append(userFilter, &openfgav1.ObjectRelation{
-				Object: val.ObjectRelation.GetObject(),
-			})
-		} else {
-			panic("unexpected source for reverse expansion of tuple to userset")
-		}
-	default:
-		panic("unsupported edge type")
+	relationFilter, userFilter, err := c.readTuplesFilter(req.edge, req.User)
+	if err != nil {
+		return err
 	}
 
 	combinedTupleReader := storagewrappers.NewCombinedTupleReader(c.datastore, req.ContextualTuples)
@@ -585,6 +576,58 @@ LoopOnIterator:
 	return nil
 }
 
+// readTuplesFilter returns the relation and the users of the tuples to read in order to expand the
+// given user through the given direct or tuple to userset edge.
+func (c *ReverseExpandQuery) readTuplesFilter(edge *graph.RelationshipEdge, user IsUserRef) (string, []*openfgav1.ObjectRelation, error) {
+	var userFilter []*openfgav1.ObjectRelation
+	var relationFilter string
+
+	switch edge.Type {
+	case graph.DirectEdge:
+		relationFilter = edge.TargetReference.GetRelation()
+		targetUserObjectType := user.GetObjectType()
+
+		publiclyAssignable, err := c.typesystem.IsPubliclyAssignable(edge.TargetReference, targetUserObjectType)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if publiclyAssignable {
+			// e.g. 'user:*'
+			userFilter = append(userFilter, &openfgav1.ObjectRelation{
+				Object: tuple.TypedPublicWildcard(targetUserObjectType),
+			})
+		}
+
+		// e.g. 'user:bob'
+		if val, ok := user.(*UserRefObject); ok {
+			userFilter = append(userFilter, &openfgav1.ObjectRelation{
+				Object: tuple.BuildObject(val.Object.GetType(), val.Object.GetId()),
+			})
+		}
+
+		// e.g. 'group:eng#member'
+		if val, ok := user.(*UserRefObjectRelation); ok {
+			userFilter = append(userFilter, val.ObjectRelation)
+		}
+	case graph.TupleToUsersetEdge:
+		relationFilter = edge.TuplesetRelation
+		// a TTU edge can only have a userset as a source node
+		// e.g. 'group:eng#member'
+		if val, ok := user.(*UserRefObjectRelation); ok {
+			userFilter = append(userFilter, &openfgav1.ObjectRelation{
+				Object: val.ObjectRelation.GetObject(),
+			})
+		} else {
+			panic("unexpected source for reverse expansion of tuple to userset")
+		}
+	default:
+		panic("unsupported edge type")
+	}
+
+	return relationFilter, userFilter, nil
+}
+
 func (c *ReverseExpandQuery) trySendCandidate(ctx context.Context, intersectionOrExclusionInPreviousEdges bool, candidateObject string, candidateChan chan<- *ReverseExpandResult) error {
 	_, span := tracer.Start(ctx, "trySendCandidate", trace.WithAttributes(
 		attribute.String("object", candidateObject),
@@ -742,3 +742,97 @@ func TestReverseExpandDispatchCount(t *testing.T) {
 		})
 	}
 }
+
+func TestReverseExpandUsersetSubjectContainedInWildcard(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID, model := storagetest.BootstrapFGAStore(t, ds, `
+		model
+			schema 1.1
+
+		type user
+
+		type employee
+
+		type group
+			relations
+				define member: [user, group#member]
+
+		type team
+			relations
+				define member: [user, employee]
+
+		type document
+			relations
+				define viewer: [user:*, employee:*, group#member, team#member]`, []string{
+		"document:public#viewer@user:*",
+		"document:employees#viewer@employee:*",
+		"document:eng#viewer@group:eng#member",
+		"document:team#viewer@team:a#member",
+	})
+
+	typesys, err := typesystem.NewAndValidate(context.Background(), model)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		user     IsUserRef
+		expected []string
+	}{
+		{
+			name: "userset_of_one_type",
+			user: &UserRefObjectRelation{
+				ObjectRelation: &openfgav1.ObjectRelation{Object: "group:eng", Relation: "member"},
+			},
+			expected: []string{"document:public", "document:eng"},
+		},
+		{
+			name: "userset_of_several_types",
+			user: &UserRefObjectRelation{
+				ObjectRelation: &openfgav1.ObjectRelation{Object: "team:a", Relation: "member"},
+			},
+			expected: []string{"document:team"},
+		},
+		{
+			name:     "typed_wildcard",
+			user:     &UserRefTypedWildcard{Type: "employee"},
+			expected: []string{"document:employees"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := &ReverseExpandRequest{
+				StoreID:    storeID,
+				ObjectType: "document",
+				Relation:   "viewer",
+				User:       test.user,
+			}
+
+			resultChan := make(chan *ReverseExpandResult, 10)
+			err := NewReverseExpandQuery(ds, typesys).Execute(context.Background(), req, resultChan, NewResolutionMetadata())
+			require.NoError(t, err)
+
+			var objects []string
+			for result := range resultChan {
+				objects = append(objects, result.Object)
+			}
+			require.ElementsMatch(t, test.expected, objects)
+
+			results, frontier, err := NewReverseExpandQuery(ds, typesys).ExecutePage(context.Background(), req, nil, 0, NewResolutionMetadata())
+			require.NoError(t, err)
+			require.Nil(t, frontier)
+
+			objects = nil
+			for _, result := range results {
+				objects = append(objects, result.Object)
+			}
+			require.ElementsMatch(t, test.expected, objects)
+		})
+	}
+}
@@ -2,26 +2,66 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/oklog/ulid/v2"
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 
 	serverconfig "github.com/openfga/openfga/internal/server/config"
+	"github.com/openfga/openfga/internal/validation"
 	"github.com/openfga/openfga/pkg/logger"
 	serverErrors "github.com/openfga/openfga/pkg/server/errors"
 	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
 	"github.com/openfga/openfga/pkg/typesystem"
 )
 
+// maxIncompatibleTuples is the maximum number of tuples incompatible with a model that are reported by a
+// compatibility check, after which the tuples of the store are no longer read.
+const maxIncompatibleTuples = 100
+
+// IncompatibleTuple is a tuple of a store which is invalid under an authorization model, e.g. because the model
+// doesn't define its relation or doesn't allow its type of user, and so fails Check requests reaching it.
+type IncompatibleTuple struct {
+	TupleKey *openfgav1.TupleKey
+	Reason   error
+}
+
+// CompatibilityReport is the result of checking the compatibility of an authorization model with the tuples of
+// a store.
+type CompatibilityReport struct {
+	// IncompatibleTuples are the tuples found to be incompatible with the model, up to maxIncompatibleTuples.
+	IncompatibleTuples []*IncompatibleTuple
+
+	// Unknown is true if the check stopped before reading all the tuples of the store because it reached its
+	// maximum number of tuples or its deadline (see WithWriteAuthModelCompatibilityCheckLimits), in which case
+	// other tuples may be incompatible with the model.
+	Unknown bool
+}
+
 // WriteAuthorizationModelCommand performs updates of the store authorization model.
 type WriteAuthorizationModelCommand struct {
 	backend                          storage.TypeDefinitionWriteBackend
 	logger                           logger.Logger
 	maxAuthorizationModelSizeInBytes int
+
+	// tupleReader reads the tuples of the store to check the compatibility of the model with, if set.
+	tupleReader        storage.RelationshipTupleReader
+	rejectIncompatible bool
+
+	compatibilityCheckMaxTuples int
+	compatibilityCheckDeadline  time.Duration
+
+	// annotations are written with the model, if set.
+	annotations *storage.AuthorizationModelAnnotations
 }
 
 type WriteAuthModelOption func(*WriteAuthorizationModelCommand)
@@ -38,11 +78,40 @@ func WithWriteAuthModelMaxSizeInBytes(size int) WriteAuthModelOption {
 	}
 }
 
+// WithWriteAuthModelCompatibilityCheck makes the command read the tuples of the store with the tuple reader, before
+// writing the model, to check that they are valid under the model. If reject is true, the write is rejected if some
+// tuples are incompatible with the model; otherwise they are logged.
+func WithWriteAuthModelCompatibilityCheck(tupleReader storage.RelationshipTupleReader, reject bool) WriteAuthModelOption {
+	return func(m *WriteAuthorizationModelCommand) {
+		m.tupleReader = tupleReader
+		m.rejectIncompatible = reject
+	}
+}
+
+// WithWriteAuthModelCompatibilityCheckLimits sets the maximum number of tuples read and the maximum duration of
+// the compatibility checks of the command, after which the compatibility of the model is unknown.
+func WithWriteAuthModelCompatibilityCheckLimits(maxTuples int, deadline time.Duration) WriteAuthModelOption {
+	return func(m *WriteAuthorizationModelCommand) {
+		m.compatibilityCheckMaxTuples = maxTuples
+		m.compatibilityCheckDeadline = deadline
+	}
+}
+
+// WithWriteAuthModelAnnotations makes the command validate the annotations against the model (see
+// typesystem.ValidateAnnotations) and write them with it.
+func WithWriteAuthModelAnnotations(annotations *storage.AuthorizationModelAnnotations) WriteAuthModelOption {
+	return func(m *WriteAuthorizationModelCommand) {
+		m.annotations = annotations
+	}
+}
+
 func NewWriteAuthorizationModelCommand(backend storage.TypeDefinitionWriteBackend, opts ...WriteAuthModelOption) *WriteAuthorizationModelCommand {
 	model := &WriteAuthorizationModelCommand{
 		backend:                          backend,
 		logger:                           logger.NewNoopLogger(),
 		maxAuthorizationModelSizeInBytes: serverconfig.DefaultMaxAuthorizationModelSizeInBytes,
+		compatibilityCheckMaxTuples:      serverconfig.DefaultAuthorizationModelCompatibilityCheckMaxTuples,
+		compatibilityCheckDeadline:       serverconfig.DefaultAuthorizationModelCompatibilityCheckDeadline,
 	}
 
 	for _, opt := range opts {
@@ -53,9 +122,78 @@ func NewWriteAuthorizationModelCommand(backend storage.TypeDefinitionWriteBacken
 
 // Execute the command using the supplied request.
 func (w *WriteAuthorizationModelCommand) Execute(ctx context.Context, req *openfgav1.WriteAuthorizationModelRequest) (*openfgav1.WriteAuthorizationModelResponse, error) {
+	model, typesys, err := w.newAuthorizationModel(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if w.tupleReader != nil {
+		report, err := w.checkCompatibility(ctx, w.tupleReader, req.GetStoreId(), typesys)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case len(report.IncompatibleTuples) > 0:
+			if w.rejectIncompatible {
+				return nil, serverErrors.InvalidAuthorizationModelInput(incompatibleTuplesError(report.IncompatibleTuples))
+			}
+
+			w.logger.WarnWithContext(ctx, "authorization model is incompatible with existing tuples",
+				zap.String("store_id", req.GetStoreId()),
+				zap.String("authorization_model_id", model.GetId()),
+				zap.Error(incompatibleTuplesError(report.IncompatibleTuples)),
+			)
+		case report.Unknown:
+			// the model isn't rejected, as the check can't complete for the stores with the most tuples
+			w.logger.WarnWithContext(ctx, "compatibility of authorization model with existing tuples is unknown",
+				zap.String("store_id", req.GetStoreId()),
+				zap.String("authorization_model_id", model.GetId()),
+				zap.Int("max_tuples", w.compatibilityCheckMaxTuples),
+				zap.Duration("deadline", w.compatibilityCheckDeadline),
+			)
+		}
+	}
+
+	if w.annotations != nil {
+		// the annotations are written atomically with the model, so that the model is never read without them
+		err = w.backend.WriteAuthorizationModelWithAnnotations(ctx, req.GetStoreId(), model, w.annotations)
+	} else {
+		err = w.backend.WriteAuthorizationModel(ctx, req.GetStoreId(), model)
+	}
+	if err != nil {
+		return nil, serverErrors.
+			HandleError("Error writing authorization model configuration", err)
+	}
+
+	return &openfgav1.WriteAuthorizationModelResponse{
+		AuthorizationModelId: model.GetId(),
+	}, nil
+}
+
+// DryRun validates the model of the request as Execute does, without writing it, and reports the tuples of the
+// store, read with the tuple reader, which are incompatible with it, up to maxIncompatibleTuples.
+func (w *WriteAuthorizationModelCommand) DryRun(
+	ctx context.Context,
+	req *openfgav1.WriteAuthorizationModelRequest,
+	tupleReader storage.RelationshipTupleReader,
+) (*CompatibilityReport, error) {
+	_, typesys, err := w.newAuthorizationModel(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return w.checkCompatibility(ctx, tupleReader, req.GetStoreId(), typesys)
+}
+
+// newAuthorizationModel builds the model of the request and validates it.
+func (w *WriteAuthorizationModelCommand) newAuthorizationModel(
+	ctx context.Context,
+	req *openfgav1.WriteAuthorizationModelRequest,
+) (*openfgav1.AuthorizationModel, *typesystem.TypeSystem, error) {
 	// Until this is solved: https://github.com/envoyproxy/protoc-gen-validate/issues/74
 	if len(req.GetTypeDefinitions()) > w.backend.MaxTypesPerAuthorizationModel() {
-		return nil, serverErrors.ExceededEntityLimit("type definitions in an authorization model", w.backend.MaxTypesPerAuthorizationModel())
+		return nil, nil, serverErrors.ExceededEntityLimit("type definitions in an authorization model", w.backend.MaxTypesPerAuthorizationModel())
 	}
 
 	// Fill in the schema version for old requests, which don't contain it, while we migrate to the new schema version.
@@ -73,24 +211,110 @@ func (w *WriteAuthorizationModelCommand) Execute(ctx context.Context, req *openf
 	// Validate the size in bytes of the wire-format encoding of the authorization model.
 	modelSize := proto.Size(model)
 	if modelSize > w.maxAuthorizationModelSizeInBytes {
-		return nil, status.Error(
+		return nil, nil, status.Error(
 			codes.Code(openfgav1.ErrorCode_exceeded_entity_limit),
 			fmt.Sprintf("model exceeds size limit: %d bytes vs %d bytes", modelSize, w.maxAuthorizationModelSizeInBytes),
 		)
 	}
 
-	_, err := typesystem.NewAndValidate(ctx, model)
+	typesys, err := typesystem.NewAndValidate(ctx, model)
 	if err != nil {
-		return nil, serverErrors.InvalidAuthorizationModelInput(err)
+		return nil, nil, serverErrors.InvalidAuthorizationModelInput(err)
 	}
 
-	err = w.backend.WriteAuthorizationModel(ctx, req.GetStoreId(), model)
-	if err != nil {
-		return nil, serverErrors.
-			HandleError("Error writing authorization model configuration", err)
+	if w.annotations != nil {
+		if err := typesystem.ValidateAnnotations(typesys, w.annotations); err != nil {
+			return nil, nil, serverErrors.ValidationError(err)
+		}
 	}
 
-	return &openfgav1.WriteAuthorizationModelResponse{
-		AuthorizationModelId: model.GetId(),
-	}, nil
+	return model, typesys, nil
+}
+
+// checkCompatibility pages through the tuples of the store and reports those which are invalid under the model,
+// stopping after maxIncompatibleTuples of them, or with an unknown compatibility once the maximum number of tuples
+// has been read or the deadline has passed.
+func (w *WriteAuthorizationModelCommand) checkCompatibility(
+	ctx context.Context,
+	tupleReader storage.RelationshipTupleReader,
+	storeID string,
+	typesys *typesystem.TypeSystem,
+) (*CompatibilityReport, error) {
+	ctx, span := tracer.Start(ctx, "checkCompatibility")
+	defer span.End()
+
+	deadline := time.Now().Add(w.compatibilityCheckDeadline)
+
+	// the reads are bounded by the deadline too, so that a slow read doesn't hold the write of the model
+	readCtx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	report := &CompatibilityReport{}
+
+	var contToken []byte
+	tuplesRead := 0
+	for {
+		tuples, nextContToken, err := tupleReader.ReadPage(readCtx, storeID, nil, storage.NewPaginationOptions(0, string(contToken)))
+		if err != nil {
+			if errors.Is(readCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+				report.Unknown = true
+				return report, nil
+			}
+			return nil, serverErrors.HandleError("", err)
+		}
+
+		for _, t := range tuples {
+			if tuplesRead == w.compatibilityCheckMaxTuples {
+				report.Unknown = true
+				return report, nil
+			}
+			tuplesRead++
+
+			if err := validation.ValidateTuple(typesys, t.GetKey()); err != nil {
+				var invalidTupleErr *tuple.InvalidTupleError
+				if errors.As(err, &invalidTupleErr) {
+					err = invalidTupleErr.Cause
+				}
+
+				report.IncompatibleTuples = append(report.IncompatibleTuples, &IncompatibleTuple{
+					TupleKey: t.GetKey(),
+					Reason:   err,
+				})
+
+				if len(report.IncompatibleTuples) >= maxIncompatibleTuples {
+					return report, nil
+				}
+			}
+		}
+
+		if len(nextContToken) == 0 {
+			return report, nil
+		}
+
+		if time.Now().After(deadline) {
+			report.Unknown = true
+			return report, nil
+		}
+		contToken = nextContToken
+	}
+}
+
+// incompatibleTuplesError describes the tuples incompatible with a model.
+func incompatibleTuplesError(incompatibleTuples []*IncompatibleTuple) error {
+	const maxDescribed = 3
+
+	descriptions := make([]string, 0, maxDescribed)
+	for _, t := range incompatibleTuples {
+		if len(descriptions) == maxDescribed {
+			break
+		}
+		descriptions = append(descriptions, fmt.Sprintf("'%s': %v", tuple.TupleKeyToString(t.TupleKey), t.Reason))
+	}
+
+	count := strconv.Itoa(len(incompatibleTuples))
+	if len(incompatibleTuples) >= maxIncompatibleTuples {
+		count = "at least " + count
+	}
+
+	return fmt.Errorf("the model is incompatible with %s existing tuples, e.g. %s", count, strings.Join(descriptions, ", "))
 }
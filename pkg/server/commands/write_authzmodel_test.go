@@ -2,15 +2,24 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/oklog/ulid/v2"
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/goleak"
 	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	mockstorage "github.com/openfga/openfga/internal/mocks"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	storagetest "github.com/openfga/openfga/pkg/storage/test"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
 	"github.com/openfga/openfga/pkg/typesystem"
 )
 
@@ -57,3 +66,207 @@ func TestWriteAuthorizationModel(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteAuthorizationModelCompatibilityCheck(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID, _ := storagetest.BootstrapFGAStore(t, ds, `
+		model
+			schema 1.1
+
+		type user
+
+		type group
+			relations
+				define member: [user]
+
+		type document
+			relations
+				define editor: [user]
+				define viewer: [user, group#member]`, []string{
+		"document:1#editor@user:jon",
+		"document:1#viewer@user:jon",
+		"document:1#viewer@group:eng#member",
+		"group:eng#member@user:jon",
+	})
+
+	incompatibleModel := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+
+		type user
+
+		type group
+			relations
+				define member: [user]
+
+		type document
+			relations
+				define viewer: [user]`)
+
+	req := &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   incompatibleModel.GetSchemaVersion(),
+		TypeDefinitions: incompatibleModel.GetTypeDefinitions(),
+	}
+
+	t.Run("dry_run", func(t *testing.T) {
+		report, err := NewWriteAuthorizationModelCommand(ds).DryRun(ctx, req, ds)
+		require.NoError(t, err)
+		require.False(t, report.Unknown)
+
+		var tupleKeys []string
+		for _, incompatibleTuple := range report.IncompatibleTuples {
+			require.Error(t, incompatibleTuple.Reason)
+			tupleKeys = append(tupleKeys, tuple.TupleKeyToString(incompatibleTuple.TupleKey))
+		}
+		require.ElementsMatch(t, []string{"document:1#editor@user:jon", "document:1#viewer@group:eng#member"}, tupleKeys)
+	})
+
+	t.Run("reject", func(t *testing.T) {
+		cmd := NewWriteAuthorizationModelCommand(ds, WithWriteAuthModelCompatibilityCheck(ds, true))
+		_, err := cmd.Execute(ctx, req)
+		require.Error(t, err)
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_invalid_authorization_model), status.Code(err))
+		require.ErrorContains(t, err, "the model is incompatible with 2 existing tuples")
+	})
+
+	t.Run("write_despite_incompatible_tuples", func(t *testing.T) {
+		cmd := NewWriteAuthorizationModelCommand(ds, WithWriteAuthModelCompatibilityCheck(ds, false))
+		resp, err := cmd.Execute(ctx, req)
+		require.NoError(t, err)
+		require.NotEmpty(t, resp.GetAuthorizationModelId())
+	})
+
+	compatibleReq := &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:       storeID,
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: testutils.MustTransformDSLToProtoWithID(`
+			model
+				schema 1.1
+
+			type user
+
+			type group
+				relations
+					define member: [user]
+
+			type document
+				relations
+					define editor: [user]
+					define viewer: [user, group#member] or editor`).GetTypeDefinitions(),
+	}
+
+	t.Run("compatible_model", func(t *testing.T) {
+		report, err := NewWriteAuthorizationModelCommand(ds).DryRun(ctx, compatibleReq, ds)
+		require.NoError(t, err)
+		require.False(t, report.Unknown)
+		require.Empty(t, report.IncompatibleTuples)
+	})
+
+	t.Run("compatibility_unknown", func(t *testing.T) {
+		cmd := NewWriteAuthorizationModelCommand(ds,
+			WithWriteAuthModelCompatibilityCheck(ds, true),
+			WithWriteAuthModelCompatibilityCheckLimits(1, time.Minute),
+		)
+
+		report, err := cmd.DryRun(ctx, compatibleReq, ds)
+		require.NoError(t, err)
+		require.True(t, report.Unknown)
+		require.Empty(t, report.IncompatibleTuples)
+
+		resp, err := cmd.Execute(ctx, compatibleReq)
+		require.NoError(t, err)
+		require.NotEmpty(t, resp.GetAuthorizationModelId())
+	})
+
+	t.Run("compatibility_unknown_on_a_read_past_the_deadline", func(t *testing.T) {
+		mockController := gomock.NewController(t)
+		defer mockController.Finish()
+
+		mockTupleReader := mockstorage.NewMockRelationshipTupleReader(mockController)
+		mockTupleReader.EXPECT().
+			ReadPage(gomock.Any(), storeID, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(ctx context.Context, _ string, _ *openfgav1.TupleKey, _ storage.PaginationOptions) ([]*openfgav1.Tuple, []byte, error) {
+				<-ctx.Done()
+				return nil, nil, ctx.Err()
+			})
+
+		cmd := NewWriteAuthorizationModelCommand(ds,
+			WithWriteAuthModelCompatibilityCheck(mockTupleReader, true),
+			WithWriteAuthModelCompatibilityCheckLimits(100, 10*time.Millisecond),
+		)
+
+		report, err := cmd.DryRun(ctx, compatibleReq, mockTupleReader)
+		require.NoError(t, err)
+		require.True(t, report.Unknown)
+		require.Empty(t, report.IncompatibleTuples)
+	})
+}
+
+func TestWriteAuthorizationModelAnnotations(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+	mockDatastore.EXPECT().MaxTypesPerAuthorizationModel().AnyTimes().Return(100)
+
+	req := &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   typesystem.SchemaVersion1_1,
+		TypeDefinitions: []*openfgav1.TypeDefinition{{Type: "user"}},
+	}
+
+	t.Run("written_with_the_model", func(t *testing.T) {
+		annotations := &storage.AuthorizationModelAnnotations{
+			Types: map[string]*storage.TypeAnnotations{"user": {Annotations: storage.Annotations{"owner": "iam"}}},
+		}
+
+		var annotatedModelID string
+		mockDatastore.EXPECT().WriteAuthorizationModelWithAnnotations(gomock.Any(), storeID, gomock.Any(), annotations).
+			DoAndReturn(func(_ context.Context, _ string, model *openfgav1.AuthorizationModel, _ *storage.AuthorizationModelAnnotations) error {
+				annotatedModelID = model.GetId()
+				return nil
+			})
+
+		resp, err := NewWriteAuthorizationModelCommand(mockDatastore, WithWriteAuthModelAnnotations(annotations)).Execute(ctx, req)
+		require.NoError(t, err)
+		require.Equal(t, resp.GetAuthorizationModelId(), annotatedModelID)
+	})
+
+	t.Run("write_error_is_returned", func(t *testing.T) {
+		annotations := &storage.AuthorizationModelAnnotations{
+			Types: map[string]*storage.TypeAnnotations{"user": {Annotations: storage.Annotations{"owner": "iam"}}},
+		}
+
+		mockDatastore.EXPECT().WriteAuthorizationModelWithAnnotations(gomock.Any(), storeID, gomock.Any(), annotations).
+			Return(errors.New("internal"))
+
+		_, err := NewWriteAuthorizationModelCommand(mockDatastore, WithWriteAuthModelAnnotations(annotations)).Execute(ctx, req)
+		require.Error(t, err)
+	})
+
+	t.Run("invalid_annotations_reject_the_model", func(t *testing.T) {
+		annotations := &storage.AuthorizationModelAnnotations{
+			Types: map[string]*storage.TypeAnnotations{"document": {}},
+		}
+
+		_, err := NewWriteAuthorizationModelCommand(mockDatastore, WithWriteAuthModelAnnotations(annotations)).Execute(ctx, req)
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_validation_error), status.Code(err))
+		require.Contains(t, status.Convert(err).Message(), "type 'document' is not defined")
+	})
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/openfga/openfga/pkg/telemetry"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// DiffAuthorizationModels returns the changes from one authorization model of a store to another (an empty model ID
+// refers to the latest model of the store), e.g. to review what a new model changes before rolling it out. Each
+// change is classified as safe, permission-expanding or permission-reducing (see typesystem.Diff).
+func (s *Server) DiffAuthorizationModels(
+	ctx context.Context,
+	storeID, fromModelID, toModelID string,
+) ([]*typesystem.ModelChange, error) {
+	ctx, span := tracer.Start(ctx, "DiffAuthorizationModels", trace.WithAttributes(
+		attribute.String("store_id", storeID),
+		attribute.String("from_authorization_model_id", fromModelID),
+		attribute.String("to_authorization_model_id", toModelID),
+	))
+	defer span.End()
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  "DiffAuthorizationModels",
+	})
+
+	from, err := s.resolveTypesystem(ctx, storeID, fromModelID)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := s.resolveTypesystem(ctx, storeID, toModelID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := typesystem.Diff(from, to)
+
+	span.SetAttributes(attribute.Int("changes", len(changes)))
+
+	return changes, nil
+}
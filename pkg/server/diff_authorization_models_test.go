@@ -0,0 +1,84 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	language "github.com/openfga/language/pkg/go/transformer"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+func TestDiffAuthorizationModels(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+
+	s := MustNewServerWithOpts(WithDatastore(memory.New()))
+	t.Cleanup(s.Close)
+
+	writeModel := func(dsl string) string {
+		resp, err := s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+			StoreId:         storeID,
+			SchemaVersion:   typesystem.SchemaVersion1_1,
+			TypeDefinitions: language.MustTransformDSLToProto(dsl).GetTypeDefinitions(),
+		})
+		require.NoError(t, err)
+		return resp.GetAuthorizationModelId()
+	}
+
+	fromModelID := writeModel(`
+		model
+			schema 1.1
+
+		type user
+
+		type document
+			relations
+				define editor: [user]
+				define viewer: [user]`)
+
+	toModelID := writeModel(`
+		model
+			schema 1.1
+
+		type user
+
+		type document
+			relations
+				define editor: [user]
+				define viewer: [user] or editor`)
+
+	t.Run("between_models", func(t *testing.T) {
+		changes, err := s.DiffAuthorizationModels(ctx, storeID, fromModelID, toModelID)
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+		require.Equal(t, typesystem.ModelChangeRewriteChanged, changes[0].Kind)
+		require.Equal(t, "document", changes[0].Type)
+		require.Equal(t, "viewer", changes[0].Relation)
+		require.True(t, changes[0].Impact.IsPermissionExpanding())
+		require.False(t, changes[0].Impact.IsPermissionReducing())
+	})
+
+	t.Run("to_latest_model", func(t *testing.T) {
+		changes, err := s.DiffAuthorizationModels(ctx, storeID, toModelID, "")
+		require.NoError(t, err)
+		require.Empty(t, changes)
+	})
+
+	t.Run("model_not_found", func(t *testing.T) {
+		modelID := ulid.Make().String()
+
+		_, err := s.DiffAuthorizationModels(ctx, storeID, modelID, toModelID)
+		require.ErrorIs(t, err, serverErrors.AuthorizationModelNotFound(modelID))
+	})
+}
@@ -27,6 +27,10 @@ var (
 	RequestCancelled                       = status.Error(codes.Code(openfgav1.InternalErrorCode_cancelled), "Request Cancelled")
 	RequestDeadlineExceeded                = status.Error(codes.Code(openfgav1.InternalErrorCode_deadline_exceeded), "Request Deadline Exceeded")
 	ThrottledTimeout                       = status.Error(codes.Code(openfgav1.UnprocessableContentErrorCode_throttled_timeout_error), "timeout due to throttling on complex request")
+
+	// ContinuationTokenReplayDeadlineExceeded is returned when a query can't be resumed from its continuation token
+	// before the deadline, e.g. because the token is used with another server than the one which issued it.
+	ContinuationTokenReplayDeadlineExceeded = status.Error(codes.Code(openfgav1.InternalErrorCode_deadline_exceeded), "The deadline was hit while resuming the query from the continuation token. Request the next page from the server which issued the token, or restart the query")
 )
 
 type InternalError struct {
@@ -83,6 +87,10 @@ func LatestAuthorizationModelNotFound(store string) error {
 	return status.Error(codes.Code(openfgav1.ErrorCode_latest_authorization_model_not_found), fmt.Sprintf("No authorization models found for store '%s'", store))
 }
 
+func AuthorizationModelInUse(modelID, use string) error {
+	return status.Error(codes.Code(openfgav1.ErrorCode_validation_error), fmt.Sprintf("Authorization Model '%s' cannot be deleted because it is the %s model of the store", modelID, use))
+}
+
 func TypeNotFound(objectType string) error {
 	return status.Error(codes.Code(openfgav1.ErrorCode_type_not_found), fmt.Sprintf("type '%s' not found", objectType))
 }
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/openfga/openfga/internal/graph"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/telemetry"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// EstimateQueryCosts returns the static worst-case costs of the Check and ListObjects queries on the relations of an
+// authorization model of a store (an empty model ID refers to the latest model of the store), e.g. to review the cost
+// of a model before shipping it. The costs are those of every relation of the model if the object type is empty, of
+// every relation of the object type if the relation is empty, or else of the relation only.
+func (s *Server) EstimateQueryCosts(
+	ctx context.Context,
+	storeID, modelID, objectType, relation string,
+) ([]*typesystem.RelationCost, error) {
+	ctx, span := tracer.Start(ctx, "EstimateQueryCosts", trace.WithAttributes(
+		attribute.String("store_id", storeID),
+		attribute.String("authorization_model_id", modelID),
+		attribute.String("object_type", objectType),
+		attribute.String("relation", relation),
+	))
+	defer span.End()
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  "EstimateQueryCosts",
+	})
+
+	typesys, err := s.resolveTypesystem(ctx, storeID, modelID)
+	if err != nil {
+		return nil, err
+	}
+
+	g := graph.New(typesys)
+
+	if objectType != "" && relation != "" {
+		cost, err := g.EstimateCost(objectType, relation)
+		if err != nil {
+			return nil, estimateQueryCostsError(err, objectType, relation)
+		}
+
+		return []*typesystem.RelationCost{cost}, nil
+	}
+
+	if objectType != "" {
+		if _, ok := typesys.GetTypeDefinition(objectType); !ok {
+			return nil, serverErrors.TypeNotFound(objectType)
+		}
+	}
+
+	costs, err := g.EstimateCosts()
+	if err != nil {
+		return nil, serverErrors.HandleError("", err)
+	}
+
+	if objectType != "" {
+		filtered := make([]*typesystem.RelationCost, 0, len(costs))
+		for _, cost := range costs {
+			if cost.Type == objectType {
+				filtered = append(filtered, cost)
+			}
+		}
+		costs = filtered
+	}
+
+	span.SetAttributes(attribute.Int("relations", len(costs)))
+
+	return costs, nil
+}
+
+func estimateQueryCostsError(err error, objectType, relation string) error {
+	switch {
+	case errors.Is(err, typesystem.ErrObjectTypeUndefined):
+		return serverErrors.TypeNotFound(objectType)
+	case errors.Is(err, typesystem.ErrRelationUndefined):
+		return serverErrors.RelationNotFound(relation, objectType, nil)
+	default:
+		return serverErrors.HandleError("", err)
+	}
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	language "github.com/openfga/language/pkg/go/transformer"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+func TestEstimateQueryCosts(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+
+	s := MustNewServerWithOpts(WithDatastore(memory.New()))
+	t.Cleanup(s.Close)
+
+	resp, err := s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:       storeID,
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: language.MustTransformDSLToProto(`
+			model
+				schema 1.1
+
+			type user
+
+			type group
+				relations
+					define member: [user, group#member]
+
+			type document
+				relations
+					define allowed: [user]
+					define viewer: [group#member] and allowed`).GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+	modelID := resp.GetAuthorizationModelId()
+
+	t.Run("relation", func(t *testing.T) {
+		costs, err := s.EstimateQueryCosts(ctx, storeID, modelID, "document", "viewer")
+		require.NoError(t, err)
+		require.Equal(t, []*typesystem.RelationCost{{
+			Type:               "document",
+			Relation:           "viewer",
+			MaxDispatchDepth:   1,
+			HasCycle:           true,
+			DatastoreReadKinds: []typesystem.DatastoreReadKind{typesystem.DatastoreReadUserTuple, typesystem.DatastoreReadUsersetTuples},
+			HasIntersection:    true,
+
+			ListObjectsCheckFallbackUserTypes: []string{"user"},
+		}}, costs)
+	})
+
+	t.Run("type", func(t *testing.T) {
+		costs, err := s.EstimateQueryCosts(ctx, storeID, "", "document", "")
+		require.NoError(t, err)
+		require.Len(t, costs, 2)
+		require.Equal(t, "allowed", costs[0].Relation)
+		require.Equal(t, "viewer", costs[1].Relation)
+	})
+
+	t.Run("model", func(t *testing.T) {
+		costs, err := s.EstimateQueryCosts(ctx, storeID, "", "", "")
+		require.NoError(t, err)
+		require.Len(t, costs, 3)
+	})
+
+	t.Run("type_not_found", func(t *testing.T) {
+		_, err := s.EstimateQueryCosts(ctx, storeID, modelID, "folder", "")
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_type_not_found), status.Code(err))
+
+		_, err = s.EstimateQueryCosts(ctx, storeID, modelID, "folder", "viewer")
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_type_not_found), status.Code(err))
+	})
+
+	t.Run("relation_not_found", func(t *testing.T) {
+		_, err := s.EstimateQueryCosts(ctx, storeID, modelID, "document", "editor")
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_relation_not_found), status.Code(err))
+	})
+
+	t.Run("model_not_found", func(t *testing.T) {
+		modelID := ulid.Make().String()
+		_, err := s.EstimateQueryCosts(ctx, storeID, modelID, "", "")
+		require.ErrorIs(t, err, serverErrors.AuthorizationModelNotFound(modelID))
+	})
+}
@@ -12,11 +12,14 @@ import (
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/openfga/openfga/internal/condition"
 	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/internal/throttler/threshold"
 	"github.com/openfga/openfga/pkg/middleware/validator"
 	"github.com/openfga/openfga/pkg/telemetry"
 	"github.com/openfga/openfga/pkg/tuple"
@@ -32,25 +35,72 @@ func (s *Server) ListUsers(
 	ctx context.Context,
 	req *openfgav1.ListUsersRequest,
 ) (*openfgav1.ListUsersResponse, error) {
-	if !s.IsExperimentallyEnabled(ExperimentalEnableListUsers) {
-		return nil, status.Error(codes.Unimplemented, "ListUsers is not enabled. It can be enabled for experimental use by passing the `--experimentals enable-list-users` configuration option when running OpenFGA server")
+	if !validator.RequestIsValidatedFromContext(ctx) {
+		if err := req.Validate(); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	return s.listUsers(ctx, req, false)
+}
+
+// ListUsersWithFilters is ListUsers accepting any number (at least one) of user filters, e.g. both 'user' and
+// 'group#member', which are evaluated in a single traversal.
+//
+// If expandUsersets is true, each userset filter is replaced by the user types which its usersets may contain that
+// are not usersets themselves, e.g. 'group#member' by 'user', such that 'user:jon' is returned rather than
+// 'group:eng#member'. Hence, the users of those types are returned whether they have the relation through a matching
+// userset or otherwise, e.g. directly.
+func (s *Server) ListUsersWithFilters(
+	ctx context.Context,
+	req *openfgav1.ListUsersRequest,
+	expandUsersets bool,
+) (*openfgav1.ListUsersResponse, error) {
+	if err := validateListUsersRequestWithFilters(req); err != nil {
+		return nil, err
+	}
+
+	return s.listUsers(ctx, req, expandUsersets)
+}
+
+// validateListUsersRequestWithFilters validates a ListUsers request with any number (at least one) of user filters.
+func validateListUsersRequestWithFilters(req *openfgav1.ListUsersRequest) error {
+	userFilters := req.GetUserFilters()
+	if len(userFilters) == 0 {
+		return status.Error(codes.InvalidArgument, "at least one user filter must be provided")
+	}
+
+	// the request itself only allows a single user filter, so validate the others on their own
+	singleUserFilterReq := proto.Clone(req).(*openfgav1.ListUsersRequest)
+	singleUserFilterReq.UserFilters = userFilters[:1]
+	if err := singleUserFilterReq.Validate(); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	for _, userFilter := range userFilters[1:] {
+		if err := userFilter.Validate(); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
 	}
 
+	return nil
+}
+
+func (s *Server) listUsers(
+	ctx context.Context,
+	req *openfgav1.ListUsersRequest,
+	expandUsersets bool,
+) (*openfgav1.ListUsersResponse, error) {
 	start := time.Now()
 	ctx, span := tracer.Start(ctx, "ListUsers", trace.WithAttributes(
 		attribute.String("store_id", req.GetStoreId()),
 		attribute.String("object", tuple.BuildObject(req.GetObject().GetType(), req.GetObject().GetId())),
 		attribute.String("relation", req.GetRelation()),
 		attribute.String("user_filters", userFiltersToString(req.GetUserFilters())),
+		attribute.Bool("expand_usersets", expandUsersets),
 	))
 	defer span.End()
 
-	if !validator.RequestIsValidatedFromContext(ctx) {
-		if err := req.Validate(); err != nil {
-			return nil, status.Error(codes.InvalidArgument, err.Error())
-		}
-	}
-
 	const methodName = "listusers"
 
 	typesys, err := s.resolveTypesystem(ctx, req.GetStoreId(), req.GetAuthorizationModelId())
@@ -65,13 +115,22 @@ func (s *Server) ListUsers(
 
 	ctx = typesystem.ContextWithTypesystem(ctx, typesys)
 
+	limits := s.resolveStoreLimits(req.GetStoreId())
+
 	listUsersQuery := listusers.NewListUsersQuery(s.datastore,
-		listusers.WithResolveNodeLimit(s.resolveNodeLimit),
-		listusers.WithResolveNodeBreadthLimit(s.resolveNodeBreadthLimit),
+		listusers.WithResolveNodeLimit(limits.ResolveNodeLimit),
+		listusers.WithResolveNodeBreadthLimit(limits.ResolveNodeBreadthLimit),
 		listusers.WithListUsersQueryLogger(s.logger),
 		listusers.WithListUsersMaxResults(s.listUsersMaxResults),
 		listusers.WithListUsersDeadline(s.listUsersDeadline),
 		listusers.WithListUsersMaxConcurrentReads(s.maxConcurrentReadsForListUsers),
+		listusers.WithDispatchThrottlerConfig(threshold.Config{
+			Throttler:    s.listUsersDispatchThrottler,
+			Enabled:      s.listUsersDispatchThrottlingEnabled,
+			Threshold:    s.listUsersDispatchDefaultThreshold,
+			MaxThreshold: s.listUsersDispatchThrottlingMaxThreshold,
+		}),
+		listusers.WithListUsersExpandUsersets(expandUsersets),
 	)
 
 	resp, err := listUsersQuery.ListUsers(ctx, req)
@@ -88,6 +147,8 @@ func (s *Server) ListUsers(
 		}
 	}
 
+	s.setTruncationHeaders(ctx, resp.Metadata.WasDeadlineExceeded, resp.Metadata.WasMaxResultsReached, resp.Metadata.WasThrottled)
+
 	datastoreQueryCount := float64(resp.Metadata.DatastoreQueryCount)
 
 	grpc_ctxtags.Extract(ctx).Set(datastoreQueryCountHistogramName, datastoreQueryCount)
@@ -117,9 +178,139 @@ func (s *Server) ListUsers(
 	}, nil
 }
 
+// StreamedListUsersServer is the server API for the stream of a StreamedListUsers request.
+type StreamedListUsersServer interface {
+	Send(*openfgav1.User) error
+	grpc.ServerStream
+}
+
+// StreamedListUsers is the streaming variant of ListUsers: it sends each user matching a specific user filter
+// criteria that has a specific relation with some object as soon as it is found, rather than responding once all of
+// them are found. Users excluded from the relation are never sent, and the stream ends with the TruncatedHeader and
+// TruncationReasonHeader trailers.
+func (s *Server) StreamedListUsers(
+	req *openfgav1.ListUsersRequest,
+	srv StreamedListUsersServer,
+) error {
+	if !validator.RequestIsValidatedFromContext(srv.Context()) {
+		if err := req.Validate(); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	return s.streamedListUsers(req, false, srv)
+}
+
+// StreamedListUsersWithFilters is StreamedListUsers accepting any number (at least one) of user filters, and
+// optionally expanding the userset filters, as ListUsersWithFilters does.
+func (s *Server) StreamedListUsersWithFilters(
+	req *openfgav1.ListUsersRequest,
+	expandUsersets bool,
+	srv StreamedListUsersServer,
+) error {
+	if err := validateListUsersRequestWithFilters(req); err != nil {
+		return err
+	}
+
+	return s.streamedListUsers(req, expandUsersets, srv)
+}
+
+func (s *Server) streamedListUsers(
+	req *openfgav1.ListUsersRequest,
+	expandUsersets bool,
+	srv StreamedListUsersServer,
+) error {
+	start := time.Now()
+	ctx, span := tracer.Start(srv.Context(), "StreamedListUsers", trace.WithAttributes(
+		attribute.String("store_id", req.GetStoreId()),
+		attribute.String("object", tuple.BuildObject(req.GetObject().GetType(), req.GetObject().GetId())),
+		attribute.String("relation", req.GetRelation()),
+		attribute.String("user_filters", userFiltersToString(req.GetUserFilters())),
+		attribute.Bool("expand_usersets", expandUsersets),
+	))
+	defer span.End()
+
+	const methodName = "streamedlistusers"
+
+	typesys, err := s.resolveTypesystem(ctx, req.GetStoreId(), req.GetAuthorizationModelId())
+	if err != nil {
+		return err
+	}
+
+	err = listusers.ValidateListUsersRequest(ctx, req, typesys)
+	if err != nil {
+		return err
+	}
+
+	ctx = typesystem.ContextWithTypesystem(ctx, typesys)
+
+	limits := s.resolveStoreLimits(req.GetStoreId())
+
+	listUsersQuery := listusers.NewListUsersQuery(s.datastore,
+		listusers.WithResolveNodeLimit(limits.ResolveNodeLimit),
+		listusers.WithResolveNodeBreadthLimit(limits.ResolveNodeBreadthLimit),
+		listusers.WithListUsersQueryLogger(s.logger),
+		listusers.WithListUsersMaxResults(s.listUsersMaxResults),
+		listusers.WithListUsersDeadline(s.listUsersDeadline),
+		listusers.WithListUsersMaxConcurrentReads(s.maxConcurrentReadsForListUsers),
+		listusers.WithDispatchThrottlerConfig(threshold.Config{
+			Throttler:    s.listUsersDispatchThrottler,
+			Enabled:      s.listUsersDispatchThrottlingEnabled,
+			Threshold:    s.listUsersDispatchDefaultThreshold,
+			MaxThreshold: s.listUsersDispatchThrottlingMaxThreshold,
+		}),
+		listusers.WithListUsersExpandUsersets(expandUsersets),
+	)
+
+	resolutionMetadata, err := listUsersQuery.StreamedListUsers(ctx, req, srv.Send)
+	if err != nil {
+		telemetry.TraceError(span, err)
+
+		switch {
+		case errors.Is(err, graph.ErrResolutionDepthExceeded):
+			return serverErrors.AuthorizationModelResolutionTooComplex
+		case errors.Is(err, condition.ErrEvaluationFailed):
+			return serverErrors.ValidationError(err)
+		default:
+			return serverErrors.HandleError("", err)
+		}
+	}
+
+	srv.SetTrailer(truncationMetadata(resolutionMetadata.WasDeadlineExceeded, resolutionMetadata.WasMaxResultsReached, resolutionMetadata.WasThrottled))
+
+	datastoreQueryCount := float64(resolutionMetadata.DatastoreQueryCount)
+
+	grpc_ctxtags.Extract(ctx).Set(datastoreQueryCountHistogramName, datastoreQueryCount)
+	span.SetAttributes(attribute.Float64(datastoreQueryCountHistogramName, datastoreQueryCount))
+	datastoreQueryCountHistogram.WithLabelValues(
+		s.serviceName,
+		methodName,
+	).Observe(datastoreQueryCount)
+
+	dispatchCount := float64(resolutionMetadata.DispatchCounter.Load())
+	grpc_ctxtags.Extract(ctx).Set(dispatchCountHistogramName, dispatchCount)
+	span.SetAttributes(attribute.Float64(dispatchCountHistogramName, dispatchCount))
+	dispatchCountHistogram.WithLabelValues(
+		s.serviceName,
+		methodName,
+	).Observe(dispatchCount)
+
+	requestDurationHistogram.WithLabelValues(
+		s.serviceName,
+		methodName,
+		utils.Bucketize(uint(datastoreQueryCount), s.requestDurationByQueryHistogramBuckets),
+		utils.Bucketize(uint(dispatchCount), s.requestDurationByDispatchCountHistogramBuckets),
+	).Observe(float64(time.Since(start).Milliseconds()))
+
+	return nil
+}
+
 func userFiltersToString(filter []*openfgav1.UserTypeFilter) string {
 	var s strings.Builder
-	for _, f := range filter {
+	for i, f := range filter {
+		if i > 0 {
+			s.WriteString(",")
+		}
 		s.WriteString(f.GetType())
 		if f.GetRelation() != "" {
 			s.WriteString("#" + f.GetRelation())
@@ -11,7 +11,9 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/goleak"
 	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	mockstorage "github.com/openfga/openfga/internal/mocks"
@@ -160,7 +162,6 @@ func TestListUsersValidation(t *testing.T) {
 
 			s := MustNewServerWithOpts(
 				WithDatastore(ds),
-				WithExperimentals(ExperimentalEnableListUsers),
 			)
 			t.Cleanup(s.Close)
 
@@ -196,11 +197,11 @@ func TestModelIdNotFound(t *testing.T) {
 	defer mockController.Finish()
 
 	mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+	mockDatastore.EXPECT().ReadActiveAuthorizationModelID(gomock.Any(), gomock.Any()).Return("", storage.ErrNotFound)
 	mockDatastore.EXPECT().FindLatestAuthorizationModel(gomock.Any(), gomock.Any()).Return(nil, storage.ErrNotFound)
 
 	server := MustNewServerWithOpts(
 		WithDatastore(mockDatastore),
-		WithExperimentals(ExperimentalEnableListUsers),
 	)
 	t.Cleanup(func() {
 		mockDatastore.EXPECT().Close().Times(1)
@@ -216,7 +217,7 @@ func TestModelIdNotFound(t *testing.T) {
 	require.Equal(t, codes.Code(2020), e.Code())
 }
 
-func TestExperimentalListUsers(t *testing.T) {
+func TestListUsersWithoutExperimentalFlag(t *testing.T) {
 	ctx := context.Background()
 
 	storeID := ulid.Make().String()
@@ -245,20 +246,10 @@ func TestExperimentalListUsers(t *testing.T) {
 		server.Close()
 	})
 
-	t.Run("list_users_errors_if_not_experimentally_enabled", func(t *testing.T) {
-		_, err := server.ListUsers(ctx, req)
-		require.Error(t, err)
-		require.Equal(t, "rpc error: code = Unimplemented desc = ListUsers is not enabled. It can be enabled for experimental use by passing the `--experimentals enable-list-users` configuration option when running OpenFGA server", err.Error())
-
-		e, ok := status.FromError(err)
-		require.True(t, ok)
-		require.Equal(t, codes.Unimplemented, e.Code())
-	})
-
 	t.Run("list_users_returns_error_if_latest_model_not_found", func(t *testing.T) {
+		mockDatastore.EXPECT().ReadActiveAuthorizationModelID(gomock.Any(), gomock.Any()).Return("", storage.ErrNotFound)
 		mockDatastore.EXPECT().FindLatestAuthorizationModel(gomock.Any(), gomock.Any()).Return(nil, storage.ErrNotFound) // error demonstrates that main code path is reached
 
-		server.experimentals = []ExperimentalFeatureFlag{ExperimentalEnableListUsers}
 		_, err := server.ListUsers(ctx, req)
 
 		st, ok := status.FromError(err)
@@ -271,7 +262,6 @@ func TestExperimentalListUsers(t *testing.T) {
 			ReadAuthorizationModel(gomock.Any(), gomock.Any(), gomock.Any()).
 			Return(nil, storage.ErrNotFound)
 
-		server.experimentals = []ExperimentalFeatureFlag{ExperimentalEnableListUsers}
 		_, err := server.ListUsers(ctx, &openfgav1.ListUsersRequest{
 			StoreId:              storeID,
 			AuthorizationModelId: ulid.Make().String(),
@@ -303,7 +293,6 @@ func TestListUsers_ErrorCases(t *testing.T) {
 		s := MustNewServerWithOpts(
 			WithDatastore(memory.New()),
 			WithResolveNodeLimit(2),
-			WithExperimentals(ExperimentalEnableListUsers),
 		)
 		t.Cleanup(s.Close)
 
@@ -353,6 +342,22 @@ func TestListUsers_ErrorCases(t *testing.T) {
 			require.Nil(t, res)
 			require.ErrorIs(t, err, serverErrors.AuthorizationModelResolutionTooComplex)
 		})
+
+		t.Run("resolution_depth_exceeded_error_streamed", func(t *testing.T) {
+			srv := &mockStreamedListUsersServer{ctx: ctx}
+			err := s.StreamedListUsers(&openfgav1.ListUsersRequest{
+				StoreId:              store,
+				AuthorizationModelId: writeModelResp.GetAuthorizationModelId(),
+				Relation:             "viewer",
+				Object: &openfgav1.Object{
+					Type: "document",
+					Id:   "1",
+				},
+				UserFilters: []*openfgav1.UserTypeFilter{{Type: "user"}},
+			}, srv)
+
+			require.ErrorIs(t, err, serverErrors.AuthorizationModelResolutionTooComplex)
+		})
 	})
 }
 
@@ -393,7 +398,6 @@ func TestListUsers_Deadline(t *testing.T) {
 
 		s := MustNewServerWithOpts(
 			WithDatastore(ds),
-			WithExperimentals(ExperimentalEnableListUsers),
 			WithListUsersDeadline(30*time.Millisecond), // 30ms is enough for first read, but not others
 		)
 		t.Cleanup(s.Close)
@@ -447,7 +451,6 @@ func TestListUsers_Deadline(t *testing.T) {
 
 		s := MustNewServerWithOpts(
 			WithDatastore(mockDatastore),
-			WithExperimentals(ExperimentalEnableListUsers),
 			WithListUsersDeadline(1*time.Minute),
 		)
 		t.Cleanup(s.Close)
@@ -506,7 +509,6 @@ func TestListUsers_Deadline(t *testing.T) {
 
 		s := MustNewServerWithOpts(
 			WithDatastore(mockDatastore),
-			WithExperimentals(ExperimentalEnableListUsers),
 			WithListUsersDeadline(5*time.Millisecond),
 		)
 		t.Cleanup(s.Close)
@@ -538,4 +540,214 @@ func TestUserFiltersToString(t *testing.T) {
 		Type:     "group",
 		Relation: "member",
 	}}))
+
+	require.Equal(t, "user,group#member", userFiltersToString([]*openfgav1.UserTypeFilter{
+		{Type: "user"},
+		{Type: "group", Relation: "member"},
+	}))
+}
+
+type mockStreamedListUsersServer struct {
+	grpc.ServerStream
+	ctx     context.Context
+	users   []string
+	trailer metadata.MD
+}
+
+func (m *mockStreamedListUsersServer) Context() context.Context {
+	return m.ctx
+}
+
+func (m *mockStreamedListUsersServer) Send(user *openfgav1.User) error {
+	m.users = append(m.users, tuple.UserProtoToString(user))
+	return nil
+}
+
+func (m *mockStreamedListUsersServer) SetTrailer(md metadata.MD) {
+	m.trailer = metadata.Join(m.trailer, md)
+}
+
+func TestStreamedListUsers(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+
+	req := &openfgav1.ListUsersRequest{
+		StoreId:     storeID,
+		Object:      &openfgav1.Object{Type: "document", Id: "1"},
+		Relation:    "viewer",
+		UserFilters: []*openfgav1.UserTypeFilter{{Type: "user"}},
+	}
+
+	s := MustNewServerWithOpts(
+		WithDatastore(memory.New()),
+	)
+	t.Cleanup(s.Close)
+
+	_, err := s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:       storeID,
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: language.MustTransformDSLToProto(`
+			model
+				schema 1.1
+
+			type user
+
+			type group
+				relations
+					define member: [user]
+
+			type document
+				relations
+					define blocked: [user]
+					define viewer: [user, group#member] but not blocked`).GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{
+			TupleKeys: []*openfgav1.TupleKey{
+				tuple.NewTupleKey("document:1", "viewer", "user:jon"),
+				tuple.NewTupleKey("document:1", "viewer", "group:eng#member"),
+				tuple.NewTupleKey("group:eng", "member", "user:maria"),
+				tuple.NewTupleKey("group:eng", "member", "user:will"),
+				tuple.NewTupleKey("document:1", "blocked", "user:will"),
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	srv := &mockStreamedListUsersServer{ctx: ctx}
+	err = s.StreamedListUsers(req, srv)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"user:jon", "user:maria"}, srv.users)
+	require.Equal(t, []string{"false"}, srv.trailer.Get(TruncatedHeader))
+
+	resp, err := s.ListUsers(ctx, req)
+	require.NoError(t, err)
+
+	users := make([]string, 0, len(resp.GetUsers()))
+	for _, user := range resp.GetUsers() {
+		users = append(users, tuple.UserProtoToString(user))
+	}
+	require.ElementsMatch(t, users, srv.users)
+}
+
+func TestListUsersWithFilters(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+
+	s := MustNewServerWithOpts(WithDatastore(memory.New()))
+	t.Cleanup(s.Close)
+
+	_, err := s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:       storeID,
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: language.MustTransformDSLToProto(`
+			model
+				schema 1.1
+
+			type user
+
+			type group
+				relations
+					define member: [user]
+
+			type document
+				relations
+					define viewer: [user, group#member]`).GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{
+			TupleKeys: []*openfgav1.TupleKey{
+				tuple.NewTupleKey("document:1", "viewer", "user:jon"),
+				tuple.NewTupleKey("document:1", "viewer", "group:eng#member"),
+				tuple.NewTupleKey("group:eng", "member", "user:maria"),
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	newRequest := func(userFilters ...*openfgav1.UserTypeFilter) *openfgav1.ListUsersRequest {
+		return &openfgav1.ListUsersRequest{
+			StoreId:     storeID,
+			Object:      &openfgav1.Object{Type: "document", Id: "1"},
+			Relation:    "viewer",
+			UserFilters: userFilters,
+		}
+	}
+
+	tests := []struct {
+		name           string
+		req            *openfgav1.ListUsersRequest
+		expandUsersets bool
+		expectedUsers  []string
+		expectedCode   codes.Code
+	}{
+		{
+			name:          "multiple_user_filters",
+			req:           newRequest(&openfgav1.UserTypeFilter{Type: "user"}, &openfgav1.UserTypeFilter{Type: "group", Relation: "member"}),
+			expectedUsers: []string{"user:jon", "user:maria", "group:eng#member"},
+		},
+		{
+			name:           "expand_usersets",
+			req:            newRequest(&openfgav1.UserTypeFilter{Type: "user"}, &openfgav1.UserTypeFilter{Type: "group", Relation: "member"}),
+			expandUsersets: true,
+			expectedUsers:  []string{"user:jon", "user:maria"},
+		},
+		{
+			name:         "no_user_filters",
+			req:          newRequest(),
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:         "invalid_user_filter",
+			req:          newRequest(&openfgav1.UserTypeFilter{Type: "user"}, &openfgav1.UserTypeFilter{Type: "invalid type"}),
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name:         "undefined_user_filter_type",
+			req:          newRequest(&openfgav1.UserTypeFilter{Type: "user"}, &openfgav1.UserTypeFilter{Type: "undefined"}),
+			expectedCode: codes.Code(openfgav1.ErrorCode_type_not_found),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp, err := s.ListUsersWithFilters(ctx, test.req, test.expandUsersets)
+			if test.expectedCode != codes.OK {
+				require.Equal(t, test.expectedCode, status.Code(err))
+				return
+			}
+			require.NoError(t, err)
+
+			users := make([]string, 0, len(resp.GetUsers()))
+			for _, user := range resp.GetUsers() {
+				users = append(users, tuple.UserProtoToString(user))
+			}
+			require.ElementsMatch(t, test.expectedUsers, users)
+		})
+
+		t.Run(test.name+"_streamed", func(t *testing.T) {
+			srv := &mockStreamedListUsersServer{ctx: ctx}
+			err := s.StreamedListUsersWithFilters(test.req, test.expandUsersets, srv)
+			if test.expectedCode != codes.OK {
+				require.Equal(t, test.expectedCode, status.Code(err))
+				return
+			}
+			require.NoError(t, err)
+			require.ElementsMatch(t, test.expectedUsers, srv.users)
+		})
+	}
 }
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"context"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+// WriteModularAuthorizationModel composes the modules of a modular authorization model into a single model (see
+// typesystem.ComposeModules) and writes it as WriteAuthorizationModel does, so that teams can own separate modules
+// of the model of a store. The errors about invalid types, relations and conditions refer to the modules declaring them.
+func (s *Server) WriteModularAuthorizationModel(
+	ctx context.Context,
+	storeID string,
+	modules []typesystem.Module,
+) (*openfgav1.WriteAuthorizationModelResponse, error) {
+	ctx, span := tracer.Start(ctx, "WriteModularAuthorizationModel", trace.WithAttributes(
+		attribute.String("store_id", storeID),
+		attribute.Int("modules", len(modules)),
+	))
+	defer span.End()
+
+	model, err := typesystem.ComposeModules(modules)
+	if err != nil {
+		return nil, serverErrors.InvalidAuthorizationModelInput(err)
+	}
+
+	return s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   model.GetSchemaVersion(),
+		TypeDefinitions: model.GetTypeDefinitions(),
+		Conditions:      model.GetConditions(),
+	})
+}
@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+func TestWriteModularAuthorizationModel(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+
+	s := MustNewServerWithOpts(WithDatastore(memory.New()))
+	t.Cleanup(s.Close)
+
+	core := typesystem.Module{
+		Name: "core.fga",
+		Contents: `module core
+
+type user
+
+type folder
+  relations
+    define owner: [user]`,
+	}
+
+	t.Run("composed", func(t *testing.T) {
+		resp, err := s.WriteModularAuthorizationModel(ctx, storeID, []typesystem.Module{core, {
+			Name: "docs.fga",
+			Contents: `module docs
+
+extend type folder
+  relations
+    define viewer: [user] or owner`,
+		}})
+		require.NoError(t, err)
+
+		readResp, err := s.ReadAuthorizationModel(ctx, &openfgav1.ReadAuthorizationModelRequest{
+			StoreId: storeID,
+			Id:      resp.GetAuthorizationModelId(),
+		})
+		require.NoError(t, err)
+		require.Equal(t, typesystem.SchemaVersion1_2, readResp.GetAuthorizationModel().GetSchemaVersion())
+
+		_, err = s.Write(ctx, &openfgav1.WriteRequest{
+			StoreId: storeID,
+			Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+				tuple.NewTupleKey("folder:1", "owner", "user:anne"),
+			}},
+		})
+		require.NoError(t, err)
+
+		checkResp, err := s.Check(ctx, &openfgav1.CheckRequest{
+			StoreId:              storeID,
+			AuthorizationModelId: resp.GetAuthorizationModelId(),
+			TupleKey:             tuple.NewCheckRequestTupleKey("folder:1", "viewer", "user:anne"),
+		})
+		require.NoError(t, err)
+		require.True(t, checkResp.GetAllowed())
+	})
+
+	t.Run("invalid_module", func(t *testing.T) {
+		_, err := s.WriteModularAuthorizationModel(ctx, storeID, []typesystem.Module{core, {
+			Name: "audit.fga",
+			Contents: `module audit
+
+extend type folder
+  relations
+    define auditor: [user] and editor`,
+		}})
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_invalid_authorization_model), status.Code(err))
+		require.Contains(t, status.Convert(err).Message(), "in module 'audit' (file 'audit.fga')")
+	})
+
+	t.Run("extended_type_not_declared", func(t *testing.T) {
+		_, err := s.WriteModularAuthorizationModel(ctx, storeID, []typesystem.Module{{
+			Name: "docs.fga",
+			Contents: `module docs
+
+extend type folder
+  relations
+    define viewer: [user]`,
+		}})
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_invalid_authorization_model), status.Code(err))
+		require.Contains(t, status.Convert(err).Message(), "module file 'docs.fga'")
+	})
+}
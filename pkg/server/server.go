@@ -3,12 +3,17 @@ package server
 
 import (
 	"context"
+	"crypto/rand"
 	"errors"
 	"fmt"
+	"maps"
 	"net/http"
 	"slices"
 	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/openfga/openfga/internal/throttler/threshold"
@@ -16,6 +21,8 @@ import (
 	"github.com/openfga/openfga/internal/throttler"
 
 	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"github.com/karlseguin/ccache/v3"
+	"github.com/oklog/ulid/v2"
 	openfgav1 "github.com/openfga/api/proto/openfga/v1"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
@@ -24,11 +31,14 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/openfga/openfga/internal/build"
 	"github.com/openfga/openfga/internal/condition"
 	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/internal/materialize"
 	serverconfig "github.com/openfga/openfga/internal/server/config"
 	"github.com/openfga/openfga/internal/utils"
 	"github.com/openfga/openfga/internal/validation"
@@ -49,9 +59,35 @@ import (
 type ExperimentalFeatureFlag string
 
 const (
-	AuthorizationModelIDHeader                          = "Openfga-Authorization-Model-Id"
-	authorizationModelIDKey                             = "authorization_model_id"
+	AuthorizationModelIDHeader = "Openfga-Authorization-Model-Id"
+
+	// AuthorizationModelLintWarningHeader is the header of the responses of WriteAuthorizationModel, set once per
+	// lint warning about the model written if WithAuthorizationModelLinting is enabled.
+	AuthorizationModelLintWarningHeader = "Openfga-Authorization-Model-Lint-Warning"
+
+	// StreamedListObjectsDatastoreQueryCountTrailer, StreamedListObjectsDispatchCountTrailer and
+	// StreamedListObjectsDeadlineExceededTrailer are the trailers of a successful StreamedListObjects stream,
+	// carrying its resolution metadata once all objects have been streamed.
+	StreamedListObjectsDatastoreQueryCountTrailer = "Openfga-Datastore-Query-Count"
+	StreamedListObjectsDispatchCountTrailer       = "Openfga-Dispatch-Count"
+	StreamedListObjectsDeadlineExceededTrailer    = "Openfga-Deadline-Exceeded"
+
+	authorizationModelIDKey = "authorization_model_id"
+
+	// continuationTokenSigningKeySize is the size, in bytes, of the continuation token signing key generated if
+	// none is configured.
+	continuationTokenSigningKeySize = 32
+
+	// listObjectsFrontierCacheSizeInBytes is the total size of the reverse expansion frontiers of paginated
+	// ListObjects queries which are cached to resume them for their next page.
+	listObjectsFrontierCacheSizeInBytes = 64 << 20
+
+	// Deprecated: ListUsers is no longer experimental and is always enabled; this flag has no effect.
 	ExperimentalEnableListUsers ExperimentalFeatureFlag = "enable-list-users"
+
+	// ExperimentalCheckRecursiveFastPath evaluates Checks of directly recursive relations (e.g. nested groups)
+	// with a breadth-first search instead of dispatching once per level of nesting.
+	ExperimentalCheckRecursiveFastPath ExperimentalFeatureFlag = "enable-check-recursive-fast-path"
 )
 
 var tracer = otel.Tracer("openfga/pkg/server")
@@ -108,25 +144,58 @@ type Server struct {
 	changelogHorizonOffset           int
 	listObjectsDeadline              time.Duration
 	listObjectsMaxResults            uint32
+	continuationTokenSigningKey      []byte
+	listObjectsFrontierCache         *commands.ListObjectsFrontierCache
+	streamedListObjectsDeduplication bool
+	streamedListObjectsSorting       bool
 	listUsersDeadline                time.Duration
 	listUsersMaxResults              uint32
 	maxConcurrentReadsForListObjects uint32
 	maxConcurrentReadsForCheck       uint32
 	maxConcurrentReadsForListUsers   uint32
+	storeLimits                      map[string]StoreLimits
 	maxAuthorizationModelCacheSize   int
 	maxAuthorizationModelSizeInBytes int
 	experimentals                    []ExperimentalFeatureFlag
 	serviceName                      string
 
+	authorizationModelCompatibilityCheckEnabled   bool
+	rejectIncompatibleAuthorizationModels         bool
+	authorizationModelCompatibilityCheckMaxTuples int
+	authorizationModelCompatibilityCheckDeadline  time.Duration
+
+	authorizationModelLintEnabled bool
+
+	authorizationModelRetentionEnabled        bool
+	authorizationModelRetentionKeepLast       int
+	authorizationModelRetentionKeepUsedWithin time.Duration
+	authorizationModelRetentionInterval       time.Duration
+	authorizationModelsLastUsed               *ccache.Cache[time.Time]
+	authorizationModelUses                    chan authorizationModelUse
+	authorizationModelUseRecordedSince        atomic.Pointer[time.Time]
+	authorizationModelRetentionLeaseHolder    string
+	authorizationModelRetentionCancel         context.CancelFunc
+	authorizationModelRetentionWg             sync.WaitGroup
+
 	// NOTE don't use this directly, use function resolveTypesystem. See https://github.com/openfga/openfga/issues/1527
 	typesystemResolver     typesystem.TypesystemResolverFunc
 	typesystemResolverStop func()
 
+	// activeAuthorizationModelIDCache is the cache of the active models of the stores of the typesystem resolver.
+	activeAuthorizationModelIDCache *typesystem.ActiveAuthorizationModelIDCache
+
 	checkQueryCacheEnabled bool
 	checkQueryCacheLimit   uint32
 	checkQueryCacheTTL     time.Duration
 	cachedCheckResolver    *graph.CachedCheckResolver
 
+	materializationEnabled      bool
+	materializedRelations       []string
+	materializationSyncInterval time.Duration
+	materializationMaxStaleness time.Duration
+	materializationMaxEntries   int
+	materializedIndex           *materialize.Index
+
 	checkResolver graph.CheckResolver
 
 	requestDurationByQueryHistogramBuckets         []uint
@@ -142,9 +211,15 @@ type Server struct {
 	listObjectsDispatchDefaultThreshold       uint32
 	listObjectsDispatchThrottlingMaxThreshold uint32
 
+	listUsersDispatchThrottlingEnabled      bool
+	listUsersDispatchThrottlingFrequency    time.Duration
+	listUsersDispatchDefaultThreshold       uint32
+	listUsersDispatchThrottlingMaxThreshold uint32
+
 	dispatchThrottlingCheckResolver *graph.DispatchThrottlingCheckResolver
 
 	listObjectsDispatchThrottler throttler.Throttler
+	listUsersDispatchThrottler   throttler.Throttler
 }
 
 type OpenFGAServiceV1Option func(s *Server)
@@ -176,6 +251,15 @@ func WithTokenEncoder(encoder encoder.Encoder) OpenFGAServiceV1Option {
 	}
 }
 
+// WithContinuationTokenSigningKey sets the key which the continuation tokens of paginated ListObjects queries are
+// signed with (see ListObjectsPaginated). If empty, a random key is generated, so the tokens are only valid on this
+// server instance.
+func WithContinuationTokenSigningKey(key []byte) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.continuationTokenSigningKey = key
+	}
+}
+
 // WithTransport sets the connection transport.
 func WithTransport(t gateway.Transport) OpenFGAServiceV1Option {
 	return func(s *Server) {
@@ -205,6 +289,27 @@ func WithResolveNodeBreadthLimit(limit uint32) OpenFGAServiceV1Option {
 	}
 }
 
+// StoreLimits overrides the server-wide resolution limits for the queries of a single store.
+// A limit left unset (zero) falls back to the value configured for the server.
+type StoreLimits struct {
+	// ResolveNodeLimit overrides the limit set with WithResolveNodeLimit.
+	ResolveNodeLimit uint32
+
+	// ResolveNodeBreadthLimit overrides the limit set with WithResolveNodeBreadthLimit.
+	ResolveNodeBreadthLimit uint32
+
+	// MaxConcurrentReadsForCheck overrides the limit set with WithMaxConcurrentReadsForCheck.
+	MaxConcurrentReadsForCheck uint32
+}
+
+// WithStoreLimits overrides the resolution limits of Check, ListObjects and ListUsers calls for the stores
+// with the given IDs. This allows a multi-tenant deployment to give some tenants tighter or more generous limits than the rest.
+func WithStoreLimits(limits map[string]StoreLimits) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		maps.Copy(s.storeLimits, limits)
+	}
+}
+
 // WithChangelogHorizonOffset sets an offset (in minutes) from the current time.
 // Changes that occur after this offset will not be included in the response of ReadChanges API.
 // If your datastore is eventually consistent or if you have a database with replication delay, we recommend setting this (e.g. 1 minute).
@@ -230,6 +335,23 @@ func WithListObjectsMaxResults(limit uint32) OpenFGAServiceV1Option {
 	}
 }
 
+// WithStreamedListObjectsDeduplication affects the StreamedListObjects API only.
+// If enabled, each object is streamed at most once.
+func WithStreamedListObjectsDeduplication(enabled bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.streamedListObjectsDeduplication = enabled
+	}
+}
+
+// WithStreamedListObjectsSorting affects the StreamedListObjects API only.
+// If enabled, the objects are buffered until all of them have been found (up to the ListObjects max results,
+// or until the ListObjects deadline is hit) and then streamed at most once each, in lexicographical order.
+func WithStreamedListObjectsSorting(enabled bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.streamedListObjectsSorting = enabled
+	}
+}
+
 // WithListUsersDeadline affect the ListUsers API only.
 // It sets the maximum amount of time that the server will spend gathering results.
 func WithListUsersDeadline(deadline time.Duration) OpenFGAServiceV1Option {
@@ -317,6 +439,48 @@ func WithCheckQueryCacheTTL(ttl time.Duration) OpenFGAServiceV1Option {
 	}
 }
 
+// WithMaterializationEnabled enables an index of precomputed permissions for the relations set with
+// WithMaterializedRelations. Check requests against those relations are answered from the index when it was
+// synced with the changelog of the store within the max staleness, which makes their responses eventually
+// consistent.
+func WithMaterializationEnabled(enabled bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.materializationEnabled = enabled
+	}
+}
+
+// WithMaterializedRelations sets the relations to materialize if materialization is enabled, as 'type#relation'
+// (e.g. 'document#viewer'). Relations involving intersections, exclusions or conditions are not materialized.
+func WithMaterializedRelations(relations ...string) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.materializedRelations = relations
+	}
+}
+
+// WithMaterializationSyncInterval sets the interval at which the index of materialized relations applies
+// the changes of the changelog.
+func WithMaterializationSyncInterval(interval time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.materializationSyncInterval = interval
+	}
+}
+
+// WithMaterializationMaxStaleness sets the maximum time since the start of the last sync of the index of a store
+// for which Check requests are answered from it.
+func WithMaterializationMaxStaleness(maxStaleness time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.materializationMaxStaleness = maxStaleness
+	}
+}
+
+// WithMaterializationMaxEntriesPerStore sets the maximum number of (user, object) pairs the index of a store
+// holds, beyond which Check requests of the store are no longer answered from it.
+func WithMaterializationMaxEntriesPerStore(maxEntries int) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.materializationMaxEntries = maxEntries
+	}
+}
+
 // WithRequestDurationByQueryHistogramBuckets sets the buckets used in labelling the requestDurationByQueryAndDispatchHistogram.
 func WithRequestDurationByQueryHistogramBuckets(buckets []uint) OpenFGAServiceV1Option {
 	return func(s *Server) {
@@ -339,6 +503,84 @@ func WithMaxAuthorizationModelSizeInBytes(size int) OpenFGAServiceV1Option {
 	}
 }
 
+// WithAuthorizationModelCompatibilityCheck affects the WriteAuthorizationModel API only.
+// If enabled, the tuples of the store are read before writing the model, and those which are invalid under
+// the model (e.g. because it removes their relation or their type of user) are logged.
+func WithAuthorizationModelCompatibilityCheck(enabled bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.authorizationModelCompatibilityCheckEnabled = enabled
+	}
+}
+
+// WithRejectIncompatibleAuthorizationModels affects the WriteAuthorizationModel API only.
+// If enabled along with WithAuthorizationModelCompatibilityCheck, models under which some tuples of the
+// store are invalid are rejected rather than written.
+func WithRejectIncompatibleAuthorizationModels(reject bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.rejectIncompatibleAuthorizationModels = reject
+	}
+}
+
+// WithAuthorizationModelCompatibilityCheckMaxTuples affects the WriteAuthorizationModel API only.
+// It sets the maximum number of tuples of the store read by a compatibility check, after which the compatibility
+// of the model is unknown.
+func WithAuthorizationModelCompatibilityCheckMaxTuples(maxTuples int) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.authorizationModelCompatibilityCheckMaxTuples = maxTuples
+	}
+}
+
+// WithAuthorizationModelCompatibilityCheckDeadline affects the WriteAuthorizationModel API only.
+// It sets the maximum duration of a compatibility check, after which the compatibility of the model is unknown.
+func WithAuthorizationModelCompatibilityCheckDeadline(deadline time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.authorizationModelCompatibilityCheckDeadline = deadline
+	}
+}
+
+// WithAuthorizationModelLinting affects the WriteAuthorizationModel API only.
+// If enabled, the model written is linted, and the response carries an AuthorizationModelLintWarningHeader
+// header per warning about it (see typesystem.Lint).
+func WithAuthorizationModelLinting(enabled bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.authorizationModelLintEnabled = enabled
+	}
+}
+
+// WithAuthorizationModelRetentionEnabled enables garbage collecting the authorization models of the stores at the
+// interval set with WithAuthorizationModelRetentionInterval, according to the retention policy (see
+// GarbageCollectAuthorizationModels). If enabled, the use of the models by requests is recorded in the datastore, and
+// only one of the servers sharing the datastore garbage collects the models at a time.
+func WithAuthorizationModelRetentionEnabled(enabled bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.authorizationModelRetentionEnabled = enabled
+	}
+}
+
+// WithAuthorizationModelRetentionKeepLast sets the number of latest authorization models of each store which are
+// kept by the retention policy. It must be at least 1.
+func WithAuthorizationModelRetentionKeepLast(keepLast int) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.authorizationModelRetentionKeepLast = keepLast
+	}
+}
+
+// WithAuthorizationModelRetentionKeepUsedWithin sets the duration for which the authorization models written or
+// used by requests are kept by the retention policy.
+func WithAuthorizationModelRetentionKeepUsedWithin(keepUsedWithin time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.authorizationModelRetentionKeepUsedWithin = keepUsedWithin
+	}
+}
+
+// WithAuthorizationModelRetentionInterval sets the interval at which the authorization models of the stores are
+// garbage collected if the retention is enabled.
+func WithAuthorizationModelRetentionInterval(interval time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.authorizationModelRetentionInterval = interval
+	}
+}
+
 // WithDispatchThrottlingCheckResolverEnabled sets whether dispatch throttling is enabled for Check requests.
 // Enabling this feature will prioritize dispatched requests requiring less than the configured dispatch
 // threshold over requests whose dispatch count exceeds the configured threshold.
@@ -429,6 +671,44 @@ func WithListObjectsDispatchThrottlingMaxThreshold(maxThreshold uint32) OpenFGAS
 	}
 }
 
+// WithListUsersDispatchThrottlingEnabled sets whether dispatch throttling is enabled for List Users requests.
+// Enabling this feature will prioritize dispatched requests requiring less than the configured dispatch
+// threshold over requests whose dispatch count exceeds the configured threshold.
+func WithListUsersDispatchThrottlingEnabled(enabled bool) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.listUsersDispatchThrottlingEnabled = enabled
+	}
+}
+
+// WithListUsersDispatchThrottlingFrequency defines how frequent dispatch throttling
+// will be evaluated for List Users requests.
+// Frequency controls how frequently throttled dispatch requests are evaluated to determine whether
+// it can be processed.
+// This value should not be too small (i.e., in the ns ranges) as i) there are limitation in timer resolution
+// and ii) very small value will result in a higher frequency of processing dispatches,
+// which diminishes the value of the throttling.
+func WithListUsersDispatchThrottlingFrequency(frequency time.Duration) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.listUsersDispatchThrottlingFrequency = frequency
+	}
+}
+
+// WithListUsersDispatchThrottlingThreshold define the number of dispatches to be throttled
+// for List Users requests.
+func WithListUsersDispatchThrottlingThreshold(threshold uint32) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.listUsersDispatchDefaultThreshold = threshold
+	}
+}
+
+// WithListUsersDispatchThrottlingMaxThreshold define the maximum threshold values allowed
+// It will ensure listUsersDispatchThrottlingMaxThreshold will never be smaller than threshold.
+func WithListUsersDispatchThrottlingMaxThreshold(maxThreshold uint32) OpenFGAServiceV1Option {
+	return func(s *Server) {
+		s.listUsersDispatchThrottlingMaxThreshold = maxThreshold
+	}
+}
+
 // NewServerWithOpts returns a new server.
 // You must call Close on it after you are done using it.
 func NewServerWithOpts(opts ...OpenFGAServiceV1Option) (*Server, error) {
@@ -441,20 +721,40 @@ func NewServerWithOpts(opts ...OpenFGAServiceV1Option) (*Server, error) {
 		resolveNodeBreadthLimit:          serverconfig.DefaultResolveNodeBreadthLimit,
 		listObjectsDeadline:              serverconfig.DefaultListObjectsDeadline,
 		listObjectsMaxResults:            serverconfig.DefaultListObjectsMaxResults,
+		streamedListObjectsDeduplication: serverconfig.DefaultStreamedListObjectsDeduplication,
+		streamedListObjectsSorting:       serverconfig.DefaultStreamedListObjectsSorting,
 		listUsersDeadline:                serverconfig.DefaultListUsersDeadline,
 		listUsersMaxResults:              serverconfig.DefaultListUsersMaxResults,
 		maxConcurrentReadsForCheck:       serverconfig.DefaultMaxConcurrentReadsForCheck,
 		maxConcurrentReadsForListObjects: serverconfig.DefaultMaxConcurrentReadsForListObjects,
 		maxConcurrentReadsForListUsers:   serverconfig.DefaultMaxConcurrentReadsForListUsers,
+		storeLimits:                      map[string]StoreLimits{},
 		maxAuthorizationModelSizeInBytes: serverconfig.DefaultMaxAuthorizationModelSizeInBytes,
 		maxAuthorizationModelCacheSize:   serverconfig.DefaultMaxAuthorizationModelCacheSize,
 		experimentals:                    make([]ExperimentalFeatureFlag, 0, 10),
 
+		authorizationModelCompatibilityCheckEnabled:   serverconfig.DefaultAuthorizationModelCompatibilityCheckEnabled,
+		rejectIncompatibleAuthorizationModels:         serverconfig.DefaultAuthorizationModelCompatibilityCheckReject,
+		authorizationModelCompatibilityCheckMaxTuples: serverconfig.DefaultAuthorizationModelCompatibilityCheckMaxTuples,
+		authorizationModelCompatibilityCheckDeadline:  serverconfig.DefaultAuthorizationModelCompatibilityCheckDeadline,
+
+		authorizationModelLintEnabled: serverconfig.DefaultAuthorizationModelLintEnabled,
+
+		authorizationModelRetentionEnabled:        serverconfig.DefaultAuthorizationModelRetentionEnabled,
+		authorizationModelRetentionKeepLast:       serverconfig.DefaultAuthorizationModelRetentionKeepLast,
+		authorizationModelRetentionKeepUsedWithin: serverconfig.DefaultAuthorizationModelRetentionKeepUsedWithin,
+		authorizationModelRetentionInterval:       serverconfig.DefaultAuthorizationModelRetentionInterval,
+
 		checkQueryCacheEnabled: serverconfig.DefaultCheckQueryCacheEnable,
 		checkQueryCacheLimit:   serverconfig.DefaultCheckQueryCacheLimit,
 		checkQueryCacheTTL:     serverconfig.DefaultCheckQueryCacheTTL,
 		checkResolver:          nil,
 
+		materializationEnabled:      serverconfig.DefaultMaterializationEnabled,
+		materializationSyncInterval: serverconfig.DefaultMaterializationSyncInterval,
+		materializationMaxStaleness: serverconfig.DefaultMaterializationMaxStaleness,
+		materializationMaxEntries:   serverconfig.DefaultMaterializationMaxEntriesPerStore,
+
 		requestDurationByQueryHistogramBuckets:         []uint{50, 200},
 		requestDurationByDispatchCountHistogramBuckets: []uint{50, 200},
 		serviceName: openfgav1.OpenFGAService_ServiceDesc.ServiceName,
@@ -467,6 +767,11 @@ func NewServerWithOpts(opts ...OpenFGAServiceV1Option) (*Server, error) {
 		listObjectsDispatchThrottlingFrequency:    serverconfig.DefaultListObjectsDispatchThrottlingFrequency,
 		listObjectsDispatchDefaultThreshold:       serverconfig.DefaultListObjectsDispatchThrottlingDefaultThreshold,
 		listObjectsDispatchThrottlingMaxThreshold: serverconfig.DefaultListObjectsDispatchThrottlingMaxThreshold,
+
+		listUsersDispatchThrottlingEnabled:      serverconfig.DefaultListUsersDispatchThrottlingEnabled,
+		listUsersDispatchThrottlingFrequency:    serverconfig.DefaultListUsersDispatchThrottlingFrequency,
+		listUsersDispatchDefaultThreshold:       serverconfig.DefaultListUsersDispatchThrottlingDefaultThreshold,
+		listUsersDispatchThrottlingMaxThreshold: serverconfig.DefaultListUsersDispatchThrottlingMaxThreshold,
 	}
 
 	for _, opt := range opts {
@@ -492,14 +797,79 @@ func NewServerWithOpts(opts ...OpenFGAServiceV1Option) (*Server, error) {
 		return nil, fmt.Errorf("ListObjects default dispatch throttling threshold must be equal or smaller than max dispatch threshold for ListObjects")
 	}
 
+	if s.listUsersDispatchThrottlingMaxThreshold != 0 && s.listUsersDispatchDefaultThreshold > s.listUsersDispatchThrottlingMaxThreshold {
+		return nil, fmt.Errorf("ListUsers default dispatch throttling threshold must be equal or smaller than max dispatch threshold for ListUsers")
+	}
+
+	materializedRelations := make([]materialize.IndexOption, 0, len(s.materializedRelations))
+	for _, relation := range s.materializedRelations {
+		objectType, relationName, ok := strings.Cut(relation, "#")
+		if !ok || objectType == "" || relationName == "" {
+			return nil, fmt.Errorf("materialized relation '%s' must be of the form 'type#relation'", relation)
+		}
+
+		materializedRelations = append(materializedRelations, materialize.WithRelation(objectType, relationName))
+	}
+
+	if s.materializationEnabled && s.materializationSyncInterval <= 0 {
+		return nil, fmt.Errorf("materialization sync interval must be a positive time duration")
+	}
+
+	if s.materializationEnabled && s.materializationMaxStaleness < s.materializationSyncInterval {
+		return nil, fmt.Errorf("materialization max staleness must be at least the materialization sync interval")
+	}
+
+	if s.materializationEnabled && s.materializationMaxEntries <= 0 {
+		return nil, fmt.Errorf("materialization max entries per store must be a positive integer")
+	}
+
+	if s.authorizationModelRetentionKeepLast < 1 {
+		return nil, fmt.Errorf("authorization model retention must keep at least the latest model")
+	}
+
+	if s.authorizationModelRetentionEnabled && s.authorizationModelRetentionInterval <= 0 {
+		return nil, fmt.Errorf("authorization model retention interval must be a positive time duration")
+	}
+
+	if len(s.continuationTokenSigningKey) == 0 {
+		s.continuationTokenSigningKey = make([]byte, continuationTokenSigningKeySize)
+		if _, err := rand.Read(s.continuationTokenSigningKey); err != nil {
+			return nil, fmt.Errorf("failed to generate a continuation token signing key: %w", err)
+		}
+
+		s.logger.Info("No continuation token signing key is configured, so the continuation tokens of paginated ListObjects queries are only valid on this server instance")
+	}
+
 	// below this point, don't throw errors or we may leak resources in tests
 
+	s.listObjectsFrontierCache = commands.NewListObjectsFrontierCache(listObjectsFrontierCacheSizeInBytes)
+
 	cycleDetectionCheckResolver := graph.NewCycleDetectionCheckResolver()
 	s.checkResolver = cycleDetectionCheckResolver
 
-	localChecker := graph.NewLocalChecker(
+	localCheckerOpts := []graph.LocalCheckerOption{
 		graph.WithResolveNodeBreadthLimit(s.resolveNodeBreadthLimit),
-	)
+	}
+
+	var checkDispatchThrottler throttler.Throttler
+	var checkDispatchThrottlingConfig graph.DispatchThrottlingCheckResolverConfig
+	if s.checkDispatchThrottlingEnabled {
+		checkDispatchThrottler = throttler.NewConstantRateThrottler(s.checkDispatchThrottlingFrequency, "check_dispatch_throttle")
+		checkDispatchThrottlingConfig = graph.DispatchThrottlingCheckResolverConfig{
+			DefaultThreshold: s.checkDispatchThrottlingDefaultThreshold,
+			MaxThreshold:     s.checkDispatchThrottlingMaxThreshold,
+		}
+	}
+
+	if s.IsExperimentallyEnabled(ExperimentalCheckRecursiveFastPath) {
+		localCheckerOpts = append(localCheckerOpts, graph.WithRecursiveFastPath())
+
+		if checkDispatchThrottler != nil {
+			localCheckerOpts = append(localCheckerOpts, graph.WithRecursiveFastPathThrottling(checkDispatchThrottler, checkDispatchThrottlingConfig))
+		}
+	}
+
+	localChecker := graph.NewLocalChecker(localCheckerOpts...)
 
 	cycleDetectionCheckResolver.SetDelegate(localChecker)
 	localChecker.SetDelegate(cycleDetectionCheckResolver)
@@ -527,14 +897,9 @@ func NewServerWithOpts(opts ...OpenFGAServiceV1Option) (*Server, error) {
 			zap.Uint32("MaxThreshold", s.checkDispatchThrottlingMaxThreshold),
 		)
 
-		dispatchThrottlingConfig := graph.DispatchThrottlingCheckResolverConfig{
-			DefaultThreshold: s.checkDispatchThrottlingDefaultThreshold,
-			MaxThreshold:     s.checkDispatchThrottlingMaxThreshold,
-		}
-
 		dispatchThrottlingCheckResolver := graph.NewDispatchThrottlingCheckResolver(
-			graph.WithDispatchThrottlingCheckResolverConfig(dispatchThrottlingConfig),
-			graph.WithThrottler(throttler.NewConstantRateThrottler(s.checkDispatchThrottlingFrequency, "check_dispatch_throttle")),
+			graph.WithDispatchThrottlingCheckResolverConfig(checkDispatchThrottlingConfig),
+			graph.WithThrottler(checkDispatchThrottler),
 		)
 		dispatchThrottlingCheckResolver.SetDelegate(localChecker)
 		s.dispatchThrottlingCheckResolver = dispatchThrottlingCheckResolver
@@ -556,9 +921,65 @@ func NewServerWithOpts(opts ...OpenFGAServiceV1Option) (*Server, error) {
 		s.listObjectsDispatchThrottler = throttler.NewConstantRateThrottler(s.listObjectsDispatchThrottlingFrequency, "list_objects_dispatch_throttle")
 	}
 
+	if s.listUsersDispatchThrottlingEnabled {
+		s.logger.Info("Enabling ListUsers dispatch throttling",
+			zap.Duration("Frequency", s.listUsersDispatchThrottlingFrequency),
+			zap.Uint32("DefaultThreshold", s.listUsersDispatchDefaultThreshold),
+			zap.Uint32("MaxThreshold", s.listUsersDispatchThrottlingMaxThreshold),
+		)
+
+		s.listUsersDispatchThrottler = throttler.NewConstantRateThrottler(s.listUsersDispatchThrottlingFrequency, "list_users_dispatch_throttle")
+	}
+
 	s.datastore = storagewrappers.NewCachedOpenFGADatastore(storagewrappers.NewContextWrapper(s.datastore), s.maxAuthorizationModelCacheSize)
 
-	s.typesystemResolver, s.typesystemResolverStop = typesystem.MemoizedTypesystemResolverFunc(s.datastore)
+	if s.materializationEnabled && len(materializedRelations) > 0 {
+		s.logger.Info("Materialization is enabled and answers Checks of the materialized relations from an index synced at the configured sync interval",
+			zap.Strings("Relations", s.materializedRelations),
+			zap.Duration("SyncInterval", s.materializationSyncInterval),
+			zap.Duration("MaxStaleness", s.materializationMaxStaleness),
+			zap.Int("MaxEntriesPerStore", s.materializationMaxEntries))
+
+		s.materializedIndex = materialize.NewIndex(s.datastore, append(materializedRelations,
+			materialize.WithSyncInterval(s.materializationSyncInterval),
+			materialize.WithMaxStaleness(s.materializationMaxStaleness),
+			materialize.WithMaxEntriesPerStore(s.materializationMaxEntries),
+			materialize.WithLogger(s.logger),
+		)...)
+
+		materializedCheckResolver := materialize.NewCheckResolver(s.materializedIndex)
+		materializedCheckResolver.SetDelegate(cycleDetectionCheckResolver.GetDelegate())
+		cycleDetectionCheckResolver.SetDelegate(materializedCheckResolver)
+	}
+
+	s.activeAuthorizationModelIDCache = typesystem.NewActiveAuthorizationModelIDCache()
+	s.typesystemResolver, s.typesystemResolverStop = typesystem.MemoizedTypesystemResolverFunc(s.datastore,
+		typesystem.WithActiveAuthorizationModelIDCache(s.activeAuthorizationModelIDCache),
+	)
+
+	if s.authorizationModelRetentionEnabled {
+		s.logger.Info("Authorization model retention is enabled and garbage collects the unused authorization models of the stores",
+			zap.Int("KeepLast", s.authorizationModelRetentionKeepLast),
+			zap.Duration("KeepUsedWithin", s.authorizationModelRetentionKeepUsedWithin),
+			zap.Duration("Interval", s.authorizationModelRetentionInterval))
+
+		s.authorizationModelsLastUsed = ccache.New(ccache.Configure[time.Time]())
+		s.authorizationModelUses = make(chan authorizationModelUse, authorizationModelUseQueueSize)
+		s.authorizationModelRetentionLeaseHolder = ulid.Make().String()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		s.authorizationModelRetentionCancel = cancel
+
+		s.authorizationModelRetentionWg.Add(2)
+		go func() {
+			defer s.authorizationModelRetentionWg.Done()
+			s.runAuthorizationModelUseRecording(ctx)
+		}()
+		go func() {
+			defer s.authorizationModelRetentionWg.Done()
+			s.runAuthorizationModelRetention(ctx)
+		}()
+	}
 
 	return s, nil
 }
@@ -573,15 +994,34 @@ func (s *Server) Close() {
 		s.listObjectsDispatchThrottler.Close()
 	}
 
+	if s.listUsersDispatchThrottler != nil {
+		s.listUsersDispatchThrottler.Close()
+	}
+
 	if s.cachedCheckResolver != nil {
 		s.cachedCheckResolver.Close()
 	}
 
+	if s.materializedIndex != nil {
+		s.materializedIndex.Close()
+	}
+
+	if s.listObjectsFrontierCache != nil {
+		s.listObjectsFrontierCache.Stop()
+	}
+
+	if s.authorizationModelRetentionCancel != nil {
+		s.authorizationModelRetentionCancel()
+		s.authorizationModelRetentionWg.Wait()
+		s.authorizationModelsLastUsed.Stop()
+	}
+
 	if s.checkResolver != nil {
 		s.checkResolver.Close()
 	}
 	s.datastore.Close()
 	s.typesystemResolverStop()
+	s.activeAuthorizationModelIDCache.Stop()
 }
 
 func (s *Server) ListObjects(ctx context.Context, req *openfgav1.ListObjectsRequest) (*openfgav1.ListObjectsResponse, error) {
@@ -616,6 +1056,8 @@ func (s *Server) ListObjects(ctx context.Context, req *openfgav1.ListObjectsRequ
 		return nil, err
 	}
 
+	limits := s.resolveStoreLimits(storeID)
+
 	q, err := commands.NewListObjectsQuery(
 		s.datastore,
 		s.checkResolver,
@@ -628,8 +1070,8 @@ func (s *Server) ListObjects(ctx context.Context, req *openfgav1.ListObjectsRequ
 			Threshold:    s.listObjectsDispatchDefaultThreshold,
 			MaxThreshold: s.listObjectsDispatchThrottlingMaxThreshold,
 		}),
-		commands.WithResolveNodeLimit(s.resolveNodeLimit),
-		commands.WithResolveNodeBreadthLimit(s.resolveNodeBreadthLimit),
+		commands.WithResolveNodeLimit(limits.ResolveNodeLimit),
+		commands.WithResolveNodeBreadthLimit(limits.ResolveNodeBreadthLimit),
 		commands.WithMaxConcurrentReads(s.maxConcurrentReadsForListObjects),
 	)
 	if err != nil {
@@ -656,6 +1098,13 @@ func (s *Server) ListObjects(ctx context.Context, req *openfgav1.ListObjectsRequ
 
 		return nil, err
 	}
+
+	s.setTruncationHeaders(ctx,
+		result.ResolutionMetadata.WasDeadlineExceeded.Load(),
+		result.ResolutionMetadata.WasMaxResultsReached.Load(),
+		result.ResolutionMetadata.WasThrottled.Load(),
+	)
+
 	datastoreQueryCount := float64(*result.ResolutionMetadata.DatastoreQueryCount)
 
 	grpc_ctxtags.Extract(ctx).Set(datastoreQueryCountHistogramName, datastoreQueryCount)
@@ -686,6 +1135,293 @@ func (s *Server) ListObjects(ctx context.Context, req *openfgav1.ListObjectsRequ
 	}, nil
 }
 
+// ListObjectsPaginated returns a page of up to pageSize of the objects that the user of the request has the
+// relation with (or up to the ListObjects max results if pageSize is zero), along with a continuation token to get
+// the next page, which is empty once all objects have been returned. Unlike ListObjects, paging through the objects
+// yields each of them exactly once and in a deterministic order, even if there are more objects than fit in one page.
+// The continuation token is signed with the key of the server (see WithContinuationTokenSigningKey) and encoded with
+// the encoder of the server (see WithTokenEncoder), and is only valid for the same request (including its contextual
+// tuples and context) against the same authorization model. The next page should be requested from the same server,
+// which caches where the query stopped: other servers resume the query by replaying it up to the token, within the
+// ListObjects deadline.
+func (s *Server) ListObjectsPaginated(
+	ctx context.Context,
+	req *openfgav1.ListObjectsRequest,
+	pageSize uint32,
+	continuationToken string,
+) (*openfgav1.ListObjectsResponse, string, error) {
+	ctx, span := tracer.Start(ctx, "ListObjectsPaginated", trace.WithAttributes(
+		attribute.String("object_type", req.GetType()),
+		attribute.String("relation", req.GetRelation()),
+		attribute.String("user", req.GetUser()),
+	))
+	defer span.End()
+
+	if !validator.RequestIsValidatedFromContext(ctx) {
+		if err := req.Validate(); err != nil {
+			return nil, "", status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  "listobjectspaginated",
+	})
+
+	storeID := req.GetStoreId()
+
+	typesys, err := s.resolveTypesystem(ctx, storeID, req.GetAuthorizationModelId())
+	if err != nil {
+		return nil, "", err
+	}
+
+	limits := s.resolveStoreLimits(storeID)
+
+	q, err := commands.NewListObjectsQuery(
+		s.datastore,
+		s.checkResolver,
+		commands.WithLogger(s.logger),
+		commands.WithListObjectsDeadline(s.listObjectsDeadline),
+		commands.WithListObjectsMaxResults(s.listObjectsMaxResults),
+		commands.WithDispatchThrottlerConfig(threshold.Config{
+			Throttler:    s.listObjectsDispatchThrottler,
+			Enabled:      s.listObjectsDispatchThrottlingEnabled,
+			Threshold:    s.listObjectsDispatchDefaultThreshold,
+			MaxThreshold: s.listObjectsDispatchThrottlingMaxThreshold,
+		}),
+		commands.WithResolveNodeLimit(limits.ResolveNodeLimit),
+		commands.WithResolveNodeBreadthLimit(limits.ResolveNodeBreadthLimit),
+		commands.WithMaxConcurrentReads(s.maxConcurrentReadsForListObjects),
+		commands.WithListObjectsEncoder(encoder.NewHMACEncoder(s.continuationTokenSigningKey, s.encoder)),
+		commands.WithListObjectsFrontierCache(s.listObjectsFrontierCache),
+	)
+	if err != nil {
+		return nil, "", serverErrors.NewInternalError("", err)
+	}
+
+	result, err := q.ExecutePaginated(
+		typesystem.ContextWithTypesystem(ctx, typesys),
+		&openfgav1.ListObjectsRequest{
+			StoreId:              storeID,
+			ContextualTuples:     req.GetContextualTuples(),
+			AuthorizationModelId: typesys.GetAuthorizationModelID(), // the resolved model id
+			Type:                 req.GetType(),
+			Relation:             req.GetRelation(),
+			User:                 req.GetUser(),
+			Context:              req.GetContext(),
+		},
+		pageSize,
+		continuationToken,
+	)
+	if err != nil {
+		telemetry.TraceError(span, err)
+		if errors.Is(err, condition.ErrEvaluationFailed) {
+			return nil, "", serverErrors.ValidationError(err)
+		}
+
+		return nil, "", err
+	}
+
+	// a page cut short by the page size isn't truncated, since the continuation token resumes from it
+	s.setTruncationHeaders(ctx,
+		result.ResolutionMetadata.WasDeadlineExceeded.Load(),
+		false,
+		result.ResolutionMetadata.WasThrottled.Load(),
+	)
+
+	span.SetAttributes(attribute.Float64(datastoreQueryCountHistogramName, float64(*result.ResolutionMetadata.DatastoreQueryCount)))
+
+	return &openfgav1.ListObjectsResponse{
+		Objects: result.Objects,
+	}, result.ContinuationToken, nil
+}
+
+// FilterObjects returns the subset of the candidate objects (of the form 'type:id', with the type of the request)
+// that the user of the request has the relation with, in the order of the candidates. Depending on the number of
+// candidates, it either checks each of them or lists the objects of the user and intersects them with the
+// candidates. Either way, the candidates are checked through the Check resolver of the server, and the sub-problems of
+// the checks are shared across the whole batch of candidates. At most the ListObjects max results candidates may be
+// provided.
+func (s *Server) FilterObjects(
+	ctx context.Context,
+	req *openfgav1.ListObjectsRequest,
+	candidates []string,
+) (*openfgav1.ListObjectsResponse, error) {
+	ctx, span := tracer.Start(ctx, "FilterObjects", trace.WithAttributes(
+		attribute.String("object_type", req.GetType()),
+		attribute.String("relation", req.GetRelation()),
+		attribute.String("user", req.GetUser()),
+		attribute.Int("candidates", len(candidates)),
+	))
+	defer span.End()
+
+	if !validator.RequestIsValidatedFromContext(ctx) {
+		if err := req.Validate(); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  "filterobjects",
+	})
+
+	storeID := req.GetStoreId()
+
+	typesys, err := s.resolveTypesystem(ctx, storeID, req.GetAuthorizationModelId())
+	if err != nil {
+		return nil, err
+	}
+
+	limits := s.resolveStoreLimits(storeID)
+
+	q, err := commands.NewListObjectsQuery(
+		s.datastore,
+		s.checkResolver,
+		commands.WithLogger(s.logger),
+		commands.WithListObjectsDeadline(s.listObjectsDeadline),
+		commands.WithListObjectsMaxResults(s.listObjectsMaxResults),
+		commands.WithDispatchThrottlerConfig(threshold.Config{
+			Throttler:    s.listObjectsDispatchThrottler,
+			Enabled:      s.listObjectsDispatchThrottlingEnabled,
+			Threshold:    s.listObjectsDispatchDefaultThreshold,
+			MaxThreshold: s.listObjectsDispatchThrottlingMaxThreshold,
+		}),
+		commands.WithResolveNodeLimit(limits.ResolveNodeLimit),
+		commands.WithResolveNodeBreadthLimit(limits.ResolveNodeBreadthLimit),
+		commands.WithMaxConcurrentReads(s.maxConcurrentReadsForListObjects),
+	)
+	if err != nil {
+		return nil, serverErrors.NewInternalError("", err)
+	}
+
+	result, err := q.ExecuteFiltered(
+		typesystem.ContextWithTypesystem(ctx, typesys),
+		&openfgav1.ListObjectsRequest{
+			StoreId:              storeID,
+			ContextualTuples:     req.GetContextualTuples(),
+			AuthorizationModelId: typesys.GetAuthorizationModelID(), // the resolved model id
+			Type:                 req.GetType(),
+			Relation:             req.GetRelation(),
+			User:                 req.GetUser(),
+			Context:              req.GetContext(),
+		},
+		candidates,
+	)
+	if err != nil {
+		telemetry.TraceError(span, err)
+		if errors.Is(err, condition.ErrEvaluationFailed) {
+			return nil, serverErrors.ValidationError(err)
+		}
+
+		return nil, err
+	}
+
+	s.setTruncationHeaders(ctx,
+		result.ResolutionMetadata.WasDeadlineExceeded.Load(),
+		result.ResolutionMetadata.WasMaxResultsReached.Load(),
+		result.ResolutionMetadata.WasThrottled.Load(),
+	)
+
+	span.SetAttributes(attribute.Float64(datastoreQueryCountHistogramName, float64(*result.ResolutionMetadata.DatastoreQueryCount)))
+
+	return &openfgav1.ListObjectsResponse{
+		Objects: result.Objects,
+	}, nil
+}
+
+// ListObjectsMultiRelation returns the objects of the type of the request that the user of the request has each of
+// the provided relations with (the relation of the request is ignored), keyed by relation. The relations are
+// evaluated together, sharing their reads of the datastore, which is cheaper than one ListObjects request per
+// relation.
+func (s *Server) ListObjectsMultiRelation(
+	ctx context.Context,
+	req *openfgav1.ListObjectsRequest,
+	relations []string,
+) (map[string][]string, error) {
+	ctx, span := tracer.Start(ctx, "ListObjectsMultiRelation", trace.WithAttributes(
+		attribute.String("object_type", req.GetType()),
+		attribute.StringSlice("relations", relations),
+		attribute.String("user", req.GetUser()),
+	))
+	defer span.End()
+
+	if !validator.RequestIsValidatedFromContext(ctx) {
+		for _, relation := range relations {
+			relationReq := proto.Clone(req).(*openfgav1.ListObjectsRequest)
+			relationReq.Relation = relation
+			if err := relationReq.Validate(); err != nil {
+				return nil, status.Error(codes.InvalidArgument, err.Error())
+			}
+		}
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  "listobjectsmultirelation",
+	})
+
+	storeID := req.GetStoreId()
+
+	typesys, err := s.resolveTypesystem(ctx, storeID, req.GetAuthorizationModelId())
+	if err != nil {
+		return nil, err
+	}
+
+	limits := s.resolveStoreLimits(storeID)
+
+	q, err := commands.NewListObjectsQuery(
+		s.datastore,
+		s.checkResolver,
+		commands.WithLogger(s.logger),
+		commands.WithListObjectsDeadline(s.listObjectsDeadline),
+		commands.WithListObjectsMaxResults(s.listObjectsMaxResults),
+		commands.WithDispatchThrottlerConfig(threshold.Config{
+			Throttler:    s.listObjectsDispatchThrottler,
+			Enabled:      s.listObjectsDispatchThrottlingEnabled,
+			Threshold:    s.listObjectsDispatchDefaultThreshold,
+			MaxThreshold: s.listObjectsDispatchThrottlingMaxThreshold,
+		}),
+		commands.WithResolveNodeLimit(limits.ResolveNodeLimit),
+		commands.WithResolveNodeBreadthLimit(limits.ResolveNodeBreadthLimit),
+		commands.WithMaxConcurrentReads(s.maxConcurrentReadsForListObjects),
+	)
+	if err != nil {
+		return nil, serverErrors.NewInternalError("", err)
+	}
+
+	result, err := q.ExecuteMultiRelation(
+		typesystem.ContextWithTypesystem(ctx, typesys),
+		&openfgav1.ListObjectsRequest{
+			StoreId:              storeID,
+			ContextualTuples:     req.GetContextualTuples(),
+			AuthorizationModelId: typesys.GetAuthorizationModelID(), // the resolved model id
+			Type:                 req.GetType(),
+			User:                 req.GetUser(),
+			Context:              req.GetContext(),
+		},
+		relations,
+	)
+	if err != nil {
+		telemetry.TraceError(span, err)
+		if errors.Is(err, condition.ErrEvaluationFailed) {
+			return nil, serverErrors.ValidationError(err)
+		}
+
+		return nil, err
+	}
+
+	s.setTruncationHeaders(ctx,
+		result.ResolutionMetadata.WasDeadlineExceeded.Load(),
+		result.ResolutionMetadata.WasMaxResultsReached.Load(),
+		result.ResolutionMetadata.WasThrottled.Load(),
+	)
+
+	span.SetAttributes(attribute.Float64(datastoreQueryCountHistogramName, float64(*result.ResolutionMetadata.DatastoreQueryCount)))
+
+	return result.Objects, nil
+}
+
 func (s *Server) StreamedListObjects(req *openfgav1.StreamedListObjectsRequest, srv openfgav1.OpenFGAService_StreamedListObjectsServer) error {
 	start := time.Now()
 
@@ -717,6 +1453,8 @@ func (s *Server) StreamedListObjects(req *openfgav1.StreamedListObjectsRequest,
 		return err
 	}
 
+	limits := s.resolveStoreLimits(storeID)
+
 	q, err := commands.NewListObjectsQuery(
 		s.datastore,
 		s.checkResolver,
@@ -729,9 +1467,11 @@ func (s *Server) StreamedListObjects(req *openfgav1.StreamedListObjectsRequest,
 			MaxThreshold: s.listObjectsDispatchThrottlingMaxThreshold,
 		}),
 		commands.WithListObjectsMaxResults(s.listObjectsMaxResults),
-		commands.WithResolveNodeLimit(s.resolveNodeLimit),
-		commands.WithResolveNodeBreadthLimit(s.resolveNodeBreadthLimit),
+		commands.WithResolveNodeLimit(limits.ResolveNodeLimit),
+		commands.WithResolveNodeBreadthLimit(limits.ResolveNodeBreadthLimit),
 		commands.WithMaxConcurrentReads(s.maxConcurrentReadsForListObjects),
+		commands.WithStreamedDeduplication(s.streamedListObjectsDeduplication),
+		commands.WithStreamedSorting(s.streamedListObjectsSorting),
 	)
 	if err != nil {
 		return serverErrors.NewInternalError("", err)
@@ -748,6 +1488,20 @@ func (s *Server) StreamedListObjects(req *openfgav1.StreamedListObjectsRequest,
 		telemetry.TraceError(span, err)
 		return err
 	}
+
+	srv.SetTrailer(metadata.Join(
+		metadata.Pairs(
+			StreamedListObjectsDatastoreQueryCountTrailer, strconv.FormatUint(uint64(*resolutionMetadata.DatastoreQueryCount), 10),
+			StreamedListObjectsDispatchCountTrailer, strconv.FormatUint(uint64(resolutionMetadata.DispatchCounter.Load()), 10),
+			StreamedListObjectsDeadlineExceededTrailer, strconv.FormatBool(resolutionMetadata.WasDeadlineExceeded.Load()),
+		),
+		truncationMetadata(
+			resolutionMetadata.WasDeadlineExceeded.Load(),
+			resolutionMetadata.WasMaxResultsReached.Load(),
+			resolutionMetadata.WasThrottled.Load(),
+		),
+	))
+
 	datastoreQueryCount := float64(*resolutionMetadata.DatastoreQueryCount)
 
 	grpc_ctxtags.Extract(ctx).Set(datastoreQueryCountHistogramName, datastoreQueryCount)
@@ -843,10 +1597,28 @@ func (s *Server) Write(ctx context.Context, req *openfgav1.WriteRequest) (*openf
 }
 
 func (s *Server) Check(ctx context.Context, req *openfgav1.CheckRequest) (*openfgav1.CheckResponse, error) {
+	res, _, err := s.check(ctx, "Check", req, false)
+	return res, err
+}
+
+// CheckWithMissingParameters is Check in partial evaluation mode: rather than failing when the condition of a
+// relationship can't be evaluated because parameters are missing from the request context, it returns the names of
+// the missing parameters (sorted) on which the outcome depends, along with a response which isn't allowed. The
+// missing parameters are empty if the outcome doesn't depend on them.
+func (s *Server) CheckWithMissingParameters(ctx context.Context, req *openfgav1.CheckRequest) (*openfgav1.CheckResponse, []string, error) {
+	return s.check(ctx, "CheckWithMissingParameters", req, true)
+}
+
+func (s *Server) check(
+	ctx context.Context,
+	method string,
+	req *openfgav1.CheckRequest,
+	partialEvaluation bool,
+) (*openfgav1.CheckResponse, []string, error) {
 	start := time.Now()
 
 	tk := req.GetTupleKey()
-	ctx, span := tracer.Start(ctx, "Check", trace.WithAttributes(
+	ctx, span := tracer.Start(ctx, method, trace.WithAttributes(
 		attribute.KeyValue{Key: "store_id", Value: attribute.StringValue(req.GetStoreId())},
 		attribute.KeyValue{Key: "object", Value: attribute.StringValue(tk.GetObject())},
 		attribute.KeyValue{Key: "relation", Value: attribute.StringValue(tk.GetRelation())},
@@ -856,32 +1628,34 @@ func (s *Server) Check(ctx context.Context, req *openfgav1.CheckRequest) (*openf
 
 	if !validator.RequestIsValidatedFromContext(ctx) {
 		if err := req.Validate(); err != nil {
-			return nil, status.Error(codes.InvalidArgument, err.Error())
+			return nil, nil, status.Error(codes.InvalidArgument, err.Error())
 		}
 	}
 
 	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
 		Service: s.serviceName,
-		Method:  "Check",
+		Method:  method,
 	})
 
 	storeID := req.GetStoreId()
 
 	typesys, err := s.resolveTypesystem(ctx, storeID, req.GetAuthorizationModelId())
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if err := validation.ValidateUserObjectRelation(typesys, tuple.ConvertCheckRequestTupleKeyToTupleKey(tk)); err != nil {
-		return nil, serverErrors.ValidationError(err)
+		return nil, nil, serverErrors.ValidationError(err)
 	}
 
 	for _, ctxTuple := range req.GetContextualTuples().GetTupleKeys() {
 		if err := validation.ValidateTuple(typesys, ctxTuple); err != nil {
-			return nil, serverErrors.HandleTupleValidateError(err)
+			return nil, nil, serverErrors.HandleTupleValidateError(err)
 		}
 	}
 
+	limits := s.resolveStoreLimits(storeID)
+
 	ctx = typesystem.ContextWithTypesystem(ctx, typesys)
 	ctx = storage.ContextWithRelationshipTupleReader(ctx,
 		storagewrappers.NewBoundedConcurrencyTupleReader(
@@ -889,11 +1663,12 @@ func (s *Server) Check(ctx context.Context, req *openfgav1.CheckRequest) (*openf
 				s.datastore,
 				req.GetContextualTuples().GetTupleKeys(),
 			),
-			s.maxConcurrentReadsForCheck,
+			limits.MaxConcurrentReadsForCheck,
 		),
 	)
 
-	checkRequestMetadata := graph.NewCheckRequestMetadata(s.resolveNodeLimit)
+	checkRequestMetadata := graph.NewCheckRequestMetadata(limits.ResolveNodeLimit)
+	checkRequestMetadata.ResolveNodeBreadthLimit = limits.ResolveNodeBreadthLimit
 
 	resolveCheckRequest := graph.ResolveCheckRequest{
 		StoreID:              req.GetStoreId(),
@@ -902,26 +1677,27 @@ func (s *Server) Check(ctx context.Context, req *openfgav1.CheckRequest) (*openf
 		ContextualTuples:     req.GetContextualTuples().GetTupleKeys(),
 		Context:              req.GetContext(),
 		RequestMetadata:      checkRequestMetadata,
+		PartialEvaluation:    partialEvaluation,
 	}
 
 	resp, err := s.checkResolver.ResolveCheck(ctx, &resolveCheckRequest)
 	if err != nil {
 		telemetry.TraceError(span, err)
 		if errors.Is(err, graph.ErrResolutionDepthExceeded) {
-			return nil, serverErrors.AuthorizationModelResolutionTooComplex
+			return nil, nil, serverErrors.AuthorizationModelResolutionTooComplex
 		}
 
 		if errors.Is(err, condition.ErrEvaluationFailed) {
-			return nil, serverErrors.ValidationError(err)
+			return nil, nil, serverErrors.ValidationError(err)
 		}
 
 		// Note for ListObjects:
 		// Currently this is not feasible in ListObjects as we return partial results.
 		if errors.Is(err, context.DeadlineExceeded) && resolveCheckRequest.GetRequestMetadata().WasThrottled.Load() {
-			return nil, serverErrors.ThrottledTimeout
+			return nil, nil, serverErrors.ThrottledTimeout
 		}
 
-		return nil, serverErrors.HandleError("", err)
+		return nil, nil, serverErrors.HandleError("", err)
 	}
 
 	queryCount := float64(resp.GetResolutionMetadata().DatastoreQueryCount)
@@ -957,7 +1733,7 @@ func (s *Server) Check(ctx context.Context, req *openfgav1.CheckRequest) (*openf
 		utils.Bucketize(uint(rawDispatchCount), s.requestDurationByDispatchCountHistogramBuckets),
 	).Observe(float64(time.Since(start).Milliseconds()))
 
-	return res, nil
+	return res, resp.GetMissingParameters(), nil
 }
 
 func (s *Server) Expand(ctx context.Context, req *openfgav1.ExpandRequest) (*openfgav1.ExpandResponse, error) {
@@ -994,6 +1770,62 @@ func (s *Server) Expand(ctx context.Context, req *openfgav1.ExpandRequest) (*ope
 	})
 }
 
+// ExpandPaginated is Expand expanding the computed usersets and tuple to usersets of the tree recursively down to
+// depth levels (at most the resolve node limit of the store) rather than one, and returning up to pageSize users in
+// each leaf of users (or all of them if pageSize is zero) along with a continuation token to get the next page of
+// users of every leaf, which is empty once all users have been returned. The continuation token is encoded with the
+// encoder of the server (see WithTokenEncoder), and is only valid for the same request and depth against the same
+// authorization model.
+func (s *Server) ExpandPaginated(
+	ctx context.Context,
+	req *openfgav1.ExpandRequest,
+	depth uint32,
+	pageSize uint32,
+	continuationToken string,
+) (*openfgav1.ExpandResponse, string, error) {
+	tk := req.GetTupleKey()
+	ctx, span := tracer.Start(ctx, "ExpandPaginated", trace.WithAttributes(
+		attribute.KeyValue{Key: "object", Value: attribute.StringValue(tk.GetObject())},
+		attribute.KeyValue{Key: "relation", Value: attribute.StringValue(tk.GetRelation())},
+		attribute.Int("depth", int(depth)),
+	))
+	defer span.End()
+
+	if !validator.RequestIsValidatedFromContext(ctx) {
+		if err := req.Validate(); err != nil {
+			return nil, "", status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  "ExpandPaginated",
+	})
+
+	storeID := req.GetStoreId()
+
+	limits := s.resolveStoreLimits(storeID)
+	if depth > limits.ResolveNodeLimit {
+		return nil, "", serverErrors.ValidationError(fmt.Errorf("depth must be at most %d", limits.ResolveNodeLimit))
+	}
+
+	typesys, err := s.resolveTypesystem(ctx, storeID, req.GetAuthorizationModelId())
+	if err != nil {
+		return nil, "", err
+	}
+
+	q := commands.NewExpandQuery(s.datastore,
+		commands.WithExpandQueryLogger(s.logger),
+		commands.WithExpandQueryEncoder(s.encoder),
+		commands.WithExpandQueryResolveNodeBreadthLimit(limits.ResolveNodeBreadthLimit),
+	)
+	return q.ExecutePaginated(ctx, &openfgav1.ExpandRequest{
+		StoreId:              storeID,
+		AuthorizationModelId: typesys.GetAuthorizationModelID(), // the resolved model id
+		TupleKey:             tk,
+	}, depth, pageSize, continuationToken)
+}
+
 func (s *Server) ReadAuthorizationModel(ctx context.Context, req *openfgav1.ReadAuthorizationModelRequest) (*openfgav1.ReadAuthorizationModelResponse, error) {
 	ctx, span := tracer.Start(ctx, "ReadAuthorizationModel", trace.WithAttributes(
 		attribute.KeyValue{Key: authorizationModelIDKey, Value: attribute.StringValue(req.GetId())},
@@ -1030,20 +1862,122 @@ func (s *Server) WriteAuthorizationModel(ctx context.Context, req *openfgav1.Wri
 		Method:  "WriteAuthorizationModel",
 	})
 
-	c := commands.NewWriteAuthorizationModelCommand(s.datastore,
+	return s.writeAuthorizationModel(ctx, req, nil)
+}
+
+// WriteAuthorizationModelWithAnnotations writes an authorization model, as WriteAuthorizationModel does, along with
+// the annotations of its types, relations and conditions (see WriteAuthorizationModelAnnotations), which the API
+// messages have no field for. The annotations are validated against the model, and written atomically with it.
+func (s *Server) WriteAuthorizationModelWithAnnotations(
+	ctx context.Context,
+	req *openfgav1.WriteAuthorizationModelRequest,
+	annotations *storage.AuthorizationModelAnnotations,
+) (*openfgav1.WriteAuthorizationModelResponse, error) {
+	ctx, span := tracer.Start(ctx, "WriteAuthorizationModelWithAnnotations")
+	defer span.End()
+
+	if !validator.RequestIsValidatedFromContext(ctx) {
+		if err := req.Validate(); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  "WriteAuthorizationModelWithAnnotations",
+	})
+
+	if annotations == nil {
+		annotations = &storage.AuthorizationModelAnnotations{}
+	}
+
+	return s.writeAuthorizationModel(ctx, req, annotations)
+}
+
+// writeAuthorizationModel writes the model of the request, along with its annotations if they aren't nil.
+func (s *Server) writeAuthorizationModel(
+	ctx context.Context,
+	req *openfgav1.WriteAuthorizationModelRequest,
+	annotations *storage.AuthorizationModelAnnotations,
+) (*openfgav1.WriteAuthorizationModelResponse, error) {
+	opts := []commands.WriteAuthModelOption{
 		commands.WithWriteAuthModelLogger(s.logger),
 		commands.WithWriteAuthModelMaxSizeInBytes(s.maxAuthorizationModelSizeInBytes),
-	)
+	}
+	if annotations != nil {
+		opts = append(opts, commands.WithWriteAuthModelAnnotations(annotations))
+	}
+	if s.authorizationModelCompatibilityCheckEnabled {
+		opts = append(opts,
+			commands.WithWriteAuthModelCompatibilityCheck(s.datastore, s.rejectIncompatibleAuthorizationModels),
+			commands.WithWriteAuthModelCompatibilityCheckLimits(s.authorizationModelCompatibilityCheckMaxTuples, s.authorizationModelCompatibilityCheckDeadline),
+		)
+	}
+
+	c := commands.NewWriteAuthorizationModelCommand(s.datastore, opts...)
 	res, err := c.Execute(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
+	if s.authorizationModelLintEnabled {
+		typesys := typesystem.New(&openfgav1.AuthorizationModel{
+			Id:              res.GetAuthorizationModelId(),
+			SchemaVersion:   req.GetSchemaVersion(),
+			TypeDefinitions: req.GetTypeDefinitions(),
+			Conditions:      req.GetConditions(),
+		})
+		for _, warning := range typesystem.Lint(typesys) {
+			s.transport.SetHeader(ctx, AuthorizationModelLintWarningHeader, warning.String())
+		}
+	}
+
 	s.transport.SetHeader(ctx, httpmiddleware.XHttpCode, strconv.Itoa(http.StatusCreated))
 
 	return res, nil
 }
 
+// WriteAuthorizationModelDryRun validates the model of a WriteAuthorizationModel request without writing it, and
+// reports the tuples of the store which are invalid under it (e.g. because it removes their relation or their type
+// of user), up to a hundred of them, regardless of WithAuthorizationModelCompatibilityCheck. The compatibility is
+// reported as unknown if the check reaches the limits set with WithAuthorizationModelCompatibilityCheckMaxTuples
+// and WithAuthorizationModelCompatibilityCheckDeadline before reading all the tuples of the store.
+func (s *Server) WriteAuthorizationModelDryRun(
+	ctx context.Context,
+	req *openfgav1.WriteAuthorizationModelRequest,
+) (*commands.CompatibilityReport, error) {
+	ctx, span := tracer.Start(ctx, "WriteAuthorizationModelDryRun")
+	defer span.End()
+
+	if !validator.RequestIsValidatedFromContext(ctx) {
+		if err := req.Validate(); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	ctx = telemetry.ContextWithRPCInfo(ctx, telemetry.RPCInfo{
+		Service: s.serviceName,
+		Method:  "WriteAuthorizationModelDryRun",
+	})
+
+	c := commands.NewWriteAuthorizationModelCommand(s.datastore,
+		commands.WithWriteAuthModelLogger(s.logger),
+		commands.WithWriteAuthModelMaxSizeInBytes(s.maxAuthorizationModelSizeInBytes),
+		commands.WithWriteAuthModelCompatibilityCheckLimits(s.authorizationModelCompatibilityCheckMaxTuples, s.authorizationModelCompatibilityCheckDeadline),
+	)
+	report, err := c.DryRun(ctx, req, s.datastore)
+	if err != nil {
+		return nil, err
+	}
+
+	span.SetAttributes(
+		attribute.Int("incompatible_tuples", len(report.IncompatibleTuples)),
+		attribute.Bool("compatibility_unknown", report.Unknown),
+	)
+
+	return report, nil
+}
+
 func (s *Server) ReadAuthorizationModels(ctx context.Context, req *openfgav1.ReadAuthorizationModelsRequest) (*openfgav1.ReadAuthorizationModelsResponse, error) {
 	ctx, span := tracer.Start(ctx, "ReadAuthorizationModels")
 	defer span.End()
@@ -1292,9 +2226,42 @@ func (s *Server) resolveTypesystem(ctx context.Context, storeID, modelID string)
 
 	resolvedModelID := typesys.GetAuthorizationModelID()
 
+	if s.authorizationModelRetentionEnabled {
+		s.recordAuthorizationModelUse(storeID, resolvedModelID)
+	}
+
 	span.SetAttributes(attribute.KeyValue{Key: authorizationModelIDKey, Value: attribute.StringValue(resolvedModelID)})
 	grpc_ctxtags.Extract(ctx).Set(authorizationModelIDKey, resolvedModelID)
 	s.transport.SetHeader(ctx, AuthorizationModelIDHeader, resolvedModelID)
 
 	return typesys, nil
 }
+
+// resolveStoreLimits returns the resolution limits that apply to the queries of the given store,
+// which are the server-wide limits unless overridden with WithStoreLimits.
+func (s *Server) resolveStoreLimits(storeID string) StoreLimits {
+	limits := StoreLimits{
+		ResolveNodeLimit:           s.resolveNodeLimit,
+		ResolveNodeBreadthLimit:    s.resolveNodeBreadthLimit,
+		MaxConcurrentReadsForCheck: s.maxConcurrentReadsForCheck,
+	}
+
+	overrides, ok := s.storeLimits[storeID]
+	if !ok {
+		return limits
+	}
+
+	if overrides.ResolveNodeLimit != 0 {
+		limits.ResolveNodeLimit = overrides.ResolveNodeLimit
+	}
+
+	if overrides.ResolveNodeBreadthLimit != 0 {
+		limits.ResolveNodeBreadthLimit = overrides.ResolveNodeBreadthLimit
+	}
+
+	if overrides.MaxConcurrentReadsForCheck != 0 {
+		limits.MaxConcurrentReadsForCheck = overrides.MaxConcurrentReadsForCheck
+	}
+
+	return limits
+}
@@ -9,6 +9,7 @@ import (
 	"path"
 	"runtime"
 	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -21,6 +22,7 @@ import (
 	"go.uber.org/mock/gomock"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	"github.com/openfga/openfga/cmd/migrate"
@@ -256,6 +258,20 @@ func TestServerPanicIfDefaultListObjectThresholdGreaterThanMaxDispatchThreshold(
 	})
 }
 
+func TestServerPanicIfDefaultListUsersThresholdGreaterThanMaxDispatchThreshold(t *testing.T) {
+	require.PanicsWithError(t, "failed to construct the OpenFGA server: ListUsers default dispatch throttling threshold must be equal or smaller than max dispatch threshold for ListUsers", func() {
+		mockController := gomock.NewController(t)
+		defer mockController.Finish()
+		mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+		_ = MustNewServerWithOpts(
+			WithDatastore(mockDatastore),
+			WithListUsersDispatchThrottlingEnabled(true),
+			WithListUsersDispatchThrottlingThreshold(100),
+			WithListUsersDispatchThrottlingMaxThreshold(80),
+		)
+	})
+}
+
 func TestServerWithPostgresDatastore(t *testing.T) {
 	t.Cleanup(func() {
 		goleak.VerifyNone(t)
@@ -795,7 +811,6 @@ func TestReleasesConnections(t *testing.T) {
 
 	s := MustNewServerWithOpts(
 		WithDatastore(storagewrappers.NewContextWrapper(ds)),
-		WithExperimentals(ExperimentalEnableListUsers),
 	)
 	t.Cleanup(s.Close)
 
@@ -938,7 +953,6 @@ func TestOperationsWithInvalidModel(t *testing.T) {
 
 	s := MustNewServerWithOpts(
 		WithDatastore(mockDatastore),
-		WithExperimentals(ExperimentalEnableListUsers),
 	)
 	t.Cleanup(func() {
 		mockDatastore.EXPECT().Close().Times(1)
@@ -1296,6 +1310,7 @@ func TestResolveAuthorizationModel(t *testing.T) {
 		defer mockController.Finish()
 
 		mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+		mockDatastore.EXPECT().ReadActiveAuthorizationModelID(gomock.Any(), store).Return("", storage.ErrNotFound)
 		mockDatastore.EXPECT().FindLatestAuthorizationModel(gomock.Any(), store).Return(nil, storage.ErrNotFound)
 
 		s := MustNewServerWithOpts(
@@ -1320,6 +1335,7 @@ func TestResolveAuthorizationModel(t *testing.T) {
 		defer mockController.Finish()
 
 		mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+		mockDatastore.EXPECT().ReadActiveAuthorizationModelID(gomock.Any(), store).Return("", storage.ErrNotFound)
 		mockDatastore.EXPECT().FindLatestAuthorizationModel(gomock.Any(), store).Return(
 			&openfgav1.AuthorizationModel{
 				Id:            modelID,
@@ -1366,6 +1382,8 @@ func TestResolveAuthorizationModel(t *testing.T) {
 
 type mockStreamServer struct {
 	grpc.ServerStream
+	objects []string
+	trailer metadata.MD
 }
 
 func NewMockStreamServer() *mockStreamServer {
@@ -1376,10 +1394,71 @@ func (m *mockStreamServer) Context() context.Context {
 	return context.Background()
 }
 
-func (m *mockStreamServer) Send(*openfgav1.StreamedListObjectsResponse) error {
+func (m *mockStreamServer) Send(resp *openfgav1.StreamedListObjectsResponse) error {
+	m.objects = append(m.objects, resp.GetObject())
 	return nil
 }
 
+func (m *mockStreamServer) SetTrailer(md metadata.MD) {
+	m.trailer = metadata.Join(m.trailer, md)
+}
+
+func TestStreamedListObjectsGuarantees(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+
+	s := MustNewServerWithOpts(
+		WithDatastore(memory.New()),
+		WithStreamedListObjectsSorting(true),
+	)
+	t.Cleanup(s.Close)
+
+	_, err := s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:       storeID,
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: language.MustTransformDSLToProto(`
+			model
+				schema 1.1
+
+			type user
+
+			type document
+				relations
+					define editor: [user]
+					define viewer: [user] or editor`).GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:c", "viewer", "user:jon"),
+			tuple.NewTupleKey("document:a", "editor", "user:jon"),
+			tuple.NewTupleKey("document:b", "viewer", "user:jon"),
+			tuple.NewTupleKey("document:b", "editor", "user:jon"),
+		}},
+	})
+	require.NoError(t, err)
+
+	srv := NewMockStreamServer()
+	err = s.StreamedListObjects(&openfgav1.StreamedListObjectsRequest{
+		StoreId:  storeID,
+		Type:     "document",
+		Relation: "viewer",
+		User:     "user:jon",
+	}, srv)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"document:a", "document:b", "document:c"}, srv.objects)
+	require.NotEmpty(t, srv.trailer.Get(StreamedListObjectsDatastoreQueryCountTrailer))
+	require.NotEmpty(t, srv.trailer.Get(StreamedListObjectsDispatchCountTrailer))
+	require.Equal(t, []string{"false"}, srv.trailer.Get(StreamedListObjectsDeadlineExceededTrailer))
+}
+
 // This runs ListObjects and StreamedListObjects many times over to ensure no race conditions (see https://github.com/openfga/openfga/pull/762)
 func BenchmarkListObjectsNoRaceCondition(b *testing.B) {
 	b.Cleanup(func() {
@@ -1584,6 +1663,693 @@ func TestListObjects_ErrorCases(t *testing.T) {
 	})
 }
 
+func TestStoreLimits(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	limitedStoreID := ulid.Make().String()
+	unlimitedStoreID := ulid.Make().String()
+
+	s := MustNewServerWithOpts(
+		WithDatastore(memory.New()),
+		WithResolveNodeLimit(25),
+		WithResolveNodeBreadthLimit(50),
+		WithStoreLimits(map[string]StoreLimits{
+			limitedStoreID: {ResolveNodeLimit: 2},
+		}),
+	)
+	t.Cleanup(s.Close)
+
+	t.Run("unset_limits_fall_back_to_server_limits", func(t *testing.T) {
+		require.Equal(t, StoreLimits{
+			ResolveNodeLimit:           2,
+			ResolveNodeBreadthLimit:    50,
+			MaxConcurrentReadsForCheck: serverconfig.DefaultMaxConcurrentReadsForCheck,
+		}, s.resolveStoreLimits(limitedStoreID))
+
+		require.Equal(t, StoreLimits{
+			ResolveNodeLimit:           25,
+			ResolveNodeBreadthLimit:    50,
+			MaxConcurrentReadsForCheck: serverconfig.DefaultMaxConcurrentReadsForCheck,
+		}, s.resolveStoreLimits(unlimitedStoreID))
+	})
+
+	for _, storeID := range []string{limitedStoreID, unlimitedStoreID} {
+		_, err := s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+			StoreId:       storeID,
+			SchemaVersion: typesystem.SchemaVersion1_1,
+			TypeDefinitions: language.MustTransformDSLToProto(`
+				model
+					schema 1.1
+
+				type user
+
+				type group
+					relations
+						define member: [user, group#member]
+
+				type document
+					relations
+						define viewer: [group#member]`).GetTypeDefinitions(),
+		})
+		require.NoError(t, err)
+
+		_, err = s.Write(ctx, &openfgav1.WriteRequest{
+			StoreId: storeID,
+			Writes: &openfgav1.WriteRequestWrites{
+				TupleKeys: []*openfgav1.TupleKey{
+					tuple.NewTupleKey("document:1", "viewer", "group:1#member"),
+					tuple.NewTupleKey("group:1", "member", "group:2#member"),
+					tuple.NewTupleKey("group:2", "member", "group:3#member"),
+					tuple.NewTupleKey("group:3", "member", "user:jon"),
+				},
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	t.Run("check", func(t *testing.T) {
+		_, err := s.Check(ctx, &openfgav1.CheckRequest{
+			StoreId:  limitedStoreID,
+			TupleKey: tuple.NewCheckRequestTupleKey("document:1", "viewer", "user:jon"),
+		})
+		require.ErrorIs(t, err, serverErrors.AuthorizationModelResolutionTooComplex)
+
+		checkResp, err := s.Check(ctx, &openfgav1.CheckRequest{
+			StoreId:  unlimitedStoreID,
+			TupleKey: tuple.NewCheckRequestTupleKey("document:1", "viewer", "user:jon"),
+		})
+		require.NoError(t, err)
+		require.True(t, checkResp.GetAllowed())
+	})
+
+	t.Run("list_objects", func(t *testing.T) {
+		_, err := s.ListObjects(ctx, &openfgav1.ListObjectsRequest{
+			StoreId:  limitedStoreID,
+			Type:     "document",
+			Relation: "viewer",
+			User:     "user:jon",
+		})
+		require.ErrorIs(t, err, serverErrors.AuthorizationModelResolutionTooComplex)
+
+		listObjectsResp, err := s.ListObjects(ctx, &openfgav1.ListObjectsRequest{
+			StoreId:  unlimitedStoreID,
+			Type:     "document",
+			Relation: "viewer",
+			User:     "user:jon",
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"document:1"}, listObjectsResp.GetObjects())
+	})
+}
+
+func TestMaterialization(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	t.Run("invalid_relation", func(t *testing.T) {
+		ds := memory.New()
+		t.Cleanup(ds.Close)
+
+		_, err := NewServerWithOpts(
+			WithDatastore(ds),
+			WithMaterializationEnabled(true),
+			WithMaterializedRelations("document:1#viewer", "viewer"),
+		)
+		require.EqualError(t, err, "materialized relation 'viewer' must be of the form 'type#relation'")
+	})
+
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+
+	s := MustNewServerWithOpts(
+		WithDatastore(memory.New()),
+		WithMaterializationEnabled(true),
+		WithMaterializedRelations("document#viewer"),
+		WithMaterializationSyncInterval(10*time.Millisecond),
+	)
+	t.Cleanup(s.Close)
+
+	writeModelResp, err := s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:       storeID,
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: language.MustTransformDSLToProto(`
+			model
+				schema 1.1
+
+			type user
+
+			type group
+				relations
+					define member: [user, group#member]
+
+			type document
+				relations
+					define viewer: [group#member]`).GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	typesys, err := s.resolveTypesystem(ctx, storeID, writeModelResp.GetAuthorizationModelId())
+	require.NoError(t, err)
+
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{
+			TupleKeys: []*openfgav1.TupleKey{
+				tuple.NewTupleKey("document:1", "viewer", "group:1#member"),
+				tuple.NewTupleKey("group:1", "member", "group:2#member"),
+				tuple.NewTupleKey("group:2", "member", "user:jon"),
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	// the first Check registers the store with the index and is resolved from the model
+	checkResp, err := s.Check(ctx, &openfgav1.CheckRequest{
+		StoreId:  storeID,
+		TupleKey: tuple.NewCheckRequestTupleKey("document:1", "viewer", "user:jon"),
+	})
+	require.NoError(t, err)
+	require.True(t, checkResp.GetAllowed())
+
+	require.Eventually(t, func() bool {
+		_, ok := s.materializedIndex.Lookup(ctx, typesys, storeID, "document:1", "viewer", "user:jon")
+		return ok
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{
+			TupleKeys: []*openfgav1.TupleKey{
+				tuple.NewTupleKey("group:2", "member", "user:maria"),
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		checkResp, err := s.Check(ctx, &openfgav1.CheckRequest{
+			StoreId:  storeID,
+			TupleKey: tuple.NewCheckRequestTupleKey("document:1", "viewer", "user:maria"),
+		})
+		require.NoError(t, err)
+		return checkResp.GetAllowed()
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestCheckWithMissingParameters(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+
+	s := MustNewServerWithOpts(WithDatastore(memory.New()))
+	t.Cleanup(s.Close)
+
+	_, err := s.WriteAuthorizationModelDSL(ctx, storeID, `model
+  schema 1.1
+
+type user
+
+type document
+  relations
+    define viewer: [user with in_range]
+
+condition in_range(x: int, y: int) {
+  x < y
+}
+`)
+	require.NoError(t, err)
+
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+			tuple.NewTupleKeyWithCondition("document:1", "viewer", "user:jon", "in_range", testutils.MustNewStruct(t, map[string]interface{}{"x": 1})),
+		}},
+	})
+	require.NoError(t, err)
+
+	req := &openfgav1.CheckRequest{
+		StoreId:  storeID,
+		TupleKey: tuple.NewCheckRequestTupleKey("document:1", "viewer", "user:jon"),
+	}
+
+	t.Run("missing_parameters", func(t *testing.T) {
+		resp, missingParameters, err := s.CheckWithMissingParameters(ctx, req)
+		require.NoError(t, err)
+		require.False(t, resp.GetAllowed())
+		require.Equal(t, []string{"y"}, missingParameters)
+
+		// without partial evaluation the missing parameter is an error
+		_, err = s.Check(ctx, req)
+		require.Equal(t, codes.Code(openfgav1.ErrorCode_validation_error), status.Code(err))
+	})
+
+	t.Run("all_parameters", func(t *testing.T) {
+		req := &openfgav1.CheckRequest{
+			StoreId:  storeID,
+			TupleKey: req.GetTupleKey(),
+			Context:  testutils.MustNewStruct(t, map[string]interface{}{"y": 2}),
+		}
+
+		resp, missingParameters, err := s.CheckWithMissingParameters(ctx, req)
+		require.NoError(t, err)
+		require.True(t, resp.GetAllowed())
+		require.Empty(t, missingParameters)
+	})
+}
+
+func TestListObjectsPaginated(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+
+	ds := memory.New()
+
+	s := MustNewServerWithOpts(
+		WithDatastore(ds),
+		WithListObjectsMaxResults(2),
+		WithContinuationTokenSigningKey([]byte("signing-key")),
+	)
+	t.Cleanup(s.Close)
+
+	_, err := s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:       storeID,
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: language.MustTransformDSLToProto(`
+			model
+				schema 1.1
+
+			type user
+
+			type document
+				relations
+					define viewer: [user]`).GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	var tuples []*openfgav1.TupleKey
+	for i := 0; i < 5; i++ {
+		tuples = append(tuples, tuple.NewTupleKey(fmt.Sprintf("document:%d", i), "viewer", "user:jon"))
+	}
+
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes:  &openfgav1.WriteRequestWrites{TupleKeys: tuples},
+	})
+	require.NoError(t, err)
+
+	req := &openfgav1.ListObjectsRequest{
+		StoreId:  storeID,
+		Type:     "document",
+		Relation: "viewer",
+		User:     "user:jon",
+	}
+
+	var objects []string
+	var continuationToken string
+	for {
+		// the page size defaults to the ListObjects max results
+		resp, nextContinuationToken, err := s.ListObjectsPaginated(ctx, req, 0, continuationToken)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(resp.GetObjects()), 2)
+
+		objects = append(objects, resp.GetObjects()...)
+
+		if nextContinuationToken == "" {
+			break
+		}
+		continuationToken = nextContinuationToken
+	}
+
+	require.ElementsMatch(t, []string{"document:0", "document:1", "document:2", "document:3", "document:4"}, objects)
+
+	_, _, err = s.ListObjectsPaginated(ctx, req, 0, "invalid")
+	require.ErrorIs(t, err, serverErrors.InvalidContinuationToken)
+
+	resp, continuationToken, err := s.ListObjectsPaginated(ctx, req, 0, "")
+	require.NoError(t, err)
+	require.NotEmpty(t, continuationToken)
+
+	t.Run("continuation_token_of_another_server_with_the_same_key", func(t *testing.T) {
+		// the other server resumes the query by replaying it, since it hasn't cached its frontier
+		other := MustNewServerWithOpts(
+			WithDatastore(ds),
+			WithListObjectsMaxResults(2),
+			WithContinuationTokenSigningKey([]byte("signing-key")),
+		)
+		t.Cleanup(other.Close)
+
+		nextResp, _, err := other.ListObjectsPaginated(ctx, req, 0, continuationToken)
+		require.NoError(t, err)
+		require.Len(t, nextResp.GetObjects(), 2)
+		require.NotContains(t, nextResp.GetObjects(), resp.GetObjects()[0])
+		require.NotContains(t, nextResp.GetObjects(), resp.GetObjects()[1])
+	})
+
+	t.Run("continuation_token_of_another_server_with_another_key", func(t *testing.T) {
+		other := MustNewServerWithOpts(
+			WithDatastore(ds),
+			WithListObjectsMaxResults(2),
+		)
+		t.Cleanup(other.Close)
+
+		_, _, err := other.ListObjectsPaginated(ctx, req, 0, continuationToken)
+		require.ErrorIs(t, err, serverErrors.InvalidContinuationToken)
+	})
+}
+
+func TestExpandPaginated(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+
+	s := MustNewServerWithOpts(
+		WithDatastore(memory.New()),
+		WithResolveNodeLimit(5),
+	)
+	t.Cleanup(s.Close)
+
+	_, err := s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:       storeID,
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: language.MustTransformDSLToProto(`
+			model
+				schema 1.1
+
+			type user
+
+			type document
+				relations
+					define editor: [user]
+					define viewer: editor`).GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	var tuples []*openfgav1.TupleKey
+	for i := 0; i < 5; i++ {
+		tuples = append(tuples, tuple.NewTupleKey("document:1", "editor", fmt.Sprintf("user:%d", i)))
+	}
+
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes:  &openfgav1.WriteRequestWrites{TupleKeys: tuples},
+	})
+	require.NoError(t, err)
+
+	req := &openfgav1.ExpandRequest{
+		StoreId:  storeID,
+		TupleKey: tuple.NewExpandRequestTupleKey("document:1", "viewer"),
+	}
+
+	var users []string
+	var continuationToken string
+	for {
+		resp, nextContinuationToken, err := s.ExpandPaginated(ctx, req, 1, 2, continuationToken)
+		require.NoError(t, err)
+
+		editors := resp.GetTree().GetRoot().GetUnion().GetNodes()[0]
+		require.Equal(t, "document:1#editor", editors.GetName())
+		require.LessOrEqual(t, len(editors.GetLeaf().GetUsers().GetUsers()), 2)
+
+		users = append(users, editors.GetLeaf().GetUsers().GetUsers()...)
+
+		if nextContinuationToken == "" {
+			break
+		}
+		continuationToken = nextContinuationToken
+	}
+
+	require.ElementsMatch(t, []string{"user:0", "user:1", "user:2", "user:3", "user:4"}, users)
+
+	_, _, err = s.ExpandPaginated(ctx, req, 1, 2, "invalid")
+	require.ErrorIs(t, err, serverErrors.InvalidContinuationToken)
+
+	_, _, err = s.ExpandPaginated(ctx, req, 6, 2, "")
+	require.Error(t, err)
+	require.Equal(t, codes.Code(openfgav1.ErrorCode_validation_error), status.Code(err))
+}
+
+func TestWriteAuthorizationModelCompatibilityCheck(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+
+	s := MustNewServerWithOpts(
+		WithDatastore(memory.New()),
+		WithAuthorizationModelCompatibilityCheck(true),
+		WithRejectIncompatibleAuthorizationModels(true),
+	)
+	t.Cleanup(s.Close)
+
+	_, err := s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:       storeID,
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: language.MustTransformDSLToProto(`
+			model
+				schema 1.1
+
+			type user
+
+			type document
+				relations
+					define editor: [user]
+					define viewer: [user]`).GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:1", "editor", "user:jon"),
+		}},
+	})
+	require.NoError(t, err)
+
+	req := &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:       storeID,
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: language.MustTransformDSLToProto(`
+			model
+				schema 1.1
+
+			type user
+
+			type document
+				relations
+					define viewer: [user]`).GetTypeDefinitions(),
+	}
+
+	report, err := s.WriteAuthorizationModelDryRun(ctx, req)
+	require.NoError(t, err)
+	require.False(t, report.Unknown)
+	require.Len(t, report.IncompatibleTuples, 1)
+	require.Equal(t, "document:1#editor@user:jon", tuple.TupleKeyToString(report.IncompatibleTuples[0].TupleKey))
+
+	_, err = s.WriteAuthorizationModel(ctx, req)
+	require.Equal(t, codes.Code(openfgav1.ErrorCode_invalid_authorization_model), status.Code(err))
+}
+
+// headersTransport records every value of the headers set.
+type headersTransport struct {
+	mu      sync.Mutex
+	headers map[string][]string
+}
+
+func (h *headersTransport) SetHeader(_ context.Context, key, value string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.headers[key] = append(h.headers[key], value)
+}
+
+func TestWriteAuthorizationModelLinting(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+
+	req := &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:       storeID,
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: language.MustTransformDSLToProto(`
+			model
+				schema 1.1
+
+			type user
+
+			type team
+
+			type document
+				relations
+					define owner: [user, user:*]
+					define viewer: [user]`).GetTypeDefinitions(),
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		transport := &headersTransport{headers: map[string][]string{}}
+
+		s := MustNewServerWithOpts(
+			WithDatastore(memory.New()),
+			WithTransport(transport),
+		)
+		t.Cleanup(s.Close)
+
+		_, err := s.WriteAuthorizationModel(ctx, req)
+		require.NoError(t, err)
+		require.NotContains(t, transport.headers, AuthorizationModelLintWarningHeader)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		transport := &headersTransport{headers: map[string][]string{}}
+
+		s := MustNewServerWithOpts(
+			WithDatastore(memory.New()),
+			WithTransport(transport),
+			WithAuthorizationModelLinting(true),
+		)
+		t.Cleanup(s.Close)
+
+		_, err := s.WriteAuthorizationModel(ctx, req)
+		require.NoError(t, err)
+
+		warnings := transport.headers[AuthorizationModelLintWarningHeader]
+		require.Len(t, warnings, 2)
+		require.True(t, strings.HasPrefix(warnings[0], string(typesystem.LintWildcardOnSensitiveRelation)+": "))
+		require.True(t, strings.HasPrefix(warnings[1], string(typesystem.LintUnusedType)+": "))
+	})
+}
+
+func TestFilterObjects(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+
+	// the ListObjects max results limits the number of candidates
+	s := MustNewServerWithOpts(
+		WithDatastore(memory.New()),
+		WithListObjectsMaxResults(4),
+	)
+	t.Cleanup(s.Close)
+
+	_, err := s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:       storeID,
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: language.MustTransformDSLToProto(`
+			model
+				schema 1.1
+
+			type user
+
+			type document
+				relations
+					define viewer: [user]`).GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:1", "viewer", "user:jon"),
+			tuple.NewTupleKey("document:2", "viewer", "user:maria"),
+			tuple.NewTupleKey("document:3", "viewer", "user:jon"),
+		}},
+	})
+	require.NoError(t, err)
+
+	req := &openfgav1.ListObjectsRequest{
+		StoreId:  storeID,
+		Type:     "document",
+		Relation: "viewer",
+		User:     "user:jon",
+	}
+
+	resp, err := s.FilterObjects(ctx, req, []string{"document:3", "document:2", "document:4", "document:1"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"document:3", "document:1"}, resp.GetObjects())
+
+	_, err = s.FilterObjects(ctx, req, []string{"folder:1"})
+	require.ErrorIs(t, err, serverErrors.ValidationError(fmt.Errorf("invalid candidate object 'folder:1': must be an object of type 'document'")))
+
+	_, err = s.FilterObjects(ctx, req, []string{"document:1", "document:2", "document:3", "document:4", "document:5"})
+	require.ErrorIs(t, err, serverErrors.ValidationError(fmt.Errorf("at most 4 candidate objects may be provided")))
+}
+
+func TestListObjectsMultiRelation(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+
+	s := MustNewServerWithOpts(
+		WithDatastore(memory.New()),
+	)
+	t.Cleanup(s.Close)
+
+	_, err := s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:       storeID,
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: language.MustTransformDSLToProto(`
+			model
+				schema 1.1
+
+			type user
+
+			type document
+				relations
+					define editor: [user]
+					define viewer: [user] or editor`).GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:1", "viewer", "user:jon"),
+			tuple.NewTupleKey("document:2", "editor", "user:jon"),
+		}},
+	})
+	require.NoError(t, err)
+
+	req := &openfgav1.ListObjectsRequest{
+		StoreId: storeID,
+		Type:    "document",
+		User:    "user:jon",
+	}
+
+	objects, err := s.ListObjectsMultiRelation(ctx, req, []string{"viewer", "editor"})
+	require.NoError(t, err)
+	require.Len(t, objects, 2)
+	require.ElementsMatch(t, []string{"document:1", "document:2"}, objects["viewer"])
+	require.ElementsMatch(t, []string{"document:2"}, objects["editor"])
+
+	_, err = s.ListObjectsMultiRelation(ctx, req, []string{"viewer", "owner"})
+	require.ErrorIs(t, err, serverErrors.RelationNotFound("owner", "document", nil))
+
+	_, err = s.ListObjectsMultiRelation(ctx, req, []string{"viewer", ""})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
 func TestAuthorizationModelInvalidSchemaVersion(t *testing.T) {
 	t.Cleanup(func() {
 		goleak.VerifyNone(t)
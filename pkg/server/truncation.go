@@ -0,0 +1,71 @@
+package server
+
+import (
+	"context"
+	"strconv"
+
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// TruncatedHeader is the header (or the trailer, for streaming APIs) of the responses of ListObjects,
+	// StreamedListObjects and ListUsers that indicates whether their results may be incomplete.
+	TruncatedHeader = "Openfga-Truncated"
+
+	// TruncationReasonHeader is the header (or the trailer, for streaming APIs) of the responses of ListObjects,
+	// StreamedListObjects and ListUsers that indicates why their results may be incomplete, if they are.
+	TruncationReasonHeader = "Openfga-Truncation-Reason"
+)
+
+// TruncationReason is the reason why the results of a ListObjects, StreamedListObjects or ListUsers
+// request may be incomplete.
+type TruncationReason string
+
+const (
+	// TruncationReasonDeadline means that the deadline was hit before all results were found.
+	TruncationReasonDeadline TruncationReason = "deadline"
+
+	// TruncationReasonMaxResults means that the maximum number of results was reached while there
+	// were possibly more results to be found.
+	TruncationReasonMaxResults TruncationReason = "max_results"
+
+	// TruncationReasonThrottled means that the deadline was hit before all results were found
+	// while the dispatches of the request were being throttled.
+	TruncationReasonThrottled TruncationReason = "throttled"
+)
+
+// truncationReason returns the reason why the results of a request may be incomplete, given whether its deadline
+// was exceeded, its maximum number of results was reached, and it was throttled, or false if they are complete.
+func truncationReason(deadlineExceeded, maxResultsReached, throttled bool) (TruncationReason, bool) {
+	switch {
+	case deadlineExceeded && throttled:
+		return TruncationReasonThrottled, true
+	case deadlineExceeded:
+		return TruncationReasonDeadline, true
+	case maxResultsReached:
+		return TruncationReasonMaxResults, true
+	default:
+		return "", false
+	}
+}
+
+// truncationMetadata returns the TruncatedHeader and TruncationReasonHeader pairs of a response.
+func truncationMetadata(deadlineExceeded, maxResultsReached, throttled bool) metadata.MD {
+	reason, truncated := truncationReason(deadlineExceeded, maxResultsReached, throttled)
+
+	md := metadata.Pairs(TruncatedHeader, strconv.FormatBool(truncated))
+	if truncated {
+		md.Set(TruncationReasonHeader, string(reason))
+	}
+
+	return md
+}
+
+// setTruncationHeaders sets the TruncatedHeader and TruncationReasonHeader headers of a unary response.
+func (s *Server) setTruncationHeaders(ctx context.Context, deadlineExceeded, maxResultsReached, throttled bool) {
+	for key, values := range truncationMetadata(deadlineExceeded, maxResultsReached, throttled) {
+		for _, value := range values {
+			s.transport.SetHeader(ctx, key, value)
+		}
+	}
+}
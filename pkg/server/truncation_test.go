@@ -0,0 +1,282 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	language "github.com/openfga/language/pkg/go/transformer"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+)
+
+func TestTruncationReason(t *testing.T) {
+	tests := []struct {
+		name              string
+		deadlineExceeded  bool
+		maxResultsReached bool
+		throttled         bool
+		expectedReason    TruncationReason
+		expectedTruncated bool
+	}{
+		{
+			name: "complete",
+		},
+		{
+			name:      "throttled_within_deadline",
+			throttled: true,
+		},
+		{
+			name:              "deadline",
+			deadlineExceeded:  true,
+			expectedReason:    TruncationReasonDeadline,
+			expectedTruncated: true,
+		},
+		{
+			name:              "deadline_while_throttled",
+			deadlineExceeded:  true,
+			throttled:         true,
+			expectedReason:    TruncationReasonThrottled,
+			expectedTruncated: true,
+		},
+		{
+			name:              "max_results",
+			maxResultsReached: true,
+			expectedReason:    TruncationReasonMaxResults,
+			expectedTruncated: true,
+		},
+		{
+			name:              "deadline_takes_precedence_over_max_results",
+			deadlineExceeded:  true,
+			maxResultsReached: true,
+			expectedReason:    TruncationReasonDeadline,
+			expectedTruncated: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reason, truncated := truncationReason(test.deadlineExceeded, test.maxResultsReached, test.throttled)
+			require.Equal(t, test.expectedReason, reason)
+			require.Equal(t, test.expectedTruncated, truncated)
+
+			md := truncationMetadata(test.deadlineExceeded, test.maxResultsReached, test.throttled)
+			if test.expectedTruncated {
+				require.Equal(t, []string{"true"}, md.Get(TruncatedHeader))
+				require.Equal(t, []string{string(test.expectedReason)}, md.Get(TruncationReasonHeader))
+			} else {
+				require.Equal(t, []string{"false"}, md.Get(TruncatedHeader))
+				require.Empty(t, md.Get(TruncationReasonHeader))
+			}
+		})
+	}
+}
+
+type recordingTransport struct {
+	mu      sync.Mutex
+	headers map[string]string
+}
+
+func (r *recordingTransport) SetHeader(_ context.Context, key, value string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.headers[key] = value
+}
+
+func TestTruncationHeaders(t *testing.T) {
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+
+	transport := &recordingTransport{headers: map[string]string{}}
+
+	s := MustNewServerWithOpts(
+		WithDatastore(memory.New()),
+		WithTransport(transport),
+		WithListObjectsMaxResults(2),
+		WithListUsersMaxResults(2),
+	)
+	t.Cleanup(s.Close)
+
+	writeModelResp, err := s.WriteAuthorizationModel(ctx, &openfgav1.WriteAuthorizationModelRequest{
+		StoreId:       storeID,
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: language.MustTransformDSLToProto(`
+			model
+				schema 1.1
+
+			type user
+
+			type document
+				relations
+					define viewer: [user]`).GetTypeDefinitions(),
+	})
+	require.NoError(t, err)
+
+	_, err = s.Write(ctx, &openfgav1.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgav1.WriteRequestWrites{TupleKeys: []*openfgav1.TupleKey{
+			tuple.NewTupleKey("document:1", "viewer", "user:jon"),
+			tuple.NewTupleKey("document:2", "viewer", "user:jon"),
+			tuple.NewTupleKey("document:3", "viewer", "user:jon"),
+			tuple.NewTupleKey("document:1", "viewer", "user:maria"),
+			tuple.NewTupleKey("document:1", "viewer", "user:will"),
+			tuple.NewTupleKey("document:2", "viewer", "user:maria"),
+		}},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name            string
+		call            func() error
+		expectedHeaders map[string]string
+	}{
+		{
+			name: "list_objects_max_results",
+			call: func() error {
+				_, err := s.ListObjects(ctx, &openfgav1.ListObjectsRequest{
+					StoreId:  storeID,
+					Type:     "document",
+					Relation: "viewer",
+					User:     "user:jon",
+				})
+				return err
+			},
+			expectedHeaders: map[string]string{
+				TruncatedHeader:        "true",
+				TruncationReasonHeader: string(TruncationReasonMaxResults),
+			},
+		},
+		{
+			name: "list_objects_complete",
+			call: func() error {
+				_, err := s.ListObjects(ctx, &openfgav1.ListObjectsRequest{
+					StoreId:  storeID,
+					Type:     "document",
+					Relation: "viewer",
+					User:     "user:will",
+				})
+				return err
+			},
+			expectedHeaders: map[string]string{
+				TruncatedHeader: "false",
+			},
+		},
+		{
+			name: "list_users_max_results",
+			call: func() error {
+				_, err := s.ListUsers(ctx, &openfgav1.ListUsersRequest{
+					StoreId:              storeID,
+					AuthorizationModelId: writeModelResp.GetAuthorizationModelId(),
+					Object:               &openfgav1.Object{Type: "document", Id: "1"},
+					Relation:             "viewer",
+					UserFilters:          []*openfgav1.UserTypeFilter{{Type: "user"}},
+				})
+				return err
+			},
+			expectedHeaders: map[string]string{
+				TruncatedHeader:        "true",
+				TruncationReasonHeader: string(TruncationReasonMaxResults),
+			},
+		},
+		{
+			name: "list_users_exactly_max_results",
+			call: func() error {
+				_, err := s.ListUsers(ctx, &openfgav1.ListUsersRequest{
+					StoreId:              storeID,
+					AuthorizationModelId: writeModelResp.GetAuthorizationModelId(),
+					Object:               &openfgav1.Object{Type: "document", Id: "2"},
+					Relation:             "viewer",
+					UserFilters:          []*openfgav1.UserTypeFilter{{Type: "user"}},
+				})
+				return err
+			},
+			expectedHeaders: map[string]string{
+				TruncatedHeader: "false",
+			},
+		},
+		{
+			name: "list_objects_paginated",
+			call: func() error {
+				_, _, err := s.ListObjectsPaginated(ctx, &openfgav1.ListObjectsRequest{
+					StoreId:  storeID,
+					Type:     "document",
+					Relation: "viewer",
+					User:     "user:jon",
+				}, 2, "")
+				return err
+			},
+			expectedHeaders: map[string]string{
+				TruncatedHeader: "false",
+			},
+		},
+		{
+			name: "filter_objects",
+			call: func() error {
+				_, err := s.FilterObjects(ctx, &openfgav1.ListObjectsRequest{
+					StoreId:  storeID,
+					Type:     "document",
+					Relation: "viewer",
+					User:     "user:jon",
+				}, []string{"document:1", "document:2"})
+				return err
+			},
+			expectedHeaders: map[string]string{
+				TruncatedHeader: "false",
+			},
+		},
+		{
+			name: "list_objects_multi_relation_max_results",
+			call: func() error {
+				_, err := s.ListObjectsMultiRelation(ctx, &openfgav1.ListObjectsRequest{
+					StoreId: storeID,
+					Type:    "document",
+					User:    "user:jon",
+				}, []string{"viewer"})
+				return err
+			},
+			expectedHeaders: map[string]string{
+				TruncatedHeader:        "true",
+				TruncationReasonHeader: string(TruncationReasonMaxResults),
+			},
+		},
+		{
+			name: "list_users_complete",
+			call: func() error {
+				_, err := s.ListUsers(ctx, &openfgav1.ListUsersRequest{
+					StoreId:              storeID,
+					AuthorizationModelId: writeModelResp.GetAuthorizationModelId(),
+					Object:               &openfgav1.Object{Type: "document", Id: "3"},
+					Relation:             "viewer",
+					UserFilters:          []*openfgav1.UserTypeFilter{{Type: "user"}},
+				})
+				return err
+			},
+			expectedHeaders: map[string]string{
+				TruncatedHeader: "false",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			transport.mu.Lock()
+			transport.headers = map[string]string{}
+			transport.mu.Unlock()
+
+			require.NoError(t, test.call())
+
+			for key, value := range test.expectedHeaders {
+				require.Equal(t, value, transport.headers[strings.ToLower(key)])
+			}
+			if _, ok := test.expectedHeaders[TruncationReasonHeader]; !ok {
+				require.NotContains(t, transport.headers, strings.ToLower(TruncationReasonHeader))
+			}
+		})
+	}
+}
@@ -38,6 +38,12 @@ var (
 
 	// ErrNotFound is returned when the object does not exist.
 	ErrNotFound = errors.New("not found")
+
+	// ErrLatestAuthorizationModel is returned when deleting the latest authorization model of a store.
+	ErrLatestAuthorizationModel = errors.New("the authorization model is the latest model of the store")
+
+	// ErrActiveAuthorizationModel is returned when deleting the authorization model a store is pinned to.
+	ErrActiveAuthorizationModel = errors.New("the authorization model is the active model of the store")
 )
 
 // ExceededMaxTypeDefinitionsLimitError constructs an error indicating that
@@ -3,6 +3,7 @@ package memory
 import (
 	"context"
 	"fmt"
+	"maps"
 	"sort"
 	"strconv"
 	"strings"
@@ -118,6 +119,23 @@ type MemoryBackend struct {
 	authorizationModels map[string]map[string]*AuthorizationModelEntry // GUARDED_BY(mutexModels).
 	mutexModels         sync.RWMutex
 
+	// map: store id => active authorization model id
+	activeAuthorizationModels map[string]string // GUARDED_BY(mutexModels).
+
+	// map: store id => map: authorization model id => time of last use
+	authorizationModelsLastUsed map[string]map[string]time.Time // GUARDED_BY(mutexModels).
+
+	// map: store id | authorization model id => annotations
+	authorizationModelAnnotations map[string]*storage.AuthorizationModelAnnotations // GUARDED_BY(mutexModels).
+
+	// the times since and at which the use of the models has been recorded
+	authorizationModelUseRecordedSince time.Time // GUARDED_BY(mutexModels).
+	authorizationModelUseRecordedAt    time.Time // GUARDED_BY(mutexModels).
+
+	// the holder of the lease of the garbage collection of the models and the time it expires
+	authorizationModelRetentionLeaseHolder    string    // GUARDED_BY(mutexModels).
+	authorizationModelRetentionLeaseExpiresAt time.Time // GUARDED_BY(mutexModels).
+
 	// map: store id => store data
 	stores      map[string]*openfgav1.Store // GUARDED_BY(mutexStores).
 	mutexStores sync.RWMutex
@@ -145,6 +163,9 @@ func New(opts ...StorageOption) storage.OpenFGADatastore {
 		tuples:                        make(map[string][]*storage.TupleRecord, 0),
 		changes:                       make(map[string][]*openfgav1.TupleChange, 0),
 		authorizationModels:           make(map[string]map[string]*AuthorizationModelEntry),
+		activeAuthorizationModels:     make(map[string]string),
+		authorizationModelsLastUsed:   make(map[string]map[string]time.Time),
+		authorizationModelAnnotations: make(map[string]*storage.AuthorizationModelAnnotations),
 		stores:                        make(map[string]*openfgav1.Store, 0),
 		assertions:                    make(map[string][]*openfgav1.Assertion, 0),
 	}
@@ -641,6 +662,206 @@ func (s *MemoryBackend) FindLatestAuthorizationModel(ctx context.Context, store
 	return nsc, nil
 }
 
+// ReadActiveAuthorizationModelID see [storage.AuthorizationModelReadBackend].ReadActiveAuthorizationModelID.
+func (s *MemoryBackend) ReadActiveAuthorizationModelID(ctx context.Context, store string) (string, error) {
+	_, span := tracer.Start(ctx, "memory.ReadActiveAuthorizationModelID")
+	defer span.End()
+
+	s.mutexModels.RLock()
+	defer s.mutexModels.RUnlock()
+
+	modelID, ok := s.activeAuthorizationModels[store]
+	if !ok {
+		telemetry.TraceError(span, storage.ErrNotFound)
+		return "", storage.ErrNotFound
+	}
+	return modelID, nil
+}
+
+// WriteActiveAuthorizationModelID see [storage.TypeDefinitionWriteBackend].WriteActiveAuthorizationModelID.
+func (s *MemoryBackend) WriteActiveAuthorizationModelID(ctx context.Context, store string, modelID string) (string, error) {
+	_, span := tracer.Start(ctx, "memory.WriteActiveAuthorizationModelID")
+	defer span.End()
+
+	s.mutexModels.Lock()
+	defer s.mutexModels.Unlock()
+
+	previousModelID := s.activeAuthorizationModels[store]
+
+	if modelID == "" {
+		delete(s.activeAuthorizationModels, store)
+		return previousModelID, nil
+	}
+
+	if _, ok := s.authorizationModels[store][modelID]; !ok {
+		telemetry.TraceError(span, storage.ErrNotFound)
+		return "", storage.ErrNotFound
+	}
+
+	s.activeAuthorizationModels[store] = modelID
+	return previousModelID, nil
+}
+
+// ReadAuthorizationModelsLastUsed see [storage.AuthorizationModelReadBackend].ReadAuthorizationModelsLastUsed.
+func (s *MemoryBackend) ReadAuthorizationModelsLastUsed(ctx context.Context, store string) (map[string]time.Time, error) {
+	_, span := tracer.Start(ctx, "memory.ReadAuthorizationModelsLastUsed")
+	defer span.End()
+
+	s.mutexModels.RLock()
+	defer s.mutexModels.RUnlock()
+
+	lastUsed := make(map[string]time.Time, len(s.authorizationModelsLastUsed[store]))
+	for modelID, t := range s.authorizationModelsLastUsed[store] {
+		lastUsed[modelID] = t
+	}
+	return lastUsed, nil
+}
+
+// WriteAuthorizationModelLastUsed see [storage.TypeDefinitionWriteBackend].WriteAuthorizationModelLastUsed.
+func (s *MemoryBackend) WriteAuthorizationModelLastUsed(ctx context.Context, store string, modelID string, lastUsed time.Time) error {
+	_, span := tracer.Start(ctx, "memory.WriteAuthorizationModelLastUsed")
+	defer span.End()
+
+	s.mutexModels.Lock()
+	defer s.mutexModels.Unlock()
+
+	if _, ok := s.authorizationModelsLastUsed[store]; !ok {
+		s.authorizationModelsLastUsed[store] = make(map[string]time.Time)
+	}
+
+	s.authorizationModelsLastUsed[store][modelID] = lastUsed
+	return nil
+}
+
+// ReadAuthorizationModelAnnotations see [storage.AuthorizationModelReadBackend].ReadAuthorizationModelAnnotations.
+func (s *MemoryBackend) ReadAuthorizationModelAnnotations(ctx context.Context, store string, modelID string) (*storage.AuthorizationModelAnnotations, error) {
+	_, span := tracer.Start(ctx, "memory.ReadAuthorizationModelAnnotations")
+	defer span.End()
+
+	s.mutexModels.RLock()
+	defer s.mutexModels.RUnlock()
+
+	annotations, ok := s.authorizationModelAnnotations[fmt.Sprintf("%s|%s", store, modelID)]
+	if !ok {
+		return &storage.AuthorizationModelAnnotations{}, nil
+	}
+	return copyAuthorizationModelAnnotations(annotations), nil
+}
+
+// WriteAuthorizationModelAnnotations see [storage.TypeDefinitionWriteBackend].WriteAuthorizationModelAnnotations.
+func (s *MemoryBackend) WriteAuthorizationModelAnnotations(ctx context.Context, store string, modelID string, annotations *storage.AuthorizationModelAnnotations) error {
+	_, span := tracer.Start(ctx, "memory.WriteAuthorizationModelAnnotations")
+	defer span.End()
+
+	s.mutexModels.Lock()
+	defer s.mutexModels.Unlock()
+
+	s.authorizationModelAnnotations[fmt.Sprintf("%s|%s", store, modelID)] = copyAuthorizationModelAnnotations(annotations)
+	return nil
+}
+
+// copyAuthorizationModelAnnotations returns a deep copy of the annotations, so that the annotations stored aren't
+// changed through the maps of the callers which wrote or read them.
+func copyAuthorizationModelAnnotations(annotations *storage.AuthorizationModelAnnotations) *storage.AuthorizationModelAnnotations {
+	copied := &storage.AuthorizationModelAnnotations{}
+
+	if types := annotations.GetTypes(); types != nil {
+		copied.Types = make(map[string]*storage.TypeAnnotations, len(types))
+		for objectType, typeAnnotations := range types {
+			copiedType := &storage.TypeAnnotations{Annotations: maps.Clone(typeAnnotations.GetAnnotations())}
+			if relations := typeAnnotations.GetRelations(); relations != nil {
+				copiedType.Relations = make(map[string]storage.Annotations, len(relations))
+				for relation, relationAnnotations := range relations {
+					copiedType.Relations[relation] = maps.Clone(relationAnnotations)
+				}
+			}
+			copied.Types[objectType] = copiedType
+		}
+	}
+
+	if conditions := annotations.GetConditions(); conditions != nil {
+		copied.Conditions = make(map[string]storage.Annotations, len(conditions))
+		for name, conditionAnnotations := range conditions {
+			copied.Conditions[name] = maps.Clone(conditionAnnotations)
+		}
+	}
+
+	return copied
+}
+
+// WriteAuthorizationModelUseRecording see [storage.TypeDefinitionWriteBackend].WriteAuthorizationModelUseRecording.
+func (s *MemoryBackend) WriteAuthorizationModelUseRecording(ctx context.Context, recordedAt time.Time, maxGap time.Duration) (time.Time, error) {
+	_, span := tracer.Start(ctx, "memory.WriteAuthorizationModelUseRecording")
+	defer span.End()
+
+	s.mutexModels.Lock()
+	defer s.mutexModels.Unlock()
+
+	if s.authorizationModelUseRecordedAt.IsZero() || recordedAt.Sub(s.authorizationModelUseRecordedAt) > maxGap {
+		s.authorizationModelUseRecordedSince = recordedAt
+	}
+
+	if recordedAt.After(s.authorizationModelUseRecordedAt) {
+		s.authorizationModelUseRecordedAt = recordedAt
+	}
+
+	return s.authorizationModelUseRecordedSince, nil
+}
+
+// AcquireAuthorizationModelRetentionLease see [storage.TypeDefinitionWriteBackend].AcquireAuthorizationModelRetentionLease.
+func (s *MemoryBackend) AcquireAuthorizationModelRetentionLease(ctx context.Context, holder string, ttl time.Duration) (bool, error) {
+	_, span := tracer.Start(ctx, "memory.AcquireAuthorizationModelRetentionLease")
+	defer span.End()
+
+	s.mutexModels.Lock()
+	defer s.mutexModels.Unlock()
+
+	now := time.Now()
+	if s.authorizationModelRetentionLeaseHolder != holder && now.Before(s.authorizationModelRetentionLeaseExpiresAt) {
+		return false, nil
+	}
+
+	s.authorizationModelRetentionLeaseHolder = holder
+	s.authorizationModelRetentionLeaseExpiresAt = now.Add(ttl)
+	return true, nil
+}
+
+// DeleteAuthorizationModel see [storage.TypeDefinitionWriteBackend].DeleteAuthorizationModel.
+func (s *MemoryBackend) DeleteAuthorizationModel(ctx context.Context, store string, modelID string) error {
+	_, span := tracer.Start(ctx, "memory.DeleteAuthorizationModel")
+	defer span.End()
+
+	s.mutexModels.Lock()
+	defer s.mutexModels.Unlock()
+
+	entry, ok := s.authorizationModels[store][modelID]
+	if !ok {
+		telemetry.TraceError(span, storage.ErrNotFound)
+		return storage.ErrNotFound
+	}
+
+	if entry.latest {
+		telemetry.TraceError(span, storage.ErrLatestAuthorizationModel)
+		return storage.ErrLatestAuthorizationModel
+	}
+
+	if s.activeAuthorizationModels[store] == modelID {
+		telemetry.TraceError(span, storage.ErrActiveAuthorizationModel)
+		return storage.ErrActiveAuthorizationModel
+	}
+
+	delete(s.authorizationModels[store], modelID)
+	delete(s.authorizationModelsLastUsed[store], modelID)
+	delete(s.authorizationModelAnnotations, fmt.Sprintf("%s|%s", store, modelID))
+
+	s.mutexAssertions.Lock()
+	defer s.mutexAssertions.Unlock()
+
+	delete(s.assertions, fmt.Sprintf("%s|%s", store, modelID))
+
+	return nil
+}
+
 // WriteAuthorizationModel see [storage.TypeDefinitionWriteBackend].WriteAuthorizationModel.
 func (s *MemoryBackend) WriteAuthorizationModel(ctx context.Context, store string, model *openfgav1.AuthorizationModel) error {
 	_, span := tracer.Start(ctx, "memory.WriteAuthorizationModel")
@@ -649,6 +870,13 @@ func (s *MemoryBackend) WriteAuthorizationModel(ctx context.Context, store strin
 	s.mutexModels.Lock()
 	defer s.mutexModels.Unlock()
 
+	s.writeAuthorizationModel(store, model)
+
+	return nil
+}
+
+// writeAuthorizationModel writes the model as the latest model of the store, with mutexModels held.
+func (s *MemoryBackend) writeAuthorizationModel(store string, model *openfgav1.AuthorizationModel) {
 	if _, ok := s.authorizationModels[store]; !ok {
 		s.authorizationModels[store] = make(map[string]*AuthorizationModelEntry)
 	}
@@ -661,6 +889,18 @@ func (s *MemoryBackend) WriteAuthorizationModel(ctx context.Context, store strin
 		model:  model,
 		latest: true,
 	}
+}
+
+// WriteAuthorizationModelWithAnnotations see [storage.TypeDefinitionWriteBackend].WriteAuthorizationModelWithAnnotations.
+func (s *MemoryBackend) WriteAuthorizationModelWithAnnotations(ctx context.Context, store string, model *openfgav1.AuthorizationModel, annotations *storage.AuthorizationModelAnnotations) error {
+	_, span := tracer.Start(ctx, "memory.WriteAuthorizationModelWithAnnotations")
+	defer span.End()
+
+	s.mutexModels.Lock()
+	defer s.mutexModels.Unlock()
+
+	s.writeAuthorizationModel(store, model)
+	s.authorizationModelAnnotations[fmt.Sprintf("%s|%s", store, model.GetId())] = copyAuthorizationModelAnnotations(annotations)
 
 	return nil
 }
@@ -694,9 +934,20 @@ func (s *MemoryBackend) DeleteStore(ctx context.Context, id string) error {
 	defer span.End()
 
 	s.mutexStores.Lock()
-	defer s.mutexStores.Unlock()
-
 	delete(s.stores, id)
+	s.mutexStores.Unlock()
+
+	s.mutexModels.Lock()
+	defer s.mutexModels.Unlock()
+
+	delete(s.activeAuthorizationModels, id)
+	delete(s.authorizationModelsLastUsed, id)
+	for key := range s.authorizationModelAnnotations {
+		if strings.HasPrefix(key, id+"|") {
+			delete(s.authorizationModelAnnotations, key)
+		}
+	}
+
 	return nil
 }
 
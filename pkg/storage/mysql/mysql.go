@@ -447,6 +447,105 @@ func (m *MySQL) FindLatestAuthorizationModel(ctx context.Context, store string)
 	return sqlcommon.FindLatestAuthorizationModel(ctx, m.dbInfo, store)
 }
 
+// ReadActiveAuthorizationModelID see [storage.AuthorizationModelReadBackend].ReadActiveAuthorizationModelID.
+func (m *MySQL) ReadActiveAuthorizationModelID(ctx context.Context, store string) (string, error) {
+	ctx, span := tracer.Start(ctx, "mysql.ReadActiveAuthorizationModelID")
+	defer span.End()
+
+	return sqlcommon.ReadActiveAuthorizationModelID(ctx, m.dbInfo, store)
+}
+
+// WriteActiveAuthorizationModelID see [storage.TypeDefinitionWriteBackend].WriteActiveAuthorizationModelID.
+func (m *MySQL) WriteActiveAuthorizationModelID(ctx context.Context, store string, modelID string) (string, error) {
+	ctx, span := tracer.Start(ctx, "mysql.WriteActiveAuthorizationModelID")
+	defer span.End()
+
+	return sqlcommon.WriteActiveAuthorizationModelID(ctx, m.dbInfo, store, modelID)
+}
+
+// ReadAuthorizationModelsLastUsed see [storage.AuthorizationModelReadBackend].ReadAuthorizationModelsLastUsed.
+func (m *MySQL) ReadAuthorizationModelsLastUsed(ctx context.Context, store string) (map[string]time.Time, error) {
+	ctx, span := tracer.Start(ctx, "mysql.ReadAuthorizationModelsLastUsed")
+	defer span.End()
+
+	return sqlcommon.ReadAuthorizationModelsLastUsed(ctx, m.dbInfo, store)
+}
+
+// WriteAuthorizationModelLastUsed see [storage.TypeDefinitionWriteBackend].WriteAuthorizationModelLastUsed.
+func (m *MySQL) WriteAuthorizationModelLastUsed(ctx context.Context, store string, modelID string, lastUsed time.Time) error {
+	ctx, span := tracer.Start(ctx, "mysql.WriteAuthorizationModelLastUsed")
+	defer span.End()
+
+	lastUsed = lastUsed.UTC()
+
+	_, err := m.stbl.
+		Insert("authorization_model_last_used").
+		Columns("store", "authorization_model_id", "last_used_at").
+		Values(store, modelID, lastUsed).
+		Suffix("ON DUPLICATE KEY UPDATE last_used_at = ?", lastUsed).
+		ExecContext(ctx)
+	if err != nil {
+		return sqlcommon.HandleSQLError(err)
+	}
+
+	return nil
+}
+
+// ReadAuthorizationModelAnnotations see [storage.AuthorizationModelReadBackend].ReadAuthorizationModelAnnotations.
+func (m *MySQL) ReadAuthorizationModelAnnotations(ctx context.Context, store string, modelID string) (*storage.AuthorizationModelAnnotations, error) {
+	ctx, span := tracer.Start(ctx, "mysql.ReadAuthorizationModelAnnotations")
+	defer span.End()
+
+	return sqlcommon.ReadAuthorizationModelAnnotations(ctx, m.dbInfo, store, modelID)
+}
+
+// WriteAuthorizationModelAnnotations see [storage.TypeDefinitionWriteBackend].WriteAuthorizationModelAnnotations.
+func (m *MySQL) WriteAuthorizationModelAnnotations(ctx context.Context, store string, modelID string, annotations *storage.AuthorizationModelAnnotations) error {
+	ctx, span := tracer.Start(ctx, "mysql.WriteAuthorizationModelAnnotations")
+	defer span.End()
+
+	marshalledAnnotations, err := json.Marshal(annotations)
+	if err != nil {
+		return err
+	}
+
+	_, err = m.stbl.
+		Insert("authorization_model_annotation").
+		Columns("store", "authorization_model_id", "annotations").
+		Values(store, modelID, marshalledAnnotations).
+		Suffix("ON DUPLICATE KEY UPDATE annotations = ?", marshalledAnnotations).
+		ExecContext(ctx)
+	if err != nil {
+		return sqlcommon.HandleSQLError(err)
+	}
+
+	return nil
+}
+
+// WriteAuthorizationModelUseRecording see [storage.TypeDefinitionWriteBackend].WriteAuthorizationModelUseRecording.
+func (m *MySQL) WriteAuthorizationModelUseRecording(ctx context.Context, recordedAt time.Time, maxGap time.Duration) (time.Time, error) {
+	ctx, span := tracer.Start(ctx, "mysql.WriteAuthorizationModelUseRecording")
+	defer span.End()
+
+	return sqlcommon.WriteAuthorizationModelUseRecording(ctx, m.dbInfo, recordedAt, maxGap)
+}
+
+// AcquireAuthorizationModelRetentionLease see [storage.TypeDefinitionWriteBackend].AcquireAuthorizationModelRetentionLease.
+func (m *MySQL) AcquireAuthorizationModelRetentionLease(ctx context.Context, holder string, ttl time.Duration) (bool, error) {
+	ctx, span := tracer.Start(ctx, "mysql.AcquireAuthorizationModelRetentionLease")
+	defer span.End()
+
+	return sqlcommon.AcquireAuthorizationModelRetentionLease(ctx, m.dbInfo, holder, ttl)
+}
+
+// DeleteAuthorizationModel see [storage.TypeDefinitionWriteBackend].DeleteAuthorizationModel.
+func (m *MySQL) DeleteAuthorizationModel(ctx context.Context, store string, modelID string) error {
+	ctx, span := tracer.Start(ctx, "mysql.DeleteAuthorizationModel")
+	defer span.End()
+
+	return sqlcommon.DeleteAuthorizationModel(ctx, m.dbInfo, store, modelID)
+}
+
 // MaxTypesPerAuthorizationModel see [storage.TypeDefinitionWriteBackend].MaxTypesPerAuthorizationModel.
 func (m *MySQL) MaxTypesPerAuthorizationModel() int {
 	return m.maxTypesPerModelField
@@ -466,6 +565,18 @@ func (m *MySQL) WriteAuthorizationModel(ctx context.Context, store string, model
 	return sqlcommon.WriteAuthorizationModel(ctx, m.dbInfo, store, model)
 }
 
+// WriteAuthorizationModelWithAnnotations see [storage.TypeDefinitionWriteBackend].WriteAuthorizationModelWithAnnotations.
+func (m *MySQL) WriteAuthorizationModelWithAnnotations(ctx context.Context, store string, model *openfgav1.AuthorizationModel, annotations *storage.AuthorizationModelAnnotations) error {
+	ctx, span := tracer.Start(ctx, "mysql.WriteAuthorizationModelWithAnnotations")
+	defer span.End()
+
+	if len(model.GetTypeDefinitions()) > m.MaxTypesPerAuthorizationModel() {
+		return storage.ExceededMaxTypeDefinitionsLimitError(m.maxTypesPerModelField)
+	}
+
+	return sqlcommon.WriteAuthorizationModelWithAnnotations(ctx, m.dbInfo, store, model, annotations)
+}
+
 // CreateStore adds a new store to the MySQL storage.
 func (m *MySQL) CreateStore(ctx context.Context, store *openfgav1.Store) (*openfgav1.Store, error) {
 	ctx, span := tracer.Start(ctx, "mysql.CreateStore")
@@ -613,16 +724,7 @@ func (m *MySQL) DeleteStore(ctx context.Context, id string) error {
 	ctx, span := tracer.Start(ctx, "mysql.DeleteStore")
 	defer span.End()
 
-	_, err := m.stbl.
-		Update("store").
-		Set("deleted_at", sq.Expr("NOW()")).
-		Where(sq.Eq{"id": id}).
-		ExecContext(ctx)
-	if err != nil {
-		return sqlcommon.HandleSQLError(err)
-	}
-
-	return nil
+	return sqlcommon.DeleteStore(ctx, m.dbInfo, id)
 }
 
 // WriteAssertions see [storage.AssertionsBackend].WriteAssertions.
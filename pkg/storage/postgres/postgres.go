@@ -445,6 +445,105 @@ func (p *Postgres) FindLatestAuthorizationModel(ctx context.Context, store strin
 	return sqlcommon.FindLatestAuthorizationModel(ctx, p.dbInfo, store)
 }
 
+// ReadActiveAuthorizationModelID see [storage.AuthorizationModelReadBackend].ReadActiveAuthorizationModelID.
+func (p *Postgres) ReadActiveAuthorizationModelID(ctx context.Context, store string) (string, error) {
+	ctx, span := tracer.Start(ctx, "postgres.ReadActiveAuthorizationModelID")
+	defer span.End()
+
+	return sqlcommon.ReadActiveAuthorizationModelID(ctx, p.dbInfo, store)
+}
+
+// WriteActiveAuthorizationModelID see [storage.TypeDefinitionWriteBackend].WriteActiveAuthorizationModelID.
+func (p *Postgres) WriteActiveAuthorizationModelID(ctx context.Context, store string, modelID string) (string, error) {
+	ctx, span := tracer.Start(ctx, "postgres.WriteActiveAuthorizationModelID")
+	defer span.End()
+
+	return sqlcommon.WriteActiveAuthorizationModelID(ctx, p.dbInfo, store, modelID)
+}
+
+// ReadAuthorizationModelsLastUsed see [storage.AuthorizationModelReadBackend].ReadAuthorizationModelsLastUsed.
+func (p *Postgres) ReadAuthorizationModelsLastUsed(ctx context.Context, store string) (map[string]time.Time, error) {
+	ctx, span := tracer.Start(ctx, "postgres.ReadAuthorizationModelsLastUsed")
+	defer span.End()
+
+	return sqlcommon.ReadAuthorizationModelsLastUsed(ctx, p.dbInfo, store)
+}
+
+// WriteAuthorizationModelLastUsed see [storage.TypeDefinitionWriteBackend].WriteAuthorizationModelLastUsed.
+func (p *Postgres) WriteAuthorizationModelLastUsed(ctx context.Context, store string, modelID string, lastUsed time.Time) error {
+	ctx, span := tracer.Start(ctx, "postgres.WriteAuthorizationModelLastUsed")
+	defer span.End()
+
+	lastUsed = lastUsed.UTC()
+
+	_, err := p.stbl.
+		Insert("authorization_model_last_used").
+		Columns("store", "authorization_model_id", "last_used_at").
+		Values(store, modelID, lastUsed).
+		Suffix("ON CONFLICT (store, authorization_model_id) DO UPDATE SET last_used_at = ?", lastUsed).
+		ExecContext(ctx)
+	if err != nil {
+		return sqlcommon.HandleSQLError(err)
+	}
+
+	return nil
+}
+
+// ReadAuthorizationModelAnnotations see [storage.AuthorizationModelReadBackend].ReadAuthorizationModelAnnotations.
+func (p *Postgres) ReadAuthorizationModelAnnotations(ctx context.Context, store string, modelID string) (*storage.AuthorizationModelAnnotations, error) {
+	ctx, span := tracer.Start(ctx, "postgres.ReadAuthorizationModelAnnotations")
+	defer span.End()
+
+	return sqlcommon.ReadAuthorizationModelAnnotations(ctx, p.dbInfo, store, modelID)
+}
+
+// WriteAuthorizationModelAnnotations see [storage.TypeDefinitionWriteBackend].WriteAuthorizationModelAnnotations.
+func (p *Postgres) WriteAuthorizationModelAnnotations(ctx context.Context, store string, modelID string, annotations *storage.AuthorizationModelAnnotations) error {
+	ctx, span := tracer.Start(ctx, "postgres.WriteAuthorizationModelAnnotations")
+	defer span.End()
+
+	marshalledAnnotations, err := json.Marshal(annotations)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.stbl.
+		Insert("authorization_model_annotation").
+		Columns("store", "authorization_model_id", "annotations").
+		Values(store, modelID, marshalledAnnotations).
+		Suffix("ON CONFLICT (store, authorization_model_id) DO UPDATE SET annotations = ?", marshalledAnnotations).
+		ExecContext(ctx)
+	if err != nil {
+		return sqlcommon.HandleSQLError(err)
+	}
+
+	return nil
+}
+
+// WriteAuthorizationModelUseRecording see [storage.TypeDefinitionWriteBackend].WriteAuthorizationModelUseRecording.
+func (p *Postgres) WriteAuthorizationModelUseRecording(ctx context.Context, recordedAt time.Time, maxGap time.Duration) (time.Time, error) {
+	ctx, span := tracer.Start(ctx, "postgres.WriteAuthorizationModelUseRecording")
+	defer span.End()
+
+	return sqlcommon.WriteAuthorizationModelUseRecording(ctx, p.dbInfo, recordedAt, maxGap)
+}
+
+// AcquireAuthorizationModelRetentionLease see [storage.TypeDefinitionWriteBackend].AcquireAuthorizationModelRetentionLease.
+func (p *Postgres) AcquireAuthorizationModelRetentionLease(ctx context.Context, holder string, ttl time.Duration) (bool, error) {
+	ctx, span := tracer.Start(ctx, "postgres.AcquireAuthorizationModelRetentionLease")
+	defer span.End()
+
+	return sqlcommon.AcquireAuthorizationModelRetentionLease(ctx, p.dbInfo, holder, ttl)
+}
+
+// DeleteAuthorizationModel see [storage.TypeDefinitionWriteBackend].DeleteAuthorizationModel.
+func (p *Postgres) DeleteAuthorizationModel(ctx context.Context, store string, modelID string) error {
+	ctx, span := tracer.Start(ctx, "postgres.DeleteAuthorizationModel")
+	defer span.End()
+
+	return sqlcommon.DeleteAuthorizationModel(ctx, p.dbInfo, store, modelID)
+}
+
 // MaxTypesPerAuthorizationModel see [storage.TypeDefinitionWriteBackend].MaxTypesPerAuthorizationModel.
 func (p *Postgres) MaxTypesPerAuthorizationModel() int {
 	return p.maxTypesPerModelField
@@ -464,6 +563,18 @@ func (p *Postgres) WriteAuthorizationModel(ctx context.Context, store string, mo
 	return sqlcommon.WriteAuthorizationModel(ctx, p.dbInfo, store, model)
 }
 
+// WriteAuthorizationModelWithAnnotations see [storage.TypeDefinitionWriteBackend].WriteAuthorizationModelWithAnnotations.
+func (p *Postgres) WriteAuthorizationModelWithAnnotations(ctx context.Context, store string, model *openfgav1.AuthorizationModel, annotations *storage.AuthorizationModelAnnotations) error {
+	ctx, span := tracer.Start(ctx, "postgres.WriteAuthorizationModelWithAnnotations")
+	defer span.End()
+
+	if len(model.GetTypeDefinitions()) > p.MaxTypesPerAuthorizationModel() {
+		return storage.ExceededMaxTypeDefinitionsLimitError(p.maxTypesPerModelField)
+	}
+
+	return sqlcommon.WriteAuthorizationModelWithAnnotations(ctx, p.dbInfo, store, model, annotations)
+}
+
 // CreateStore adds a new store to the Postgres storage.
 func (p *Postgres) CreateStore(ctx context.Context, store *openfgav1.Store) (*openfgav1.Store, error) {
 	ctx, span := tracer.Start(ctx, "postgres.CreateStore")
@@ -588,16 +699,7 @@ func (p *Postgres) DeleteStore(ctx context.Context, id string) error {
 	ctx, span := tracer.Start(ctx, "postgres.DeleteStore")
 	defer span.End()
 
-	_, err := p.stbl.
-		Update("store").
-		Set("deleted_at", "NOW()").
-		Where(sq.Eq{"id": id}).
-		ExecContext(ctx)
-	if err != nil {
-		return sqlcommon.HandleSQLError(err)
-	}
-
-	return nil
+	return sqlcommon.DeleteStore(ctx, p.dbInfo, id)
 }
 
 // WriteAssertions see [storage.AssertionsBackend].WriteAssertions.
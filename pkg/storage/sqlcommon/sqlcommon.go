@@ -486,6 +486,65 @@ func WriteAuthorizationModel(
 	return nil
 }
 
+// WriteAuthorizationModelWithAnnotations writes an authorization model along with its annotations in a single
+// transaction, so that the model is never read without them.
+func WriteAuthorizationModelWithAnnotations(
+	ctx context.Context,
+	dbInfo *DBInfo,
+	store string,
+	model *openfgav1.AuthorizationModel,
+	annotations *storage.AuthorizationModelAnnotations,
+) error {
+	if len(model.GetTypeDefinitions()) < 1 {
+		return nil
+	}
+
+	pbdata, err := proto.Marshal(model)
+	if err != nil {
+		return err
+	}
+
+	marshalledAnnotations, err := json.Marshal(annotations)
+	if err != nil {
+		return err
+	}
+
+	txn, err := dbInfo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	defer func() {
+		_ = txn.Rollback()
+	}()
+
+	_, err = dbInfo.stbl.
+		Insert("authorization_model").
+		Columns("store", "authorization_model_id", "schema_version", "type", "type_definition", "serialized_protobuf").
+		Values(store, model.GetId(), model.GetSchemaVersion(), "", nil, pbdata).
+		RunWith(txn). // Part of a txn.
+		ExecContext(ctx)
+	if err != nil {
+		return HandleSQLError(err)
+	}
+
+	// the model is new, so it has no annotations to update yet
+	_, err = dbInfo.stbl.
+		Insert("authorization_model_annotation").
+		Columns("store", "authorization_model_id", "annotations").
+		Values(store, model.GetId(), marshalledAnnotations).
+		RunWith(txn). // Part of a txn.
+		ExecContext(ctx)
+	if err != nil {
+		return HandleSQLError(err)
+	}
+
+	if err := txn.Commit(); err != nil {
+		return HandleSQLError(err)
+	}
+
+	return nil
+}
+
 func constructAuthorizationModelFromSQLRows(rows *sql.Rows) (*openfgav1.AuthorizationModel, error) {
 	var modelID string
 	var schemaVersion string
@@ -552,6 +611,445 @@ func FindLatestAuthorizationModel(
 	return constructAuthorizationModelFromSQLRows(rows)
 }
 
+// ReadActiveAuthorizationModelID returns the ID of the model the store is pinned to.
+func ReadActiveAuthorizationModelID(
+	ctx context.Context,
+	dbInfo *DBInfo,
+	store string,
+) (string, error) {
+	var modelID string
+	err := dbInfo.stbl.
+		Select("authorization_model_id").
+		From("active_authorization_model").
+		Where(sq.Eq{"store": store}).
+		QueryRowContext(ctx).
+		Scan(&modelID)
+	if err != nil {
+		return "", HandleSQLError(err)
+	}
+
+	return modelID, nil
+}
+
+// WriteActiveAuthorizationModelID pins the store to the model with the given ID, or unpins it if the ID is empty,
+// and returns the ID of the model it was pinned to before, in a single transaction which locks the row of the store
+// so that concurrent changes are serialized.
+func WriteActiveAuthorizationModelID(
+	ctx context.Context,
+	dbInfo *DBInfo,
+	store, modelID string,
+) (string, error) {
+	txn, err := dbInfo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", HandleSQLError(err)
+	}
+	defer func() {
+		_ = txn.Rollback()
+	}()
+
+	var storeID string
+	err = dbInfo.stbl.
+		Select("id").
+		From("store").
+		Where(sq.Eq{"id": store}).
+		Suffix("FOR UPDATE").
+		RunWith(txn). // Part of a txn.
+		QueryRowContext(ctx).
+		Scan(&storeID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", HandleSQLError(err)
+	}
+
+	// the model is read under the lock of the store, so that it can't be deleted before the store is pinned to it
+	if modelID != "" {
+		var existingModelID string
+		err = dbInfo.stbl.
+			Select("authorization_model_id").
+			From("authorization_model").
+			Where(sq.Eq{
+				"store":                  store,
+				"authorization_model_id": modelID,
+			}).
+			Limit(1).
+			RunWith(txn). // Part of a txn.
+			QueryRowContext(ctx).
+			Scan(&existingModelID)
+		if err != nil {
+			return "", HandleSQLError(err)
+		}
+	}
+
+	var previousModelID string
+	err = dbInfo.stbl.
+		Select("authorization_model_id").
+		From("active_authorization_model").
+		Where(sq.Eq{"store": store}).
+		Suffix("FOR UPDATE").
+		RunWith(txn). // Part of a txn.
+		QueryRowContext(ctx).
+		Scan(&previousModelID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", HandleSQLError(err)
+	}
+
+	switch {
+	case modelID == "":
+		_, err = dbInfo.stbl.
+			Delete("active_authorization_model").
+			Where(sq.Eq{"store": store}).
+			RunWith(txn). // Part of a txn.
+			ExecContext(ctx)
+	case previousModelID == "":
+		_, err = dbInfo.stbl.
+			Insert("active_authorization_model").
+			Columns("store", "authorization_model_id", "updated_at").
+			Values(store, modelID, dbInfo.sqlTime).
+			RunWith(txn). // Part of a txn.
+			ExecContext(ctx)
+	default:
+		_, err = dbInfo.stbl.
+			Update("active_authorization_model").
+			Set("authorization_model_id", modelID).
+			Set("updated_at", dbInfo.sqlTime).
+			Where(sq.Eq{"store": store}).
+			RunWith(txn). // Part of a txn.
+			ExecContext(ctx)
+	}
+	if err != nil {
+		return "", HandleSQLError(err)
+	}
+
+	if err := txn.Commit(); err != nil {
+		return "", HandleSQLError(err)
+	}
+
+	return previousModelID, nil
+}
+
+// DeleteStore marks the store as deleted, and deletes the state of its authorization models which isn't kept once
+// the store is deleted (the model it is pinned to, the records of the last use of its models and their annotations),
+// in a single transaction.
+func DeleteStore(ctx context.Context, dbInfo *DBInfo, id string) error {
+	txn, err := dbInfo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	defer func() {
+		_ = txn.Rollback()
+	}()
+
+	_, err = dbInfo.stbl.
+		Update("store").
+		Set("deleted_at", dbInfo.sqlTime).
+		Where(sq.Eq{"id": id}).
+		RunWith(txn). // Part of a txn.
+		ExecContext(ctx)
+	if err != nil {
+		return HandleSQLError(err)
+	}
+
+	for _, table := range []string{"active_authorization_model", "authorization_model_last_used", "authorization_model_annotation"} {
+		_, err := dbInfo.stbl.
+			Delete(table).
+			Where(sq.Eq{"store": id}).
+			RunWith(txn). // Part of a txn.
+			ExecContext(ctx)
+		if err != nil {
+			return HandleSQLError(err)
+		}
+	}
+
+	if err := txn.Commit(); err != nil {
+		return HandleSQLError(err)
+	}
+
+	return nil
+}
+
+// ReadAuthorizationModelsLastUsed returns the times at which the models of the store were last used, keyed by
+// model ID.
+func ReadAuthorizationModelsLastUsed(
+	ctx context.Context,
+	dbInfo *DBInfo,
+	store string,
+) (map[string]time.Time, error) {
+	rows, err := dbInfo.stbl.
+		Select("authorization_model_id", "last_used_at").
+		From("authorization_model_last_used").
+		Where(sq.Eq{"store": store}).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, HandleSQLError(err)
+	}
+	defer rows.Close()
+
+	lastUsed := make(map[string]time.Time)
+	for rows.Next() {
+		var modelID string
+		var lastUsedAt time.Time
+		if err := rows.Scan(&modelID, &lastUsedAt); err != nil {
+			return nil, HandleSQLError(err)
+		}
+
+		lastUsed[modelID] = lastUsedAt.UTC()
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, HandleSQLError(err)
+	}
+
+	return lastUsed, nil
+}
+
+// ReadAuthorizationModelAnnotations returns the annotations of the model, or empty annotations if none were written.
+func ReadAuthorizationModelAnnotations(
+	ctx context.Context,
+	dbInfo *DBInfo,
+	store, modelID string,
+) (*storage.AuthorizationModelAnnotations, error) {
+	var marshalledAnnotations []byte
+	err := dbInfo.stbl.
+		Select("annotations").
+		From("authorization_model_annotation").
+		Where(sq.Eq{
+			"store":                  store,
+			"authorization_model_id": modelID,
+		}).
+		QueryRowContext(ctx).
+		Scan(&marshalledAnnotations)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &storage.AuthorizationModelAnnotations{}, nil
+		}
+		return nil, HandleSQLError(err)
+	}
+
+	var annotations storage.AuthorizationModelAnnotations
+	if err := json.Unmarshal(marshalledAnnotations, &annotations); err != nil {
+		return nil, err
+	}
+
+	return &annotations, nil
+}
+
+// authorizationModelRetentionID is the ID of the single row of the authorization_model_retention table.
+const authorizationModelRetentionID = 1
+
+// WriteAuthorizationModelUseRecording records that the use of the models by requests is being recorded at the given
+// time, and returns the time since which it has been recorded without a gap longer than maxGap.
+func WriteAuthorizationModelUseRecording(
+	ctx context.Context,
+	dbInfo *DBInfo,
+	recordedAt time.Time,
+	maxGap time.Duration,
+) (time.Time, error) {
+	recordedAt = recordedAt.UTC()
+
+	txn, err := dbInfo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return time.Time{}, HandleSQLError(err)
+	}
+	defer func() {
+		_ = txn.Rollback()
+	}()
+
+	var recordedSince, lastRecordedAt sql.NullTime
+	err = dbInfo.stbl.
+		Select("use_recorded_since", "use_recorded_at").
+		From("authorization_model_retention").
+		Where(sq.Eq{"id": authorizationModelRetentionID}).
+		Suffix("FOR UPDATE").
+		RunWith(txn). // Part of a txn.
+		QueryRowContext(ctx).
+		Scan(&recordedSince, &lastRecordedAt)
+	if err != nil {
+		return time.Time{}, HandleSQLError(err)
+	}
+
+	if !lastRecordedAt.Valid || recordedAt.Sub(lastRecordedAt.Time) > maxGap {
+		recordedSince = sql.NullTime{Time: recordedAt, Valid: true}
+	}
+
+	if !lastRecordedAt.Valid || recordedAt.After(lastRecordedAt.Time) {
+		lastRecordedAt = sql.NullTime{Time: recordedAt, Valid: true}
+	}
+
+	_, err = dbInfo.stbl.
+		Update("authorization_model_retention").
+		Set("use_recorded_since", recordedSince.Time.UTC()).
+		Set("use_recorded_at", lastRecordedAt.Time.UTC()).
+		Where(sq.Eq{"id": authorizationModelRetentionID}).
+		RunWith(txn). // Part of a txn.
+		ExecContext(ctx)
+	if err != nil {
+		return time.Time{}, HandleSQLError(err)
+	}
+
+	if err := txn.Commit(); err != nil {
+		return time.Time{}, HandleSQLError(err)
+	}
+
+	return recordedSince.Time.UTC(), nil
+}
+
+// AcquireAuthorizationModelRetentionLease acquires or renews the lease of the garbage collection of the models for
+// the holder, unless another holder holds it and it hasn't expired, and returns whether the holder holds it.
+func AcquireAuthorizationModelRetentionLease(
+	ctx context.Context,
+	dbInfo *DBInfo,
+	holder string,
+	ttl time.Duration,
+) (bool, error) {
+	txn, err := dbInfo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, HandleSQLError(err)
+	}
+	defer func() {
+		_ = txn.Rollback()
+	}()
+
+	var leaseHolder sql.NullString
+	var leaseExpiresAt sql.NullTime
+	err = dbInfo.stbl.
+		Select("lease_holder", "lease_expires_at").
+		From("authorization_model_retention").
+		Where(sq.Eq{"id": authorizationModelRetentionID}).
+		Suffix("FOR UPDATE").
+		RunWith(txn). // Part of a txn.
+		QueryRowContext(ctx).
+		Scan(&leaseHolder, &leaseExpiresAt)
+	if err != nil {
+		return false, HandleSQLError(err)
+	}
+
+	now := time.Now().UTC()
+	if leaseHolder.Valid && leaseHolder.String != holder && leaseExpiresAt.Valid && now.Before(leaseExpiresAt.Time) {
+		return false, nil
+	}
+
+	_, err = dbInfo.stbl.
+		Update("authorization_model_retention").
+		Set("lease_holder", holder).
+		Set("lease_expires_at", now.Add(ttl)).
+		Where(sq.Eq{"id": authorizationModelRetentionID}).
+		RunWith(txn). // Part of a txn.
+		ExecContext(ctx)
+	if err != nil {
+		return false, HandleSQLError(err)
+	}
+
+	if err := txn.Commit(); err != nil {
+		return false, HandleSQLError(err)
+	}
+
+	return true, nil
+}
+
+// DeleteAuthorizationModel deletes the model, along with its assertions, its annotations and the record of its last
+// use, in a single transaction, unless it's the latest model of the store or the model the store is pinned to.
+func DeleteAuthorizationModel(
+	ctx context.Context,
+	dbInfo *DBInfo,
+	store, modelID string,
+) error {
+	txn, err := dbInfo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return HandleSQLError(err)
+	}
+	defer func() {
+		_ = txn.Rollback()
+	}()
+
+	// locking the store serializes the deletion with the changes of the model the store is pinned to
+	var storeID string
+	err = dbInfo.stbl.
+		Select("id").
+		From("store").
+		Where(sq.Eq{"id": store}).
+		Suffix("FOR UPDATE").
+		RunWith(txn). // Part of a txn.
+		QueryRowContext(ctx).
+		Scan(&storeID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return HandleSQLError(err)
+	}
+
+	var latestModelID string
+	err = dbInfo.stbl.
+		Select("authorization_model_id").
+		From("authorization_model").
+		Where(sq.Eq{"store": store}).
+		OrderBy("authorization_model_id desc").
+		Limit(1).
+		RunWith(txn). // Part of a txn.
+		QueryRowContext(ctx).
+		Scan(&latestModelID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return HandleSQLError(err)
+	}
+
+	if latestModelID == modelID {
+		return storage.ErrLatestAuthorizationModel
+	}
+
+	var activeModelID string
+	err = dbInfo.stbl.
+		Select("authorization_model_id").
+		From("active_authorization_model").
+		Where(sq.Eq{"store": store}).
+		RunWith(txn). // Part of a txn.
+		QueryRowContext(ctx).
+		Scan(&activeModelID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return HandleSQLError(err)
+	}
+
+	if activeModelID == modelID {
+		return storage.ErrActiveAuthorizationModel
+	}
+
+	res, err := dbInfo.stbl.
+		Delete("authorization_model").
+		Where(sq.Eq{
+			"store":                  store,
+			"authorization_model_id": modelID,
+		}).
+		RunWith(txn). // Part of a txn.
+		ExecContext(ctx)
+	if err != nil {
+		return HandleSQLError(err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return HandleSQLError(err)
+	}
+
+	if rowsAffected == 0 {
+		return storage.ErrNotFound
+	}
+
+	for _, table := range []string{"assertion", "authorization_model_annotation", "authorization_model_last_used"} {
+		_, err := dbInfo.stbl.
+			Delete(table).
+			Where(sq.Eq{
+				"store":                  store,
+				"authorization_model_id": modelID,
+			}).
+			RunWith(txn). // Part of a txn.
+			ExecContext(ctx)
+		if err != nil {
+			return HandleSQLError(err)
+		}
+	}
+
+	if err := txn.Commit(); err != nil {
+		return HandleSQLError(err)
+	}
+
+	return nil
+}
+
 // ReadAuthorizationModel reads the model corresponding to store and model ID.
 func ReadAuthorizationModel(
 	ctx context.Context,
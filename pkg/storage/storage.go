@@ -181,6 +181,55 @@ type ReadUsersetTuplesFilter struct {
 	AllowedUserTypeRestrictions []*openfgav1.RelationReference // Optional.
 }
 
+// Annotations are free-form key/value metadata, such as descriptions, owners or UI hints.
+type Annotations map[string]string
+
+// TypeAnnotations are the annotations of a type and of its relations, keyed by relation name.
+type TypeAnnotations struct {
+	Annotations Annotations            `json:"annotations,omitempty"`
+	Relations   map[string]Annotations `json:"relations,omitempty"`
+}
+
+// GetAnnotations returns the annotations of the type, or nil if t is nil.
+func (t *TypeAnnotations) GetAnnotations() Annotations {
+	if t == nil {
+		return nil
+	}
+	return t.Annotations
+}
+
+// GetRelations returns the annotations of the relations of the type, or nil if t is nil.
+func (t *TypeAnnotations) GetRelations() map[string]Annotations {
+	if t == nil {
+		return nil
+	}
+	return t.Relations
+}
+
+// AuthorizationModelAnnotations are the annotations of the types, relations and conditions of an authorization
+// model, keyed by type and condition name. They are stored alongside the model, which is immutable, and can be
+// overwritten.
+type AuthorizationModelAnnotations struct {
+	Types      map[string]*TypeAnnotations `json:"types,omitempty"`
+	Conditions map[string]Annotations      `json:"conditions,omitempty"`
+}
+
+// GetTypes returns the annotations of the types of the model, or nil if a is nil.
+func (a *AuthorizationModelAnnotations) GetTypes() map[string]*TypeAnnotations {
+	if a == nil {
+		return nil
+	}
+	return a.Types
+}
+
+// GetConditions returns the annotations of the conditions of the model, or nil if a is nil.
+func (a *AuthorizationModelAnnotations) GetConditions() map[string]Annotations {
+	if a == nil {
+		return nil
+	}
+	return a.Conditions
+}
+
 // AuthorizationModelReadBackend provides a read interface for managing type definitions.
 type AuthorizationModelReadBackend interface {
 	// ReadAuthorizationModel reads the model corresponding to store and model ID.
@@ -193,6 +242,18 @@ type AuthorizationModelReadBackend interface {
 	// FindLatestAuthorizationModel returns the last model for the store.
 	// If none were ever written, it must return ErrNotFound.
 	FindLatestAuthorizationModel(ctx context.Context, store string) (*openfgav1.AuthorizationModel, error)
+
+	// ReadActiveAuthorizationModelID returns the ID of the model the store is pinned to, which is used instead of
+	// the latest model when none is specified. If the store isn't pinned to a model, it must return ErrNotFound.
+	ReadActiveAuthorizationModelID(ctx context.Context, store string) (string, error)
+
+	// ReadAuthorizationModelsLastUsed returns the times at which the models of the store were last used, as recorded
+	// with WriteAuthorizationModelLastUsed, keyed by model ID. Models whose use was never recorded are omitted.
+	ReadAuthorizationModelsLastUsed(ctx context.Context, store string) (map[string]time.Time, error)
+
+	// ReadAuthorizationModelAnnotations returns the annotations of the model.
+	// If none were ever written, it must return empty annotations.
+	ReadAuthorizationModelAnnotations(ctx context.Context, store string, modelID string) (*AuthorizationModelAnnotations, error)
 }
 
 // TypeDefinitionWriteBackend provides a write interface for managing typed definition.
@@ -202,6 +263,39 @@ type TypeDefinitionWriteBackend interface {
 
 	// WriteAuthorizationModel writes an authorization model for the given store.
 	WriteAuthorizationModel(ctx context.Context, store string, model *openfgav1.AuthorizationModel) error
+
+	// WriteAuthorizationModelWithAnnotations writes an authorization model for the given store along with its
+	// annotations, atomically, so that the model is never read without its annotations and the annotations are
+	// never written without the model.
+	WriteAuthorizationModelWithAnnotations(ctx context.Context, store string, model *openfgav1.AuthorizationModel, annotations *AuthorizationModelAnnotations) error
+
+	// WriteActiveAuthorizationModelID pins the store to the model with the given ID, or unpins it if the ID is empty.
+	// It returns the ID of the model the store was pinned to before (empty if it wasn't pinned), read atomically with
+	// the change, so that concurrent changes each return the ID they replaced. If the model doesn't exist (e.g. it was
+	// deleted), it must return ErrNotFound, checked atomically with the change.
+	WriteActiveAuthorizationModelID(ctx context.Context, store string, modelID string) (string, error)
+
+	// WriteAuthorizationModelLastUsed records the time at which the model was last used by a request.
+	WriteAuthorizationModelLastUsed(ctx context.Context, store string, modelID string, lastUsed time.Time) error
+
+	// WriteAuthorizationModelAnnotations overwrites the annotations of the model.
+	WriteAuthorizationModelAnnotations(ctx context.Context, store string, modelID string, annotations *AuthorizationModelAnnotations) error
+
+	// WriteAuthorizationModelUseRecording records that the use of the models by requests is being recorded at the
+	// given time, and returns the time since which it has been recorded without interruption. If it wasn't recorded
+	// within maxGap before, the recording is considered to start again at the given time.
+	WriteAuthorizationModelUseRecording(ctx context.Context, recordedAt time.Time, maxGap time.Duration) (time.Time, error)
+
+	// AcquireAuthorizationModelRetentionLease acquires or renews the lease of the garbage collection of the models for
+	// the holder, until ttl from now, and returns whether the holder holds it. The lease can't be acquired while
+	// another holder holds it and it hasn't expired.
+	AcquireAuthorizationModelRetentionLease(ctx context.Context, holder string, ttl time.Duration) (bool, error)
+
+	// DeleteAuthorizationModel deletes the model, along with its assertions, its annotations and the record of its
+	// last use. If it's not found, it must return ErrNotFound. If it's the latest model of the store, or the model the
+	// store is pinned to, it must return ErrLatestAuthorizationModel or ErrActiveAuthorizationModel respectively and
+	// leave it, checking so atomically with the deletion.
+	DeleteAuthorizationModel(ctx context.Context, store string, modelID string) error
 }
 
 // AuthorizationModelBackend provides an read/write interface for managing models and their type definitions.
@@ -62,6 +62,18 @@ func (c *cachedOpenFGADatastore) FindLatestAuthorizationModel(ctx context.Contex
 	return v.(*openfgav1.AuthorizationModel), nil
 }
 
+// DeleteAuthorizationModel see [storage.TypeDefinitionWriteBackend].DeleteAuthorizationModel.
+// The model is also evicted from the cache.
+func (c *cachedOpenFGADatastore) DeleteAuthorizationModel(ctx context.Context, storeID, modelID string) error {
+	if err := c.OpenFGADatastore.DeleteAuthorizationModel(ctx, storeID, modelID); err != nil {
+		return err
+	}
+
+	c.cache.Delete(fmt.Sprintf("%s:%s", storeID, modelID))
+
+	return nil
+}
+
 // Close closes the datastore and cleans up any residual resources.
 func (c *cachedOpenFGADatastore) Close() {
 	c.cache.Stop()
@@ -14,6 +14,7 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/openfga/openfga/internal/mocks"
+	"github.com/openfga/openfga/pkg/storage"
 	"github.com/openfga/openfga/pkg/typesystem"
 )
 
@@ -122,3 +123,46 @@ func TestSingleFlightFindLatestAuthorizationModel(t *testing.T) {
 	err := wg.Wait()
 	require.NoError(t, err)
 }
+
+func TestDeleteAuthorizationModelEvictsCachedModel(t *testing.T) {
+	ctx := context.Background()
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+	mockController := gomock.NewController(t)
+	mockController.Finish()
+
+	mockDatastore := mocks.NewMockOpenFGADatastore(mockController)
+	cachingBackend := NewCachedOpenFGADatastore(mockDatastore, 5)
+	t.Cleanup(cachingBackend.Close)
+	model := &openfgav1.AuthorizationModel{
+		Id:            ulid.Make().String(),
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: []*openfgav1.TypeDefinition{
+			{
+				Type: "documents",
+				Relations: map[string]*openfgav1.Userset{
+					"admin": typesystem.This(),
+				},
+			},
+		},
+	}
+	storeID := ulid.Make().String()
+	gomock.InOrder(
+		mockDatastore.EXPECT().ReadAuthorizationModel(gomock.Any(), storeID, model.GetId()).Times(1).Return(model, nil),
+		mockDatastore.EXPECT().DeleteAuthorizationModel(gomock.Any(), storeID, model.GetId()).Times(1).Return(nil),
+		mockDatastore.EXPECT().ReadAuthorizationModel(gomock.Any(), storeID, model.GetId()).Times(1).Return(nil, storage.ErrNotFound),
+		mockDatastore.EXPECT().Close().Times(1),
+	)
+
+	_, err := cachingBackend.ReadAuthorizationModel(ctx, storeID, model.GetId())
+	require.NoError(t, err)
+
+	err = cachingBackend.DeleteAuthorizationModel(ctx, storeID, model.GetId())
+	require.NoError(t, err)
+
+	require.Nil(t, cachingBackend.cache.Get(fmt.Sprintf("%s:%s", storeID, model.GetId())))
+
+	_, err = cachingBackend.ReadAuthorizationModel(ctx, storeID, model.GetId())
+	require.ErrorIs(t, err, storage.ErrNotFound)
+}
@@ -0,0 +1,198 @@
+package storagewrappers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+var _ storage.RelationshipTupleReader = (*memoizingTupleReader)(nil)
+
+type memoizedRead struct {
+	done   chan struct{}
+	tuples []*openfgav1.Tuple
+	err    error
+
+	// streamed is true if the read had more tuples than could be memoized, so that every caller streams them from
+	// the datastore instead.
+	streamed bool
+}
+
+type memoizingTupleReader struct {
+	storage.RelationshipTupleReader
+
+	maxTuples uint32
+
+	mu     sync.Mutex
+	reads  map[string]*memoizedRead
+	tuples uint32
+}
+
+// NewMemoizingTupleReader returns a wrapper over a datastore that memoizes the tuples returned by calls to
+// Read, ReadUsersetTuples and ReadStartingWithUser, so that identical reads (including concurrent ones) only
+// go to the datastore once. The tuples of each read are held in memory for the lifetime of the wrapper, so it
+// is meant to be scoped to a single request. Failed reads are not memoized.
+//
+// At most maxTuples tuples are held in memory across all reads. A read with more tuples than fit is not memoized:
+// its tuples are streamed from the datastore, once buffered up to the limit, and every identical read streams them
+// again.
+func NewMemoizingTupleReader(wrapped storage.RelationshipTupleReader, maxTuples uint32) *memoizingTupleReader {
+	return &memoizingTupleReader{
+		RelationshipTupleReader: wrapped,
+		maxTuples:               maxTuples,
+		reads:                   map[string]*memoizedRead{},
+	}
+}
+
+// Read see [storage.RelationshipTupleReader.Read].
+func (m *memoizingTupleReader) Read(
+	ctx context.Context,
+	store string,
+	tupleKey *openfgav1.TupleKey,
+) (storage.TupleIterator, error) {
+	key := strings.Join([]string{"read", store, tupleKey.GetObject(), tupleKey.GetRelation(), tupleKey.GetUser()}, "|")
+
+	return m.memoize(ctx, key, func(ctx context.Context) (storage.TupleIterator, error) {
+		return m.RelationshipTupleReader.Read(ctx, store, tupleKey)
+	})
+}
+
+// ReadUsersetTuples see [storage.RelationshipTupleReader.ReadUsersetTuples].
+func (m *memoizingTupleReader) ReadUsersetTuples(
+	ctx context.Context,
+	store string,
+	filter storage.ReadUsersetTuplesFilter,
+) (storage.TupleIterator, error) {
+	parts := []string{"userset", store, filter.Object, filter.Relation}
+	for _, ref := range filter.AllowedUserTypeRestrictions {
+		switch {
+		case ref.GetWildcard() != nil:
+			parts = append(parts, tuple.TypedPublicWildcard(ref.GetType()))
+		default:
+			parts = append(parts, tuple.ToObjectRelationString(ref.GetType(), ref.GetRelation()))
+		}
+	}
+
+	return m.memoize(ctx, strings.Join(parts, "|"), func(ctx context.Context) (storage.TupleIterator, error) {
+		return m.RelationshipTupleReader.ReadUsersetTuples(ctx, store, filter)
+	})
+}
+
+// ReadStartingWithUser see [storage.RelationshipTupleReader.ReadStartingWithUser].
+func (m *memoizingTupleReader) ReadStartingWithUser(
+	ctx context.Context,
+	store string,
+	filter storage.ReadStartingWithUserFilter,
+) (storage.TupleIterator, error) {
+	parts := []string{"startingwithuser", store, filter.ObjectType, filter.Relation}
+	for _, user := range filter.UserFilter {
+		parts = append(parts, tuple.ToObjectRelationString(user.GetObject(), user.GetRelation()))
+	}
+
+	return m.memoize(ctx, strings.Join(parts, "|"), func(ctx context.Context) (storage.TupleIterator, error) {
+		return m.RelationshipTupleReader.ReadStartingWithUser(ctx, store, filter)
+	})
+}
+
+// memoize returns an iterator over the tuples of the read identified by key, reading them with the provided
+// function if they haven't been read yet. If the same read is in flight, it waits for it instead.
+func (m *memoizingTupleReader) memoize(
+	ctx context.Context,
+	key string,
+	read func(ctx context.Context) (storage.TupleIterator, error),
+) (storage.TupleIterator, error) {
+	for {
+		m.mu.Lock()
+		entry, ok := m.reads[key]
+		if !ok {
+			entry = &memoizedRead{done: make(chan struct{})}
+			m.reads[key] = entry
+			budget := m.maxTuples - m.tuples
+			m.mu.Unlock()
+
+			tuples, remaining, err := readUpTo(ctx, read, budget)
+			if err != nil {
+				m.mu.Lock()
+				delete(m.reads, key)
+				m.mu.Unlock()
+
+				entry.err = err
+				close(entry.done)
+
+				return nil, err
+			}
+
+			m.mu.Lock()
+			if remaining != nil || m.tuples+uint32(len(tuples)) > m.maxTuples {
+				// the read doesn't fit in what is left of the budget, which concurrent reads may have used
+				entry.streamed = true
+			} else {
+				entry.tuples = tuples
+				m.tuples += uint32(len(tuples))
+			}
+			m.mu.Unlock()
+			close(entry.done)
+
+			if remaining != nil {
+				return storage.NewCombinedIterator(storage.NewStaticTupleIterator(tuples), remaining), nil
+			}
+
+			return storage.NewStaticTupleIterator(tuples), nil
+		}
+		m.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-entry.done:
+		}
+
+		if entry.streamed {
+			return read(ctx)
+		}
+
+		if entry.err == nil {
+			return storage.NewStaticTupleIterator(entry.tuples), nil
+		}
+
+		// the read of another caller failed (e.g. its context was cancelled), so read again
+	}
+}
+
+// readUpTo reads the tuples of a read up to the limit. If the read has more tuples, it returns one tuple beyond the
+// limit along with the iterator over the rest of them, which the caller must stop.
+func readUpTo(
+	ctx context.Context,
+	read func(ctx context.Context) (storage.TupleIterator, error),
+	limit uint32,
+) ([]*openfgav1.Tuple, storage.TupleIterator, error) {
+	iter, err := read(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var tuples []*openfgav1.Tuple
+	for {
+		if uint32(len(tuples)) > limit {
+			return tuples, iter, nil
+		}
+
+		t, err := iter.Next(ctx)
+		if err != nil {
+			iter.Stop()
+			if errors.Is(err, storage.ErrIteratorDone) {
+				return tuples, nil, nil
+			}
+
+			return nil, nil, err
+		}
+
+		tuples = append(tuples, t)
+	}
+}
@@ -0,0 +1,184 @@
+package storagewrappers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"go.uber.org/mock/gomock"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/openfga/openfga/internal/mocks"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+func TestMemoizingTupleReader(t *testing.T) {
+	t.Cleanup(func() {
+		goleak.VerifyNone(t)
+	})
+
+	store := ulid.Make().String()
+
+	readAllTuples := func(t *testing.T, iter storage.TupleIterator) []*openfgav1.TupleKey {
+		t.Helper()
+
+		var tupleKeys []*openfgav1.TupleKey
+		for {
+			tk, err := iter.Next(context.Background())
+			if errors.Is(err, storage.ErrIteratorDone) {
+				return tupleKeys
+			}
+			require.NoError(t, err)
+
+			tupleKeys = append(tupleKeys, tk.GetKey())
+		}
+	}
+
+	t.Run("identical_reads_are_read_once", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+
+		tk := tuple.NewTupleKey("document:1", "viewer", "user:jon")
+		filter := storage.ReadStartingWithUserFilter{
+			ObjectType: "document",
+			Relation:   "viewer",
+			UserFilter: []*openfgav1.ObjectRelation{{Object: "user:jon"}},
+		}
+
+		mockDatastore := mocks.NewMockRelationshipTupleReader(ctrl)
+		mockDatastore.EXPECT().
+			ReadStartingWithUser(gomock.Any(), store, filter).
+			Times(1).
+			Return(storage.NewStaticTupleIterator([]*openfgav1.Tuple{{Key: tk}}), nil)
+		mockDatastore.EXPECT().
+			ReadStartingWithUser(gomock.Any(), store, gomock.Not(filter)).
+			Times(1).
+			Return(storage.NewStaticTupleIterator(nil), nil)
+
+		reader := NewMemoizingTupleReader(mockDatastore, 10)
+
+		iters := make([]storage.TupleIterator, 5)
+
+		var wg errgroup.Group
+		for i := range iters {
+			wg.Go(func() error {
+				var err error
+				iters[i], err = reader.ReadStartingWithUser(context.Background(), store, filter)
+				return err
+			})
+		}
+		require.NoError(t, wg.Wait())
+
+		for _, iter := range iters {
+			require.Equal(t, []*openfgav1.TupleKey{tk}, readAllTuples(t, iter))
+		}
+
+		iter, err := reader.ReadStartingWithUser(context.Background(), store, storage.ReadStartingWithUserFilter{
+			ObjectType: "document",
+			Relation:   "editor",
+			UserFilter: []*openfgav1.ObjectRelation{{Object: "user:jon"}},
+		})
+		require.NoError(t, err)
+		require.Empty(t, readAllTuples(t, iter))
+	})
+
+	t.Run("failed_reads_are_not_memoized", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+
+		tk := tuple.NewTupleKey("document:1", "viewer", "group:eng#member")
+		filter := storage.ReadUsersetTuplesFilter{
+			Object:   "document:1",
+			Relation: "viewer",
+			AllowedUserTypeRestrictions: []*openfgav1.RelationReference{
+				{Type: "group", RelationOrWildcard: &openfgav1.RelationReference_Relation{Relation: "member"}},
+			},
+		}
+
+		mockDatastore := mocks.NewMockRelationshipTupleReader(ctrl)
+		gomock.InOrder(
+			mockDatastore.EXPECT().
+				ReadUsersetTuples(gomock.Any(), store, filter).
+				Return(nil, storage.ErrTransactionalWriteFailed),
+			mockDatastore.EXPECT().
+				ReadUsersetTuples(gomock.Any(), store, filter).
+				Return(storage.NewStaticTupleIterator([]*openfgav1.Tuple{{Key: tk}}), nil),
+		)
+
+		reader := NewMemoizingTupleReader(mockDatastore, 10)
+
+		_, err := reader.ReadUsersetTuples(context.Background(), store, filter)
+		require.ErrorIs(t, err, storage.ErrTransactionalWriteFailed)
+
+		for i := 0; i < 2; i++ {
+			iter, err := reader.ReadUsersetTuples(context.Background(), store, filter)
+			require.NoError(t, err)
+			require.Equal(t, []*openfgav1.TupleKey{tk}, readAllTuples(t, iter))
+		}
+	})
+
+	t.Run("reads_beyond_the_limit_are_streamed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+
+		small := tuple.NewTupleKey("document:1", "viewer", "")
+		large := tuple.NewTupleKey("document:2", "viewer", "")
+
+		smallTuples := []*openfgav1.Tuple{
+			{Key: tuple.NewTupleKey("document:1", "viewer", "user:jon")},
+			{Key: tuple.NewTupleKey("document:1", "viewer", "user:maria")},
+		}
+		largeTuples := []*openfgav1.Tuple{
+			{Key: tuple.NewTupleKey("document:2", "viewer", "user:jon")},
+			{Key: tuple.NewTupleKey("document:2", "viewer", "user:maria")},
+			{Key: tuple.NewTupleKey("document:2", "viewer", "user:anne")},
+		}
+
+		mockDatastore := mocks.NewMockRelationshipTupleReader(ctrl)
+		mockDatastore.EXPECT().Read(gomock.Any(), store, small).Times(1).
+			Return(storage.NewStaticTupleIterator(smallTuples), nil)
+		mockDatastore.EXPECT().Read(gomock.Any(), store, large).Times(2).
+			DoAndReturn(func(context.Context, string, *openfgav1.TupleKey) (storage.TupleIterator, error) {
+				return storage.NewStaticTupleIterator(largeTuples), nil
+			})
+
+		// the small read uses 2 of the 4 tuples of the budget, so the large read doesn't fit and is streamed
+		reader := NewMemoizingTupleReader(mockDatastore, 4)
+
+		for i := 0; i < 2; i++ {
+			iter, err := reader.Read(context.Background(), store, small)
+			require.NoError(t, err)
+			require.Len(t, readAllTuples(t, iter), 2)
+
+			iter, err = reader.Read(context.Background(), store, large)
+			require.NoError(t, err)
+			require.Equal(t, []*openfgav1.TupleKey{largeTuples[0].GetKey(), largeTuples[1].GetKey(), largeTuples[2].GetKey()}, readAllTuples(t, iter))
+			iter.Stop()
+		}
+	})
+
+	t.Run("reads_of_different_stores_are_distinct", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		t.Cleanup(ctrl.Finish)
+
+		otherStore := ulid.Make().String()
+		tk := tuple.NewTupleKey("document:1", "viewer", "")
+
+		mockDatastore := mocks.NewMockRelationshipTupleReader(ctrl)
+		mockDatastore.EXPECT().Read(gomock.Any(), store, tk).Times(1).Return(storage.NewStaticTupleIterator(nil), nil)
+		mockDatastore.EXPECT().Read(gomock.Any(), otherStore, tk).Times(1).Return(storage.NewStaticTupleIterator(nil), nil)
+
+		reader := NewMemoizingTupleReader(mockDatastore, 10)
+
+		for _, s := range []string{store, otherStore, store, otherStore} {
+			iter, err := reader.Read(context.Background(), s, tk)
+			require.NoError(t, err)
+			require.Empty(t, readAllTuples(t, iter))
+		}
+	})
+}
@@ -3,6 +3,7 @@ package test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/oklog/ulid/v2"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/openfga/openfga/pkg/storage"
 	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
 	"github.com/openfga/openfga/pkg/typesystem"
 )
 
@@ -225,3 +227,406 @@ func FindLatestAuthorizationModelTest(t *testing.T, datastore storage.OpenFGADat
 		}
 	})
 }
+
+func ActiveAuthorizationModelIDTest(t *testing.T, datastore storage.OpenFGADatastore) {
+	ctx := context.Background()
+
+	writeModel := func(t *testing.T, store string) string {
+		model := &openfgav1.AuthorizationModel{
+			Id:              ulid.Make().String(),
+			SchemaVersion:   typesystem.SchemaVersion1_1,
+			TypeDefinitions: []*openfgav1.TypeDefinition{{Type: "user"}},
+		}
+
+		err := datastore.WriteAuthorizationModel(ctx, store, model)
+		require.NoError(t, err)
+
+		return model.GetId()
+	}
+
+	t.Run("read_active_authorization_model_id_should_return_not_found_when_not_pinned", func(t *testing.T) {
+		store := ulid.Make().String()
+		_, err := datastore.ReadActiveAuthorizationModelID(ctx, store)
+		require.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("write_then_read_active_authorization_model_id_succeeds", func(t *testing.T) {
+		store := ulid.Make().String()
+		modelID1 := writeModel(t, store)
+		modelID2 := writeModel(t, store)
+
+		previousModelID, err := datastore.WriteActiveAuthorizationModelID(ctx, store, modelID1)
+		require.NoError(t, err)
+		require.Empty(t, previousModelID)
+
+		activeModelID, err := datastore.ReadActiveAuthorizationModelID(ctx, store)
+		require.NoError(t, err)
+		require.Equal(t, modelID1, activeModelID)
+
+		previousModelID, err = datastore.WriteActiveAuthorizationModelID(ctx, store, modelID2)
+		require.NoError(t, err)
+		require.Equal(t, modelID1, previousModelID)
+
+		activeModelID, err = datastore.ReadActiveAuthorizationModelID(ctx, store)
+		require.NoError(t, err)
+		require.Equal(t, modelID2, activeModelID)
+
+		_, err = datastore.ReadActiveAuthorizationModelID(ctx, ulid.Make().String())
+		require.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("write_empty_active_authorization_model_id_unpins_the_store", func(t *testing.T) {
+		store := ulid.Make().String()
+
+		modelID := writeModel(t, store)
+
+		_, err := datastore.WriteActiveAuthorizationModelID(ctx, store, modelID)
+		require.NoError(t, err)
+
+		previousModelID, err := datastore.WriteActiveAuthorizationModelID(ctx, store, "")
+		require.NoError(t, err)
+		require.Equal(t, modelID, previousModelID)
+
+		_, err = datastore.ReadActiveAuthorizationModelID(ctx, store)
+		require.ErrorIs(t, err, storage.ErrNotFound)
+
+		// unpinning a store which isn't pinned is a no-op
+		previousModelID, err = datastore.WriteActiveAuthorizationModelID(ctx, store, "")
+		require.NoError(t, err)
+		require.Empty(t, previousModelID)
+	})
+
+	t.Run("delete_store_deletes_the_state_of_its_authorization_models", func(t *testing.T) {
+		store, err := datastore.CreateStore(ctx, &openfgav1.Store{
+			Id:   ulid.Make().String(),
+			Name: "store",
+		})
+		require.NoError(t, err)
+
+		modelID := writeModel(t, store.GetId())
+
+		_, err = datastore.WriteActiveAuthorizationModelID(ctx, store.GetId(), modelID)
+		require.NoError(t, err)
+
+		err = datastore.WriteAuthorizationModelLastUsed(ctx, store.GetId(), modelID, time.Now())
+		require.NoError(t, err)
+
+		err = datastore.WriteAuthorizationModelAnnotations(ctx, store.GetId(), modelID, &storage.AuthorizationModelAnnotations{
+			Types: map[string]*storage.TypeAnnotations{
+				"document": {Annotations: storage.Annotations{"description": "a document"}},
+			},
+		})
+		require.NoError(t, err)
+
+		err = datastore.DeleteStore(ctx, store.GetId())
+		require.NoError(t, err)
+
+		_, err = datastore.ReadActiveAuthorizationModelID(ctx, store.GetId())
+		require.ErrorIs(t, err, storage.ErrNotFound)
+
+		lastUsed, err := datastore.ReadAuthorizationModelsLastUsed(ctx, store.GetId())
+		require.NoError(t, err)
+		require.Empty(t, lastUsed)
+
+		annotations, err := datastore.ReadAuthorizationModelAnnotations(ctx, store.GetId(), modelID)
+		require.NoError(t, err)
+		require.Empty(t, annotations.GetTypes())
+	})
+
+	t.Run("pin_a_deleted_model_returns_not_found", func(t *testing.T) {
+		store := ulid.Make().String()
+		modelID := writeModel(t, store)
+		latestModelID := writeModel(t, store)
+
+		err := datastore.DeleteAuthorizationModel(ctx, store, modelID)
+		require.NoError(t, err)
+
+		_, err = datastore.WriteActiveAuthorizationModelID(ctx, store, modelID)
+		require.ErrorIs(t, err, storage.ErrNotFound)
+
+		_, err = datastore.WriteActiveAuthorizationModelID(ctx, store, ulid.Make().String())
+		require.ErrorIs(t, err, storage.ErrNotFound)
+
+		_, err = datastore.ReadActiveAuthorizationModelID(ctx, store)
+		require.ErrorIs(t, err, storage.ErrNotFound)
+
+		// a model of another store can't be pinned either
+		_, err = datastore.WriteActiveAuthorizationModelID(ctx, ulid.Make().String(), latestModelID)
+		require.ErrorIs(t, err, storage.ErrNotFound)
+	})
+}
+
+func AuthorizationModelLastUsedTest(t *testing.T, datastore storage.OpenFGADatastore) {
+	ctx := context.Background()
+
+	t.Run("read_authorization_models_last_used_returns_empty_when_never_recorded", func(t *testing.T) {
+		lastUsed, err := datastore.ReadAuthorizationModelsLastUsed(ctx, ulid.Make().String())
+		require.NoError(t, err)
+		require.Empty(t, lastUsed)
+	})
+
+	t.Run("write_then_read_authorization_model_last_used_succeeds", func(t *testing.T) {
+		store := ulid.Make().String()
+		modelID1 := ulid.Make().String()
+		modelID2 := ulid.Make().String()
+
+		usedAt := time.Now().UTC().Truncate(time.Second)
+
+		err := datastore.WriteAuthorizationModelLastUsed(ctx, store, modelID1, usedAt.Add(-time.Hour))
+		require.NoError(t, err)
+
+		err = datastore.WriteAuthorizationModelLastUsed(ctx, store, modelID2, usedAt.Add(-time.Hour))
+		require.NoError(t, err)
+
+		// overwrites the earlier record
+		err = datastore.WriteAuthorizationModelLastUsed(ctx, store, modelID1, usedAt)
+		require.NoError(t, err)
+
+		lastUsed, err := datastore.ReadAuthorizationModelsLastUsed(ctx, store)
+		require.NoError(t, err)
+		require.Len(t, lastUsed, 2)
+		require.True(t, usedAt.Equal(lastUsed[modelID1]))
+		require.True(t, usedAt.Add(-time.Hour).Equal(lastUsed[modelID2]))
+	})
+}
+
+func DeleteAuthorizationModelTest(t *testing.T, datastore storage.OpenFGADatastore) {
+	ctx := context.Background()
+
+	newModel := func() *openfgav1.AuthorizationModel {
+		return &openfgav1.AuthorizationModel{
+			Id:              ulid.Make().String(),
+			SchemaVersion:   typesystem.SchemaVersion1_1,
+			TypeDefinitions: []*openfgav1.TypeDefinition{{Type: "user"}},
+		}
+	}
+
+	t.Run("delete_authorization_model_which_does_not_exist_returns_not_found", func(t *testing.T) {
+		err := datastore.DeleteAuthorizationModel(ctx, ulid.Make().String(), ulid.Make().String())
+		require.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("delete_authorization_model_deletes_its_assertions_annotations_and_last_use", func(t *testing.T) {
+		store := ulid.Make().String()
+		model := newModel()
+		otherModel := newModel()
+
+		for _, m := range []*openfgav1.AuthorizationModel{model, otherModel} {
+			err := datastore.WriteAuthorizationModel(ctx, store, m)
+			require.NoError(t, err)
+
+			err = datastore.WriteAssertions(ctx, store, m.GetId(), []*openfgav1.Assertion{{
+				TupleKey:    tuple.NewAssertionTupleKey("user:anne", "member", "user:anne"),
+				Expectation: false,
+			}})
+			require.NoError(t, err)
+
+			err = datastore.WriteAuthorizationModelLastUsed(ctx, store, m.GetId(), time.Now())
+			require.NoError(t, err)
+
+			err = datastore.WriteAuthorizationModelAnnotations(ctx, store, m.GetId(), &storage.AuthorizationModelAnnotations{
+				Types: map[string]*storage.TypeAnnotations{"user": {Annotations: storage.Annotations{"owner": "iam"}}},
+			})
+			require.NoError(t, err)
+		}
+
+		err := datastore.DeleteAuthorizationModel(ctx, store, model.GetId())
+		require.NoError(t, err)
+
+		_, err = datastore.ReadAuthorizationModel(ctx, store, model.GetId())
+		require.ErrorIs(t, err, storage.ErrNotFound)
+
+		assertions, err := datastore.ReadAssertions(ctx, store, model.GetId())
+		require.NoError(t, err)
+		require.Empty(t, assertions)
+
+		annotations, err := datastore.ReadAuthorizationModelAnnotations(ctx, store, model.GetId())
+		require.NoError(t, err)
+		require.Empty(t, annotations.Types)
+
+		lastUsed, err := datastore.ReadAuthorizationModelsLastUsed(ctx, store)
+		require.NoError(t, err)
+		require.NotContains(t, lastUsed, model.GetId())
+		require.Contains(t, lastUsed, otherModel.GetId())
+
+		// the other model is left as it is
+		_, err = datastore.ReadAuthorizationModel(ctx, store, otherModel.GetId())
+		require.NoError(t, err)
+
+		assertions, err = datastore.ReadAssertions(ctx, store, otherModel.GetId())
+		require.NoError(t, err)
+		require.Len(t, assertions, 1)
+
+		err = datastore.DeleteAuthorizationModel(ctx, store, model.GetId())
+		require.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("delete_latest_or_active_authorization_model_fails", func(t *testing.T) {
+		store := ulid.Make().String()
+		activeModel := newModel()
+		latestModel := newModel()
+
+		err := datastore.WriteAuthorizationModel(ctx, store, activeModel)
+		require.NoError(t, err)
+
+		err = datastore.WriteAuthorizationModel(ctx, store, latestModel)
+		require.NoError(t, err)
+
+		_, err = datastore.WriteActiveAuthorizationModelID(ctx, store, activeModel.GetId())
+		require.NoError(t, err)
+
+		err = datastore.DeleteAuthorizationModel(ctx, store, latestModel.GetId())
+		require.ErrorIs(t, err, storage.ErrLatestAuthorizationModel)
+
+		err = datastore.DeleteAuthorizationModel(ctx, store, activeModel.GetId())
+		require.ErrorIs(t, err, storage.ErrActiveAuthorizationModel)
+
+		models, _, err := datastore.ReadAuthorizationModels(ctx, store, storage.PaginationOptions{PageSize: storage.DefaultPageSize})
+		require.NoError(t, err)
+		require.Len(t, models, 2)
+
+		// once unpinned, the model can be deleted
+		_, err = datastore.WriteActiveAuthorizationModelID(ctx, store, "")
+		require.NoError(t, err)
+
+		err = datastore.DeleteAuthorizationModel(ctx, store, activeModel.GetId())
+		require.NoError(t, err)
+	})
+}
+
+func AuthorizationModelRetentionTest(t *testing.T, datastore storage.OpenFGADatastore) {
+	ctx := context.Background()
+
+	t.Run("write_authorization_model_use_recording_restarts_after_a_gap", func(t *testing.T) {
+		// later than any recording written before
+		start := time.Now().UTC().Add(24 * time.Hour).Truncate(time.Second)
+
+		recordedSince, err := datastore.WriteAuthorizationModelUseRecording(ctx, start, time.Minute)
+		require.NoError(t, err)
+		require.True(t, start.Equal(recordedSince))
+
+		recordedSince, err = datastore.WriteAuthorizationModelUseRecording(ctx, start.Add(30*time.Second), time.Minute)
+		require.NoError(t, err)
+		require.True(t, start.Equal(recordedSince))
+
+		recordedSince, err = datastore.WriteAuthorizationModelUseRecording(ctx, start.Add(5*time.Minute), time.Minute)
+		require.NoError(t, err)
+		require.True(t, start.Add(5*time.Minute).Equal(recordedSince))
+	})
+
+	t.Run("acquire_authorization_model_retention_lease_excludes_other_holders_until_it_expires", func(t *testing.T) {
+		holder := ulid.Make().String()
+		otherHolder := ulid.Make().String()
+
+		acquired, err := datastore.AcquireAuthorizationModelRetentionLease(ctx, holder, time.Hour)
+		require.NoError(t, err)
+		require.True(t, acquired)
+
+		acquired, err = datastore.AcquireAuthorizationModelRetentionLease(ctx, otherHolder, time.Hour)
+		require.NoError(t, err)
+		require.False(t, acquired)
+
+		// renewed by the holder, with an expiry in the past
+		acquired, err = datastore.AcquireAuthorizationModelRetentionLease(ctx, holder, -time.Minute)
+		require.NoError(t, err)
+		require.True(t, acquired)
+
+		acquired, err = datastore.AcquireAuthorizationModelRetentionLease(ctx, otherHolder, time.Hour)
+		require.NoError(t, err)
+		require.True(t, acquired)
+	})
+}
+
+func AuthorizationModelAnnotationsTest(t *testing.T, datastore storage.OpenFGADatastore) {
+	ctx := context.Background()
+
+	t.Run("read_authorization_model_annotations_returns_empty_when_never_written", func(t *testing.T) {
+		annotations, err := datastore.ReadAuthorizationModelAnnotations(ctx, ulid.Make().String(), ulid.Make().String())
+		require.NoError(t, err)
+		require.Equal(t, &storage.AuthorizationModelAnnotations{}, annotations)
+	})
+
+	t.Run("write_then_read_authorization_model_annotations_succeeds", func(t *testing.T) {
+		store := ulid.Make().String()
+		modelID := ulid.Make().String()
+
+		err := datastore.WriteAuthorizationModelAnnotations(ctx, store, modelID, &storage.AuthorizationModelAnnotations{
+			Types: map[string]*storage.TypeAnnotations{"document": {Annotations: storage.Annotations{"owner": "docs"}}},
+		})
+		require.NoError(t, err)
+
+		// overwrites the earlier annotations
+		expected := &storage.AuthorizationModelAnnotations{
+			Types: map[string]*storage.TypeAnnotations{
+				"document": {
+					Annotations: storage.Annotations{"description": "A document", "owner": "docs"},
+					Relations: map[string]storage.Annotations{
+						"viewer": {"description": "Can read the document", "ui.hidden": "false"},
+					},
+				},
+			},
+			Conditions: map[string]storage.Annotations{
+				"in_office_hours": {"description": "Between 9am and 5pm"},
+			},
+		}
+		err = datastore.WriteAuthorizationModelAnnotations(ctx, store, modelID, expected)
+		require.NoError(t, err)
+
+		annotations, err := datastore.ReadAuthorizationModelAnnotations(ctx, store, modelID)
+		require.NoError(t, err)
+		require.Equal(t, expected, annotations)
+
+		annotations, err = datastore.ReadAuthorizationModelAnnotations(ctx, store, ulid.Make().String())
+		require.NoError(t, err)
+		require.Equal(t, &storage.AuthorizationModelAnnotations{}, annotations)
+	})
+
+	t.Run("write_authorization_model_with_annotations_writes_both", func(t *testing.T) {
+		store := ulid.Make().String()
+		model := &openfgav1.AuthorizationModel{
+			Id:              ulid.Make().String(),
+			SchemaVersion:   typesystem.SchemaVersion1_1,
+			TypeDefinitions: []*openfgav1.TypeDefinition{{Type: "document"}},
+		}
+
+		expected := &storage.AuthorizationModelAnnotations{
+			Types: map[string]*storage.TypeAnnotations{"document": {Annotations: storage.Annotations{"owner": "docs"}}},
+		}
+
+		err := datastore.WriteAuthorizationModelWithAnnotations(ctx, store, model, expected)
+		require.NoError(t, err)
+
+		latestModel, err := datastore.FindLatestAuthorizationModel(ctx, store)
+		require.NoError(t, err)
+		require.Equal(t, model.GetId(), latestModel.GetId())
+
+		annotations, err := datastore.ReadAuthorizationModelAnnotations(ctx, store, model.GetId())
+		require.NoError(t, err)
+		require.Equal(t, expected, annotations)
+	})
+
+	t.Run("changing_the_annotations_written_or_read_leaves_the_stored_ones", func(t *testing.T) {
+		store := ulid.Make().String()
+		modelID := ulid.Make().String()
+
+		written := &storage.AuthorizationModelAnnotations{
+			Types: map[string]*storage.TypeAnnotations{
+				"document": {Relations: map[string]storage.Annotations{"viewer": {"description": "Can read"}}},
+			},
+		}
+		err := datastore.WriteAuthorizationModelAnnotations(ctx, store, modelID, written)
+		require.NoError(t, err)
+
+		written.Types["document"].Relations["viewer"]["description"] = "changed after the write"
+
+		read, err := datastore.ReadAuthorizationModelAnnotations(ctx, store, modelID)
+		require.NoError(t, err)
+		require.Equal(t, "Can read", read.Types["document"].Relations["viewer"]["description"])
+
+		read.Types["document"].Relations["viewer"]["description"] = "changed after the read"
+
+		read, err = datastore.ReadAuthorizationModelAnnotations(ctx, store, modelID)
+		require.NoError(t, err)
+		require.Equal(t, "Can read", read.Types["document"].Relations["viewer"]["description"])
+	})
+}
@@ -42,6 +42,11 @@ func RunAllTests(t *testing.T, ds storage.OpenFGADatastore) {
 	t.Run("TestWriteAndReadAuthorizationModel", func(t *testing.T) { WriteAndReadAuthorizationModelTest(t, ds) })
 	t.Run("TestReadAuthorizationModels", func(t *testing.T) { ReadAuthorizationModelsTest(t, ds) })
 	t.Run("TestFindLatestAuthorizationModel", func(t *testing.T) { FindLatestAuthorizationModelTest(t, ds) })
+	t.Run("TestActiveAuthorizationModelID", func(t *testing.T) { ActiveAuthorizationModelIDTest(t, ds) })
+	t.Run("TestAuthorizationModelLastUsed", func(t *testing.T) { AuthorizationModelLastUsedTest(t, ds) })
+	t.Run("TestAuthorizationModelAnnotations", func(t *testing.T) { AuthorizationModelAnnotationsTest(t, ds) })
+	t.Run("TestDeleteAuthorizationModel", func(t *testing.T) { DeleteAuthorizationModelTest(t, ds) })
+	t.Run("TestAuthorizationModelRetention", func(t *testing.T) { AuthorizationModelRetentionTest(t, ds) })
 
 	// Assertions.
 	t.Run("TestWriteAndReadAssertions", func(t *testing.T) { AssertionsTest(t, ds) })
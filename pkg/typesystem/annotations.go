@@ -0,0 +1,319 @@
+package typesystem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+const (
+	// MaxAnnotationKeyLength is the maximum length, in bytes, of the key of an annotation.
+	MaxAnnotationKeyLength = 128
+
+	// MaxAnnotationValueLength is the maximum length, in bytes, of the value of an annotation.
+	MaxAnnotationValueLength = 4096
+
+	// MaxAnnotationsPerEntity is the maximum number of annotations of a type, relation or condition.
+	MaxAnnotationsPerEntity = 64
+
+	// MaxAnnotationsSizeInBytes is the maximum size, in bytes, of the JSON encoding of the annotations of an
+	// authorization model, in which they are stored.
+	MaxAnnotationsSizeInBytes = 1 << 20
+)
+
+// ErrInvalidAnnotations is returned when the annotations of an authorization model refer to types, relations or
+// conditions which the model doesn't define, or exceed the limits on annotations.
+var ErrInvalidAnnotations = errors.New("invalid authorization model annotations")
+
+// ValidateAnnotations validates the annotations of the types, relations and conditions of an authorization model:
+// every annotated type, relation and condition must be defined by the model, and the keys of the annotations must be
+// non-empty, have no whitespace and be at most MaxAnnotationKeyLength bytes long, their values at most
+// MaxAnnotationValueLength bytes long, each type, relation and condition may have at most MaxAnnotationsPerEntity
+// annotations, and all of them may take at most MaxAnnotationsSizeInBytes once encoded.
+func ValidateAnnotations(t *TypeSystem, annotations *storage.AuthorizationModelAnnotations) error {
+	for _, objectType := range sortedMapKeys(annotations.GetTypes()) {
+		if _, ok := t.GetTypeDefinition(objectType); !ok {
+			return fmt.Errorf("%w: type '%s' is not defined", ErrInvalidAnnotations, objectType)
+		}
+
+		typeAnnotations := annotations.GetTypes()[objectType]
+		if err := validateAnnotations("type '"+objectType+"'", typeAnnotations.GetAnnotations()); err != nil {
+			return err
+		}
+
+		for _, relation := range sortedMapKeys(typeAnnotations.GetRelations()) {
+			if _, err := t.GetRelation(objectType, relation); err != nil {
+				return fmt.Errorf("%w: relation '%s' is not defined", ErrInvalidAnnotations, tuple.ToObjectRelationString(objectType, relation))
+			}
+
+			entity := "relation '" + tuple.ToObjectRelationString(objectType, relation) + "'"
+			if err := validateAnnotations(entity, typeAnnotations.GetRelations()[relation]); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, name := range sortedMapKeys(annotations.GetConditions()) {
+		if _, ok := t.GetCondition(name); !ok {
+			return fmt.Errorf("%w: condition '%s' is not defined", ErrInvalidAnnotations, name)
+		}
+
+		if err := validateAnnotations("condition '"+name+"'", annotations.GetConditions()[name]); err != nil {
+			return err
+		}
+	}
+
+	marshalledAnnotations, err := json.Marshal(annotations)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidAnnotations, err)
+	}
+
+	if len(marshalledAnnotations) > MaxAnnotationsSizeInBytes {
+		return fmt.Errorf("%w: the annotations take more than %d bytes", ErrInvalidAnnotations, MaxAnnotationsSizeInBytes)
+	}
+
+	return nil
+}
+
+func validateAnnotations(entity string, annotations storage.Annotations) error {
+	if len(annotations) > MaxAnnotationsPerEntity {
+		return fmt.Errorf("%w: %s has more than %d annotations", ErrInvalidAnnotations, entity, MaxAnnotationsPerEntity)
+	}
+
+	for _, key := range sortedMapKeys(annotations) {
+		switch {
+		case key == "":
+			return fmt.Errorf("%w: %s has an annotation with an empty key", ErrInvalidAnnotations, entity)
+		case len(key) > MaxAnnotationKeyLength:
+			return fmt.Errorf("%w: the key of the annotation '%s' of %s is longer than %d bytes", ErrInvalidAnnotations, key, entity, MaxAnnotationKeyLength)
+		case strings.IndexFunc(key, unicode.IsSpace) >= 0:
+			return fmt.Errorf("%w: the key of the annotation '%s' of %s has whitespace", ErrInvalidAnnotations, key, entity)
+		case len(annotations[key]) > MaxAnnotationValueLength:
+			return fmt.Errorf("%w: the value of the annotation '%s' of %s is longer than %d bytes", ErrInvalidAnnotations, key, entity, MaxAnnotationValueLength)
+		}
+	}
+
+	return nil
+}
+
+func sortedMapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// RelationSourceOperator is the operator of the operation which a relation source is an operand of, which tells how
+// it contributes to the relation computed from it.
+type RelationSourceOperator string
+
+const (
+	// RelationSourceUnion is the operator of the sources which grant the relation, as operands of a union, as the
+	// base of an exclusion, or on their own (e.g. 'owner' in 'define editor: [user] or owner').
+	RelationSourceUnion RelationSourceOperator = "union"
+
+	// RelationSourceIntersection is the operator of the sources which are required by the relation, as operands of
+	// an intersection (e.g. 'member' in 'define viewer: [user] and member').
+	RelationSourceIntersection RelationSourceOperator = "intersection"
+
+	// RelationSourceExclusion is the operator of the sources which revoke the relation, as the subtracted operand of
+	// an exclusion (e.g. 'blocked' in 'define viewer: [user] but not blocked').
+	RelationSourceExclusion RelationSourceOperator = "exclusion"
+)
+
+// RelationSource is a relation which a relation is computed from, through a computed userset (e.g. 'owner' in
+// 'define editor: [user] or owner') or a tuple to userset rewrite (e.g. 'viewer from parent').
+type RelationSource struct {
+	// Relation is the relation computed from, e.g. 'document#owner' or 'folder#viewer'.
+	Relation string `json:"relation"`
+
+	// Tupleset is the tupleset relation of the tuple to userset rewrite, e.g. 'parent', or empty for computed
+	// usersets.
+	Tupleset string `json:"tupleset,omitempty"`
+
+	// Operator is the operator of the innermost operation the source is an operand of.
+	Operator RelationSourceOperator `json:"operator"`
+}
+
+// RelationDescription describes a relation of an authorization model, with its annotations and those of its type.
+type RelationDescription struct {
+	Type     string `json:"type"`
+	Relation string `json:"relation"`
+
+	// Definition is the definition of the relation in the DSL, e.g. '[user, group#member] or owner or viewer from
+	// parent'.
+	Definition string `json:"definition"`
+
+	// DirectlyRelatedUserTypes are the user types which may be directly related to the relation, e.g. 'user',
+	// 'group#member', 'user:*' or 'user with in_office_hours'.
+	DirectlyRelatedUserTypes []string `json:"directly_related_user_types,omitempty"`
+
+	// ComputedFrom are the relations which the relation is computed from, in the order of its definition.
+	ComputedFrom []*RelationSource `json:"computed_from,omitempty"`
+
+	Annotations     storage.Annotations `json:"annotations,omitempty"`
+	TypeAnnotations storage.Annotations `json:"type_annotations,omitempty"`
+}
+
+// DescribeRelation describes a relation of an authorization model with its annotations, which may be nil. It returns
+// ErrObjectTypeUndefined or ErrRelationUndefined if the model doesn't define the relation.
+func DescribeRelation(t *TypeSystem, annotations *storage.AuthorizationModelAnnotations, objectType, relation string) (*RelationDescription, error) {
+	rel, err := t.GetRelation(objectType, relation)
+	if err != nil {
+		return nil, err
+	}
+
+	description := &RelationDescription{
+		Type:       objectType,
+		Relation:   relation,
+		Definition: formatRewrite(rel, rel.GetRewrite(), false),
+	}
+
+	for _, rr := range rel.GetTypeInfo().GetDirectlyRelatedUserTypes() {
+		description.DirectlyRelatedUserTypes = append(description.DirectlyRelatedUserTypes, formatRelationReference(rr))
+	}
+
+	seen := make(map[RelationSource]bool)
+	for _, source := range t.relationSources(objectType, rel.GetRewrite(), RelationSourceUnion) {
+		if !seen[*source] {
+			seen[*source] = true
+			description.ComputedFrom = append(description.ComputedFrom, source)
+		}
+	}
+
+	if typeAnnotations, ok := annotations.GetTypes()[objectType]; ok {
+		description.TypeAnnotations = typeAnnotations.GetAnnotations()
+		description.Annotations = typeAnnotations.GetRelations()[relation]
+	}
+
+	return description, nil
+}
+
+// relationSources returns the relations which a userset rewrite of a relation of the object type is computed from,
+// with the operator of the operation the rewrite is an operand of. The sources of a tuple to userset rewrite are the
+// computed relation of every type related to the tupleset which defines it.
+func (t *TypeSystem) relationSources(objectType string, rewrite *openfgav1.Userset, operator RelationSourceOperator) []*RelationSource {
+	switch rw := rewrite.GetUserset().(type) {
+	case *openfgav1.Userset_ComputedUserset:
+		return []*RelationSource{{
+			Relation: tuple.ToObjectRelationString(objectType, rw.ComputedUserset.GetRelation()),
+			Operator: operator,
+		}}
+	case *openfgav1.Userset_TupleToUserset:
+		tupleset := rw.TupleToUserset.GetTupleset().GetRelation()
+		tuplesetRel, err := t.GetRelation(objectType, tupleset)
+		if err != nil {
+			return nil
+		}
+
+		var sources []*RelationSource
+		computedRelation := rw.TupleToUserset.GetComputedUserset().GetRelation()
+		for _, rr := range tuplesetRel.GetTypeInfo().GetDirectlyRelatedUserTypes() {
+			if _, err := t.GetRelation(rr.GetType(), computedRelation); err != nil {
+				continue
+			}
+			sources = append(sources, &RelationSource{
+				Relation: tuple.ToObjectRelationString(rr.GetType(), computedRelation),
+				Tupleset: tupleset,
+				Operator: operator,
+			})
+		}
+
+		return sources
+	case *openfgav1.Userset_Union:
+		var sources []*RelationSource
+		for _, child := range rw.Union.GetChild() {
+			sources = append(sources, t.relationSources(objectType, child, RelationSourceUnion)...)
+		}
+
+		return sources
+	case *openfgav1.Userset_Intersection:
+		var sources []*RelationSource
+		for _, child := range rw.Intersection.GetChild() {
+			sources = append(sources, t.relationSources(objectType, child, RelationSourceIntersection)...)
+		}
+
+		return sources
+	case *openfgav1.Userset_Difference:
+		return append(
+			t.relationSources(objectType, rw.Difference.GetBase(), operator),
+			t.relationSources(objectType, rw.Difference.GetSubtract(), RelationSourceExclusion)...,
+		)
+	default:
+		return nil
+	}
+}
+
+// formatRewrite renders a userset rewrite of a relation in the DSL. Nested operations are parenthesized.
+func formatRewrite(rel *openfgav1.Relation, rewrite *openfgav1.Userset, nested bool) string {
+	var operands []string
+	var operator string
+
+	switch rw := rewrite.GetUserset().(type) {
+	case *openfgav1.Userset_This:
+		userTypes := make([]string, 0, len(rel.GetTypeInfo().GetDirectlyRelatedUserTypes()))
+		for _, rr := range rel.GetTypeInfo().GetDirectlyRelatedUserTypes() {
+			userTypes = append(userTypes, formatRelationReference(rr))
+		}
+
+		return "[" + strings.Join(userTypes, ", ") + "]"
+	case *openfgav1.Userset_ComputedUserset:
+		return rw.ComputedUserset.GetRelation()
+	case *openfgav1.Userset_TupleToUserset:
+		return rw.TupleToUserset.GetComputedUserset().GetRelation() + " from " + rw.TupleToUserset.GetTupleset().GetRelation()
+	case *openfgav1.Userset_Union:
+		operator = " or "
+		for _, child := range rw.Union.GetChild() {
+			operands = append(operands, formatRewrite(rel, child, true))
+		}
+	case *openfgav1.Userset_Intersection:
+		operator = " and "
+		for _, child := range rw.Intersection.GetChild() {
+			operands = append(operands, formatRewrite(rel, child, true))
+		}
+	case *openfgav1.Userset_Difference:
+		operator = " but not "
+		operands = []string{
+			formatRewrite(rel, rw.Difference.GetBase(), true),
+			formatRewrite(rel, rw.Difference.GetSubtract(), true),
+		}
+	default:
+		return ""
+	}
+
+	formatted := strings.Join(operands, operator)
+	if nested {
+		return "(" + formatted + ")"
+	}
+
+	return formatted
+}
+
+// formatRelationReference renders a user type of a type restriction in the DSL, e.g. 'user', 'group#member',
+// 'user:*' or 'user with in_office_hours'.
+func formatRelationReference(rr *openfgav1.RelationReference) string {
+	formatted := rr.GetType()
+	switch {
+	case rr.GetRelation() != "":
+		formatted = tuple.ToObjectRelationString(rr.GetType(), rr.GetRelation())
+	case rr.GetWildcard() != nil:
+		formatted = tuple.TypedPublicWildcard(rr.GetType())
+	}
+
+	if rr.GetCondition() != "" {
+		formatted += " with " + rr.GetCondition()
+	}
+
+	return formatted
+}
@@ -0,0 +1,243 @@
+package typesystem
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/testutils"
+)
+
+func newAnnotationsTestTypesystem(t *testing.T) *TypeSystem {
+	t.Helper()
+
+	typesys, err := NewAndValidate(context.Background(), testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type group
+			relations
+				define member: [user, group#member]
+		type folder
+			relations
+				define viewer: [user, user:*]
+		type document
+			relations
+				define parent: [folder]
+				define owner: [user]
+				define blocked: [user]
+				define editor: [user with in_office_hours] or owner
+				define viewer: ([group#member] or editor or viewer from parent) but not blocked
+				define approver: [user] and editor
+		condition in_office_hours(hour: int) {
+			hour >= 9 && hour <= 17
+		}`))
+	require.NoError(t, err)
+
+	return typesys
+}
+
+func TestValidateAnnotations(t *testing.T) {
+	typesys := newAnnotationsTestTypesystem(t)
+
+	tests := map[string]struct {
+		annotations   *storage.AuthorizationModelAnnotations
+		expectedError string
+	}{
+		`nil`: {},
+		`valid`: {
+			annotations: &storage.AuthorizationModelAnnotations{
+				Types: map[string]*storage.TypeAnnotations{
+					"document": {
+						Annotations: storage.Annotations{"owner": "docs-team"},
+						Relations: map[string]storage.Annotations{
+							"viewer": {"description": "Can read the document", "ui.hidden": ""},
+						},
+					},
+				},
+				Conditions: map[string]storage.Annotations{
+					"in_office_hours": {"description": "Between 9am and 5pm"},
+				},
+			},
+		},
+		`undefined_type`: {
+			annotations: &storage.AuthorizationModelAnnotations{
+				Types: map[string]*storage.TypeAnnotations{"team": {}},
+			},
+			expectedError: "type 'team' is not defined",
+		},
+		`undefined_relation`: {
+			annotations: &storage.AuthorizationModelAnnotations{
+				Types: map[string]*storage.TypeAnnotations{
+					"document": {Relations: map[string]storage.Annotations{"writer": {}}},
+				},
+			},
+			expectedError: "relation 'document#writer' is not defined",
+		},
+		`undefined_condition`: {
+			annotations: &storage.AuthorizationModelAnnotations{
+				Conditions: map[string]storage.Annotations{"is_weekend": {}},
+			},
+			expectedError: "condition 'is_weekend' is not defined",
+		},
+		`empty_key`: {
+			annotations: &storage.AuthorizationModelAnnotations{
+				Types: map[string]*storage.TypeAnnotations{
+					"user": {Annotations: storage.Annotations{"": "value"}},
+				},
+			},
+			expectedError: "type 'user' has an annotation with an empty key",
+		},
+		`key_with_whitespace`: {
+			annotations: &storage.AuthorizationModelAnnotations{
+				Types: map[string]*storage.TypeAnnotations{
+					"document": {Relations: map[string]storage.Annotations{"owner": {"the owner": "value"}}},
+				},
+			},
+			expectedError: "the key of the annotation 'the owner' of relation 'document#owner' has whitespace",
+		},
+		`key_too_long`: {
+			annotations: &storage.AuthorizationModelAnnotations{
+				Conditions: map[string]storage.Annotations{
+					"in_office_hours": {strings.Repeat("k", MaxAnnotationKeyLength+1): "value"},
+				},
+			},
+			expectedError: "of condition 'in_office_hours' is longer than 128 bytes",
+		},
+		`value_too_long`: {
+			annotations: &storage.AuthorizationModelAnnotations{
+				Types: map[string]*storage.TypeAnnotations{
+					"user": {Annotations: storage.Annotations{"description": strings.Repeat("v", MaxAnnotationValueLength+1)}},
+				},
+			},
+			expectedError: "the value of the annotation 'description' of type 'user' is longer than 4096 bytes",
+		},
+		`too_many_annotations`: {
+			annotations: &storage.AuthorizationModelAnnotations{
+				Types: map[string]*storage.TypeAnnotations{
+					"user": {Annotations: func() storage.Annotations {
+						annotations := storage.Annotations{}
+						for i := 0; i <= MaxAnnotationsPerEntity; i++ {
+							annotations[strings.Repeat("k", i+1)] = ""
+						}
+						return annotations
+					}()},
+				},
+			},
+			expectedError: "type 'user' has more than 64 annotations",
+		},
+		`too_large`: {
+			annotations: &storage.AuthorizationModelAnnotations{
+				Types: map[string]*storage.TypeAnnotations{
+					"document": {Relations: func() map[string]storage.Annotations {
+						relations := map[string]storage.Annotations{}
+						for _, relation := range []string{"parent", "owner", "blocked", "editor", "viewer"} {
+							relations[relation] = storage.Annotations{}
+							for i := 0; i < MaxAnnotationsPerEntity; i++ {
+								relations[relation][strings.Repeat("k", i+1)] = strings.Repeat("v", MaxAnnotationValueLength)
+							}
+						}
+						return relations
+					}()},
+				},
+			},
+			expectedError: "the annotations take more than 1048576 bytes",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateAnnotations(typesys, test.annotations)
+			if test.expectedError == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, ErrInvalidAnnotations)
+			require.ErrorContains(t, err, test.expectedError)
+		})
+	}
+}
+
+func TestDescribeRelation(t *testing.T) {
+	typesys := newAnnotationsTestTypesystem(t)
+
+	annotations := &storage.AuthorizationModelAnnotations{
+		Types: map[string]*storage.TypeAnnotations{
+			"document": {
+				Annotations: storage.Annotations{"owner": "docs-team"},
+				Relations: map[string]storage.Annotations{
+					"viewer": {"description": "Can read the document"},
+				},
+			},
+		},
+	}
+
+	t.Run("computed_relation", func(t *testing.T) {
+		description, err := DescribeRelation(typesys, annotations, "document", "viewer")
+		require.NoError(t, err)
+		require.Equal(t, &RelationDescription{
+			Type:                     "document",
+			Relation:                 "viewer",
+			Definition:               "([group#member] or editor or viewer from parent) but not blocked",
+			DirectlyRelatedUserTypes: []string{"group#member"},
+			ComputedFrom: []*RelationSource{
+				{Relation: "document#editor", Operator: RelationSourceUnion},
+				{Relation: "folder#viewer", Tupleset: "parent", Operator: RelationSourceUnion},
+				{Relation: "document#blocked", Operator: RelationSourceExclusion},
+			},
+			Annotations:     storage.Annotations{"description": "Can read the document"},
+			TypeAnnotations: storage.Annotations{"owner": "docs-team"},
+		}, description)
+	})
+
+	t.Run("conditional_user_type", func(t *testing.T) {
+		description, err := DescribeRelation(typesys, annotations, "document", "editor")
+		require.NoError(t, err)
+		require.Equal(t, &RelationDescription{
+			Type:                     "document",
+			Relation:                 "editor",
+			Definition:               "[user with in_office_hours] or owner",
+			DirectlyRelatedUserTypes: []string{"user with in_office_hours"},
+			ComputedFrom:             []*RelationSource{{Relation: "document#owner", Operator: RelationSourceUnion}},
+			TypeAnnotations:          storage.Annotations{"owner": "docs-team"},
+		}, description)
+	})
+
+	t.Run("intersection", func(t *testing.T) {
+		description, err := DescribeRelation(typesys, annotations, "document", "approver")
+		require.NoError(t, err)
+		require.Equal(t, &RelationDescription{
+			Type:                     "document",
+			Relation:                 "approver",
+			Definition:               "[user] and editor",
+			DirectlyRelatedUserTypes: []string{"user"},
+			ComputedFrom:             []*RelationSource{{Relation: "document#editor", Operator: RelationSourceIntersection}},
+			TypeAnnotations:          storage.Annotations{"owner": "docs-team"},
+		}, description)
+	})
+
+	t.Run("without_annotations", func(t *testing.T) {
+		description, err := DescribeRelation(typesys, nil, "folder", "viewer")
+		require.NoError(t, err)
+		require.Equal(t, &RelationDescription{
+			Type:                     "folder",
+			Relation:                 "viewer",
+			Definition:               "[user, user:*]",
+			DirectlyRelatedUserTypes: []string{"user", "user:*"},
+		}, description)
+	})
+
+	t.Run("undefined_type", func(t *testing.T) {
+		_, err := DescribeRelation(typesys, annotations, "team", "member")
+		require.ErrorIs(t, err, ErrObjectTypeUndefined)
+	})
+
+	t.Run("undefined_relation", func(t *testing.T) {
+		_, err := DescribeRelation(typesys, annotations, "document", "writer")
+		require.ErrorIs(t, err, ErrRelationUndefined)
+	})
+}
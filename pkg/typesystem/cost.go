@@ -0,0 +1,300 @@
+package typesystem
+
+import (
+	"sort"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// DatastoreReadKind is a kind of datastore read performed by Check.
+type DatastoreReadKind string
+
+const (
+	// DatastoreReadUserTuple is the read of a single tuple of a relation with a user, for directly assignable relations.
+	DatastoreReadUserTuple DatastoreReadKind = "read_user_tuple"
+
+	// DatastoreReadUsersetTuples is the read of the tuples of a relation with usersets (e.g. 'group:eng#member') or
+	// typed wildcards, for relations which allow them.
+	DatastoreReadUsersetTuples DatastoreReadKind = "read_userset_tuples"
+
+	// DatastoreRead is the read of the tuples of a tupleset relation, for tuple to userset rewrites.
+	DatastoreRead DatastoreReadKind = "read"
+)
+
+// RelationCost is the static worst-case cost of the Check and ListObjects queries on a relation, predicted from the
+// authorization model only.
+type RelationCost struct {
+	Type     string `json:"type"`
+	Relation string `json:"relation"`
+
+	// MaxDispatchDepth is the maximum number of nested dispatches of a Check on the relation (one per computed
+	// userset, userset type restriction and tuple to userset rewrite followed), not counting the dispatches through
+	// cycles.
+	MaxDispatchDepth int `json:"max_dispatch_depth"`
+
+	// HasCycle is true if the relation is, or refers to, a recursive relation (e.g. 'define member: [user, group#member]'),
+	// in which case dispatches may be nested until the resolution depth limit.
+	HasCycle bool `json:"has_cycle"`
+
+	// DatastoreReadKinds are the kinds of datastore reads which a Check on the relation may perform, sorted.
+	DatastoreReadKinds []DatastoreReadKind `json:"datastore_read_kinds"`
+
+	// HasIntersection and HasExclusion are true if the relation is, or refers to, a relation defined with an
+	// intersection or an exclusion.
+	HasIntersection bool `json:"has_intersection"`
+	HasExclusion    bool `json:"has_exclusion"`
+
+	// ListObjectsCheckFallbackUserTypes are the types of the users for which ListObjects needs to Check each of the
+	// objects found by reverse expansion, because intersections or exclusions are involved. It's only set by
+	// the analysis of the relationship graph of the model.
+	ListObjectsCheckFallbackUserTypes []string `json:"list_objects_check_fallback_user_types"`
+}
+
+// relationCostNode is the local cost of resolving a relation, not counting the relations it dispatches to.
+type relationCostNode struct {
+	dispatches      []string
+	reads           map[DatastoreReadKind]struct{}
+	hasIntersection bool
+	hasExclusion    bool
+}
+
+// costEstimator computes the costs of the relations of a model from the graph of the dispatches of Check between
+// them, condensed into its strongly connected components.
+type costEstimator struct {
+	*TypeSystem
+
+	nodes map[string]*relationCostNode
+
+	// components are the strongly connected components of the relations, and component maps each relation to the
+	// index of its component.
+	components [][]string
+	component  map[string]int
+
+	costs map[int]*RelationCost
+}
+
+// EstimateCosts returns the static worst-case costs of Check on the relations of a model, sorted by type and relation.
+// The ListObjectsCheckFallbackUserTypes of the costs aren't set.
+func EstimateCosts(t *TypeSystem) []*RelationCost {
+	e := newCostEstimator(t)
+
+	var costs []*RelationCost
+	for objectType, relations := range t.GetAllRelations() {
+		for relation := range relations {
+			costs = append(costs, e.cost(objectType, relation))
+		}
+	}
+
+	sort.Slice(costs, func(i, j int) bool {
+		if costs[i].Type != costs[j].Type {
+			return costs[i].Type < costs[j].Type
+		}
+		return costs[i].Relation < costs[j].Relation
+	})
+
+	return costs
+}
+
+// EstimateCost returns the static worst-case cost of Check on a relation. The ListObjectsCheckFallbackUserTypes of
+// the cost aren't set.
+func EstimateCost(t *TypeSystem, objectType, relation string) (*RelationCost, error) {
+	if _, err := t.GetRelation(objectType, relation); err != nil {
+		return nil, err
+	}
+
+	return newCostEstimator(t).cost(objectType, relation), nil
+}
+
+func newCostEstimator(t *TypeSystem) *costEstimator {
+	e := &costEstimator{
+		TypeSystem: t,
+		nodes:      make(map[string]*relationCostNode),
+		component:  make(map[string]int),
+		costs:      make(map[int]*RelationCost),
+	}
+
+	for objectType, relations := range t.GetAllRelations() {
+		for relation, rel := range relations {
+			node := &relationCostNode{reads: make(map[DatastoreReadKind]struct{})}
+			e.visitRewrite(objectType, rel, rel.GetRewrite(), node)
+			e.nodes[tuple.ToObjectRelationString(objectType, relation)] = node
+		}
+	}
+
+	e.condense()
+
+	return e
+}
+
+func (e *costEstimator) visitRewrite(objectType string, rel *openfgav1.Relation, rewrite *openfgav1.Userset, node *relationCostNode) {
+	switch rw := rewrite.GetUserset().(type) {
+	case *openfgav1.Userset_This:
+		node.reads[DatastoreReadUserTuple] = struct{}{}
+		for _, rr := range rel.GetTypeInfo().GetDirectlyRelatedUserTypes() {
+			switch {
+			case rr.GetRelation() != "":
+				node.reads[DatastoreReadUsersetTuples] = struct{}{}
+				node.dispatches = append(node.dispatches, tuple.ToObjectRelationString(rr.GetType(), rr.GetRelation()))
+			case rr.GetWildcard() != nil:
+				node.reads[DatastoreReadUsersetTuples] = struct{}{}
+			}
+		}
+	case *openfgav1.Userset_ComputedUserset:
+		node.dispatches = append(node.dispatches, tuple.ToObjectRelationString(objectType, rw.ComputedUserset.GetRelation()))
+	case *openfgav1.Userset_TupleToUserset:
+		node.reads[DatastoreRead] = struct{}{}
+
+		tuplesetRel, err := e.GetRelation(objectType, rw.TupleToUserset.GetTupleset().GetRelation())
+		if err != nil {
+			return
+		}
+
+		computedRelation := rw.TupleToUserset.GetComputedUserset().GetRelation()
+		for _, rr := range tuplesetRel.GetTypeInfo().GetDirectlyRelatedUserTypes() {
+			if _, err := e.GetRelation(rr.GetType(), computedRelation); err != nil {
+				// the related type doesn't define the computed relation, so no dispatch happens
+				continue
+			}
+			node.dispatches = append(node.dispatches, tuple.ToObjectRelationString(rr.GetType(), computedRelation))
+		}
+	case *openfgav1.Userset_Union:
+		for _, child := range rw.Union.GetChild() {
+			e.visitRewrite(objectType, rel, child, node)
+		}
+	case *openfgav1.Userset_Intersection:
+		node.hasIntersection = true
+		for _, child := range rw.Intersection.GetChild() {
+			e.visitRewrite(objectType, rel, child, node)
+		}
+	case *openfgav1.Userset_Difference:
+		node.hasExclusion = true
+		e.visitRewrite(objectType, rel, rw.Difference.GetBase(), node)
+		e.visitRewrite(objectType, rel, rw.Difference.GetSubtract(), node)
+	}
+}
+
+// condense computes the strongly connected components of the relations with Tarjan's algorithm, which finds them
+// in reverse topological order: the components a component dispatches to are found before it.
+func (e *costEstimator) condense() {
+	index := make(map[string]int)
+	lowlink := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+
+	var strongConnect func(v string)
+	strongConnect = func(v string) {
+		index[v] = len(index)
+		lowlink[v] = index[v]
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, w := range e.nodes[v].dispatches {
+			if _, ok := e.nodes[w]; !ok {
+				continue
+			}
+
+			if _, visited := index[w]; !visited {
+				strongConnect(w)
+				lowlink[v] = min(lowlink[v], lowlink[w])
+			} else if onStack[w] {
+				lowlink[v] = min(lowlink[v], index[w])
+			}
+		}
+
+		if lowlink[v] != index[v] {
+			return
+		}
+
+		var component []string
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			e.component[w] = len(e.components)
+			component = append(component, w)
+			if w == v {
+				break
+			}
+		}
+		e.components = append(e.components, component)
+	}
+
+	objectRelations := make([]string, 0, len(e.nodes))
+	for objectRelation := range e.nodes {
+		objectRelations = append(objectRelations, objectRelation)
+	}
+	sort.Strings(objectRelations)
+
+	for _, v := range objectRelations {
+		if _, visited := index[v]; !visited {
+			strongConnect(v)
+		}
+	}
+}
+
+// componentCost returns the cost of the relations of a component, computing it from the costs of the components it
+// dispatches to.
+func (e *costEstimator) componentCost(c int) *RelationCost {
+	if cost, ok := e.costs[c]; ok {
+		return cost
+	}
+
+	cost := &RelationCost{}
+	reads := make(map[DatastoreReadKind]struct{})
+
+	for _, v := range e.components[c] {
+		node := e.nodes[v]
+
+		cost.HasIntersection = cost.HasIntersection || node.hasIntersection
+		cost.HasExclusion = cost.HasExclusion || node.hasExclusion
+		for kind := range node.reads {
+			reads[kind] = struct{}{}
+		}
+
+		for _, w := range node.dispatches {
+			other, ok := e.component[w]
+			if !ok {
+				continue
+			}
+
+			if other == c {
+				// dispatches within the component go through a cycle
+				cost.HasCycle = true
+				continue
+			}
+
+			dispatched := e.componentCost(other)
+			cost.MaxDispatchDepth = max(cost.MaxDispatchDepth, dispatched.MaxDispatchDepth+1)
+			cost.HasCycle = cost.HasCycle || dispatched.HasCycle
+			cost.HasIntersection = cost.HasIntersection || dispatched.HasIntersection
+			cost.HasExclusion = cost.HasExclusion || dispatched.HasExclusion
+			for _, kind := range dispatched.DatastoreReadKinds {
+				reads[kind] = struct{}{}
+			}
+		}
+	}
+
+	cost.DatastoreReadKinds = make([]DatastoreReadKind, 0, len(reads))
+	for kind := range reads {
+		cost.DatastoreReadKinds = append(cost.DatastoreReadKinds, kind)
+	}
+	sort.Slice(cost.DatastoreReadKinds, func(i, j int) bool {
+		return cost.DatastoreReadKinds[i] < cost.DatastoreReadKinds[j]
+	})
+
+	e.costs[c] = cost
+	return cost
+}
+
+func (e *costEstimator) cost(objectType, relation string) *RelationCost {
+	componentCost := e.componentCost(e.component[tuple.ToObjectRelationString(objectType, relation)])
+
+	cost := *componentCost
+	cost.Type = objectType
+	cost.Relation = relation
+	cost.DatastoreReadKinds = append([]DatastoreReadKind(nil), componentCost.DatastoreReadKinds...)
+
+	return &cost
+}
@@ -0,0 +1,134 @@
+package typesystem
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/pkg/testutils"
+)
+
+func TestEstimateCosts(t *testing.T) {
+	typesys, err := NewAndValidate(context.Background(), testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type group
+			relations
+				define member: [user, group#member]
+		type folder
+			relations
+				define parent: [folder]
+				define viewer: [user, user:*] or viewer from parent
+		type document
+			relations
+				define parent: [folder]
+				define owner: [user]
+				define editor: [user] or owner
+				define blocked: [user]
+				define viewer: ([group#member] or editor or viewer from parent) but not blocked
+				define auditor: [user] and owner`))
+	require.NoError(t, err)
+
+	costs := EstimateCosts(typesys)
+
+	var actual []string
+	for _, cost := range costs {
+		actual = append(actual, cost.Type+"#"+cost.Relation)
+		require.Empty(t, cost.ListObjectsCheckFallbackUserTypes)
+	}
+	require.Equal(t, []string{
+		"document#auditor",
+		"document#blocked",
+		"document#editor",
+		"document#owner",
+		"document#parent",
+		"document#viewer",
+		"folder#parent",
+		"folder#viewer",
+		"group#member",
+	}, actual)
+
+	tests := map[string]RelationCost{
+		`direct`: {
+			Type:               "document",
+			Relation:           "owner",
+			DatastoreReadKinds: []DatastoreReadKind{DatastoreReadUserTuple},
+		},
+		`computed_userset`: {
+			Type:               "document",
+			Relation:           "editor",
+			MaxDispatchDepth:   1,
+			DatastoreReadKinds: []DatastoreReadKind{DatastoreReadUserTuple},
+		},
+		`intersection`: {
+			Type:               "document",
+			Relation:           "auditor",
+			MaxDispatchDepth:   1,
+			DatastoreReadKinds: []DatastoreReadKind{DatastoreReadUserTuple},
+			HasIntersection:    true,
+		},
+		`recursive_userset`: {
+			Type:               "group",
+			Relation:           "member",
+			HasCycle:           true,
+			DatastoreReadKinds: []DatastoreReadKind{DatastoreReadUserTuple, DatastoreReadUsersetTuples},
+		},
+		`recursive_tuple_to_userset`: {
+			Type:               "folder",
+			Relation:           "viewer",
+			HasCycle:           true,
+			DatastoreReadKinds: []DatastoreReadKind{DatastoreRead, DatastoreReadUserTuple, DatastoreReadUsersetTuples},
+		},
+		`exclusion_over_recursive_relations`: {
+			Type:               "document",
+			Relation:           "viewer",
+			MaxDispatchDepth:   2,
+			HasCycle:           true,
+			DatastoreReadKinds: []DatastoreReadKind{DatastoreRead, DatastoreReadUserTuple, DatastoreReadUsersetTuples},
+			HasExclusion:       true,
+		},
+	}
+
+	for name, expected := range tests {
+		t.Run(name, func(t *testing.T) {
+			cost, err := EstimateCost(typesys, expected.Type, expected.Relation)
+			require.NoError(t, err)
+			require.Equal(t, &expected, cost)
+		})
+	}
+
+	t.Run("undefined_relation", func(t *testing.T) {
+		_, err := EstimateCost(typesys, "document", "commenter")
+		require.ErrorIs(t, err, ErrRelationUndefined)
+	})
+}
+
+func TestEstimateCostsOfComputedUsersetChains(t *testing.T) {
+	typesys, err := NewAndValidate(context.Background(), testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type organization
+			relations
+				define admin: [user]
+				define member: [user] or admin
+		type team
+			relations
+				define org: [organization]
+				define member: [user] or member from org
+		type document
+			relations
+				define team: [team]
+				define viewer: member from team`))
+	require.NoError(t, err)
+
+	cost, err := EstimateCost(typesys, "document", "viewer")
+	require.NoError(t, err)
+
+	// document#viewer -> team#member -> organization#member -> organization#admin
+	require.Equal(t, 3, cost.MaxDispatchDepth)
+	require.False(t, cost.HasCycle)
+	require.Equal(t, []DatastoreReadKind{DatastoreRead, DatastoreReadUserTuple}, cost.DatastoreReadKinds)
+}
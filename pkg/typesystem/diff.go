@@ -0,0 +1,346 @@
+package typesystem
+
+import (
+	"sort"
+	"strings"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"golang.org/x/exp/maps"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// ModelChangeKind is the kind of a change between two authorization models.
+type ModelChangeKind string
+
+const (
+	ModelChangeTypeAdded                      ModelChangeKind = "type_added"
+	ModelChangeTypeRemoved                    ModelChangeKind = "type_removed"
+	ModelChangeRelationAdded                  ModelChangeKind = "relation_added"
+	ModelChangeRelationRemoved                ModelChangeKind = "relation_removed"
+	ModelChangeDirectlyRelatedUserTypeAdded   ModelChangeKind = "directly_related_user_type_added"
+	ModelChangeDirectlyRelatedUserTypeRemoved ModelChangeKind = "directly_related_user_type_removed"
+	ModelChangeRewriteChanged                 ModelChangeKind = "rewrite_changed"
+	ModelChangeConditionAdded                 ModelChangeKind = "condition_added"
+	ModelChangeConditionRemoved               ModelChangeKind = "condition_removed"
+	ModelChangeConditionChanged               ModelChangeKind = "condition_changed"
+)
+
+// ModelChangeImpact is the impact of a change between two authorization models on the permissions granted by the
+// relationship tuples of a store. A change which may both grant and revoke permissions (e.g. a rewrite replaced by
+// an unrelated one) is both permission-expanding and permission-reducing.
+type ModelChangeImpact uint8
+
+const (
+	// ModelChangeImpactSafe means that the change neither grants nor revokes any permission.
+	ModelChangeImpactSafe ModelChangeImpact = 0
+
+	// ModelChangeImpactPermissionExpanding means that the change may grant permissions which weren't granted before.
+	ModelChangeImpactPermissionExpanding ModelChangeImpact = 1
+
+	// ModelChangeImpactPermissionReducing means that the change may revoke permissions which were granted before.
+	ModelChangeImpactPermissionReducing ModelChangeImpact = 2
+)
+
+// IsPermissionExpanding returns true if the change may grant permissions which weren't granted before.
+func (i ModelChangeImpact) IsPermissionExpanding() bool {
+	return i&ModelChangeImpactPermissionExpanding != 0
+}
+
+// IsPermissionReducing returns true if the change may revoke permissions which were granted before.
+func (i ModelChangeImpact) IsPermissionReducing() bool {
+	return i&ModelChangeImpactPermissionReducing != 0
+}
+
+func (i ModelChangeImpact) String() string {
+	var impacts []string
+	if i.IsPermissionExpanding() {
+		impacts = append(impacts, "permission_expanding")
+	}
+	if i.IsPermissionReducing() {
+		impacts = append(impacts, "permission_reducing")
+	}
+	if len(impacts) == 0 {
+		return "safe"
+	}
+	return strings.Join(impacts, ",")
+}
+
+// ModelChange is a change between two authorization models.
+type ModelChange struct {
+	Kind   ModelChangeKind
+	Impact ModelChangeImpact
+
+	// Type is the type changed, or the type whose relation changed.
+	Type string
+
+	// Relation is the relation changed, if any.
+	Relation string
+
+	// UserType is the directly related user type added or removed, e.g. 'user', 'user:*', 'group#member' or
+	// 'user with condition'.
+	UserType string
+
+	// Condition is the condition changed, if any.
+	Condition string
+
+	// From and To are the rewrites of the relation before and after a ModelChangeRewriteChanged change.
+	From, To *openfgav1.Userset
+}
+
+// Diff returns the changes from one authorization model to another, sorted by type, relation and condition. The
+// impact of each change is local to the relation changed: relations rewritten from a changed relation are subject
+// to the same impact, but aren't reported as changed themselves.
+func Diff(from, to *TypeSystem) []*ModelChange {
+	var changes []*ModelChange
+
+	types := maps.Keys(from.typeDefinitions)
+	for objectType := range to.typeDefinitions {
+		if _, ok := from.typeDefinitions[objectType]; !ok {
+			types = append(types, objectType)
+		}
+	}
+	sort.Strings(types)
+
+	for _, objectType := range types {
+		_, inFrom := from.typeDefinitions[objectType]
+		_, inTo := to.typeDefinitions[objectType]
+
+		switch {
+		case !inFrom:
+			changes = append(changes, &ModelChange{
+				Kind:   ModelChangeTypeAdded,
+				Impact: ModelChangeImpactSafe,
+				Type:   objectType,
+			})
+		case !inTo:
+			changes = append(changes, &ModelChange{
+				Kind:   ModelChangeTypeRemoved,
+				Impact: ModelChangeImpactPermissionReducing,
+				Type:   objectType,
+			})
+		default:
+			changes = append(changes, diffRelations(objectType, from.relations[objectType], to.relations[objectType])...)
+		}
+	}
+
+	return append(changes, diffConditions(from, to)...)
+}
+
+func diffRelations(objectType string, from, to map[string]*openfgav1.Relation) []*ModelChange {
+	var changes []*ModelChange
+
+	relations := maps.Keys(from)
+	for relation := range to {
+		if _, ok := from[relation]; !ok {
+			relations = append(relations, relation)
+		}
+	}
+	sort.Strings(relations)
+
+	for _, relation := range relations {
+		fromRelation, inFrom := from[relation]
+		toRelation, inTo := to[relation]
+
+		switch {
+		case !inFrom:
+			changes = append(changes, &ModelChange{
+				Kind:     ModelChangeRelationAdded,
+				Impact:   ModelChangeImpactSafe,
+				Type:     objectType,
+				Relation: relation,
+			})
+			continue
+		case !inTo:
+			changes = append(changes, &ModelChange{
+				Kind:     ModelChangeRelationRemoved,
+				Impact:   ModelChangeImpactPermissionReducing,
+				Type:     objectType,
+				Relation: relation,
+			})
+			continue
+		}
+
+		fromUserTypes := userTypesOf(fromRelation)
+		toUserTypes := userTypesOf(toRelation)
+		for _, userType := range sortedKeys(toUserTypes) {
+			if !fromUserTypes[userType] {
+				changes = append(changes, &ModelChange{
+					Kind:     ModelChangeDirectlyRelatedUserTypeAdded,
+					Impact:   ModelChangeImpactPermissionExpanding,
+					Type:     objectType,
+					Relation: relation,
+					UserType: userType,
+				})
+			}
+		}
+		for _, userType := range sortedKeys(fromUserTypes) {
+			if !toUserTypes[userType] {
+				changes = append(changes, &ModelChange{
+					Kind:     ModelChangeDirectlyRelatedUserTypeRemoved,
+					Impact:   ModelChangeImpactPermissionReducing,
+					Type:     objectType,
+					Relation: relation,
+					UserType: userType,
+				})
+			}
+		}
+
+		if !rewritesEqual(fromRelation.GetRewrite(), toRelation.GetRewrite()) {
+			changes = append(changes, &ModelChange{
+				Kind:     ModelChangeRewriteChanged,
+				Impact:   rewriteChangeImpact(fromRelation.GetRewrite(), toRelation.GetRewrite()),
+				Type:     objectType,
+				Relation: relation,
+				From:     fromRelation.GetRewrite(),
+				To:       toRelation.GetRewrite(),
+			})
+		}
+	}
+
+	return changes
+}
+
+func diffConditions(from, to *TypeSystem) []*ModelChange {
+	var changes []*ModelChange
+
+	conditions := maps.Keys(from.conditions)
+	for name := range to.conditions {
+		if _, ok := from.conditions[name]; !ok {
+			conditions = append(conditions, name)
+		}
+	}
+	sort.Strings(conditions)
+
+	for _, name := range conditions {
+		fromCondition, inFrom := from.conditions[name]
+		toCondition, inTo := to.conditions[name]
+
+		switch {
+		case !inFrom:
+			changes = append(changes, &ModelChange{
+				Kind:      ModelChangeConditionAdded,
+				Impact:    ModelChangeImpactSafe,
+				Condition: name,
+			})
+		case !inTo:
+			changes = append(changes, &ModelChange{
+				Kind:      ModelChangeConditionRemoved,
+				Impact:    ModelChangeImpactPermissionReducing,
+				Condition: name,
+			})
+		case fromCondition.GetExpression() != toCondition.GetExpression() ||
+			!maps.EqualFunc(fromCondition.GetParameters(), toCondition.GetParameters(), func(a, b *openfgav1.ConditionParamTypeRef) bool {
+				return proto.Equal(a, b)
+			}):
+			// whether a changed expression grants more or less permissions can't be told in general
+			changes = append(changes, &ModelChange{
+				Kind:      ModelChangeConditionChanged,
+				Impact:    ModelChangeImpactPermissionExpanding | ModelChangeImpactPermissionReducing,
+				Condition: name,
+			})
+		}
+	}
+
+	return changes
+}
+
+// userTypesOf returns the directly related user types of a relation, e.g. 'user', 'user:*', 'group#member' or
+// 'user with condition'.
+func userTypesOf(relation *openfgav1.Relation) map[string]bool {
+	userTypes := make(map[string]bool)
+	for _, rr := range relation.GetTypeInfo().GetDirectlyRelatedUserTypes() {
+		userType := rr.GetType()
+		switch rr.GetRelationOrWildcard().(type) {
+		case *openfgav1.RelationReference_Relation:
+			userType = tuple.ToObjectRelationString(rr.GetType(), rr.GetRelation())
+		case *openfgav1.RelationReference_Wildcard:
+			userType = tuple.TypedPublicWildcard(rr.GetType())
+		}
+
+		if rr.GetCondition() != "" {
+			userType += " with " + rr.GetCondition()
+		}
+
+		userTypes[userType] = true
+	}
+	return userTypes
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := maps.Keys(m)
+	sort.Strings(keys)
+	return keys
+}
+
+// rewriteChangeImpact returns the impact of changing the rewrite of a relation from one rewrite to another.
+// Adding operands to a union, or removing them from an intersection, only grants permissions, and vice versa.
+// Subtracting from the rewrite only revokes permissions, and no longer subtracting from it only grants them.
+func rewriteChangeImpact(from, to *openfgav1.Userset) ModelChangeImpact {
+	switch {
+	case containsOperands(unionOperands(to), unionOperands(from)):
+		return ModelChangeImpactPermissionExpanding
+	case containsOperands(unionOperands(from), unionOperands(to)):
+		return ModelChangeImpactPermissionReducing
+	case containsOperands(intersectionOperands(to), intersectionOperands(from)):
+		return ModelChangeImpactPermissionReducing
+	case containsOperands(intersectionOperands(from), intersectionOperands(to)):
+		return ModelChangeImpactPermissionExpanding
+	case to.GetDifference() != nil && rewritesEqual(to.GetDifference().GetBase(), from):
+		return ModelChangeImpactPermissionReducing
+	case from.GetDifference() != nil && rewritesEqual(from.GetDifference().GetBase(), to):
+		return ModelChangeImpactPermissionExpanding
+	default:
+		return ModelChangeImpactPermissionExpanding | ModelChangeImpactPermissionReducing
+	}
+}
+
+func unionOperands(rewrite *openfgav1.Userset) []*openfgav1.Userset {
+	if union := rewrite.GetUnion(); union != nil {
+		return union.GetChild()
+	}
+	return []*openfgav1.Userset{rewrite}
+}
+
+func intersectionOperands(rewrite *openfgav1.Userset) []*openfgav1.Userset {
+	if intersection := rewrite.GetIntersection(); intersection != nil {
+		return intersection.GetChild()
+	}
+	return []*openfgav1.Userset{rewrite}
+}
+
+// rewritesEqual returns true if two rewrites are equivalent, comparing the operands of unions and intersections as
+// sets, so that reordering them is not a change.
+func rewritesEqual(a, b *openfgav1.Userset) bool {
+	switch {
+	case a.GetUnion() != nil && b.GetUnion() != nil:
+		return containsOperands(a.GetUnion().GetChild(), b.GetUnion().GetChild()) &&
+			containsOperands(b.GetUnion().GetChild(), a.GetUnion().GetChild())
+	case a.GetIntersection() != nil && b.GetIntersection() != nil:
+		return containsOperands(a.GetIntersection().GetChild(), b.GetIntersection().GetChild()) &&
+			containsOperands(b.GetIntersection().GetChild(), a.GetIntersection().GetChild())
+	case a.GetDifference() != nil && b.GetDifference() != nil:
+		return rewritesEqual(a.GetDifference().GetBase(), b.GetDifference().GetBase()) &&
+			rewritesEqual(a.GetDifference().GetSubtract(), b.GetDifference().GetSubtract())
+	default:
+		return proto.Equal(a, b)
+	}
+}
+
+// containsOperands returns true if each of the operands is one of the operands of the superset.
+func containsOperands(superset, operands []*openfgav1.Userset) bool {
+	for _, operand := range operands {
+		found := false
+		for _, s := range superset {
+			if rewritesEqual(s, operand) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
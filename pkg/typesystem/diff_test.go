@@ -0,0 +1,284 @@
+package typesystem
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/pkg/testutils"
+)
+
+func TestDiff(t *testing.T) {
+	type change struct {
+		kind      ModelChangeKind
+		impact    ModelChangeImpact
+		objType   string
+		relation  string
+		userType  string
+		condition string
+	}
+
+	tests := map[string]struct {
+		from     string
+		to       string
+		expected []change
+	}{
+		`no_changes`: {
+			from: `
+				model
+					schema 1.1
+				type user
+				type document
+					relations
+						define viewer: [user]`,
+			to: `
+				model
+					schema 1.1
+				type user
+				type document
+					relations
+						define viewer: [user]`,
+		},
+		`types_added_and_removed`: {
+			from: `
+				model
+					schema 1.1
+				type user
+				type folder`,
+			to: `
+				model
+					schema 1.1
+				type user
+				type document`,
+			expected: []change{
+				{kind: ModelChangeTypeAdded, impact: ModelChangeImpactSafe, objType: "document"},
+				{kind: ModelChangeTypeRemoved, impact: ModelChangeImpactPermissionReducing, objType: "folder"},
+			},
+		},
+		`relations_added_and_removed`: {
+			from: `
+				model
+					schema 1.1
+				type user
+				type document
+					relations
+						define editor: [user]`,
+			to: `
+				model
+					schema 1.1
+				type user
+				type document
+					relations
+						define viewer: [user]`,
+			expected: []change{
+				{kind: ModelChangeRelationRemoved, impact: ModelChangeImpactPermissionReducing, objType: "document", relation: "editor"},
+				{kind: ModelChangeRelationAdded, impact: ModelChangeImpactSafe, objType: "document", relation: "viewer"},
+			},
+		},
+		`directly_related_user_types_added_and_removed`: {
+			from: `
+				model
+					schema 1.1
+				type user
+				type group
+					relations
+						define member: [user]
+				type document
+					relations
+						define viewer: [user, group#member]`,
+			to: `
+				model
+					schema 1.1
+				type user
+				type group
+					relations
+						define member: [user]
+				type document
+					relations
+						define viewer: [user, user:*, user with cond]
+				condition cond(x: int) {
+					x < 100
+				}`,
+			expected: []change{
+				{kind: ModelChangeDirectlyRelatedUserTypeAdded, impact: ModelChangeImpactPermissionExpanding, objType: "document", relation: "viewer", userType: "user with cond"},
+				{kind: ModelChangeDirectlyRelatedUserTypeAdded, impact: ModelChangeImpactPermissionExpanding, objType: "document", relation: "viewer", userType: "user:*"},
+				{kind: ModelChangeDirectlyRelatedUserTypeRemoved, impact: ModelChangeImpactPermissionReducing, objType: "document", relation: "viewer", userType: "group#member"},
+				{kind: ModelChangeConditionAdded, impact: ModelChangeImpactSafe, condition: "cond"},
+			},
+		},
+		`rewrites_changed`: {
+			from: `
+				model
+					schema 1.1
+				type user
+				type document
+					relations
+						define blocked: [user]
+						define owner: [user]
+						define editor: [user] or owner
+						define viewer: [user]
+						define commenter: [user] and editor
+						define reader: [user] but not blocked
+						define other: owner`,
+			to: `
+				model
+					schema 1.1
+				type user
+				type document
+					relations
+						define blocked: [user]
+						define owner: [user]
+						define editor: [user]
+						define viewer: [user] or editor
+						define commenter: [user]
+						define reader: [user]
+						define other: editor`,
+			expected: []change{
+				{kind: ModelChangeRewriteChanged, impact: ModelChangeImpactPermissionExpanding, objType: "document", relation: "commenter"},
+				{kind: ModelChangeRewriteChanged, impact: ModelChangeImpactPermissionReducing, objType: "document", relation: "editor"},
+				{kind: ModelChangeRewriteChanged, impact: ModelChangeImpactPermissionExpanding | ModelChangeImpactPermissionReducing, objType: "document", relation: "other"},
+				{kind: ModelChangeRewriteChanged, impact: ModelChangeImpactPermissionExpanding, objType: "document", relation: "reader"},
+				{kind: ModelChangeRewriteChanged, impact: ModelChangeImpactPermissionExpanding, objType: "document", relation: "viewer"},
+			},
+		},
+		`rewrites_restricted`: {
+			from: `
+				model
+					schema 1.1
+				type user
+				type document
+					relations
+						define blocked: [user]
+						define editor: [user]
+						define viewer: [user]`,
+			to: `
+				model
+					schema 1.1
+				type user
+				type document
+					relations
+						define blocked: [user]
+						define editor: [user] and blocked
+						define viewer: [user] but not blocked`,
+			expected: []change{
+				{kind: ModelChangeRewriteChanged, impact: ModelChangeImpactPermissionReducing, objType: "document", relation: "editor"},
+				{kind: ModelChangeRewriteChanged, impact: ModelChangeImpactPermissionReducing, objType: "document", relation: "viewer"},
+			},
+		},
+		`operands_reordered`: {
+			from: `
+				model
+					schema 1.1
+				type user
+				type document
+					relations
+						define blocked: [user]
+						define editor: [user]
+						define owner: [user]
+						define viewer: ([user] or editor or owner) but not blocked
+						define commenter: [user] and editor and owner`,
+			to: `
+				model
+					schema 1.1
+				type user
+				type document
+					relations
+						define blocked: [user]
+						define editor: [user]
+						define owner: [user]
+						define viewer: ([user] or owner or editor) but not blocked
+						define commenter: [user] and owner and editor`,
+		},
+		`operands_reordered_and_added`: {
+			from: `
+				model
+					schema 1.1
+				type user
+				type document
+					relations
+						define editor: [user]
+						define owner: [user]
+						define viewer: [user] or editor`,
+			to: `
+				model
+					schema 1.1
+				type user
+				type document
+					relations
+						define editor: [user]
+						define owner: [user]
+						define viewer: [user] or owner or editor`,
+			expected: []change{
+				{kind: ModelChangeRewriteChanged, impact: ModelChangeImpactPermissionExpanding, objType: "document", relation: "viewer"},
+			},
+		},
+		`conditions_changed_and_removed`: {
+			from: `
+				model
+					schema 1.1
+				type user
+				type document
+					relations
+						define viewer: [user]
+				condition changed(x: int) {
+					x < 100
+				}
+				condition removed(x: int) {
+					x < 100
+				}
+				condition same(x: int) {
+					x < 100
+				}`,
+			to: `
+				model
+					schema 1.1
+				type user
+				type document
+					relations
+						define viewer: [user]
+				condition changed(x: int) {
+					x < 200
+				}
+				condition same(x: int) {
+					x < 100
+				}`,
+			expected: []change{
+				{kind: ModelChangeConditionChanged, impact: ModelChangeImpactPermissionExpanding | ModelChangeImpactPermissionReducing, condition: "changed"},
+				{kind: ModelChangeConditionRemoved, impact: ModelChangeImpactPermissionReducing, condition: "removed"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			from := New(testutils.MustTransformDSLToProtoWithID(test.from))
+			to := New(testutils.MustTransformDSLToProtoWithID(test.to))
+
+			var actual []change
+			for _, c := range Diff(from, to) {
+				actual = append(actual, change{
+					kind:      c.Kind,
+					impact:    c.Impact,
+					objType:   c.Type,
+					relation:  c.Relation,
+					userType:  c.UserType,
+					condition: c.Condition,
+				})
+
+				if c.Kind == ModelChangeRewriteChanged {
+					require.NotNil(t, c.From)
+					require.NotNil(t, c.To)
+				}
+			}
+
+			require.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestModelChangeImpactString(t *testing.T) {
+	require.Equal(t, "safe", ModelChangeImpactSafe.String())
+	require.Equal(t, "permission_expanding", ModelChangeImpactPermissionExpanding.String())
+	require.Equal(t, "permission_reducing", ModelChangeImpactPermissionReducing.String())
+	require.Equal(t, "permission_expanding,permission_reducing", (ModelChangeImpactPermissionExpanding | ModelChangeImpactPermissionReducing).String())
+}
@@ -0,0 +1,63 @@
+package typesystem
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	parser "github.com/openfga/language/pkg/go/transformer"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrInvalidDSL is returned when an authorization model written in the DSL can't be parsed.
+var ErrInvalidDSL = errors.New("invalid authorization model DSL")
+
+// ParseDSL transforms an authorization model written in the DSL into its protobuf representation, without
+// validating it. The error lists every syntax error with its line and column (both zero based).
+func ParseDSL(dsl string) (*openfgav1.AuthorizationModel, error) {
+	model, err := parser.TransformDSLToProto(dsl)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidDSL, syntaxErrors(err))
+	}
+
+	return model, nil
+}
+
+// syntaxErrors returns the messages of the errors wrapped by a multierror of the parser, e.g.
+// 'syntax error at line=1, column=2: ...', on a single line.
+func syntaxErrors(err error) string {
+	multiErr, ok := err.(interface{ WrappedErrors() []error })
+	if !ok {
+		return err.Error()
+	}
+
+	messages := make([]string, 0, len(multiErr.WrappedErrors()))
+	for _, err := range multiErr.WrappedErrors() {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// FormatDSL renders an authorization model in the DSL.
+func FormatDSL(model *openfgav1.AuthorizationModel) (string, error) {
+	// the models returned by the parser have nil direct usersets, which can't be rendered, so normalize the model
+	// through its wire encoding, as it would be once stored
+	encoded, err := proto.Marshal(model)
+	if err != nil {
+		return "", fmt.Errorf("failed to render the authorization model in the DSL: %w", err)
+	}
+
+	var normalized openfgav1.AuthorizationModel
+	if err := proto.Unmarshal(encoded, &normalized); err != nil {
+		return "", fmt.Errorf("failed to render the authorization model in the DSL: %w", err)
+	}
+
+	dsl, err := parser.TransformJSONProtoToDSL(&normalized)
+	if err != nil {
+		return "", fmt.Errorf("failed to render the authorization model in the DSL: %w", err)
+	}
+
+	return dsl, nil
+}
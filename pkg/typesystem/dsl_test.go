@@ -0,0 +1,41 @@
+package typesystem
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAndFormatDSL(t *testing.T) {
+	dsl := `model
+  schema 1.1
+
+type user
+
+type document
+  relations
+    define viewer: [user with non_expired]
+
+condition non_expired(current_time: timestamp, expiration: timestamp) {
+  current_time < expiration
+}
+`
+
+	model, err := ParseDSL(dsl)
+	require.NoError(t, err)
+	require.Equal(t, SchemaVersion1_1, model.GetSchemaVersion())
+	require.Len(t, model.GetTypeDefinitions(), 2)
+	require.Contains(t, model.GetConditions(), "non_expired")
+
+	formatted, err := FormatDSL(model)
+	require.NoError(t, err)
+	require.Equal(t, dsl, formatted)
+}
+
+func TestParseDSLSyntaxErrors(t *testing.T) {
+	_, err := ParseDSL("model\n  schema 1.1\ntype user\ntype document\n  relations\n    define viewer: [user\n    define editor: [user]")
+	require.ErrorIs(t, err, ErrInvalidDSL)
+	require.EqualError(t, err, "invalid authorization model DSL: "+
+		"syntax error at line=6, column=4: extraneous input 'define' expecting {',', WHITESPACE, ']'}; "+
+		"syntax error at line=6, column=11: mismatched input 'editor' expecting {',', ']'}")
+}
@@ -82,6 +82,31 @@ func (e *InvalidRelationError) Unwrap() error {
 	return e.Cause
 }
 
+// ModuleError represents an error about the definition of a type, relation or condition of a modular authorization
+// model, recording the module (and the file) which declares it.
+type ModuleError struct {
+	Module string
+	File   string
+	Err    error
+}
+
+// Error implements the error interface for ModuleError.
+func (e *ModuleError) Error() string {
+	switch {
+	case e.File == "":
+		return fmt.Sprintf("in module '%s': %s", e.Module, e.Err)
+	case e.Module == "":
+		return fmt.Sprintf("in module file '%s': %s", e.File, e.Err)
+	default:
+		return fmt.Sprintf("in module '%s' (file '%s'): %s", e.Module, e.File, e.Err)
+	}
+}
+
+// Unwrap returns the underlying cause of the error.
+func (e *ModuleError) Unwrap() error {
+	return e.Err
+}
+
 // ObjectTypeUndefinedError represents an error indicating an undefined object type.
 type ObjectTypeUndefinedError struct {
 	ObjectType string
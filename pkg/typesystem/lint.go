@@ -0,0 +1,503 @@
+package typesystem
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"golang.org/x/exp/maps"
+
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+const (
+	// DefaultLintMaxTupleToUsersetDepth is the default length of the chains of tuple to userset rewrites above which
+	// a relation is reported by Lint.
+	DefaultLintMaxTupleToUsersetDepth = 3
+)
+
+// DefaultLintSensitiveRelations are the default words of the names of the relations on which Lint reports
+// typed wildcards.
+var DefaultLintSensitiveRelations = []string{"admin", "owner", "edit", "write", "delete", "manage"}
+
+// LintCode is the kind of a warning reported by Lint.
+type LintCode string
+
+const (
+	// LintDeepTupleToUsersetChain is reported for the relations which follow a long chain of tuple to userset
+	// rewrites (e.g. 'viewer from parent' where the parent's viewer relation is 'viewer from parent' too), each
+	// requiring reads to resolve.
+	LintDeepTupleToUsersetChain LintCode = "deep_tuple_to_userset_chain"
+
+	// LintIntersectionOverRecursiveRelation is reported for the relations which intersect with, or subtract, a
+	// relation which is or refers to a recursive relation (e.g. 'define viewer: [user] and member from parent' where
+	// member is recursive), which ListObjects and ListUsers resolve slowly.
+	LintIntersectionOverRecursiveRelation LintCode = "intersection_over_recursive_relation"
+
+	// LintUnusedType is reported for the types which have no relations and aren't related to any relation.
+	LintUnusedType LintCode = "unused_type"
+
+	// LintUnreachableRelation is reported for the relations which no user can ever be related to, e.g. because
+	// they intersect relations which users of different types are related to.
+	LintUnreachableRelation LintCode = "unreachable_relation"
+
+	// LintWildcardOnSensitiveRelation is reported for the relations whose name suggests that they grant sensitive
+	// permissions (e.g. 'owner' or 'can_delete'), and which can be granted to every user of a type with a typed
+	// wildcard.
+	LintWildcardOnSensitiveRelation LintCode = "wildcard_on_sensitive_relation"
+
+	// LintUnusedCondition is reported for the conditions which no relation refers to.
+	LintUnusedCondition LintCode = "unused_condition"
+)
+
+// LintWarning is a warning reported by Lint about a valid authorization model which may be slow to resolve or may
+// not model what was intended.
+type LintWarning struct {
+	Code      LintCode `json:"code"`
+	Type      string   `json:"type,omitempty"`
+	Relation  string   `json:"relation,omitempty"`
+	Condition string   `json:"condition,omitempty"`
+	Message   string   `json:"message"`
+}
+
+func (w *LintWarning) String() string {
+	return fmt.Sprintf("%s: %s", w.Code, w.Message)
+}
+
+type linter struct {
+	*TypeSystem
+
+	maxTupleToUsersetDepth int
+	sensitiveRelations     []string
+
+	// recursive memoizes whether relations (of the form 'type#relation') are recursive.
+	recursive map[string]bool
+
+	// depths memoizes the lengths of the chains of tuple to userset rewrites of relations.
+	depths map[string]int
+
+	// types memoizes the types of the users which may be related to relations.
+	types map[string]userTypes
+}
+
+type LintOption func(*linter)
+
+// WithLintMaxTupleToUsersetDepth sets the length of the chains of tuple to userset rewrites above which a relation
+// is reported.
+func WithLintMaxTupleToUsersetDepth(depth int) LintOption {
+	return func(l *linter) {
+		l.maxTupleToUsersetDepth = depth
+	}
+}
+
+// WithLintSensitiveRelations sets the words of the names of the relations on which typed wildcards are reported.
+func WithLintSensitiveRelations(words ...string) LintOption {
+	return func(l *linter) {
+		l.sensitiveRelations = words
+	}
+}
+
+// Lint returns the warnings about a valid authorization model, sorted by type and relation, and then by condition. Unlike the
+// errors of NewAndValidate, the warnings don't prevent the model from being written.
+func Lint(t *TypeSystem, opts ...LintOption) []*LintWarning {
+	l := &linter{
+		TypeSystem:             t,
+		maxTupleToUsersetDepth: DefaultLintMaxTupleToUsersetDepth,
+		sensitiveRelations:     DefaultLintSensitiveRelations,
+		recursive:              make(map[string]bool),
+		depths:                 make(map[string]int),
+		types:                  make(map[string]userTypes),
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	var warnings []*LintWarning
+
+	referencedTypes := make(map[string]bool)
+	referencedConditions := make(map[string]bool)
+
+	types := maps.Keys(t.typeDefinitions)
+	sort.Strings(types)
+
+	for _, objectType := range types {
+		relations := maps.Keys(t.relations[objectType])
+		sort.Strings(relations)
+
+		for _, relation := range relations {
+			rel := t.relations[objectType][relation]
+			for _, rr := range rel.GetTypeInfo().GetDirectlyRelatedUserTypes() {
+				referencedTypes[rr.GetType()] = true
+				if rr.GetCondition() != "" {
+					referencedConditions[rr.GetCondition()] = true
+				}
+			}
+
+			warnings = append(warnings, l.lintRelation(objectType, relation, rel)...)
+		}
+	}
+
+	for _, objectType := range types {
+		if len(t.relations[objectType]) == 0 && !referencedTypes[objectType] {
+			warnings = append(warnings, &LintWarning{
+				Code:    LintUnusedType,
+				Type:    objectType,
+				Message: fmt.Sprintf("type '%s' has no relations and isn't related to any relation", objectType),
+			})
+		}
+	}
+
+	conditions := maps.Keys(t.conditions)
+	sort.Strings(conditions)
+
+	for _, condition := range conditions {
+		if !referencedConditions[condition] {
+			warnings = append(warnings, &LintWarning{
+				Code:      LintUnusedCondition,
+				Condition: condition,
+				Message:   fmt.Sprintf("condition '%s' isn't referred to by any relation", condition),
+			})
+		}
+	}
+
+	sort.SliceStable(warnings, func(i, j int) bool {
+		a, b := warnings[i], warnings[j]
+		if (a.Condition == "") != (b.Condition == "") {
+			return a.Condition == ""
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		if a.Relation != b.Relation {
+			return a.Relation < b.Relation
+		}
+		return a.Condition < b.Condition
+	})
+
+	return warnings
+}
+
+func (l *linter) lintRelation(objectType, relation string, rel *openfgav1.Relation) []*LintWarning {
+	var warnings []*LintWarning
+
+	objectRelation := tuple.ToObjectRelationString(objectType, relation)
+	warning := func(code LintCode, format string, args ...interface{}) {
+		warnings = append(warnings, &LintWarning{
+			Code:     code,
+			Type:     objectType,
+			Relation: relation,
+			Message:  fmt.Sprintf("relation '%s' %s", objectRelation, fmt.Sprintf(format, args...)),
+		})
+	}
+
+	if depth := l.tupleToUsersetDepth(objectType, relation, map[string]bool{}); depth > l.maxTupleToUsersetDepth {
+		warning(LintDeepTupleToUsersetChain, "follows a chain of %d tuple to userset rewrites (more than %d)", depth, l.maxTupleToUsersetDepth)
+	}
+
+	if recursive := l.recursiveOperand(objectType, rel, rel.GetRewrite(), false); recursive != "" {
+		warning(LintIntersectionOverRecursiveRelation, "intersects with or subtracts recursive relation '%s'", recursive)
+	}
+
+	if userTypes := l.userTypes(objectType, relation, map[string]bool{}); !userTypes.all && len(userTypes.types) == 0 {
+		warning(LintUnreachableRelation, "can't be related to any user")
+	}
+
+	if l.isSensitive(relation) {
+		for _, rr := range rel.GetTypeInfo().GetDirectlyRelatedUserTypes() {
+			if rr.GetWildcard() != nil {
+				warning(LintWildcardOnSensitiveRelation, "can be granted to every user of type '%s' with '%s'", rr.GetType(), tuple.TypedPublicWildcard(rr.GetType()))
+			}
+		}
+	}
+
+	return warnings
+}
+
+func (l *linter) isSensitive(relation string) bool {
+	for _, word := range l.sensitiveRelations {
+		if strings.Contains(relation, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// edge is a relation (of the form 'type#relation') which a relation's rewrite refers to, and whether it's referred
+// to through a tuple to userset rewrite.
+type edge struct {
+	objectRelation string
+	tupleToUserset bool
+}
+
+// edges returns the relations which a rewrite of a relation refers to.
+func (l *linter) edges(objectType string, rel *openfgav1.Relation, rewrite *openfgav1.Userset) []edge {
+	var edges []edge
+
+	switch rw := rewrite.GetUserset().(type) {
+	case *openfgav1.Userset_This:
+		for _, rr := range rel.GetTypeInfo().GetDirectlyRelatedUserTypes() {
+			if rr.GetRelation() != "" {
+				edges = append(edges, edge{objectRelation: tuple.ToObjectRelationString(rr.GetType(), rr.GetRelation())})
+			}
+		}
+	case *openfgav1.Userset_ComputedUserset:
+		edges = append(edges, edge{objectRelation: tuple.ToObjectRelationString(objectType, rw.ComputedUserset.GetRelation())})
+	case *openfgav1.Userset_TupleToUserset:
+		tuplesetRel, err := l.GetRelation(objectType, rw.TupleToUserset.GetTupleset().GetRelation())
+		if err != nil {
+			return nil
+		}
+
+		computedRelation := rw.TupleToUserset.GetComputedUserset().GetRelation()
+		for _, rr := range tuplesetRel.GetTypeInfo().GetDirectlyRelatedUserTypes() {
+			if _, err := l.GetRelation(rr.GetType(), computedRelation); err == nil {
+				edges = append(edges, edge{
+					objectRelation: tuple.ToObjectRelationString(rr.GetType(), computedRelation),
+					tupleToUserset: true,
+				})
+			}
+		}
+	case *openfgav1.Userset_Union:
+		for _, child := range rw.Union.GetChild() {
+			edges = append(edges, l.edges(objectType, rel, child)...)
+		}
+	case *openfgav1.Userset_Intersection:
+		for _, child := range rw.Intersection.GetChild() {
+			edges = append(edges, l.edges(objectType, rel, child)...)
+		}
+	case *openfgav1.Userset_Difference:
+		edges = append(edges, l.edges(objectType, rel, rw.Difference.GetBase())...)
+		edges = append(edges, l.edges(objectType, rel, rw.Difference.GetSubtract())...)
+	}
+
+	return edges
+}
+
+func (l *linter) relationEdges(objectRelation string) []edge {
+	objectType, relation := tuple.SplitObjectRelation(objectRelation)
+	rel, err := l.GetRelation(objectType, relation)
+	if err != nil {
+		return nil
+	}
+	return l.edges(objectType, rel, rel.GetRewrite())
+}
+
+// tupleToUsersetDepth returns the length of the longest chain of tuple to userset rewrites which a relation
+// follows, not counting the chains looping back to a relation of the chain (e.g. 'viewer from parent' on a type
+// whose parent is of the same type), whose length depends on the tuples.
+func (l *linter) tupleToUsersetDepth(objectType, relation string, visiting map[string]bool) int {
+	objectRelation := tuple.ToObjectRelationString(objectType, relation)
+	if visiting[objectRelation] {
+		return 0
+	}
+	if depth, ok := l.depths[objectRelation]; ok {
+		return depth
+	}
+
+	visiting[objectRelation] = true
+	defer delete(visiting, objectRelation)
+
+	depth := 0
+	for _, e := range l.relationEdges(objectRelation) {
+		edgeType, edgeRelation := tuple.SplitObjectRelation(e.objectRelation)
+		d := l.tupleToUsersetDepth(edgeType, edgeRelation, visiting)
+		if e.tupleToUserset {
+			d++
+		}
+		if d > depth {
+			depth = d
+		}
+	}
+
+	l.depths[objectRelation] = depth
+	return depth
+}
+
+// isRecursive returns true if a relation (of the form 'type#relation') refers to itself, directly or not.
+func (l *linter) isRecursive(objectRelation string) bool {
+	if recursive, ok := l.recursive[objectRelation]; ok {
+		return recursive
+	}
+
+	visited := map[string]bool{}
+	stack := []string{objectRelation}
+	recursive := false
+	for len(stack) > 0 && !recursive {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		for _, e := range l.relationEdges(current) {
+			if e.objectRelation == objectRelation {
+				recursive = true
+				break
+			}
+			if !visited[e.objectRelation] {
+				visited[e.objectRelation] = true
+				stack = append(stack, e.objectRelation)
+			}
+		}
+	}
+
+	l.recursive[objectRelation] = recursive
+	return recursive
+}
+
+// reachesRecursive returns the first recursive relation which a relation (of the form 'type#relation') refers to,
+// directly or not, including the relation itself, or an empty string if there is none.
+func (l *linter) reachesRecursive(objectRelation string) string {
+	visited := map[string]bool{objectRelation: true}
+	queue := []string{objectRelation}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		if l.isRecursive(current) {
+			return current
+		}
+
+		for _, e := range l.relationEdges(current) {
+			if !visited[e.objectRelation] {
+				visited[e.objectRelation] = true
+				queue = append(queue, e.objectRelation)
+			}
+		}
+	}
+
+	return ""
+}
+
+// recursiveOperand returns the first recursive relation which an operand of an intersection or of an exclusion
+// of a rewrite refers to, directly or not, or an empty string if there is none.
+func (l *linter) recursiveOperand(objectType string, rel *openfgav1.Relation, rewrite *openfgav1.Userset, inOperand bool) string {
+	var children []*openfgav1.Userset
+
+	switch rw := rewrite.GetUserset().(type) {
+	case *openfgav1.Userset_Union:
+		children = rw.Union.GetChild()
+	case *openfgav1.Userset_Intersection:
+		children = rw.Intersection.GetChild()
+		inOperand = true
+	case *openfgav1.Userset_Difference:
+		children = []*openfgav1.Userset{rw.Difference.GetBase(), rw.Difference.GetSubtract()}
+		inOperand = true
+	default:
+		if !inOperand {
+			return ""
+		}
+		for _, e := range l.edges(objectType, rel, rewrite) {
+			if recursive := l.reachesRecursive(e.objectRelation); recursive != "" {
+				return recursive
+			}
+		}
+		return ""
+	}
+
+	for _, child := range children {
+		if recursive := l.recursiveOperand(objectType, rel, child, inOperand); recursive != "" {
+			return recursive
+		}
+	}
+
+	return ""
+}
+
+// userTypes are the types of the users (or usersets) which may be related to a relation, or all types if they can't
+// be told because the relation is recursive.
+type userTypes struct {
+	all   bool
+	types map[string]bool
+}
+
+func (u userTypes) union(other userTypes) userTypes {
+	if u.all || other.all {
+		return userTypes{all: true}
+	}
+
+	types := make(map[string]bool, len(u.types)+len(other.types))
+	for t := range u.types {
+		types[t] = true
+	}
+	for t := range other.types {
+		types[t] = true
+	}
+	return userTypes{types: types}
+}
+
+func (u userTypes) intersection(other userTypes) userTypes {
+	switch {
+	case u.all:
+		return other
+	case other.all:
+		return u
+	}
+
+	types := make(map[string]bool)
+	for t := range u.types {
+		if other.types[t] {
+			types[t] = true
+		}
+	}
+	return userTypes{types: types}
+}
+
+// userTypes returns the types of the users which may be related to a relation. Unlike TerminalUserTypes, the types of
+// an intersection are the types common to all of its operands.
+func (l *linter) userTypes(objectType, relation string, visiting map[string]bool) userTypes {
+	objectRelation := tuple.ToObjectRelationString(objectType, relation)
+	if visiting[objectRelation] {
+		return userTypes{all: true}
+	}
+	if types, ok := l.types[objectRelation]; ok {
+		return types
+	}
+
+	rel, err := l.GetRelation(objectType, relation)
+	if err != nil {
+		return userTypes{}
+	}
+
+	visiting[objectRelation] = true
+	defer delete(visiting, objectRelation)
+
+	types := l.userTypesOfRewrite(objectType, rel, rel.GetRewrite(), visiting)
+	l.types[objectRelation] = types
+	return types
+}
+
+func (l *linter) userTypesOfRewrite(objectType string, rel *openfgav1.Relation, rewrite *openfgav1.Userset, visiting map[string]bool) userTypes {
+	types := userTypes{types: map[string]bool{}}
+
+	switch rw := rewrite.GetUserset().(type) {
+	case *openfgav1.Userset_This:
+		for _, rr := range rel.GetTypeInfo().GetDirectlyRelatedUserTypes() {
+			if rr.GetRelation() != "" {
+				types = types.union(l.userTypes(rr.GetType(), rr.GetRelation(), visiting))
+				continue
+			}
+			types.types[rr.GetType()] = true
+		}
+	case *openfgav1.Userset_ComputedUserset:
+		types = l.userTypes(objectType, rw.ComputedUserset.GetRelation(), visiting)
+	case *openfgav1.Userset_TupleToUserset:
+		for _, e := range l.edges(objectType, rel, rewrite) {
+			edgeType, edgeRelation := tuple.SplitObjectRelation(e.objectRelation)
+			types = types.union(l.userTypes(edgeType, edgeRelation, visiting))
+		}
+	case *openfgav1.Userset_Union:
+		for _, child := range rw.Union.GetChild() {
+			types = types.union(l.userTypesOfRewrite(objectType, rel, child, visiting))
+		}
+	case *openfgav1.Userset_Intersection:
+		types = userTypes{all: true}
+		for _, child := range rw.Intersection.GetChild() {
+			types = types.intersection(l.userTypesOfRewrite(objectType, rel, child, visiting))
+		}
+	case *openfgav1.Userset_Difference:
+		// members of the subtracted branch are never added
+		types = l.userTypesOfRewrite(objectType, rel, rw.Difference.GetBase(), visiting)
+	}
+
+	return types
+}
@@ -0,0 +1,203 @@
+package typesystem
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/pkg/testutils"
+)
+
+func TestLint(t *testing.T) {
+	type warning struct {
+		code      LintCode
+		objType   string
+		relation  string
+		condition string
+	}
+
+	tests := map[string]struct {
+		model    string
+		opts     []LintOption
+		expected []warning
+	}{
+		`no_warnings`: {
+			model: `
+				model
+					schema 1.1
+				type user
+				type folder
+					relations
+						define viewer: [user]
+				type document
+					relations
+						define parent: [folder]
+						define viewer: [user with cond] or viewer from parent
+				condition cond(x: int) {
+					x < 100
+				}`,
+		},
+		`deep_tuple_to_userset_chain`: {
+			model: `
+				model
+					schema 1.1
+				type user
+				type org
+					relations
+						define member: [user]
+				type team
+					relations
+						define org: [org]
+						define member: member from org
+				type folder
+					relations
+						define team: [team]
+						define viewer: member from team
+				type document
+					relations
+						define parent: [folder]
+						define viewer: viewer from parent`,
+			opts: []LintOption{WithLintMaxTupleToUsersetDepth(2)},
+			expected: []warning{
+				{code: LintDeepTupleToUsersetChain, objType: "document", relation: "viewer"},
+			},
+		},
+		`recursive_tuple_to_userset_chains_are_not_deep`: {
+			model: `
+				model
+					schema 1.1
+				type user
+				type folder
+					relations
+						define parent: [folder]
+						define viewer: [user] or viewer from parent`,
+			opts: []LintOption{WithLintMaxTupleToUsersetDepth(0)},
+			expected: []warning{
+				{code: LintDeepTupleToUsersetChain, objType: "folder", relation: "viewer"},
+			},
+		},
+		`intersection_over_recursive_relation`: {
+			model: `
+				model
+					schema 1.1
+				type user
+				type group
+					relations
+						define member: [user, group#member]
+				type document
+					relations
+						define allowed: [user]
+						define blocked: [group#member]
+						define owner: [group]
+						define editor: allowed and member from owner
+						define viewer: allowed but not blocked
+						define commenter: allowed or member from owner`,
+			expected: []warning{
+				{code: LintIntersectionOverRecursiveRelation, objType: "document", relation: "editor"},
+				{code: LintIntersectionOverRecursiveRelation, objType: "document", relation: "viewer"},
+			},
+		},
+		`unused_types_and_conditions`: {
+			model: `
+				model
+					schema 1.1
+				type user
+				type employee
+				type document
+					relations
+						define viewer: [user]
+				condition unused(x: int) {
+					x < 100
+				}`,
+			expected: []warning{
+				{code: LintUnusedType, objType: "employee"},
+				{code: LintUnusedCondition, condition: "unused"},
+			},
+		},
+		`unreachable_relation`: {
+			model: `
+				model
+					schema 1.1
+				type user
+				type employee
+				type group
+					relations
+						define member: [user, employee]
+				type document
+					relations
+						define owner: [group]
+						define editor: [employee]
+						define viewer: [user] and editor
+						define commenter: [user] and member from owner
+						define blocked: viewer and commenter`,
+			expected: []warning{
+				{code: LintUnreachableRelation, objType: "document", relation: "blocked"},
+				{code: LintUnreachableRelation, objType: "document", relation: "viewer"},
+			},
+		},
+		`wildcard_on_sensitive_relation`: {
+			model: `
+				model
+					schema 1.1
+				type user
+				type document
+					relations
+						define can_delete: [user:*]
+						define owner: [user, user:*]
+						define viewer: [user:*]`,
+			expected: []warning{
+				{code: LintWildcardOnSensitiveRelation, objType: "document", relation: "can_delete"},
+				{code: LintWildcardOnSensitiveRelation, objType: "document", relation: "owner"},
+			},
+		},
+		`custom_sensitive_relations`: {
+			model: `
+				model
+					schema 1.1
+				type user
+				type document
+					relations
+						define owner: [user:*]
+						define viewer: [user:*]`,
+			opts: []LintOption{WithLintSensitiveRelations("view")},
+			expected: []warning{
+				{code: LintWildcardOnSensitiveRelation, objType: "document", relation: "viewer"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			typesys, err := NewAndValidate(context.Background(), testutils.MustTransformDSLToProtoWithID(test.model))
+			require.NoError(t, err)
+
+			var actual []warning
+			for _, w := range Lint(typesys, test.opts...) {
+				actual = append(actual, warning{
+					code:      w.Code,
+					objType:   w.Type,
+					relation:  w.Relation,
+					condition: w.Condition,
+				})
+				require.NotEmpty(t, w.Message)
+			}
+
+			require.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestLintWarningString(t *testing.T) {
+	typesys := New(testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type document
+			relations
+				define owner: [user:*]`))
+
+	warnings := Lint(typesys)
+	require.Len(t, warnings, 1)
+	require.Equal(t, "wildcard_on_sensitive_relation: relation 'document#owner' can be granted to every user of type 'user' with 'user:*'", warnings[0].String())
+}
@@ -0,0 +1,104 @@
+package typesystem
+
+import (
+	"errors"
+	"fmt"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	parser "github.com/openfga/language/pkg/go/transformer"
+)
+
+// ErrInvalidModularModel is returned when the modules of a modular authorization model can't be composed.
+var ErrInvalidModularModel = errors.New("invalid modular authorization model")
+
+// Module is a file of a modular authorization model. Each module starts with a 'module <name>' header, and declares
+// types and conditions, and extends the types declared by other modules with relations (e.g. 'extend type folder').
+type Module struct {
+	// Name is the name of the file of the module, e.g. 'core.fga'.
+	Name string `json:"name"`
+
+	// Contents is the DSL of the module.
+	Contents string `json:"contents"`
+}
+
+// ComposeModules composes the modules of a modular authorization model into a single authorization model of schema
+// version 1.2, without validating it. The types, relations and conditions of the model record the module (and the
+// file) which declares them, so that the errors of NewAndValidate refer to it (see ModuleError).
+func ComposeModules(modules []Module) (*openfgav1.AuthorizationModel, error) {
+	if len(modules) == 0 {
+		return nil, fmt.Errorf("%w: no modules", ErrInvalidModularModel)
+	}
+
+	files := make([]parser.ModuleFile, 0, len(modules))
+	names := make(map[string]struct{}, len(modules))
+
+	var errs []error
+	for _, module := range modules {
+		if _, ok := names[module.Name]; ok {
+			errs = append(errs, fmt.Errorf("module file '%s': duplicate module file", module.Name))
+			continue
+		}
+		names[module.Name] = struct{}{}
+
+		// the syntax errors of the modules are reported without their file when composing them, so parse them first
+		if _, _, err := parser.TransformModularDSLToProto(module.Contents); err != nil {
+			errs = append(errs, fmt.Errorf("module file '%s': %w", module.Name, err))
+			continue
+		}
+
+		files = append(files, parser.ModuleFile{Name: module.Name, Contents: module.Contents})
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidModularModel, errors.Join(errs...))
+	}
+
+	model, err := parser.TransformModuleFilesToModel(files, SchemaVersion1_2)
+	if err != nil {
+		var validationErr *parser.ModuleValidationMultipleError
+		if !errors.As(err, &validationErr) {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidModularModel, err)
+		}
+
+		for _, err := range validationErr.Errors {
+			var transformationErr *parser.ModuleTransformationSingleError
+			if errors.As(err, &transformationErr) {
+				err = fmt.Errorf("module file '%s': %w", transformationErr.File, transformationErr)
+			}
+			errs = append(errs, err)
+		}
+
+		return nil, fmt.Errorf("%w: %w", ErrInvalidModularModel, errors.Join(errs...))
+	}
+
+	return model, nil
+}
+
+// moduleOf returns the module and the file which declare a type, or a relation of it if set.
+func moduleOf(typedef *openfgav1.TypeDefinition, relation string) (module, file string) {
+	module = typedef.GetMetadata().GetModule()
+	file = typedef.GetMetadata().GetSourceInfo().GetFile()
+
+	if relation == "" {
+		return module, file
+	}
+
+	relationMetadata := typedef.GetMetadata().GetRelations()[relation]
+	if m := relationMetadata.GetModule(); m != "" {
+		module = m
+	}
+	if f := relationMetadata.GetSourceInfo().GetFile(); f != "" {
+		file = f
+	}
+
+	return module, file
+}
+
+// withModule wraps an error about a type, relation or condition into a ModuleError if it's declared by a module.
+func withModule(err error, module, file string) error {
+	if err == nil || (module == "" && file == "") {
+		return err
+	}
+
+	return &ModuleError{Module: module, File: file, Err: err}
+}
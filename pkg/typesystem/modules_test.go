@@ -0,0 +1,147 @@
+package typesystem
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestComposeModules(t *testing.T) {
+	core := Module{
+		Name: "core.fga",
+		Contents: `module core
+
+type user
+
+type organization
+  relations
+    define member: [user]
+    define admin: [user]
+
+type folder
+  relations
+    define owner: [user]`,
+	}
+
+	docs := Module{
+		Name: "docs.fga",
+		Contents: `module docs
+
+extend type folder
+  relations
+    define viewer: [user] or owner
+
+type document
+  relations
+    define parent: [folder]
+    define viewer: [user with non_expired] or viewer from parent
+
+condition non_expired(current_time: timestamp, expiration: timestamp) {
+  current_time < expiration
+}`,
+	}
+
+	t.Run("composed", func(t *testing.T) {
+		model, err := ComposeModules([]Module{core, docs})
+		require.NoError(t, err)
+		require.Equal(t, SchemaVersion1_2, model.GetSchemaVersion())
+
+		typesys, err := NewAndValidate(context.Background(), model)
+		require.NoError(t, err)
+
+		_, err = typesys.GetRelation("folder", "viewer")
+		require.NoError(t, err)
+		_, ok := typesys.GetCondition("non_expired")
+		require.True(t, ok)
+
+		folder, ok := typesys.GetTypeDefinition("folder")
+		require.True(t, ok)
+
+		module, file := moduleOf(folder, "")
+		require.Equal(t, "core", module)
+		require.Equal(t, "core.fga", file)
+
+		module, file = moduleOf(folder, "viewer")
+		require.Equal(t, "docs", module)
+		require.Equal(t, "docs.fga", file)
+	})
+
+	t.Run("validation_errors_refer_to_the_module", func(t *testing.T) {
+		invalid := Module{
+			Name: "audit.fga",
+			Contents: `module audit
+
+extend type organization
+  relations
+    define auditor: [user] and viewer`,
+		}
+
+		model, err := ComposeModules([]Module{core, invalid})
+		require.NoError(t, err)
+
+		_, err = NewAndValidate(context.Background(), model)
+		require.ErrorIs(t, err, ErrRelationUndefined)
+
+		var moduleErr *ModuleError
+		require.ErrorAs(t, err, &moduleErr)
+		require.Equal(t, "audit", moduleErr.Module)
+		require.Equal(t, "audit.fga", moduleErr.File)
+		require.Contains(t, err.Error(), "in module 'audit' (file 'audit.fga'): ")
+	})
+
+	t.Run("invalid_condition_refers_to_the_module", func(t *testing.T) {
+		invalid := Module{
+			Name: "conditions.fga",
+			Contents: `module conditions
+
+condition invalid(x: int) {
+  x < "a"
+}`,
+		}
+
+		model, err := ComposeModules([]Module{core, invalid})
+		require.NoError(t, err)
+
+		_, err = NewAndValidate(context.Background(), model)
+		require.ErrorContains(t, err, "in module 'conditions' (file 'conditions.fga'): ")
+	})
+
+	t.Run("syntax_error", func(t *testing.T) {
+		_, err := ComposeModules([]Module{core, {Name: "broken.fga", Contents: "module broken\n\ntype"}})
+		require.ErrorIs(t, err, ErrInvalidModularModel)
+		require.ErrorContains(t, err, "module file 'broken.fga': ")
+	})
+
+	t.Run("extended_type_not_declared", func(t *testing.T) {
+		_, err := ComposeModules([]Module{docs})
+		require.ErrorIs(t, err, ErrInvalidModularModel)
+		require.ErrorContains(t, err, "module file 'docs.fga': ")
+		require.ErrorContains(t, err, "extended type folder does not exist")
+	})
+
+	t.Run("duplicate_type", func(t *testing.T) {
+		_, err := ComposeModules([]Module{core, {Name: "users.fga", Contents: "module users\n\ntype user"}})
+		require.ErrorIs(t, err, ErrInvalidModularModel)
+		require.ErrorContains(t, err, "module file 'users.fga': ")
+		require.ErrorContains(t, err, "duplicate type definition user")
+	})
+
+	t.Run("duplicate_module_file", func(t *testing.T) {
+		_, err := ComposeModules([]Module{core, core})
+		require.ErrorContains(t, err, "module file 'core.fga': duplicate module file")
+	})
+
+	t.Run("no_modules", func(t *testing.T) {
+		_, err := ComposeModules(nil)
+		require.ErrorIs(t, err, ErrInvalidModularModel)
+	})
+}
+
+func TestModuleError(t *testing.T) {
+	err := ErrNoEntrypoints
+	require.Equal(t, err, withModule(err, "", ""))
+	require.EqualError(t, withModule(err, "core", ""), "in module 'core': no entrypoints defined")
+	require.EqualError(t, withModule(err, "", "core.fga"), "in module file 'core.fga': no entrypoints defined")
+	require.EqualError(t, withModule(err, "core", "core.fga"), "in module 'core' (file 'core.fga'): no entrypoints defined")
+}
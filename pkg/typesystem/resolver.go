@@ -16,23 +16,73 @@ import (
 
 const (
 	typesystemCacheTTL = 168 * time.Hour // 7 days.
+
+	// activeAuthorizationModelIDCacheTTL bounds how long a replica keeps resolving the model a store was pinned to
+	// after another replica changed it.
+	activeAuthorizationModelIDCacheTTL = 10 * time.Second
 )
 
+// ActiveAuthorizationModelIDCache caches the IDs of the active authorization models of stores, keyed by store ID,
+// with an empty ID for the stores which aren't pinned to a model.
+type ActiveAuthorizationModelIDCache = ccache.Cache[string]
+
+// NewActiveAuthorizationModelIDCache creates an ActiveAuthorizationModelIDCache.
+func NewActiveAuthorizationModelIDCache() *ActiveAuthorizationModelIDCache {
+	return ccache.New(ccache.Configure[string]())
+}
+
+type memoizedTypesystemResolverConfig struct {
+	activeAuthorizationModelIDCache *ActiveAuthorizationModelIDCache
+}
+
+type MemoizedTypesystemResolverFuncOption func(*memoizedTypesystemResolverConfig)
+
+// WithActiveAuthorizationModelIDCache sets the cache of the IDs of the active authorization models of stores, so
+// that the entry of a store can be deleted when its active model is changed. Otherwise the resolver uses a cache of
+// its own.
+func WithActiveAuthorizationModelIDCache(cache *ActiveAuthorizationModelIDCache) MemoizedTypesystemResolverFuncOption {
+	return func(c *memoizedTypesystemResolverConfig) {
+		c.activeAuthorizationModelIDCache = cache
+	}
+}
+
 // TypesystemResolverFunc is a function type that implementations
 // can use to provide lookup and resolution of a Typesystem.
 type TypesystemResolverFunc func(ctx context.Context, storeID, modelID string) (*TypeSystem, error)
 
 // MemoizedTypesystemResolverFunc returns a TypesystemResolverFunc that fetches the provided authorization
-// model (if provided) or looks up the latest authorization model. It then constructs a TypeSystem from
+// model (if provided), or else the active authorization model the store is pinned to (if any), or else looks
+// up the latest authorization model. It then constructs a TypeSystem from
 // the resolved model, and memoizes the type-system resolution. If another lookup of the same model occurs,
-// the earlier constructed TypeSystem will be used.
+// the earlier constructed TypeSystem will be used. The active authorization models of stores are cached for
+// a few seconds.
 //
 // The memoized resolver function is designed for concurrent use.
-func MemoizedTypesystemResolverFunc(datastore storage.AuthorizationModelReadBackend) (TypesystemResolverFunc, func()) {
+func MemoizedTypesystemResolverFunc(
+	datastore storage.AuthorizationModelReadBackend,
+	opts ...MemoizedTypesystemResolverFuncOption,
+) (TypesystemResolverFunc, func()) {
 	lookupGroup := singleflight.Group{}
 
 	cache := ccache.New(ccache.Configure[*TypeSystem]())
 
+	config := &memoizedTypesystemResolverConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	activeModelIDCache := config.activeAuthorizationModelIDCache
+	if activeModelIDCache == nil {
+		activeModelIDCache = NewActiveAuthorizationModelIDCache()
+	}
+
+	stop := func() {
+		cache.Stop()
+		if config.activeAuthorizationModelIDCache == nil {
+			activeModelIDCache.Stop()
+		}
+	}
+
 	return func(ctx context.Context, storeID, modelID string) (*TypeSystem, error) {
 		ctx, span := tracer.Start(ctx, "MemoizedTypesystemResolverFunc")
 		defer span.End()
@@ -45,6 +95,24 @@ func MemoizedTypesystemResolverFunc(datastore storage.AuthorizationModelReadBack
 			}
 		}
 
+		if modelID == "" {
+			if item := activeModelIDCache.Get(storeID); item != nil && !item.Expired() {
+				modelID = item.Value()
+			} else {
+				v, err, _ := lookupGroup.Do(fmt.Sprintf("ReadActiveAuthorizationModelID:%s", storeID), func() (interface{}, error) {
+					return datastore.ReadActiveAuthorizationModelID(ctx, storeID)
+				})
+				if err != nil && !errors.Is(err, storage.ErrNotFound) {
+					return nil, fmt.Errorf("failed to ReadActiveAuthorizationModelID: %w", err)
+				}
+				if err == nil {
+					modelID = v.(string)
+				}
+
+				activeModelIDCache.Set(storeID, modelID, activeAuthorizationModelIDCacheTTL)
+			}
+		}
+
 		var v interface{}
 		var key string
 		if modelID == "" {
@@ -90,5 +158,5 @@ func MemoizedTypesystemResolverFunc(datastore storage.AuthorizationModelReadBack
 		cache.Set(key, typesys, typesystemCacheTTL)
 
 		return typesys, nil
-	}, cache.Stop
+	}, stop
 }
@@ -13,6 +13,7 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	mockstorage "github.com/openfga/openfga/internal/mocks"
+	"github.com/openfga/openfga/pkg/storage"
 )
 
 func TestMemoizedTypesystemResolverFunc(t *testing.T) {
@@ -42,6 +43,10 @@ func TestMemoizedTypesystemResolverFunc(t *testing.T) {
 				TypeDefinitions: typedefs,
 			}, nil),
 
+		mockDatastore.EXPECT().
+			ReadActiveAuthorizationModelID(gomock.Any(), storeID).
+			Return("", storage.ErrNotFound),
+
 		mockDatastore.EXPECT().
 			FindLatestAuthorizationModel(gomock.Any(), storeID).
 			Return(&openfgav1.AuthorizationModel{
@@ -74,6 +79,58 @@ func TestMemoizedTypesystemResolverFunc(t *testing.T) {
 	require.NotNil(t, relation)
 }
 
+func TestMemoizedTypesystemResolverFuncWithActiveModel(t *testing.T) {
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+
+	storeID := ulid.Make().String()
+	modelID := ulid.Make().String()
+
+	gomock.InOrder(
+		mockDatastore.EXPECT().
+			ReadActiveAuthorizationModelID(gomock.Any(), storeID).
+			Return(modelID, nil),
+
+		mockDatastore.EXPECT().
+			ReadAuthorizationModel(gomock.Any(), storeID, modelID).
+			Return(&openfgav1.AuthorizationModel{
+				Id:            modelID,
+				SchemaVersion: SchemaVersion1_1,
+			}, nil),
+
+		// the active model is read again once its cache entry is deleted, and the model is resolved from the cache
+		mockDatastore.EXPECT().
+			ReadActiveAuthorizationModelID(gomock.Any(), storeID).
+			Return(modelID, nil),
+	)
+
+	mockDatastore.EXPECT().FindLatestAuthorizationModel(gomock.Any(), gomock.Any()).Times(0)
+
+	activeModelIDCache := NewActiveAuthorizationModelIDCache()
+	defer activeModelIDCache.Stop()
+
+	resolver, resolverStop := MemoizedTypesystemResolverFunc(
+		mockDatastore,
+		WithActiveAuthorizationModelIDCache(activeModelIDCache),
+	)
+	defer resolverStop()
+
+	// the active model is cached
+	for i := 0; i < 2; i++ {
+		typesys, err := resolver(context.Background(), storeID, "")
+		require.NoError(t, err)
+		require.Equal(t, modelID, typesys.GetAuthorizationModelID())
+	}
+
+	activeModelIDCache.Delete(storeID)
+
+	typesys, err := resolver(context.Background(), storeID, "")
+	require.NoError(t, err)
+	require.Equal(t, modelID, typesys.GetAuthorizationModelID())
+}
+
 func TestSingleFlightMemoizedTypesystemResolverFunc(t *testing.T) {
 	const numGoroutines = 2
 
@@ -85,6 +142,10 @@ func TestSingleFlightMemoizedTypesystemResolverFunc(t *testing.T) {
 	storeID := ulid.Make().String()
 	modelID := ulid.Make().String()
 
+	mockDatastore.EXPECT().
+		ReadActiveAuthorizationModelID(gomock.Any(), storeID).
+		Return("", storage.ErrNotFound).MinTimes(1).MaxTimes(numGoroutines)
+
 	mockDatastore.EXPECT().
 		FindLatestAuthorizationModel(gomock.Any(), storeID).
 		Return(&openfgav1.AuthorizationModel{
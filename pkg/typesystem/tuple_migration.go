@@ -0,0 +1,583 @@
+package typesystem
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/exp/maps"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+const (
+	defaultTupleMigrationBatchSize = 100
+
+	// maxReportedTupleRewrites is the maximum number of rewritten and invalid tuples listed in a TupleMigrationReport.
+	maxReportedTupleRewrites = 100
+)
+
+// ErrInvalidTupleMapping is returned when a TupleMapping doesn't apply to the authorization models the tuples are
+// migrated between.
+var ErrInvalidTupleMapping = errors.New("invalid tuple mapping")
+
+// RenameRelation renames a relation of a type, e.g. 'document#viewer' to 'document#reader'. Both the tuples of the
+// relation and the tuples whose user is a userset of the relation (e.g. 'document:1#viewer') are rewritten.
+type RenameRelation struct {
+	Type string `json:"type"`
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// MoveType renames a type, e.g. 'team' to 'group'. Both the tuples of the objects of the type and the tuples whose
+// user is of the type are rewritten.
+type MoveType struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// SplitRelation splits a relation of a type into several relations, e.g. 'document#editor' into 'document#writer'
+// and 'document#commenter', by rewriting each tuple of the relation into a tuple of each of the relations. The
+// relation may be one of the relations it's split into, in which case its tuples are kept.
+type SplitRelation struct {
+	Type string   `json:"type"`
+	From string   `json:"from"`
+	To   []string `json:"to"`
+}
+
+// TupleMapping is a declarative mapping of the relationship tuples of an authorization model to the tuples of
+// another. The types and relations are named as in the model the tuples are migrated from.
+type TupleMapping struct {
+	RenameRelations []RenameRelation `json:"renameRelations,omitempty"`
+	MoveTypes       []MoveType       `json:"moveTypes,omitempty"`
+	SplitRelations  []SplitRelation  `json:"splitRelations,omitempty"`
+}
+
+// TupleRewrite is a tuple rewritten by a tuple migration.
+type TupleRewrite struct {
+	From string   `json:"from"`
+	To   []string `json:"to"`
+
+	// Reason is why the tuple can't be rewritten, if it can't.
+	Reason string `json:"reason,omitempty"`
+}
+
+// TupleMigrationReport is the outcome of a tuple migration.
+type TupleMigrationReport struct {
+	// Migrated is the number of tuples rewritten, or which would be rewritten in a dry run.
+	Migrated int `json:"migrated"`
+
+	// Invalid is the number of tuples which would be rewritten into tuples that are invalid under the model
+	// migrated to. These tuples are left as they are.
+	Invalid int `json:"invalid"`
+
+	// Rewrites and InvalidTuples list up to 100 of the tuples rewritten and of the invalid tuples.
+	Rewrites      []*TupleRewrite `json:"rewrites,omitempty"`
+	InvalidTuples []*TupleRewrite `json:"invalid_tuples,omitempty"`
+
+	// ContinuationToken resumes the migration from where it stopped, if it stopped on an error.
+	ContinuationToken string `json:"continuation_token,omitempty"`
+}
+
+type tupleMigrationContinuationToken struct {
+	Step int    `json:"step"`
+	From string `json:"from"`
+}
+
+// TupleMigrator migrates the relationship tuples of a store from an authorization model to another according to a
+// TupleMapping.
+type TupleMigrator struct {
+	datastore storage.TupleBackend
+	storeID   string
+	to        *TypeSystem
+	batchSize int
+	dryRun    bool
+
+	// types maps the types moved to their new names.
+	types map[string]string
+
+	// relations maps the relations renamed or split (of the form 'type#relation') to their new relations.
+	relations map[string][]string
+
+	// steps are the filters of the tuples which may be rewritten, one for each relation of the model migrated from
+	// whose tuples may be rewritten.
+	steps []*openfgav1.TupleKey
+}
+
+type TupleMigratorOption func(*TupleMigrator)
+
+// WithTupleMigrationBatchSize sets the number of tuples read, and rewritten, at once.
+func WithTupleMigrationBatchSize(batchSize int) TupleMigratorOption {
+	return func(m *TupleMigrator) {
+		m.batchSize = batchSize
+	}
+}
+
+// WithTupleMigrationDryRun reports the tuples that would be rewritten without rewriting them.
+func WithTupleMigrationDryRun(dryRun bool) TupleMigratorOption {
+	return func(m *TupleMigrator) {
+		m.dryRun = dryRun
+	}
+}
+
+// NewTupleMigrator returns a TupleMigrator of the tuples of a store from the model 'from' to the model 'to', or an
+// error wrapping ErrInvalidTupleMapping if the mapping doesn't apply to these models.
+func NewTupleMigrator(
+	datastore storage.TupleBackend,
+	storeID string,
+	from, to *TypeSystem,
+	mapping *TupleMapping,
+	opts ...TupleMigratorOption,
+) (*TupleMigrator, error) {
+	m := &TupleMigrator{
+		datastore: datastore,
+		storeID:   storeID,
+		to:        to,
+		batchSize: defaultTupleMigrationBatchSize,
+		types:     make(map[string]string),
+		relations: make(map[string][]string),
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	if m.batchSize <= 0 {
+		return nil, fmt.Errorf("tuple migration batch size must be positive, got %d", m.batchSize)
+	}
+
+	for _, move := range mapping.MoveTypes {
+		if _, ok := from.GetTypeDefinition(move.From); !ok {
+			return nil, fmt.Errorf("%w: type '%s' moved doesn't exist", ErrInvalidTupleMapping, move.From)
+		}
+		if _, ok := to.GetTypeDefinition(move.To); !ok {
+			return nil, fmt.Errorf("%w: type '%s' moved to doesn't exist", ErrInvalidTupleMapping, move.To)
+		}
+		if _, ok := from.GetTypeDefinition(move.To); ok {
+			return nil, fmt.Errorf("%w: type '%s' can't be moved to existing type '%s'", ErrInvalidTupleMapping, move.From, move.To)
+		}
+		if _, ok := m.types[move.From]; ok {
+			return nil, fmt.Errorf("%w: type '%s' is moved more than once", ErrInvalidTupleMapping, move.From)
+		}
+		m.types[move.From] = move.To
+	}
+
+	addRelations := func(objectType, relation string, relations []string) error {
+		if _, err := from.GetRelation(objectType, relation); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidTupleMapping, err)
+		}
+
+		objectRelation := tuple.ToObjectRelationString(objectType, relation)
+		if _, ok := m.relations[objectRelation]; ok {
+			return fmt.Errorf("%w: relation '%s' is mapped more than once", ErrInvalidTupleMapping, objectRelation)
+		}
+
+		for _, r := range relations {
+			if _, err := to.GetRelation(m.moveType(objectType), r); err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidTupleMapping, err)
+			}
+		}
+
+		m.relations[objectRelation] = relations
+		return nil
+	}
+
+	for _, rename := range mapping.RenameRelations {
+		if err := addRelations(rename.Type, rename.From, []string{rename.To}); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, split := range mapping.SplitRelations {
+		if len(split.To) == 0 {
+			return nil, fmt.Errorf("%w: relation '%s' is split into no relations", ErrInvalidTupleMapping, tuple.ToObjectRelationString(split.Type, split.From))
+		}
+		if err := addRelations(split.Type, split.From, split.To); err != nil {
+			return nil, err
+		}
+	}
+
+	// tuples rewritten by a mapping would be rewritten again by a chained mapping when read again
+	for objectRelation, relations := range m.relations {
+		objectType, relation := tuple.SplitObjectRelation(objectRelation)
+		for _, r := range relations {
+			if _, ok := m.relations[tuple.ToObjectRelationString(objectType, r)]; ok && r != relation {
+				return nil, fmt.Errorf("%w: relation '%s' can't be mapped to relation '%s' which is mapped too", ErrInvalidTupleMapping, objectRelation, r)
+			}
+		}
+	}
+
+	// the steps are sorted by type and relation, so that a continuation token resumes the same step
+	types := maps.Keys(from.typeDefinitions)
+	sort.Strings(types)
+	for _, objectType := range types {
+		relations := from.relations[objectType]
+		names := maps.Keys(relations)
+		sort.Strings(names)
+		for _, relation := range names {
+			if m.mayRewrite(objectType, relation, relations[relation]) {
+				m.steps = append(m.steps, &openfgav1.TupleKey{
+					Object:   tuple.BuildObject(objectType, ""),
+					Relation: relation,
+				})
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// mayRewrite returns true if the mapping may rewrite tuples of the relation, that is if the type or relation is
+// mapped, or if one of the directly related user types is.
+func (m *TupleMigrator) mayRewrite(objectType, relation string, r *openfgav1.Relation) bool {
+	if _, ok := m.types[objectType]; ok {
+		return true
+	}
+	if _, ok := m.relations[tuple.ToObjectRelationString(objectType, relation)]; ok {
+		return true
+	}
+
+	for _, rr := range r.GetTypeInfo().GetDirectlyRelatedUserTypes() {
+		if _, ok := m.types[rr.GetType()]; ok {
+			return true
+		}
+		if _, ok := m.relations[tuple.ToObjectRelationString(rr.GetType(), rr.GetRelation())]; ok && rr.GetRelation() != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *TupleMigrator) moveType(objectType string) string {
+	if to, ok := m.types[objectType]; ok {
+		return to
+	}
+	return objectType
+}
+
+func (m *TupleMigrator) mapRelation(objectType, relation string) []string {
+	if to, ok := m.relations[tuple.ToObjectRelationString(objectType, relation)]; ok {
+		return to
+	}
+	return []string{relation}
+}
+
+// rewrite returns the tuples a tuple of the model migrated from is rewritten into.
+func (m *TupleMigrator) rewrite(tk *openfgav1.TupleKey) []*openfgav1.TupleKey {
+	objectType, objectID := tuple.SplitObject(tk.GetObject())
+	object := tuple.BuildObject(m.moveType(objectType), objectID)
+
+	var users []string
+	userObject, userRelation := tuple.SplitObjectRelation(tk.GetUser())
+	userType, userID := tuple.SplitObject(userObject)
+	if userRelation == "" {
+		users = []string{tuple.BuildObject(m.moveType(userType), userID)}
+	} else {
+		for _, r := range m.mapRelation(userType, userRelation) {
+			users = append(users, tuple.ToObjectRelationString(tuple.BuildObject(m.moveType(userType), userID), r))
+		}
+	}
+
+	var rewritten []*openfgav1.TupleKey
+	for _, relation := range m.mapRelation(objectType, tk.GetRelation()) {
+		for _, user := range users {
+			rewritten = append(rewritten, tuple.NewTupleKeyWithCondition(
+				object,
+				relation,
+				user,
+				tk.GetCondition().GetName(),
+				tk.GetCondition().GetContext(),
+			))
+		}
+	}
+
+	return rewritten
+}
+
+// validate returns an error if a tuple is invalid under the model migrated to.
+func (m *TupleMigrator) validate(tk *openfgav1.TupleKey) error {
+	objectType, _ := tuple.SplitObject(tk.GetObject())
+	relation, err := m.to.GetRelation(objectType, tk.GetRelation())
+	if err != nil {
+		return err
+	}
+
+	userObject, userRelation := tuple.SplitObjectRelation(tk.GetUser())
+	userType, userID := tuple.SplitObject(userObject)
+	switch {
+	case userRelation != "":
+		userType = tuple.ToObjectRelationString(userType, userRelation)
+	case userID == tuple.Wildcard:
+		userType = tuple.TypedPublicWildcard(userType)
+	}
+	if tk.GetCondition().GetName() != "" {
+		userType += " with " + tk.GetCondition().GetName()
+	}
+
+	if !userTypesOf(relation)[userType] {
+		return fmt.Errorf("type '%s' is not an allowed type restriction for '%s'", userType, tuple.ToObjectRelationString(objectType, tk.GetRelation()))
+	}
+
+	return nil
+}
+
+// Migrate rewrites the tuples of the store in batches, resuming from a continuation token returned in the report
+// of a previous migration which stopped on an error, if any. The tuples which would be rewritten into invalid tuples
+// are reported and left as they are.
+//
+// Each tuple is deleted in the same write as the tuples it is rewritten into are written, unless it is one of them,
+// and the tuples which already exist aren't written again, so that a migration can also be restarted from the
+// beginning.
+func (m *TupleMigrator) Migrate(ctx context.Context, continuationToken string) (*TupleMigrationReport, error) {
+	ctx, span := tracer.Start(ctx, "TupleMigrator.Migrate")
+	defer span.End()
+
+	span.SetAttributes(attribute.Bool("dry_run", m.dryRun))
+
+	token, err := decodeTupleMigrationContinuationToken(continuationToken)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &TupleMigrationReport{}
+
+	for ; token.Step < len(m.steps); token.Step, token.From = token.Step+1, "" {
+		for {
+			next, err := m.migrateBatch(ctx, m.steps[token.Step], token.From, report)
+			if err != nil {
+				report.ContinuationToken = encodeTupleMigrationContinuationToken(token)
+				return report, err
+			}
+
+			if next == nil {
+				break
+			}
+			token.From = *next
+		}
+	}
+
+	span.SetAttributes(attribute.Int("migrated", report.Migrated), attribute.Int("invalid", report.Invalid))
+
+	return report, nil
+}
+
+// migrateBatch rewrites a batch of the tuples matching the filter, and returns where to read the next batch from,
+// or nil if there are no more tuples.
+//
+// As the datastore continuation tokens may be offsets in the tuples read, they may not resume the read where it
+// stopped once tuples have been deleted or written. Thus, the batch is read again from the same position until none
+// of its tuples needs rewriting, which eventually happens as rewritten tuples are never rewritten again.
+func (m *TupleMigrator) migrateBatch(ctx context.Context, filter *openfgav1.TupleKey, from string, report *TupleMigrationReport) (*string, error) {
+	tuples, contToken, err := m.datastore.ReadPage(ctx, m.storeID, filter, storage.PaginationOptions{
+		PageSize: m.batchSize,
+		From:     from,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		batch    [][]*openfgav1.TupleKey
+		rewrites []*TupleRewrite
+		invalid  []*TupleRewrite
+	)
+
+	for _, t := range tuples {
+		tk := t.GetKey()
+
+		rewritten := m.rewrite(tk)
+		if len(rewritten) == 1 && proto.Equal(rewritten[0], tk) {
+			continue
+		}
+
+		rewrite := &TupleRewrite{From: tupleString(tk)}
+		for _, r := range rewritten {
+			rewrite.To = append(rewrite.To, tupleString(r))
+			if err := m.validate(r); err != nil && rewrite.Reason == "" {
+				rewrite.Reason = err.Error()
+			}
+		}
+
+		if rewrite.Reason != "" {
+			invalid = append(invalid, rewrite)
+			continue
+		}
+
+		rewrites = append(rewrites, rewrite)
+		batch = append(batch, append([]*openfgav1.TupleKey{tk}, rewritten...))
+	}
+
+	if m.dryRun {
+		for _, rewrite := range rewrites {
+			report.addRewrite(rewrite)
+		}
+	} else {
+		written, err := m.write(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, rewrite := range rewrites {
+			if written[i] {
+				report.addRewrite(rewrite)
+			}
+		}
+
+		for _, w := range written {
+			if w {
+				// read the batch again
+				return &from, nil
+			}
+		}
+	}
+
+	for _, rewrite := range invalid {
+		report.addInvalid(rewrite)
+	}
+
+	if len(contToken) == 0 {
+		return nil, nil
+	}
+
+	next := string(contToken)
+	return &next, nil
+}
+
+// write writes the tuples rewritten (the first tuple of each group is the tuple rewritten, and the others the tuples
+// it is rewritten into) without exceeding the datastore's write limit, and returns whether each group caused a write.
+// The groups too large to be written at once are split, writing the tuples a tuple is rewritten into before deleting
+// it, so that a migration resumed after a failure writes the rest of them.
+func (m *TupleMigrator) write(ctx context.Context, groups [][]*openfgav1.TupleKey) ([]bool, error) {
+	written := make([]bool, len(groups))
+	maxTuplesPerWrite := m.datastore.MaxTuplesPerWrite()
+
+	var deletes storage.Deletes
+	var writes storage.Writes
+	flush := func() error {
+		if len(deletes)+len(writes) == 0 {
+			return nil
+		}
+		if err := m.datastore.Write(ctx, m.storeID, deletes, writes); err != nil {
+			return err
+		}
+		deletes, writes = nil, nil
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	for i, group := range groups {
+		tk := group[0]
+
+		var groupDeletes storage.Deletes
+		var groupWrites storage.Writes
+
+		keep := false
+		for _, r := range group[1:] {
+			if proto.Equal(r, tk) {
+				keep = true
+				continue
+			}
+
+			key := tuple.TupleKeyToString(r)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			_, err := m.datastore.ReadUserTuple(ctx, m.storeID, r)
+			if err == nil {
+				continue
+			}
+			if !errors.Is(err, storage.ErrNotFound) {
+				return nil, err
+			}
+
+			groupWrites = append(groupWrites, r)
+		}
+
+		if !keep {
+			groupDeletes = append(groupDeletes, tuple.TupleKeyToTupleKeyWithoutCondition(tk))
+		}
+
+		if len(groupDeletes)+len(groupWrites) == 0 {
+			continue
+		}
+		written[i] = true
+
+		if len(deletes)+len(writes)+len(groupDeletes)+len(groupWrites) > maxTuplesPerWrite {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+
+		for len(groupDeletes)+len(groupWrites) > maxTuplesPerWrite {
+			n := min(maxTuplesPerWrite, len(groupWrites))
+			if err := m.datastore.Write(ctx, m.storeID, nil, groupWrites[:n]); err != nil {
+				return nil, err
+			}
+			groupWrites = groupWrites[n:]
+		}
+
+		deletes = append(deletes, groupDeletes...)
+		writes = append(writes, groupWrites...)
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return written, nil
+}
+
+// tupleString returns the string representation of a tuple, along with the name of its condition if any.
+func tupleString(tk *openfgav1.TupleKey) string {
+	if tk.GetCondition().GetName() == "" {
+		return tuple.TupleKeyToString(tk)
+	}
+	return fmt.Sprintf("%s (condition %s)", tuple.TupleKeyToString(tk), tk.GetCondition().GetName())
+}
+
+func (r *TupleMigrationReport) addRewrite(rewrite *TupleRewrite) {
+	r.Migrated++
+	if len(r.Rewrites) < maxReportedTupleRewrites {
+		r.Rewrites = append(r.Rewrites, rewrite)
+	}
+}
+
+func (r *TupleMigrationReport) addInvalid(rewrite *TupleRewrite) {
+	r.Invalid++
+	if len(r.InvalidTuples) < maxReportedTupleRewrites {
+		r.InvalidTuples = append(r.InvalidTuples, rewrite)
+	}
+}
+
+func decodeTupleMigrationContinuationToken(continuationToken string) (*tupleMigrationContinuationToken, error) {
+	token := &tupleMigrationContinuationToken{}
+	if continuationToken == "" {
+		return token, nil
+	}
+
+	decoded, err := base64.URLEncoding.DecodeString(continuationToken)
+	if err != nil {
+		return nil, storage.ErrInvalidContinuationToken
+	}
+
+	if err := json.Unmarshal(decoded, token); err != nil || token.Step < 0 {
+		return nil, storage.ErrInvalidContinuationToken
+	}
+
+	return token, nil
+}
+
+func encodeTupleMigrationContinuationToken(token *tupleMigrationContinuationToken) string {
+	marshalled, _ := json.Marshal(token)
+	return base64.URLEncoding.EncodeToString(marshalled)
+}
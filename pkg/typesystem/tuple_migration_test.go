@@ -0,0 +1,298 @@
+package typesystem
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	openfgav1 "github.com/openfga/api/proto/openfga/v1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/testutils"
+	"github.com/openfga/openfga/pkg/tuple"
+)
+
+// failingWriter fails the writes after a number of them.
+type failingWriter struct {
+	storage.OpenFGADatastore
+	writes int
+}
+
+func (f *failingWriter) Write(ctx context.Context, store string, d storage.Deletes, w storage.Writes) error {
+	if f.writes == 0 {
+		return errors.New("write failed")
+	}
+	f.writes--
+	return f.OpenFGADatastore.Write(ctx, store, d, w)
+}
+
+func TestTupleMigrator(t *testing.T) {
+	from := New(testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type team
+			relations
+				define member: [user, team#member]
+		type document
+			relations
+				define owner: [user]
+				define editor: [user, team#member]
+				define viewer: [user, user:*, team#member]`))
+
+	to := New(testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type group
+			relations
+				define members: [user, group#members]
+		type document
+			relations
+				define owner: [user]
+				define writer: [user, group#members]
+				define commenter: [user, group#members]
+				define reader: [user, user:*, group#members]`))
+
+	mapping := &TupleMapping{
+		RenameRelations: []RenameRelation{
+			{Type: "document", From: "viewer", To: "reader"},
+			{Type: "team", From: "member", To: "members"},
+		},
+		MoveTypes: []MoveType{
+			{From: "team", To: "group"},
+		},
+		SplitRelations: []SplitRelation{
+			{Type: "document", From: "editor", To: []string{"writer", "commenter"}},
+		},
+	}
+
+	tuples := []string{
+		"document:1#owner@user:anne",
+		"document:1#viewer@user:*",
+		"document:1#viewer@user:bob",
+		"document:1#viewer@team:eng#member",
+		"document:1#editor@user:carl",
+		"document:1#editor@team:eng#member",
+		"team:eng#member@user:dave",
+		"team:eng#member@team:ops#member",
+	}
+
+	expected := []string{
+		"document:1#owner@user:anne",
+		"document:1#reader@user:*",
+		"document:1#reader@user:bob",
+		"document:1#reader@group:eng#members",
+		"document:1#writer@user:carl",
+		"document:1#commenter@user:carl",
+		"document:1#writer@group:eng#members",
+		"document:1#commenter@group:eng#members",
+		"group:eng#members@user:dave",
+		"group:eng#members@group:ops#members",
+	}
+
+	ctx := context.Background()
+
+	bootstrap := func(t *testing.T) (storage.OpenFGADatastore, string) {
+		ds := memory.New()
+		t.Cleanup(ds.Close)
+
+		storeID := ulid.Make().String()
+		err := ds.Write(ctx, storeID, nil, tuple.MustParseTupleStrings(tuples...))
+		require.NoError(t, err)
+
+		return ds, storeID
+	}
+
+	readAll := func(t *testing.T, ds storage.OpenFGADatastore, storeID string) []string {
+		got, _, err := ds.ReadPage(ctx, storeID, &openfgav1.TupleKey{}, storage.PaginationOptions{PageSize: 100})
+		require.NoError(t, err)
+
+		var keys []string
+		for _, t := range got {
+			keys = append(keys, tuple.TupleKeyToString(t.GetKey()))
+		}
+		return keys
+	}
+
+	t.Run("dry_run", func(t *testing.T) {
+		ds, storeID := bootstrap(t)
+
+		m, err := NewTupleMigrator(ds, storeID, from, to, mapping, WithTupleMigrationDryRun(true), WithTupleMigrationBatchSize(2))
+		require.NoError(t, err)
+
+		report, err := m.Migrate(ctx, "")
+		require.NoError(t, err)
+		require.Equal(t, 7, report.Migrated)
+		require.Len(t, report.Rewrites, 7)
+		require.Zero(t, report.Invalid)
+		require.Empty(t, report.ContinuationToken)
+
+		require.ElementsMatch(t, tuples, readAll(t, ds, storeID))
+	})
+
+	t.Run("migrate", func(t *testing.T) {
+		ds, storeID := bootstrap(t)
+
+		m, err := NewTupleMigrator(ds, storeID, from, to, mapping, WithTupleMigrationBatchSize(2))
+		require.NoError(t, err)
+
+		report, err := m.Migrate(ctx, "")
+		require.NoError(t, err)
+		require.Equal(t, 7, report.Migrated)
+		require.Zero(t, report.Invalid)
+
+		require.ElementsMatch(t, expected, readAll(t, ds, storeID))
+
+		// migrating again is a no-op
+		report, err = m.Migrate(ctx, "")
+		require.NoError(t, err)
+		require.Zero(t, report.Migrated)
+		require.ElementsMatch(t, expected, readAll(t, ds, storeID))
+	})
+
+	t.Run("resume", func(t *testing.T) {
+		ds, storeID := bootstrap(t)
+		writer := &failingWriter{OpenFGADatastore: ds, writes: 1}
+
+		m, err := NewTupleMigrator(writer, storeID, from, to, mapping, WithTupleMigrationBatchSize(2))
+		require.NoError(t, err)
+
+		report, err := m.Migrate(ctx, "")
+		require.ErrorContains(t, err, "write failed")
+		require.NotEmpty(t, report.ContinuationToken)
+		migrated := report.Migrated
+
+		writer.writes = 100
+		report, err = m.Migrate(ctx, report.ContinuationToken)
+		require.NoError(t, err)
+		require.Equal(t, 7, migrated+report.Migrated)
+
+		require.ElementsMatch(t, expected, readAll(t, ds, storeID))
+	})
+
+	t.Run("groups_larger_than_the_write_limit_are_split", func(t *testing.T) {
+		ds := memory.New(memory.WithMaxTuplesPerWrite(1))
+		t.Cleanup(ds.Close)
+
+		storeID := ulid.Make().String()
+		for _, tk := range tuple.MustParseTupleStrings(tuples...) {
+			err := ds.Write(ctx, storeID, nil, []*openfgav1.TupleKey{tk})
+			require.NoError(t, err)
+		}
+
+		m, err := NewTupleMigrator(ds, storeID, from, to, mapping)
+		require.NoError(t, err)
+
+		report, err := m.Migrate(ctx, "")
+		require.NoError(t, err)
+		require.Equal(t, 7, report.Migrated)
+
+		require.ElementsMatch(t, expected, readAll(t, ds, storeID))
+	})
+
+	t.Run("split_into_kept_relation", func(t *testing.T) {
+		ds, storeID := bootstrap(t)
+
+		to := New(testutils.MustTransformDSLToProtoWithID(`
+			model
+				schema 1.1
+			type user
+			type team
+				relations
+					define member: [user, team#member]
+			type document
+				relations
+					define owner: [user]
+					define editor: [user, team#member]
+					define commenter: [user, team#member]
+					define viewer: [user, user:*, team#member]`))
+
+		m, err := NewTupleMigrator(ds, storeID, from, to, &TupleMapping{
+			SplitRelations: []SplitRelation{
+				{Type: "document", From: "editor", To: []string{"editor", "commenter"}},
+			},
+		}, WithTupleMigrationBatchSize(1))
+		require.NoError(t, err)
+
+		report, err := m.Migrate(ctx, "")
+		require.NoError(t, err)
+		require.Equal(t, 2, report.Migrated)
+
+		require.ElementsMatch(t, append(tuples,
+			"document:1#commenter@user:carl",
+			"document:1#commenter@team:eng#member",
+		), readAll(t, ds, storeID))
+	})
+
+	t.Run("invalid_tuples_are_left_as_they_are", func(t *testing.T) {
+		ds, storeID := bootstrap(t)
+
+		to := New(testutils.MustTransformDSLToProtoWithID(`
+			model
+				schema 1.1
+			type user
+			type team
+				relations
+					define member: [user, team#member]
+			type document
+				relations
+					define owner: [user]
+					define editor: [user, team#member]
+					define reader: [user]`))
+
+		m, err := NewTupleMigrator(ds, storeID, from, to, &TupleMapping{
+			RenameRelations: []RenameRelation{
+				{Type: "document", From: "viewer", To: "reader"},
+			},
+		}, WithTupleMigrationBatchSize(2))
+		require.NoError(t, err)
+
+		report, err := m.Migrate(ctx, "")
+		require.NoError(t, err)
+		require.Equal(t, 1, report.Migrated)
+		require.Equal(t, 2, report.Invalid)
+		require.Len(t, report.InvalidTuples, 2)
+		require.Equal(t, "document:1#viewer@user:*", report.InvalidTuples[0].From)
+		require.Equal(t, []string{"document:1#reader@user:*"}, report.InvalidTuples[0].To)
+		require.Equal(t, "type 'user:*' is not an allowed type restriction for 'document#reader'", report.InvalidTuples[0].Reason)
+
+		all := readAll(t, ds, storeID)
+		require.Contains(t, all, "document:1#reader@user:bob")
+		require.Contains(t, all, "document:1#viewer@user:*")
+		require.Contains(t, all, "document:1#viewer@team:eng#member")
+	})
+
+	t.Run("invalid_continuation_token", func(t *testing.T) {
+		ds, storeID := bootstrap(t)
+
+		m, err := NewTupleMigrator(ds, storeID, from, to, mapping)
+		require.NoError(t, err)
+
+		_, err = m.Migrate(ctx, "invalid")
+		require.ErrorIs(t, err, storage.ErrInvalidContinuationToken)
+	})
+
+	t.Run("invalid_mappings", func(t *testing.T) {
+		tests := map[string]*TupleMapping{
+			`undefined_type_moved`:           {MoveTypes: []MoveType{{From: "folder", To: "group"}}},
+			`undefined_type_moved_to`:        {MoveTypes: []MoveType{{From: "team", To: "folder"}}},
+			`type_moved_to_existing_type`:    {MoveTypes: []MoveType{{From: "team", To: "document"}}},
+			`undefined_relation_renamed`:     {RenameRelations: []RenameRelation{{Type: "document", From: "parent", To: "reader"}}},
+			`undefined_relation_renamed_to`:  {RenameRelations: []RenameRelation{{Type: "document", From: "viewer", To: "parent"}}},
+			`relation_split_into_nothing`:    {SplitRelations: []SplitRelation{{Type: "document", From: "editor"}}},
+			`relation_mapped_more_than_once`: {RenameRelations: []RenameRelation{{Type: "document", From: "editor", To: "writer"}}, SplitRelations: []SplitRelation{{Type: "document", From: "editor", To: []string{"writer"}}}},
+			`chained_relations`:              {RenameRelations: []RenameRelation{{Type: "document", From: "editor", To: "owner"}, {Type: "document", From: "owner", To: "writer"}}},
+		}
+
+		for name, mapping := range tests {
+			t.Run(name, func(t *testing.T) {
+				_, err := NewTupleMigrator(memory.New(), ulid.Make().String(), from, to, mapping)
+				require.ErrorIs(t, err, ErrInvalidTupleMapping)
+			})
+		}
+	})
+}
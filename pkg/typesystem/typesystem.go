@@ -768,7 +768,8 @@ func NewAndValidate(ctx context.Context, model *openfgav1.AuthorizationModel) (*
 		for _, relationName := range relationNames {
 			err := t.validateRelation(typeName, relationName, relationMap)
 			if err != nil {
-				return nil, err
+				module, file := moduleOf(typedef, relationName)
+				return nil, withModule(err, module, file)
 			}
 		}
 	}
@@ -1016,7 +1017,7 @@ func (t *TypeSystem) validateConditions() error {
 		}
 
 		if err := c.Compile(); err != nil {
-			return err
+			return withModule(err, c.GetMetadata().GetModule(), c.GetMetadata().GetSourceInfo().GetFile())
 		}
 	}
 	return nil
@@ -1105,6 +1106,115 @@ func (t *TypeSystem) HasCycle(objectType, relationName string) (bool, error) {
 	return t.hasCycle(objectType, relationName, relation.GetRewrite(), visited)
 }
 
+// TerminalUserTypes returns the sorted object types of the concrete users and typed wildcards that may
+// ultimately be related to the provided relation, following usersets, computed relations and tuple to
+// userset rewrites. The result over-approximates the possible user types: every operand of an intersection
+// is taken into account, while the subtracted branch of an exclusion is not.
+//
+// For example, given
+//
+//	type user
+//	type employee
+//	type group
+//	  relations
+//	    define member: [user, employee:*, group#member]
+//
+// the terminal user types of 'group#member' are 'employee' and 'user'.
+func (t *TypeSystem) TerminalUserTypes(objectType, relation string) ([]string, error) {
+	types := map[string]struct{}{}
+	if err := t.terminalUserTypes(objectType, relation, map[string]struct{}{}, types); err != nil {
+		return nil, err
+	}
+
+	terminalTypes := make([]string, 0, len(types))
+	for userType := range types {
+		terminalTypes = append(terminalTypes, userType)
+	}
+	sort.Strings(terminalTypes)
+
+	return terminalTypes, nil
+}
+
+func (t *TypeSystem) terminalUserTypes(objectType, relation string, visited, types map[string]struct{}) error {
+	key := tuple.ToObjectRelationString(objectType, relation)
+	if _, ok := visited[key]; ok {
+		return nil
+	}
+
+	visited[key] = struct{}{}
+
+	rel, err := t.GetRelation(objectType, relation)
+	if err != nil {
+		return err
+	}
+
+	return t.terminalUserTypesOfRewrite(objectType, rel, rel.GetRewrite(), visited, types)
+}
+
+func (t *TypeSystem) terminalUserTypesOfRewrite(
+	objectType string,
+	rel *openfgav1.Relation,
+	rewrite *openfgav1.Userset,
+	visited, types map[string]struct{},
+) error {
+	var children []*openfgav1.Userset
+
+	switch rw := rewrite.GetUserset().(type) {
+	case *openfgav1.Userset_This:
+		for _, typeRestriction := range rel.GetTypeInfo().GetDirectlyRelatedUserTypes() {
+			if typeRestriction.GetRelation() != "" {
+				if err := t.terminalUserTypes(typeRestriction.GetType(), typeRestriction.GetRelation(), visited, types); err != nil {
+					return err
+				}
+
+				continue
+			}
+
+			types[typeRestriction.GetType()] = struct{}{}
+		}
+
+		return nil
+	case *openfgav1.Userset_ComputedUserset:
+		return t.terminalUserTypes(objectType, rw.ComputedUserset.GetRelation(), visited, types)
+	case *openfgav1.Userset_TupleToUserset:
+		tuplesetRel, err := t.GetRelation(objectType, rw.TupleToUserset.GetTupleset().GetRelation())
+		if err != nil {
+			return err
+		}
+
+		computedRelation := rw.TupleToUserset.GetComputedUserset().GetRelation()
+		for _, relatedType := range tuplesetRel.GetTypeInfo().GetDirectlyRelatedUserTypes() {
+			err := t.terminalUserTypes(relatedType.GetType(), computedRelation, visited, types)
+			if err != nil {
+				if errors.Is(err, ErrRelationUndefined) {
+					continue
+				}
+
+				return err
+			}
+		}
+
+		return nil
+	case *openfgav1.Userset_Union:
+		children = rw.Union.GetChild()
+	case *openfgav1.Userset_Intersection:
+		children = rw.Intersection.GetChild()
+	case *openfgav1.Userset_Difference:
+		// members of the subtracted branch are never added
+		children = []*openfgav1.Userset{rw.Difference.GetBase()}
+	default:
+		return fmt.Errorf("unexpected userset rewrite type encountered")
+	}
+
+	for _, child := range children {
+		if err := t.terminalUserTypesOfRewrite(objectType, rel, child, visited, types); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // IsTuplesetRelation returns a boolean indicating if the provided relation is defined under a
 // TupleToUserset rewrite as a tupleset relation (i.e. the right hand side of a `X from Y`).
 func (t *TypeSystem) IsTuplesetRelation(objectType, relation string) (bool, error) {
@@ -3131,3 +3131,75 @@ func BenchmarkNewAndValidate(b *testing.B) {
 		require.NoError(b, err)
 	}
 }
+
+func TestTerminalUserTypes(t *testing.T) {
+	model := testutils.MustTransformDSLToProtoWithID(`
+		model
+			schema 1.1
+		type user
+		type employee
+		type bot
+
+		type group
+			relations
+				define member: [user, employee:*, group#member]
+				define blocked: [bot]
+				define allowed: member but not blocked
+
+		type folder
+			relations
+				define viewer: [bot, group#member]
+
+		type document
+			relations
+				define parent: [folder]
+				define owner: [user]
+				define editor: owner and viewer
+				define viewer: [user:*] or viewer from parent`)
+
+	tests := []struct {
+		name       string
+		objectType string
+		relation   string
+		expected   []string
+	}{
+		{
+			name:       "direct_with_wildcard_and_recursive_userset",
+			objectType: "group",
+			relation:   "member",
+			expected:   []string{"employee", "user"},
+		},
+		{
+			name:       "exclusion_ignores_subtracted_branch",
+			objectType: "group",
+			relation:   "allowed",
+			expected:   []string{"employee", "user"},
+		},
+		{
+			name:       "ttu",
+			objectType: "document",
+			relation:   "viewer",
+			expected:   []string{"bot", "employee", "user"},
+		},
+		{
+			name:       "intersection",
+			objectType: "document",
+			relation:   "editor",
+			expected:   []string{"bot", "employee", "user"},
+		},
+	}
+
+	typesys := New(model)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			terminalTypes, err := typesys.TerminalUserTypes(test.objectType, test.relation)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, terminalTypes)
+		})
+	}
+
+	t.Run("undefined_relation", func(t *testing.T) {
+		_, err := typesys.TerminalUserTypes("document", "undefined")
+		require.ErrorIs(t, err, ErrRelationUndefined)
+	})
+}
@@ -45,7 +45,6 @@ func testRunAll(t *testing.T, engine string) {
 	cfg := config.MustDefaultConfig()
 	cfg.Log.Level = "error"
 	cfg.Datastore.Engine = engine
-	cfg.Experimentals = []string{"enable-list-users"}
 
 	tests.StartServer(t, cfg)
 
@@ -67,7 +66,6 @@ func TestListUsersLogs(t *testing.T) {
 	cfg.Trace.Enabled = true
 	cfg.Trace.OTLP.Endpoint = localOTLPServerURL
 	cfg.Datastore.Engine = "memory"
-	cfg.Experimentals = append(cfg.Experimentals, "enable-list-users")
 
 	observerLogger, logs := observer.New(zap.DebugLevel)
 	serverCtx := &run.ServerContext{